@@ -0,0 +1,202 @@
+package backend
+
+import (
+	"log"
+	"os"
+	"os/user"
+	"sync"
+	"time"
+
+	"todo-list/backend/internal/audit"
+	"todo-list/backend/internal/models"
+)
+
+// activityLimit сколько последних записей журнала хранит TaskManager
+const activityLimit = 2000
+
+// FieldChange старое и новое значение поля задачи в виде текста
+type FieldChange = models.FieldChange
+
+// Activity запись журнала изменений задачи
+type Activity struct {
+	ID      int           `json:"id"`
+	TaskID  int           `json:"task_id"`
+	Title   string        `json:"title"`  // заголовок задачи на момент изменения
+	Action  string        `json:"action"` // create, update, complete, reopen, delete, restore, purge
+	Changes []FieldChange `json:"changes"`
+	Source  string        `json:"source"`          // app, api или caldav
+	Actor   string        `json:"actor,omitempty"` // кто внес изменение: пользователь ОС или API
+	At      time.Time     `json:"at"`
+}
+
+// GetTaskHistory возвращает журнал изменений задачи, новые записи первыми.
+// История доступна и после окончательного удаления задачи.
+func (a *App) GetTaskHistory(id int, limit int) []Activity {
+	activity, err := a.store.History(id, limit)
+	if err != nil {
+		log.Printf("Error loading history of task %d: %v", id, err)
+		return []Activity{}
+	}
+	return activity
+}
+
+// GetActivity возвращает ленту изменений всех задач, новые записи первыми;
+// beforeID > 0 продолжает ленту с записей старше beforeID
+func (a *App) GetActivity(beforeID, limit int) []Activity {
+	activity, err := a.store.Activity(beforeID, limit)
+	if err != nil {
+		log.Printf("Error loading activity: %v", err)
+		return []Activity{}
+	}
+	return activity
+}
+
+// activityFromModel преобразует models.Activity в Activity
+func activityFromModel(entry models.Activity) Activity {
+	return Activity{
+		ID:      int(entry.ID),
+		TaskID:  int(entry.TodoID),
+		Title:   entry.Title,
+		Action:  entry.Action,
+		Changes: entry.Changes,
+		Source:  entry.Source,
+		Actor:   entry.Actor,
+		At:      entry.CreatedAt,
+	}
+}
+
+// LocalActor возвращает имя пользователя ОС, от которого записываются изменения
+// приложения и командной строки
+var LocalActor = sync.OnceValue(func() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	if name := os.Getenv("USER"); name != "" {
+		return name
+	}
+	return os.Getenv("USERNAME")
+})
+
+// taskDiff сравнивает состояния задачи; nil - задачи не было (или больше нет)
+func taskDiff(before, after *Task) []FieldChange {
+	return audit.Diff(todoPtr(before), todoPtr(after))
+}
+
+// taskAction определяет действие по состояниям задачи до и после изменения
+func taskAction(before, after *Task) string {
+	return audit.Action(todoPtr(before), todoPtr(after))
+}
+
+func todoPtr(task *Task) *models.Todo {
+	if task == nil {
+		return nil
+	}
+	todo := todoFromTask(*task)
+	return &todo
+}
+
+// record добавляет запись в журнал TaskManager; старые записи сверх activityLimit отбрасываются.
+// Сохраняет файл вызывающий метод.
+func (tm *TaskManager) record(task Task, action string, changes []FieldChange) {
+	if changes == nil {
+		changes = []FieldChange{}
+	}
+	id := 1
+	if n := len(tm.activity); n > 0 {
+		id = tm.activity[n-1].ID + 1
+	}
+	tm.activity = append(tm.activity, Activity{
+		ID:      id,
+		TaskID:  task.ID,
+		Title:   task.Title,
+		Action:  action,
+		Changes: changes,
+		Source:  models.SourceApp,
+		Actor:   LocalActor(),
+		At:      time.Now(),
+	})
+	if extra := len(tm.activity) - activityLimit; extra > 0 {
+		tm.activity = append([]Activity{}, tm.activity[extra:]...)
+	}
+}
+
+// recordChange записывает разницу между состояниями задачи, если она есть
+func (tm *TaskManager) recordChange(before, after *Task) {
+	changes := taskDiff(before, after)
+	if len(changes) == 0 {
+		return
+	}
+	task := after
+	if task == nil {
+		task = before
+	}
+	tm.record(*task, taskAction(before, after), changes)
+}
+
+// History возвращает журнал изменений задачи, новые записи первыми
+func (tm *TaskManager) History(id int, limit int) ([]Activity, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return tm.listActivity(func(entry Activity) bool { return entry.TaskID == id }, limit), nil
+}
+
+// Activity возвращает ленту изменений всех задач; beforeID > 0 - записи старше beforeID
+func (tm *TaskManager) Activity(beforeID, limit int) ([]Activity, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	return tm.listActivity(func(entry Activity) bool {
+		return beforeID <= 0 || entry.ID < beforeID
+	}, limit), nil
+}
+
+// listActivity возвращает до limit подходящих записей журнала, новые первыми
+func (tm *TaskManager) listActivity(match func(Activity) bool, limit int) []Activity {
+	limit = pageLimit(limit)
+	result := []Activity{}
+	for i := len(tm.activity) - 1; i >= 0 && len(result) < limit; i-- {
+		if match(tm.activity[i]) {
+			result = append(result, tm.activity[i])
+		}
+	}
+	return result
+}
+
+// History возвращает журнал изменений задачи, новые записи первыми
+func (s *PostgresStore) History(id int, limit int) ([]Activity, error) {
+	if id <= 0 {
+		return []Activity{}, nil
+	}
+	found, err := s.service.Todo.GetTodoHistory(uint(id), limit)
+	if err != nil {
+		return nil, err
+	}
+	return activityFromModels(found), nil
+}
+
+// Activity возвращает ленту изменений всех задач; beforeID > 0 - записи старше beforeID
+func (s *PostgresStore) Activity(beforeID, limit int) ([]Activity, error) {
+	if beforeID < 0 {
+		beforeID = 0
+	}
+	found, err := s.service.Todo.GetActivity(uint(beforeID), limit)
+	if err != nil {
+		return nil, err
+	}
+	return activityFromModels(found), nil
+}
+
+func activityFromModels(entries []models.Activity) []Activity {
+	activity := make([]Activity, 0, len(entries))
+	for _, entry := range entries {
+		activity = append(activity, activityFromModel(entry))
+	}
+	return activity
+}
@@ -0,0 +1,19 @@
+package backend
+
+import "testing"
+
+func TestActivityRecordsLocalActor(t *testing.T) {
+	a, tm := newTestApp(t)
+	task := a.AddTask("task", "", "medium", "")
+
+	history, err := tm.History(task.ID, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 {
+		t.Fatalf("history = %+v, want one entry", history)
+	}
+	if history[0].Source != "app" || history[0].Actor != LocalActor() {
+		t.Errorf("entry source = %q, actor = %q, want app, %q", history[0].Source, history[0].Actor, LocalActor())
+	}
+}
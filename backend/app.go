@@ -1,11 +1,14 @@
 package backend
 
 import (
-	"encoding/json"
-	"fmt"
-	"os"
-	"path/filepath"
+	"context"
+	"errors"
+	"log"
+	"sync"
 	"time"
+
+	"todo-list/backend/config"
+	"todo-list/backend/internal/reminder"
 )
 
 // Task структура задачи
@@ -16,126 +19,317 @@ type Task struct {
 	Completed   bool      `json:"completed"`
 	Priority    string    `json:"priority"` // low, medium, high
 	DueDate     time.Time `json:"due_date"`
+	ParentID    int       `json:"parent_id"`  // 0 - задача верхнего уровня
+	Recurrence  string    `json:"recurrence"` // правило RRULE и EXDATE, пусто - не повторяется
+	Reminders   []int     `json:"reminders"`  // напоминания в минутах до срока, пусто - по умолчанию
+	Tags        []string  `json:"tags"`       // имена меток по алфавиту
 	CreatedAt   time.Time `json:"created_at"`
+	DeletedAt   time.Time `json:"deleted_at"` // время удаления в корзину, нулевое - задача не удалена
 }
 
 // App структура приложения
 type App struct {
-	taskManager *TaskManager
+	store     Store
+	reminders *reminder.Scheduler
+	retention time.Duration      // срок хранения задач в корзине
+	stop      context.CancelFunc // останавливает очистку корзины и слежение за файлом
+
+	mu          sync.Mutex // изменения задач и история отмены
+	history     *history
+	rec         *recorder // изменения текущей команды record
+	historyFile string    // файл истории; не используется, пока хранилище зашифровано
 }
 
-// NewApp создает новый экземпляр приложения
-func NewApp() *App {
-	return &App{}
+// NewApp создает новый экземпляр приложения поверх выбранного хранилища
+func NewApp(store Store, cfg *config.Config) *App {
+	a := &App{store: store, retention: cfg.TrashRetention, historyFile: cfg.HistoryFile}
+	if storeEncrypted(store) {
+		a.history = newHistory("")
+	} else {
+		a.history = newHistory(cfg.HistoryFile)
+	}
+	a.reminders = reminder.NewScheduler(cfg.RemindersFile, a.reminderItems)
+	return a
 }
 
-// TaskManager управляет задачами
-type TaskManager struct {
-	tasks    []Task
-	nextID   int
-	filename string
-}
+// Startup запускает фоновые задачи приложения: планировщик напоминаний
+// отправляет события ReminderEvent в окно Wails, корзина очищается от старых задач,
+// а об изменениях файла задач извне приходят события TasksChangedEvent и StoreLockedEvent
+func (a *App) Startup(ctx context.Context) {
+	a.startReminders(ctx)
 
-// NewTaskManager создает новый менеджер задач
-func NewTaskManager() *TaskManager {
-	homeDir, _ := os.UserHomeDir()
-	filename := filepath.Join(homeDir, ".todo-list.json")
+	ctx, a.stop = context.WithCancel(ctx)
+	go RunTrashPurge(ctx, a.purgeTrash, a.retention)
+	a.startWatch(ctx)
+}
 
-	tm := &TaskManager{
-		tasks:    []Task{},
-		nextID:   1,
-		filename: filename,
+// Shutdown останавливает фоновые задачи и закрывает хранилище при завершении приложения
+func (a *App) Shutdown(ctx context.Context) {
+	a.reminders.Stop()
+	if a.stop != nil {
+		a.stop()
 	}
+	if err := a.store.Close(); err != nil {
+		log.Printf("Error closing store: %v", err)
+	}
+}
 
-	// Загружаем существующие задачи
-	tm.loadTasks()
-
-	return tm
+// listTasks возвращает все задачи из хранилища, логируя ошибки
+func (a *App) listTasks() []Task {
+	tasks, err := a.store.List()
+	if err != nil {
+		log.Printf("Error loading tasks: %v", err)
+		return []Task{}
+	}
+	return tasks
 }
 
 // GetTasks возвращает все задачи
 func (a *App) GetTasks() []Task {
-	if a.taskManager == nil {
-		a.taskManager = NewTaskManager()
+	return a.listTasks()
+}
+
+// GetTasksPage возвращает страницу задач с фильтром по статусу (all, active, completed).
+// Пустой cursor - первая страница; limit <= 0 - размер страницы по умолчанию.
+func (a *App) GetTasksPage(statusFilter, cursor string, limit int) TaskPage {
+	var completed *bool
+	switch statusFilter {
+	case "active":
+		value := false
+		completed = &value
+	case "completed":
+		value := true
+		completed = &value
 	}
-	return a.taskManager.tasks
+
+	page, err := a.store.ListPage(completed, cursor, limit)
+	if err != nil {
+		log.Printf("Error loading tasks page: %v", err)
+		return TaskPage{Tasks: []Task{}}
+	}
+	return page
+}
+
+// SearchTasks ищет задачи по заголовку и описанию.
+// Совпадения в TitleHighlight и DescriptionHighlight обернуты в <mark>.
+func (a *App) SearchTasks(query string, limit int) []SearchResult {
+	results, err := a.store.Search(query, limit)
+	if err != nil {
+		log.Printf("Error searching tasks: %v", err)
+		return []SearchResult{}
+	}
+	return results
 }
 
 // AddTask добавляет новую задачу
 func (a *App) AddTask(title, description, priority string, dueDate string) Task {
-	if a.taskManager == nil {
-		a.taskManager = NewTaskManager()
-	}
+	var task Task
+	a.record("Добавление задачи", func() bool {
+		task = a.addTask(0, title, description, priority, dueDate, "")
+		return task.ID != 0
+	})
+	return task
+}
+
+// AddSubtask добавляет подзадачу к задаче parentID
+func (a *App) AddSubtask(parentID int, title, description, priority string, dueDate string) Task {
+	var task Task
+	a.record("Добавление подзадачи", func() bool {
+		if _, err := a.store.Get(parentID); err != nil {
+			log.Printf("Error loading parent task %d: %v", parentID, err)
+			return false
+		}
+		task = a.addTask(parentID, title, description, priority, dueDate, "")
+		return task.ID != 0
+	})
+	return task
+}
+
+// dueLayout формат срока, который принимают методы App
+const dueLayout = "2006-01-02T15:04"
 
+// parseDue разбирает срок в местном времени: с ним сравнивают time.Now() напоминания,
+// и так же срок читают todo.txt, iCalendar и импорт
+func parseDue(value string) (time.Time, error) {
+	return time.ParseInLocation(dueLayout, value, time.Local)
+}
+
+// addTask создает задачу; открытая подзадача открывает и всех своих предков
+func (a *App) addTask(parentID int, title, description, priority string, dueDate string, rule string) Task {
 	if title == "" {
 		return Task{} // Валидация на пустой ввод
 	}
 
 	var due time.Time
 	if dueDate != "" {
-		due, _ = time.Parse("2006-01-02T15:04", dueDate)
+		due, _ = parseDue(dueDate)
+	}
+
+	rule, err := normalizeRule(rule, due)
+	if err != nil {
+		log.Printf("Error creating task: %v", err)
+		return Task{}
 	}
 
 	task := Task{
-		ID:          a.taskManager.nextID,
 		Title:       title,
 		Description: description,
 		Priority:    priority,
 		DueDate:     due,
+		ParentID:    parentID,
+		Recurrence:  rule,
 		CreatedAt:   time.Now(),
 		Completed:   false,
 	}
 
-	a.taskManager.tasks = append(a.taskManager.tasks, task)
-	a.taskManager.nextID++
+	created, err := a.writer().Create(task)
+	if err != nil {
+		log.Printf("Error creating task: %v", err)
+		return Task{}
+	}
 
-	// Сохраняем изменения
-	a.taskManager.saveTasks()
+	if parentID != 0 {
+		a.setCompleted(ancestorsOf(a.listTasks(), created.ID), false)
+	}
+	return created
+}
 
-	return task
+// UpdateTask меняет название, описание, приоритет и срок задачи; пустой dueDate снимает срок.
+// У повторяющейся задачи срок обязателен.
+func (a *App) UpdateTask(id int, title, description, priority string, dueDate string) bool {
+	return a.record("Изменение задачи", func() bool { return a.updateTask(id, title, description, priority, dueDate) })
 }
 
-// DeleteTask удаляет задачу по ID
-func (a *App) DeleteTask(id int) bool {
-	if a.taskManager == nil {
+func (a *App) updateTask(id int, title, description, priority string, dueDate string) bool {
+	if title == "" {
 		return false
 	}
 
-	for i, task := range a.taskManager.tasks {
-		if task.ID == id {
-			a.taskManager.tasks = append(a.taskManager.tasks[:i], a.taskManager.tasks[i+1:]...)
-			a.taskManager.saveTasks()
-			return true
+	var due time.Time
+	if dueDate != "" {
+		var err error
+		if due, err = parseDue(dueDate); err != nil {
+			log.Printf("Error updating task %d: %v", id, err)
+			return false
+		}
+	}
+
+	return a.modify(id, func(task *Task) error {
+		var err error
+		if task.Recurrence, err = normalizeRule(task.Recurrence, due); err != nil {
+			return err
+		}
+		task.Title = title
+		task.Description = description
+		task.Priority = priority
+		task.DueDate = due
+		return nil
+	})
+}
+
+// modify меняет задачу через Store.Modify и логирует ошибки, кроме отсутствия задачи
+func (a *App) modify(id int, fn func(task *Task) error) bool {
+	if err := a.writer().Modify(id, fn); err != nil {
+		if !errors.Is(err, ErrTaskNotFound) && !errors.Is(err, errNotRecurring) {
+			log.Printf("Error updating task %d: %v", id, err)
 		}
+		return false
 	}
-	return false
+	return true
 }
 
-// ToggleTask переключает состояние выполнения задачи
+// DeleteTask переносит задачу вместе со всеми подзадачами в корзину
+func (a *App) DeleteTask(id int) bool {
+	return a.record("Удаление задачи", func() bool { return a.deleteTask(id) })
+}
+
+// DeleteTasks переносит несколько задач в корзину одним шагом отмены
+func (a *App) DeleteTasks(ids []int) bool {
+	return a.record("Удаление задач", func() bool {
+		ok := true
+		for _, id := range ids {
+			ok = a.deleteTask(id) && ok
+		}
+		return ok
+	})
+}
+
+func (a *App) deleteTask(id int) bool {
+	// Хранилище переносит в корзину и все подзадачи
+	if err := a.writer().Delete(id); err != nil {
+		if !errors.Is(err, ErrTaskNotFound) {
+			log.Printf("Error deleting task %d: %v", id, err)
+		}
+		return false
+	}
+	return true
+}
+
+// ToggleTask переключает состояние выполнения задачи.
+// Выполненная задача закрывает все подзадачи, открытая - открывает всех предков.
+// Выполнение повторяющейся задачи создает следующее повторение.
 func (a *App) ToggleTask(id int) bool {
-	if a.taskManager == nil {
+	return a.record("Изменение статуса задачи", func() bool { return a.toggleTask(id) })
+}
+
+// SetTasksCompleted отмечает несколько задач выполненными или открытыми одним шагом отмены
+func (a *App) SetTasksCompleted(ids []int, completed bool) bool {
+	return a.record("Изменение статуса задач", func() bool {
+		ok := true
+		for _, id := range ids {
+			task, err := a.store.Get(id)
+			if err != nil {
+				log.Printf("Error loading task %d: %v", id, err)
+				ok = false
+				continue
+			}
+			if task.Completed != completed {
+				ok = a.toggleTask(id) && ok
+			}
+		}
+		return ok
+	})
+}
+
+func (a *App) toggleTask(id int) bool {
+	var next Task
+	hasNext, completed := false, false
+	ok := a.modify(id, func(task *Task) error {
+		task.Completed = !task.Completed
+		completed = task.Completed
+		if task.Completed && task.Recurrence != "" {
+			next, hasNext = nextOccurrence(*task)
+			task.Recurrence = "" // серия продолжается в следующем повторении
+		}
+		return nil
+	})
+	if !ok {
 		return false
 	}
 
-	for i, task := range a.taskManager.tasks {
-		if task.ID == id {
-			a.taskManager.tasks[i].Completed = !task.Completed
-			a.taskManager.saveTasks()
-			return true
+	if hasNext {
+		created, err := a.writer().Create(next)
+		if err != nil {
+			log.Printf("Error creating next occurrence of task %d: %v", id, err)
+		} else if created.ParentID != 0 {
+			a.setCompleted(ancestorsOf(a.listTasks(), created.ID), false)
 		}
 	}
-	return false
+
+	tasks := a.listTasks()
+	if completed {
+		a.setCompleted(descendantsOf(tasks, id), true)
+	} else {
+		a.setCompleted(ancestorsOf(tasks, id), false)
+	}
+	return true
 }
 
 // GetFilteredTasks возвращает отфильтрованные задачи
 func (a *App) GetFilteredTasks(filter string) []Task {
-	if a.taskManager == nil {
-		a.taskManager = NewTaskManager()
-	}
-
 	var filtered []Task
 
-	for _, task := range a.taskManager.tasks {
+	for _, task := range a.listTasks() {
 		switch filter {
 		case "active":
 			if !task.Completed {
@@ -155,16 +349,12 @@ func (a *App) GetFilteredTasks(filter string) []Task {
 
 // GetTasksByDateFilter возвращает задачи по фильтру даты
 func (a *App) GetTasksByDateFilter(filter string) []Task {
-	if a.taskManager == nil {
-		a.taskManager = NewTaskManager()
-	}
-
 	var filtered []Task
 	now := time.Now()
 	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
 	weekFromNow := today.AddDate(0, 0, 7)
 
-	for _, task := range a.taskManager.tasks {
+	for _, task := range a.listTasks() {
 		if task.DueDate.IsZero() {
 			continue // Пропускаем задачи без даты
 		}
@@ -194,12 +384,7 @@ func (a *App) GetTasksByDateFilter(filter string) []Task {
 
 // GetSortedTasks возвращает отсортированные задачи
 func (a *App) GetSortedTasks(sortBy string, ascending bool) []Task {
-	if a.taskManager == nil {
-		a.taskManager = NewTaskManager()
-	}
-
-	tasks := make([]Task, len(a.taskManager.tasks))
-	copy(tasks, a.taskManager.tasks)
+	tasks := a.listTasks()
 
 	switch sortBy {
 	case "date":
@@ -264,13 +449,15 @@ func (a *App) GetSortedTasks(sortBy string, ascending bool) []Task {
 
 // GetCombinedFilteredTasks возвращает задачи с комбинированными фильтрами
 func (a *App) GetCombinedFilteredTasks(statusFilter, dateFilter, sortBy string, ascending bool) []Task {
-	if a.taskManager == nil {
-		a.taskManager = NewTaskManager()
-	}
+	return FilterTasks(a.listTasks(), statusFilter, dateFilter, sortBy, ascending)
+}
 
+// FilterTasks применяет к задачам фильтр по статусу (all, active, completed), по сроку
+// (all, today, week, overdue) и сортировку (date, priority); ее же использует командная строка
+func FilterTasks(tasks []Task, statusFilter, dateFilter, sortBy string, ascending bool) []Task {
 	// Сначала применяем фильтр по статусу
 	var filtered []Task
-	for _, task := range a.taskManager.tasks {
+	for _, task := range tasks {
 		switch statusFilter {
 		case "active":
 			if !task.Completed {
@@ -362,44 +549,3 @@ func (a *App) GetCombinedFilteredTasks(statusFilter, dateFilter, sortBy string,
 
 	return filtered
 }
-
-// loadTasks загружает задачи из файла
-func (tm *TaskManager) loadTasks() {
-	data, err := os.ReadFile(tm.filename)
-	if err != nil {
-		return // Файл не существует или ошибка чтения
-	}
-
-	var savedData struct {
-		Tasks  []Task `json:"tasks"`
-		NextID int    `json:"next_id"`
-	}
-
-	if err := json.Unmarshal(data, &savedData); err != nil {
-		return
-	}
-
-	tm.tasks = savedData.Tasks
-	tm.nextID = savedData.NextID
-}
-
-// saveTasks сохраняет задачи в файл
-func (tm *TaskManager) saveTasks() {
-	data := struct {
-		Tasks  []Task `json:"tasks"`
-		NextID int    `json:"next_id"`
-	}{
-		Tasks:  tm.tasks,
-		NextID: tm.nextID,
-	}
-
-	jsonData, err := json.MarshalIndent(data, "", "  ")
-	if err != nil {
-		fmt.Printf("Error marshaling tasks: %v\n", err)
-		return
-	}
-
-	if err := os.WriteFile(tm.filename, jsonData, 0644); err != nil {
-		fmt.Printf("Error saving tasks: %v\n", err)
-	}
-}
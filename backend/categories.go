@@ -0,0 +1,52 @@
+package backend
+
+import "errors"
+
+// ErrNoCategories возвращается хранилищами без категорий: JSON-файлом и todo.txt
+var ErrNoCategories = errors.New("категории есть только в хранилище PostgreSQL")
+
+// Category категория задач с числом задач в ней
+type Category struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Count int    `json:"count"`
+}
+
+// Categories возвращает категории хранилища по имени; ErrNoCategories, если их там нет
+func Categories(store Store) ([]Category, error) {
+	pg, ok := store.(*PostgresStore)
+	if !ok {
+		return nil, ErrNoCategories
+	}
+	return pg.categories()
+}
+
+// categories возвращает категории с числом задач, не считая задачи из корзины
+func (s *PostgresStore) categories() ([]Category, error) {
+	categories, err := s.service.Category.GetAllCategories()
+	if err != nil {
+		return nil, err
+	}
+	todos, err := s.service.Todo.GetAllTodos()
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[uint]int)
+	for _, todo := range todos {
+		if todo.CategoryID != nil {
+			counts[*todo.CategoryID]++
+		}
+	}
+
+	result := make([]Category, 0, len(categories))
+	for _, category := range categories {
+		result = append(result, Category{
+			ID:    int(category.ID),
+			Name:  category.Name,
+			Color: category.Color,
+			Count: counts[category.ID],
+		})
+	}
+	return result, nil
+}
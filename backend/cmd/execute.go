@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"embed"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"todo-list/backend"
+)
+
+// Коды завершения команд
+const (
+	exitOK       = 0
+	exitError    = 1 // ошибка выполнения
+	exitUsage    = 2 // неверные аргументы или флаги
+	exitNotFound = 3 // задача не найдена
+)
+
+// usageError ошибка в аргументах командной строки
+type usageError struct {
+	msg string
+}
+
+func (e *usageError) Error() string { return e.msg }
+
+func usagef(format string, args ...interface{}) error {
+	return &usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// Execute разбирает аргументы командной строки и запускает нужную команду.
+// Без аргументов запускается графическое приложение.
+func Execute(assets embed.FS, args []string) int {
+	if len(args) == 0 {
+		Start(assets)
+		return exitOK
+	}
+
+	var err error
+	switch args[0] {
+	case "migrate":
+		err = Migrate(args[1:])
+	case "migrate-json":
+		err = MigrateJSON(args[1:])
+	case "serve":
+		err = Serve(args[1:])
+	case "export":
+		err = Export(args[1:])
+	case "import":
+		err = Import(args[1:])
+	case "add":
+		err = Add(args[1:])
+	case "list", "ls":
+		err = List(args[1:])
+	case "done":
+		err = Done(args[1:])
+	case "edit":
+		err = Edit(args[1:])
+	case "rm":
+		err = Remove(args[1:])
+	case "categories":
+		err = Categories(args[1:])
+	case "help", "-h", "--help":
+		printUsage()
+		return exitOK
+	default:
+		printUsage()
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", args[0])
+		return exitUsage
+	}
+
+	code := exitCode(err)
+	if code != exitOK {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+	}
+	return code
+}
+
+// exitCode выбирает код завершения по ошибке команды
+func exitCode(err error) int {
+	var usage *usageError
+	switch {
+	case err == nil, errors.Is(err, flag.ErrHelp):
+		return exitOK
+	case errors.As(err, &usage):
+		return exitUsage
+	case errors.Is(err, backend.ErrTaskNotFound):
+		return exitNotFound
+	}
+	return exitError
+}
+
+// parseFlags разбирает флаги вперемешку с позиционными аргументами, чтобы работало
+// и "add -p high Купить хлеб", и "add Купить хлеб -p high". После "--" все аргументы
+// позиционные.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var rest []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			if errors.Is(err, flag.ErrHelp) {
+				return nil, err
+			}
+			return nil, usagef("%v", err)
+		}
+		// flag останавливается на первом позиционном аргументе или после "--"
+		consumed := len(args) - fs.NArg()
+		if consumed > 0 && args[consumed-1] == "--" {
+			return append(rest, fs.Args()...), nil
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return rest, nil
+		}
+		rest = append(rest, args[0])
+		args = args[1:]
+	}
+}
+
+// printUsage выводит список доступных команд
+func printUsage() {
+	fmt.Fprintln(os.Stderr, `usage: todo-list [command] [flags]
+
+commands:
+  (none)        start the desktop application
+  serve         run the REST API as a headless daemon (no window)
+  migrate       apply, roll back or inspect database migrations
+  migrate-json  copy tasks from the JSON file store into PostgreSQL
+  export        write all tasks as JSON, CSV, Markdown, org-mode, todo.txt or iCalendar
+  import        load tasks from a JSON, CSV, Markdown, org-mode, todo.txt or iCalendar file
+  add           add a task
+  list          list tasks with the same filters as the desktop application
+  done          mark tasks as completed (-undo to reopen)
+  edit          change a task's title, description, priority, due date, parent, tags or recurrence
+  rm            move tasks and their subtasks to the trash
+  categories    list categories (PostgreSQL storage only)
+  help          show this message
+
+Task commands use the same storage as the desktop application.
+Run "todo-list COMMAND -h" for the flags of a command.
+
+exit codes: 0 success, 1 error, 2 invalid arguments, 3 task not found`)
+}
@@ -0,0 +1,213 @@
+package cmd
+
+import (
+	"database/sql"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"todo-list/backend"
+	"todo-list/backend/config"
+	"todo-list/backend/database"
+	"todo-list/backend/internal/models"
+)
+
+// migrateOptions разобранные аргументы команды migrate
+type migrateOptions struct {
+	action string
+	dryRun bool
+	steps  int
+	target int
+}
+
+// parseMigrateArgs разбирает аргументы migrate; флаги допускаются и после действия
+func parseMigrateArgs(args []string) (migrateOptions, error) {
+	opts := migrateOptions{action: "up"}
+	fs := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "print SQL instead of executing it")
+	fs.IntVar(&opts.steps, "steps", 1, "number of migrations to roll back (down)")
+	fs.IntVar(&opts.target, "to", 0, "apply migrations up to this version (up)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list migrate [flags] up|down|status")
+		fs.PrintDefaults()
+	}
+
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return opts, err
+	}
+	if len(rest) > 1 {
+		return opts, usagef("unexpected arguments: %s", strings.Join(rest[1:], " "))
+	}
+	if len(rest) == 1 {
+		opts.action = rest[0]
+	}
+	switch opts.action {
+	case "up", "down", "status":
+	default:
+		return opts, usagef("unknown migrate action %q", opts.action)
+	}
+	return opts, nil
+}
+
+// Migrate выполняет команду migrate: up, down, status
+func Migrate(args []string) error {
+	opts, err := parseMigrateArgs(args)
+	if err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db.DB)
+	if err != nil {
+		return err
+	}
+	migrator.DryRun = opts.dryRun
+
+	switch opts.action {
+	case "up":
+		applied, err := migrator.UpTo(opts.target)
+		if err != nil {
+			return err
+		}
+		printMigrations(os.Stdout, "applied", applied, opts.dryRun)
+	case "down":
+		reverted, err := migrator.Down(opts.steps)
+		if err != nil {
+			return err
+		}
+		printMigrations(os.Stdout, "reverted", reverted, opts.dryRun)
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		printStatus(os.Stdout, statuses)
+	}
+	return nil
+}
+
+// printMigrations выводит список обработанных миграций
+func printMigrations(w io.Writer, verb string, migrations []database.Migration, dryRun bool) {
+	if dryRun {
+		verb = "would be " + verb
+	}
+	if len(migrations) == 0 {
+		fmt.Fprintln(w, "nothing to do")
+		return
+	}
+	for _, m := range migrations {
+		fmt.Fprintf(w, "%s %04d_%s\n", verb, m.Version, m.Name)
+	}
+}
+
+// printStatus выводит таблицу состояния миграций
+func printStatus(w io.Writer, statuses []database.MigrationStatus) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VERSION\tNAME\tSTATUS\tAPPLIED AT")
+	for _, s := range statuses {
+		state, at := "pending", "-"
+		if s.Applied {
+			state = "applied"
+			at = s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(tw, "%04d\t%s\t%s\t%s\n", s.Version, s.Name, state, at)
+	}
+	tw.Flush()
+}
+
+// MigrateJSON выполняет команду migrate-json: переносит задачи из JSON-файла приложения
+// в базу данных PostgreSQL
+func MigrateJSON(args []string) error {
+	fs := flag.NewFlagSet("migrate-json", flag.ContinueOnError)
+	file := fs.String("file", "", "JSON task file (default: TODO_FILE, else ~/.todo-list.json)")
+	dryRun := fs.Bool("dry-run", false, "show what would be migrated without changing the database")
+	out := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list migrate-json [flags]")
+		fmt.Fprintln(fs.Output(), "An encrypted task file is read with the passphrase from TODO_PASSPHRASE.")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+	filename := *file
+	if filename == "" && cfg.Storage == config.StorageJSON {
+		filename = cfg.DataFile
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	if *dryRun {
+		// Пробный перенос не меняет базу, в том числе схему: она должна быть актуальной
+		if err := checkMigrated(db.DB); err != nil {
+			return err
+		}
+	} else if err := database.Migrate(db.DB); err != nil {
+		return err
+	}
+
+	report, err := backend.MigrateJSONFile(db.DB, filename, cfg.Passphrase, *dryRun)
+	if err != nil {
+		return err
+	}
+	if out.json {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	err = writeItems(os.Stdout, out, report.Items, "ACTION\tID\tTITLE", func(item models.FileImportItem) string {
+		return fmt.Sprintf("%s\t%d\t%s", item.Action, item.TodoID, item.Title)
+	})
+	if err != nil || out.tmpl != nil {
+		// Шаблон выводит только задачи, без итоговой строки
+		return err
+	}
+
+	prefix := ""
+	if report.DryRun {
+		prefix = "dry run: "
+	}
+	fmt.Printf("%s%d created, %d skipped (already migrated), %d tags created\n",
+		prefix, report.Created, report.Skipped, report.TagsCreated)
+	return nil
+}
+
+// checkMigrated проверяет, что к базе применены все миграции
+func checkMigrated(db *sql.DB) error {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+	pending, err := migrator.Pending()
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("database schema is out of date (%d pending migrations, starting with %04d_%s): run \"todo-list migrate up\" first",
+			len(pending), pending[0].Version, pending[0].Name)
+	}
+	return nil
+}
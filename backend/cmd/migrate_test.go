@@ -0,0 +1,30 @@
+package cmd
+
+import "testing"
+
+func TestParseMigrateArgs(t *testing.T) {
+	tests := []struct {
+		args []string
+		want migrateOptions
+	}{
+		{nil, migrateOptions{action: "up", steps: 1}},
+		{[]string{"-dry-run", "up"}, migrateOptions{action: "up", dryRun: true, steps: 1}},
+		// Флаг после действия не должен теряться: иначе пробный запуск применил бы миграции
+		{[]string{"up", "-dry-run"}, migrateOptions{action: "up", dryRun: true, steps: 1}},
+		{[]string{"up", "-to", "3"}, migrateOptions{action: "up", steps: 1, target: 3}},
+		{[]string{"down", "-steps", "2", "-dry-run"}, migrateOptions{action: "down", dryRun: true, steps: 2}},
+		{[]string{"status"}, migrateOptions{action: "status", steps: 1}},
+	}
+	for _, tt := range tests {
+		got, err := parseMigrateArgs(tt.args)
+		if err != nil || got != tt.want {
+			t.Errorf("parseMigrateArgs(%q) = %+v, %v; want %+v", tt.args, got, err, tt.want)
+		}
+	}
+
+	for _, args := range [][]string{{"sideways"}, {"up", "down"}, {"up", "-dry"}} {
+		if _, err := parseMigrateArgs(args); exitCode(err) != exitUsage {
+			t.Errorf("parseMigrateArgs(%q) = %v, want a usage error", args, err)
+		}
+	}
+}
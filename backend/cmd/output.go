@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+
+	"todo-list/backend"
+)
+
+// output флаги вывода команд: JSON, шаблон Go или таблица по умолчанию
+type output struct {
+	json   bool
+	format string
+	tmpl   *template.Template
+}
+
+func addOutputFlags(fs *flag.FlagSet) *output {
+	out := &output{}
+	fs.BoolVar(&out.json, "json", false, "print JSON")
+	fs.StringVar(&out.format, "format", "", `print each item with a Go template, e.g. '{{.ID}} {{.Title}} {{join .Tags ","}}'`)
+	return out
+}
+
+// templateFuncs функции, доступные в шаблонах -format
+var templateFuncs = template.FuncMap{
+	"join": func(items []string, sep string) string { return strings.Join(items, sep) },
+	"date": formatDue,
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// check разбирает шаблон до того, как команда что-то изменит
+func (o *output) check() error {
+	if o.json && o.format != "" {
+		return usagef("-json and -format cannot be used together")
+	}
+	if o.format == "" {
+		return nil
+	}
+	tmpl, err := template.New("format").Funcs(templateFuncs).Parse(o.format)
+	if err != nil {
+		return usagef("invalid -format: %v", err)
+	}
+	o.tmpl = tmpl
+	return nil
+}
+
+// writeItems выводит элементы JSON-массивом, шаблоном по строке на элемент
+// или таблицей с заголовком header и строками row
+func writeItems[T any](w io.Writer, out *output, items []T, header string, row func(T) string) error {
+	if items == nil {
+		items = []T{}
+	}
+	switch {
+	case out.json:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+	case out.tmpl != nil:
+		for _, item := range items {
+			if err := out.tmpl.Execute(w, item); err != nil {
+				return err
+			}
+			if _, err := io.WriteString(w, "\n"); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, header)
+	for _, item := range items {
+		fmt.Fprintln(tw, row(item))
+	}
+	return tw.Flush()
+}
+
+// writeTasks выводит задачи; в таблице выполненные отмечены "x"
+func writeTasks(w io.Writer, out *output, tasks []backend.Task) error {
+	return writeItems(w, out, tasks, "ID\tDONE\tPRIORITY\tDUE\tTITLE\tTAGS", func(task backend.Task) string {
+		done := ""
+		if task.Completed {
+			done = "x"
+		}
+		return fmt.Sprintf("%d\t%s\t%s\t%s\t%s\t%s", task.ID, done, task.Priority,
+			formatDue(task.DueDate), task.Title, strings.Join(task.Tags, " "))
+	})
+}
+
+// formatDue записывает срок датой, а если у него есть время - датой и временем
+func formatDue(t time.Time) string {
+	switch {
+	case t.IsZero():
+		return ""
+	case t.Hour() == 0 && t.Minute() == 0:
+		return t.Format("2006-01-02")
+	}
+	return t.Format("2006-01-02 15:04")
+}
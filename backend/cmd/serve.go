@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"todo-list/backend"
+	"todo-list/backend/config"
+	"todo-list/backend/internal/handler"
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+	"todo-list/backend/internal/server"
+	"todo-list/backend/internal/service"
+)
+
+// shutdownTimeout время на завершение активных HTTP-запросов при остановке
+const shutdownTimeout = 10 * time.Second
+
+// Serve запускает REST API без графического интерфейса и работает до SIGINT/SIGTERM
+func Serve(args []string) error {
+	cfg := config.LoadConfig()
+
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	fs.StringVar(&cfg.Host, "host", cfg.Host, "address to listen on")
+	fs.StringVar(&cfg.Port, "port", cfg.Port, "port to listen on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if cfg.Storage != config.StoragePostgres {
+		return errors.New("HTTP API requires TODO_STORAGE=postgres")
+	}
+
+	store, err := backend.NewPostgresStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	srv := newAPIServer(cfg, store.DB())
+	if err := srv.Start(); err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go backend.RunTrashPurge(ctx, store.Purge, cfg.TrashRetention)
+
+	select {
+	case <-ctx.Done():
+		log.Println("received shutdown signal")
+	case err := <-srv.Done():
+		return err
+	}
+
+	return shutdownServer(srv)
+}
+
+// newAPIServer собирает REST API поверх подключения к базе данных
+func newAPIServer(cfg *config.Config, db *sql.DB) *server.Server {
+	auth := handler.Credentials{User: cfg.APIUser, Password: cfg.APIPassword}
+	if !auth.Enabled() && !config.IsLoopback(cfg.Host) {
+		log.Printf("APP_PASSWORD is not set: the API on %s answers only localhost, calendar feeds are public", cfg.Addr())
+	}
+
+	// Изменения через API записываются от имени пользователя Basic-авторизации
+	actor := ""
+	if auth.Enabled() {
+		actor = auth.User
+	}
+	repo := repository.NewSourceRepository(db, models.SourceAPI, actor)
+	taskHandler := handler.NewTaskHandler(service.NewTaskServiceHandler(repo))
+	tagHandler := handler.NewTagHandler(service.NewTagService(repo))
+	categoryHandler := handler.NewCategoryHandler(service.NewCategoryService(repo))
+	transferHandler := handler.NewTransferHandler(service.NewTransferService(repo))
+	feedHandler := handler.NewFeedHandler(service.NewFeedService(repo))
+	// Изменения из календарей попадают в журнал со своим источником
+	caldavHandler := handler.NewCalDAVHandler(service.NewCalDAVService(repository.NewSourceRepository(db, models.SourceCalDAV, actor)))
+	return server.New(cfg.Addr(), handler.NewRouter(auth, taskHandler, tagHandler, categoryHandler, transferHandler, feedHandler, caldavHandler))
+}
+
+// shutdownServer останавливает сервер с ограничением по времени
+func shutdownServer(srv *server.Server) error {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return srv.Shutdown(ctx)
+}
@@ -3,16 +3,23 @@ package cmd
 import (
 	"context"
 	"embed"
+	"fmt"
 	"log"
 	"os"
 	"runtime/debug"
 	"todo-list/backend"
+	"todo-list/backend/config"
+	"todo-list/backend/internal/server"
 
 	"github.com/wailsapp/wails/v2"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/options/assetserver"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
 )
 
+// windowTitle заголовок окна приложения
+const windowTitle = "To-Do List (Wails)"
+
 func Start(assets embed.FS) {
 	// в самом начале main() / Start()
 	f, _ := os.OpenFile("app.log", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
@@ -27,19 +34,50 @@ func Start(assets embed.FS) {
 		}
 	}()
 
-	// Создаем простое приложение без базы данных
-	app := backend.NewApp()
+	// Выбираем хранилище задач по конфигурации
+	cfg := config.LoadConfig()
+	store, err := backend.NewStore(cfg)
+	if err != nil {
+		log.Printf("failed to open %s storage: %v", cfg.Storage, err)
+		showStartupError(assets, fmt.Sprintf("Не удалось открыть хранилище задач (%s):\n%v", cfg.Storage, err))
+		os.Exit(1)
+	}
+	log.Printf("using %s storage", cfg.Storage)
+
+	app := backend.NewApp(store, cfg)
+
+	// REST API рядом с окном, если включен и доступна база данных
+	var srv *server.Server
+	if cfg.HTTPEnabled {
+		if pg, ok := store.(*backend.PostgresStore); ok {
+			srv = newAPIServer(cfg, pg.DB())
+			if err := srv.Start(); err != nil {
+				log.Printf("failed to start HTTP API: %v", err)
+				srv = nil
+			}
+		} else {
+			log.Println("HTTP API requires TODO_STORAGE=postgres, skipping")
+		}
+	}
 
 	// Запуск Wails-приложения
-	err := wails.Run(&options.App{
-		Title:  "To-Do List (Wails)",
+	err = wails.Run(&options.App{
+		Title:  windowTitle,
 		Width:  1024,
 		Height: 700,
 		AssetServer: &assetserver.Options{
 			Assets: assets,
 		},
 		OnStartup: func(ctx context.Context) {
-			// Инициализация при необходимости
+			app.Startup(ctx)
+		},
+		OnShutdown: func(ctx context.Context) {
+			if srv != nil {
+				if err := shutdownServer(srv); err != nil {
+					log.Printf("HTTP API shutdown error: %v", err)
+				}
+			}
+			app.Shutdown(ctx)
 		},
 		Bind: []interface{}{
 			app,
@@ -49,3 +87,31 @@ func Start(assets embed.FS) {
 		log.Fatal(err)
 	}
 }
+
+// showStartupError открывает окно только для того, чтобы показать message в диалоге
+// ошибки, и закрывает приложение, когда диалог закрыт. Так причина, по которой
+// приложение не запустилось, видна пользователю, а не только в app.log.
+func showStartupError(assets embed.FS, message string) {
+	err := wails.Run(&options.App{
+		Title:  windowTitle,
+		Width:  480,
+		Height: 200,
+		AssetServer: &assetserver.Options{
+			Assets: assets,
+		},
+		OnDomReady: func(ctx context.Context) {
+			_, err := runtime.MessageDialog(ctx, runtime.MessageDialogOptions{
+				Type:    runtime.ErrorDialog,
+				Title:   windowTitle,
+				Message: message,
+			})
+			if err != nil {
+				log.Printf("Error showing startup error dialog: %v", err)
+			}
+			runtime.Quit(ctx)
+		},
+	})
+	if err != nil {
+		log.Printf("Error showing startup error: %v", err)
+	}
+}
@@ -0,0 +1,465 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-list/backend"
+	"todo-list/backend/config"
+	"todo-list/backend/internal/recurrence"
+)
+
+// dueLayout формат срока, который принимают методы App
+const dueLayout = "2006-01-02T15:04"
+
+// openApp открывает хранилище из конфигурации и приложение поверх него: команды меняют
+// задачи по тем же правилам, что и окно, и их можно отменить в окне. Ошибки операций
+// App пишет в журнал - в командной строке это stderr без даты.
+func openApp() (*backend.App, backend.Store, error) {
+	log.SetFlags(0)
+	cfg := config.LoadConfig()
+	store, err := openStore(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return backend.NewApp(store, cfg), store, nil
+}
+
+// openStore открывает хранилище из конфигурации. Зашифрованный файл задач
+// разблокируется парольной фразой из TODO_PASSPHRASE.
+func openStore(cfg *config.Config) (backend.Store, error) {
+	store, err := backend.NewStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if tm, ok := store.(*backend.TaskManager); ok && tm.Locked() {
+		store.Close()
+		return nil, errors.New("task file is encrypted: set TODO_PASSPHRASE")
+	}
+	return store, nil
+}
+
+// Add выполняет команду add: создает задачу и выводит ее
+func Add(args []string) error {
+	fs := flag.NewFlagSet("add", flag.ContinueOnError)
+	description := fs.String("d", "", "description")
+	priority := fs.String("p", "medium", "priority: low, medium or high")
+	due := fs.String("due", "", "due date: YYYY-MM-DD, YYYY-MM-DDTHH:MM, today or tomorrow")
+	parent := fs.Int("parent", 0, "add as a subtask of this task")
+	tags := fs.String("tags", "", "comma-separated tags")
+	repeat := fs.String("repeat", "", "recurrence rule, e.g. FREQ=WEEKLY;BYDAY=MO (requires -due)")
+	out := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list add [flags] TITLE...")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	title := strings.TrimSpace(strings.Join(rest, " "))
+	if title == "" {
+		fs.Usage()
+		return usagef("task title is required")
+	}
+	if err := checkPriority(*priority); err != nil {
+		return err
+	}
+	dueDate, err := parseDue(*due)
+	if err != nil {
+		return err
+	}
+	if err := checkRepeat(*repeat, dueDate); err != nil {
+		return err
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+
+	app, store, err := openApp()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if *parent != 0 {
+		if _, err := store.Get(*parent); err != nil {
+			return fmt.Errorf("parent task %d: %w", *parent, err)
+		}
+	}
+
+	app.BeginGroup("Добавление задачи")
+	defer app.EndGroup()
+
+	var task backend.Task
+	switch {
+	case *parent != 0:
+		task = app.AddSubtask(*parent, title, *description, *priority, dueDate)
+	case *repeat != "":
+		task = app.AddRecurringTask(title, *description, *priority, dueDate, *repeat)
+	default:
+		task = app.AddTask(title, *description, *priority, dueDate)
+	}
+	if task.ID == 0 {
+		return errors.New("task was not created")
+	}
+	if *parent != 0 && *repeat != "" && !app.SetTaskRecurrence(task.ID, *repeat) {
+		return fmt.Errorf("task %d was created without recurrence", task.ID)
+	}
+	if *tags != "" && !app.SetTaskTags(task.ID, splitList(*tags)) {
+		return fmt.Errorf("task %d was created without tags", task.ID)
+	}
+
+	if task, err = store.Get(task.ID); err != nil {
+		return err
+	}
+	return writeTasks(os.Stdout, out, []backend.Task{task})
+}
+
+// List выполняет команду list: выводит задачи с теми же фильтрами, что и окно приложения
+func List(args []string) error {
+	fs := flag.NewFlagSet("list", flag.ContinueOnError)
+	status := fs.String("status", "all", "all, active or completed")
+	date := fs.String("date", "all", "all, today, week or overdue")
+	sortBy := fs.String("sort", "", "date or priority (default: storage order)")
+	ascending := fs.Bool("asc", false, "sort in ascending order")
+	out := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list list [flags]")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	if err := checkChoice("-status", *status, "all", "active", "completed"); err != nil {
+		return err
+	}
+	if err := checkChoice("-date", *date, "all", "today", "week", "overdue"); err != nil {
+		return err
+	}
+	if err := checkChoice("-sort", *sortBy, "", "date", "priority"); err != nil {
+		return err
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+
+	store, err := openStore(config.LoadConfig())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	tasks, err := store.List()
+	if err != nil {
+		return err
+	}
+	return writeTasks(os.Stdout, out, backend.FilterTasks(tasks, *status, *date, *sortBy, *ascending))
+}
+
+// Done выполняет команду done: отмечает задачи выполненными или, с -undo, открытыми
+func Done(args []string) error {
+	fs := flag.NewFlagSet("done", flag.ContinueOnError)
+	undo := fs.Bool("undo", false, "mark tasks as not completed")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list done [-undo] ID...")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(fs, rest)
+	if err != nil {
+		return err
+	}
+
+	app, store, err := openApp()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := checkTasks(store, ids); err != nil {
+		return err
+	}
+	if !app.SetTasksCompleted(ids, !*undo) {
+		return errors.New("failed to update tasks")
+	}
+
+	state := "completed"
+	if *undo {
+		state = "reopened"
+	}
+	for _, id := range ids {
+		fmt.Printf("task %d %s\n", id, state)
+	}
+	return nil
+}
+
+// Edit выполняет команду edit: меняет только поля, заданные флагами
+func Edit(args []string) error {
+	fs := flag.NewFlagSet("edit", flag.ContinueOnError)
+	title := fs.String("title", "", "new title")
+	description := fs.String("d", "", "new description")
+	priority := fs.String("p", "", "new priority: low, medium or high")
+	due := fs.String("due", "", `new due date (YYYY-MM-DD, YYYY-MM-DDTHH:MM, today, tomorrow; "" removes it)`)
+	parent := fs.Int("parent", 0, "move under this task (0 - to the top level)")
+	tags := fs.String("tags", "", `replace tags with this comma-separated list ("" removes all)`)
+	repeat := fs.String("repeat", "", `new recurrence rule ("" stops repeating)`)
+	out := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list edit [flags] ID")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(fs, rest)
+	if err != nil {
+		return err
+	}
+	if len(ids) != 1 {
+		return usagef("expected one task ID")
+	}
+	id := ids[0]
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if set["title"] && strings.TrimSpace(*title) == "" {
+		return usagef("task title cannot be empty")
+	}
+	if set["p"] {
+		if err := checkPriority(*priority); err != nil {
+			return err
+		}
+	}
+	dueDate, err := parseDue(*due)
+	if err != nil {
+		return err
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+
+	app, store, err := openApp()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	task, err := store.Get(id)
+	if err != nil {
+		return fmt.Errorf("task %d: %w", id, err)
+	}
+	if set["parent"] && *parent != 0 {
+		if _, err := store.Get(*parent); err != nil {
+			return fmt.Errorf("parent task %d: %w", *parent, err)
+		}
+	}
+
+	app.BeginGroup("Изменение задачи")
+	defer app.EndGroup()
+
+	// Повторение снимается до изменения срока: без срока оно недопустимо
+	if set["repeat"] && *repeat == "" && !app.SetTaskRecurrence(id, "") {
+		return fmt.Errorf("failed to stop repeating task %d", id)
+	}
+	if set["title"] || set["d"] || set["p"] || set["due"] {
+		if set["title"] {
+			task.Title = strings.TrimSpace(*title)
+		}
+		if set["d"] {
+			task.Description = *description
+		}
+		if set["p"] {
+			task.Priority = *priority
+		}
+		if !set["due"] && !task.DueDate.IsZero() {
+			dueDate = task.DueDate.Format(dueLayout)
+		}
+		if !app.UpdateTask(id, task.Title, task.Description, task.Priority, dueDate) {
+			return fmt.Errorf("failed to update task %d", id)
+		}
+	}
+	if set["repeat"] && *repeat != "" && !app.SetTaskRecurrence(id, *repeat) {
+		return fmt.Errorf("failed to set recurrence of task %d", id)
+	}
+	if set["parent"] && !app.MoveTask(id, *parent) {
+		return fmt.Errorf("failed to move task %d under %d", id, *parent)
+	}
+	if set["tags"] && !app.SetTaskTags(id, splitList(*tags)) {
+		return fmt.Errorf("failed to set tags of task %d", id)
+	}
+
+	if task, err = store.Get(id); err != nil {
+		return err
+	}
+	return writeTasks(os.Stdout, out, []backend.Task{task})
+}
+
+// Remove выполняет команду rm: переносит задачи вместе с подзадачами в корзину
+func Remove(args []string) error {
+	fs := flag.NewFlagSet("rm", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list rm ID...")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(fs, rest)
+	if err != nil {
+		return err
+	}
+
+	app, store, err := openApp()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	if err := checkTasks(store, ids); err != nil {
+		return err
+	}
+	if !app.DeleteTasks(ids) {
+		return errors.New("failed to delete tasks")
+	}
+	for _, id := range ids {
+		fmt.Printf("task %d moved to trash\n", id)
+	}
+	return nil
+}
+
+// Categories выполняет команду categories: выводит категории с числом задач
+func Categories(args []string) error {
+	fs := flag.NewFlagSet("categories", flag.ContinueOnError)
+	out := addOutputFlags(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list categories [flags]")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected arguments: %s", strings.Join(rest, " "))
+	}
+	if err := out.check(); err != nil {
+		return err
+	}
+
+	store, err := openStore(config.LoadConfig())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	categories, err := backend.Categories(store)
+	if err != nil {
+		return err
+	}
+	return writeItems(os.Stdout, out, categories, "ID\tNAME\tCOLOR\tTASKS", func(c backend.Category) string {
+		return fmt.Sprintf("%d\t%s\t%s\t%d", c.ID, c.Name, c.Color, c.Count)
+	})
+}
+
+// parseIDs разбирает номера задач из аргументов; нужен хотя бы один
+func parseIDs(fs *flag.FlagSet, args []string) ([]int, error) {
+	if len(args) == 0 {
+		fs.Usage()
+		return nil, usagef("task ID is required")
+	}
+	ids := make([]int, 0, len(args))
+	for _, arg := range args {
+		id, err := strconv.Atoi(arg)
+		if err != nil || id <= 0 {
+			return nil, usagef("invalid task ID %q", arg)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// checkTasks проверяет, что все задачи существуют, до того как что-то менять
+func checkTasks(store backend.Store, ids []int) error {
+	for _, id := range ids {
+		if _, err := store.Get(id); err != nil {
+			return fmt.Errorf("task %d: %w", id, err)
+		}
+	}
+	return nil
+}
+
+func checkPriority(priority string) error {
+	return checkChoice("-p", priority, "low", "medium", "high")
+}
+
+// checkChoice проверяет, что значение флага - одно из choices
+func checkChoice(name, value string, choices ...string) error {
+	for _, choice := range choices {
+		if value == choice {
+			return nil
+		}
+	}
+	return usagef("invalid %s %q", name, value)
+}
+
+// checkRepeat проверяет правило повторения; повторяющейся задаче нужен срок
+func checkRepeat(rule, dueDate string) error {
+	if rule == "" {
+		return nil
+	}
+	if _, err := recurrence.Parse(rule); err != nil {
+		return usagef("invalid -repeat: %v", err)
+	}
+	if dueDate == "" {
+		return usagef("-repeat requires -due")
+	}
+	return nil
+}
+
+// parseDue переводит срок из командной строки в формат App: пустая строка - без срока
+func parseDue(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	today := time.Now().Format("2006-01-02")
+	switch value {
+	case "":
+		return "", nil
+	case "today":
+		return today + "T00:00", nil
+	case "tomorrow":
+		return time.Now().AddDate(0, 0, 1).Format("2006-01-02") + "T00:00", nil
+	}
+	for _, layout := range []string{"2006-01-02", dueLayout, "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t.Format(dueLayout), nil
+		}
+	}
+	return "", usagef("invalid -due %q: use YYYY-MM-DD or YYYY-MM-DDTHH:MM", value)
+}
+
+// splitList разбирает список через запятую, пропуская пустые элементы
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
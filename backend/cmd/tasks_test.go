@@ -0,0 +1,232 @@
+package cmd
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-list/backend"
+)
+
+// setupStore направляет команды в пустое JSON-хранилище во временном каталоге
+func setupStore(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "tasks.json")
+	t.Setenv("TODO_STORAGE", "json")
+	t.Setenv("TODO_FILE", filename)
+	t.Setenv("TODO_HISTORY_FILE", filepath.Join(dir, "history.json"))
+	t.Setenv("TODO_REMINDERS_FILE", filepath.Join(dir, "reminders.json"))
+	t.Setenv("TODO_PASSPHRASE", "")
+	return filename
+}
+
+// run выполняет команду и возвращает ее вывод и код завершения
+func run(t *testing.T, args ...string) (string, int) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+
+	code := Execute(embed.FS{}, args)
+	os.Stdout = stdout
+	w.Close()
+	return <-output, code
+}
+
+// runTasks выполняет команду с -json и разбирает выведенные задачи
+func runTasks(t *testing.T, args ...string) []backend.Task {
+	t.Helper()
+	out, code := run(t, append(args, "-json")...)
+	if code != exitOK {
+		t.Fatalf("%s: exit code %d, output %q", strings.Join(args, " "), code, out)
+	}
+	var tasks []backend.Task
+	if err := json.Unmarshal([]byte(out), &tasks); err != nil {
+		t.Fatalf("%s: %v\n%s", strings.Join(args, " "), err, out)
+	}
+	return tasks
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		priority string
+		rest     []string
+	}{
+		{[]string{"-p", "high", "Купить", "хлеб"}, "high", []string{"Купить", "хлеб"}},
+		{[]string{"Купить", "-p", "high", "хлеб"}, "high", []string{"Купить", "хлеб"}},
+		{[]string{"Купить", "--", "-p", "high"}, "low", []string{"Купить", "-p", "high"}},
+		{nil, "low", nil},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		priority := fs.String("p", "low", "")
+		rest, err := parseFlags(fs, tt.args)
+		if err != nil || *priority != tt.priority || !reflect.DeepEqual(rest, tt.rest) {
+			t.Errorf("parseFlags(%q) = %q, %q, %v", tt.args, *priority, rest, err)
+		}
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	if _, err := parseFlags(fs, []string{"-x"}); exitCode(err) != exitUsage {
+		t.Errorf("unknown flag: %v", err)
+	}
+	if _, err := parseFlags(fs, []string{"-h"}); exitCode(err) != exitOK {
+		t.Errorf("-h: %v", err)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := map[error]int{
+		nil:                     exitOK,
+		flag.ErrHelp:            exitOK,
+		usagef("bad"):           exitUsage,
+		backend.ErrTaskNotFound: exitNotFound,
+		fmt.Errorf("task 1: %w", backend.ErrTaskNotFound): exitNotFound,
+		errors.New("disk full"):                           exitError,
+	}
+	for err, want := range tests {
+		if got := exitCode(err); got != want {
+			t.Errorf("exitCode(%v) = %d, want %d", err, got, want)
+		}
+	}
+}
+
+func TestParseDue(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	tests := map[string]string{
+		"":                 "",
+		"today":            today + "T00:00",
+		"2026-10-20":       "2026-10-20T00:00",
+		"2026-10-20T09:30": "2026-10-20T09:30",
+		"2026-10-20 09:30": "2026-10-20T09:30",
+	}
+	for value, want := range tests {
+		if got, err := parseDue(value); err != nil || got != want {
+			t.Errorf("parseDue(%q) = %q, %v; want %q", value, got, err, want)
+		}
+	}
+	if _, err := parseDue("20.10.2026"); exitCode(err) != exitUsage {
+		t.Errorf("parseDue accepted 20.10.2026: %v", err)
+	}
+	if got := splitList(" a, ,b,"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("splitList = %q", got)
+	}
+}
+
+func TestTaskCommands(t *testing.T) {
+	setupStore(t)
+
+	added := runTasks(t, "add", "Отчет", "-p", "high", "-due", "2026-10-20T09:30", "-tags", "work, q4", "-d", "за квартал")
+	if len(added) != 1 {
+		t.Fatalf("add printed %+v", added)
+	}
+	report := added[0]
+	if report.Title != "Отчет" || report.Priority != "high" || report.Description != "за квартал" ||
+		report.DueDate.Format(dueLayout) != "2026-10-20T09:30" || !reflect.DeepEqual(report.Tags, []string{"q4", "work"}) {
+		t.Errorf("added task = %+v", report)
+	}
+
+	sub := runTasks(t, "add", "-parent", fmt.Sprint(report.ID), "Таблицы")[0]
+	if sub.ParentID != report.ID || sub.Priority != "medium" {
+		t.Errorf("subtask = %+v", sub)
+	}
+	weekly := runTasks(t, "add", "Планерка", "-due", "2026-10-19", "-repeat", "FREQ=WEEKLY")[0]
+	if weekly.Recurrence == "" {
+		t.Errorf("recurring task = %+v", weekly)
+	}
+
+	if out, code := run(t, "done", fmt.Sprint(sub.ID)); code != exitOK || out != fmt.Sprintf("task %d completed\n", sub.ID) {
+		t.Errorf("done = %d, %q", code, out)
+	}
+	active := runTasks(t, "list", "-status", "active", "-sort", "priority")
+	if len(active) != 2 || active[0].ID != report.ID || active[1].ID != weekly.ID {
+		t.Errorf("active tasks = %+v", active)
+	}
+
+	edited := runTasks(t, "edit", fmt.Sprint(report.ID), "-title", "Годовой отчет", "-tags", "")[0]
+	if edited.Title != "Годовой отчет" || len(edited.Tags) != 0 || edited.Priority != "high" ||
+		edited.DueDate.Format(dueLayout) != "2026-10-20T09:30" || edited.Description != "за квартал" {
+		t.Errorf("edited task = %+v", edited)
+	}
+	edited = runTasks(t, "edit", fmt.Sprint(weekly.ID), "-repeat", "", "-due", "")[0]
+	if edited.Recurrence != "" || !edited.DueDate.IsZero() {
+		t.Errorf("task after removing due date and recurrence = %+v", edited)
+	}
+
+	if out, code := run(t, "list", "-format", "{{.ID}}:{{.Title}}"); code != exitOK ||
+		out != fmt.Sprintf("%d:Годовой отчет\n%d:Таблицы\n%d:Планерка\n", report.ID, sub.ID, weekly.ID) {
+		t.Errorf("list -format = %d, %q", code, out)
+	}
+
+	if _, code := run(t, "rm", fmt.Sprint(report.ID)); code != exitOK {
+		t.Errorf("rm exit code = %d", code)
+	}
+	// Подзадачи уходят в корзину вместе с задачей
+	if left := runTasks(t, "list"); len(left) != 1 || left[0].ID != weekly.ID {
+		t.Errorf("tasks after rm = %+v", left)
+	}
+}
+
+func TestCommandErrors(t *testing.T) {
+	filename := setupStore(t)
+	task := runTasks(t, "add", "Задача")[0]
+
+	tests := []struct {
+		args []string
+		code int
+	}{
+		{[]string{"frobnicate"}, exitUsage},
+		{[]string{"add"}, exitUsage},
+		{[]string{"add", "x", "-p", "urgent"}, exitUsage},
+		{[]string{"add", "x", "-repeat", "FREQ=WEEKLY"}, exitUsage},
+		{[]string{"add", "x", "-repeat", "FREQ=SOMETIMES", "-due", "today"}, exitUsage},
+		{[]string{"add", "x", "-json", "-format", "{{.ID}}"}, exitUsage},
+		{[]string{"add", "x", "-format", "{{.ID"}, exitUsage},
+		{[]string{"add", "x", "-parent", "99"}, exitNotFound},
+		{[]string{"list", "extra"}, exitUsage},
+		{[]string{"list", "-status", "open"}, exitUsage},
+		{[]string{"done"}, exitUsage},
+		{[]string{"done", "abc"}, exitUsage},
+		{[]string{"done", fmt.Sprint(task.ID), "99"}, exitNotFound},
+		{[]string{"edit", fmt.Sprint(task.ID), "99"}, exitUsage},
+		{[]string{"edit", fmt.Sprint(task.ID), "-title", " "}, exitUsage},
+		{[]string{"edit", "99", "-title", "x"}, exitNotFound},
+		{[]string{"rm", "99"}, exitNotFound},
+	}
+	for _, tt := range tests {
+		if out, code := run(t, tt.args...); code != tt.code {
+			t.Errorf("%q: exit code %d, want %d; output %q", tt.args, code, tt.code, out)
+		}
+	}
+
+	// Неудачные команды ничего не меняют: задача одна и не выполнена
+	tm, err := backend.NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tm.Close()
+	tasks, err := tm.List()
+	if err != nil || len(tasks) != 1 || tasks[0].Completed {
+		t.Errorf("tasks = %+v, %v", tasks, err)
+	}
+}
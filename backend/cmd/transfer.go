@@ -0,0 +1,166 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"todo-list/backend"
+	"todo-list/backend/config"
+	"todo-list/backend/internal/fsutil"
+	"todo-list/backend/internal/transfer"
+)
+
+// Export выполняет команду export: выгружает задачи в файл или в stdout
+func Export(args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	format := fs.String("format", "", "json, csv, markdown, org, todotxt or ics (default: from -o extension, else json)")
+	columns := fs.String("columns", "", "CSV column names, e.g. title=Name,due_date=Deadline")
+	output := fs.String("o", "", "output file (default: stdout)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list export [flags]")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usagef("unexpected arguments: %s", rest[0])
+	}
+
+	formatName, err := resolveFormat(*format, *output, transfer.FormatJSON)
+	if err != nil {
+		return err
+	}
+	opts, err := columnOptions(*columns)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(config.LoadConfig())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var buf bytes.Buffer
+	if err := backend.ExportTasks(store, &buf, formatName, opts); err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return fsutil.WriteFileAtomic(*output, buf.Bytes(), 0644)
+}
+
+// Import выполняет команду import: загружает задачи из файла ("-" - stdin)
+func Import(args []string) error {
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	format := fs.String("format", "", "json, csv, markdown, org, todotxt or ics (default: from file extension)")
+	columns := fs.String("columns", "", "CSV column names, e.g. title=Name,due_date=Deadline")
+	duplicates := fs.String("duplicates", transfer.DuplicatesSkip, "what to do with tasks that already exist: skip, update or create")
+	dryRun := fs.Bool("dry-run", false, "show what would be imported without changing anything")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "usage: todo-list import [flags] FILE")
+		fs.PrintDefaults()
+	}
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) != 1 {
+		fs.Usage()
+		return usagef("expected one file to import")
+	}
+
+	filename := rest[0]
+	formatName, err := resolveFormat(*format, filename, "")
+	if err != nil {
+		return err
+	}
+	opts, err := columnOptions(*columns)
+	if err != nil {
+		return err
+	}
+
+	var in io.Reader = os.Stdin
+	if filename != "-" {
+		file, err := os.Open(filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		in = file
+	}
+
+	store, err := openStore(config.LoadConfig())
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	report, err := backend.ImportTasks(store, in, formatName, opts,
+		transfer.ImportOptions{Duplicates: *duplicates, DryRun: *dryRun})
+	if err != nil {
+		return err
+	}
+	printReport(os.Stdout, report)
+	if report.Failed > 0 {
+		return fmt.Errorf("%d of the imported tasks failed", report.Failed)
+	}
+	return nil
+}
+
+// resolveFormat определяет формат по флагу -format, затем по расширению файла;
+// fallback - формат для stdin/stdout
+func resolveFormat(format, filename, fallback string) (string, error) {
+	if format != "" {
+		return transfer.ParseFormat(format)
+	}
+	if filename == "" || filename == "-" {
+		if fallback == "" {
+			return "", fmt.Errorf("-format is required when reading from stdin")
+		}
+		return fallback, nil
+	}
+	name, err := transfer.FormatFromFilename(filename)
+	if err != nil {
+		return "", fmt.Errorf("%w (use -format)", err)
+	}
+	return name, nil
+}
+
+// columnOptions разбирает флаг -columns
+func columnOptions(columns string) (transfer.Options, error) {
+	mapping, err := transfer.ParseColumns(columns)
+	if err != nil {
+		return transfer.Options{}, err
+	}
+	return transfer.Options{Columns: mapping}, nil
+}
+
+// printReport выводит отчет импорта
+func printReport(w io.Writer, report transfer.Report) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "REF\tACTION\tID\tTITLE\tERROR")
+	for _, item := range report.Items {
+		id := "-"
+		if item.TaskID != 0 {
+			id = fmt.Sprint(item.TaskID)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", item.Ref, item.Action, id, item.Title, item.Error)
+	}
+	tw.Flush()
+
+	prefix := ""
+	if report.DryRun {
+		prefix = "dry run: "
+	}
+	fmt.Fprintf(w, "%s%d created, %d updated, %d skipped, %d failed\n",
+		prefix, report.Created, report.Updated, report.Skipped, report.Failed)
+}
@@ -3,7 +3,11 @@ package config
 
 import (
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
+	"time"
 )
 
 // DatabaseConfig содержит настройки для подключения к базе данных
@@ -16,10 +20,27 @@ type DatabaseConfig struct {
 	SSLMode  string
 }
 
+// Доступные бэкенды хранения задач
+const (
+	StorageJSON     = "json"
+	StorageTodoTxt  = "todotxt"
+	StoragePostgres = "postgres"
+)
+
 // Config содержит все настройки приложения
 type Config struct {
-	Database DatabaseConfig
-	Port     string
+	Database       DatabaseConfig
+	Host           string
+	Port           string
+	HTTPEnabled    bool          // запускать REST API вместе с окном приложения
+	APIUser        string        // логин Basic-авторизации REST API и CalDAV
+	APIPassword    string        // пароль REST API и CalDAV, пустой - доступ только с localhost
+	Storage        string        // json, todotxt или postgres
+	DataFile       string        // путь к файлу задач (JSON или todo.txt), пустой - файл по умолчанию
+	RemindersFile  string        // состояние и настройки напоминаний
+	HistoryFile    string        // история отмены действий
+	Passphrase     string        // парольная фраза зашифрованного файла задач
+	TrashRetention time.Duration // сколько хранить удаленное в корзине, 0 - не очищать
 }
 
 // LoadConfig загружает конфигурацию из переменных окружения
@@ -33,8 +54,32 @@ func LoadConfig() *Config {
 			DBName:   getEnv("DB_NAME", "todo"),
 			SSLMode:  getEnv("DB_SSLMODE", "disable"),
 		},
-		Port: getEnv("APP_PORT", "8080"),
+		Host:           getEnv("APP_HOST", "127.0.0.1"),
+		Port:           getEnv("APP_PORT", "8080"),
+		HTTPEnabled:    getEnv("APP_HTTP_ENABLED", "false") == "true",
+		APIUser:        getEnv("APP_USER", "todo"),
+		APIPassword:    os.Getenv("APP_PASSWORD"),
+		Storage:        getEnv("TODO_STORAGE", StorageJSON),
+		DataFile:       getEnv("TODO_FILE", ""),
+		RemindersFile:  getEnv("TODO_REMINDERS_FILE", defaultHomeFile(".todo-list.reminders.json")),
+		HistoryFile:    getEnv("TODO_HISTORY_FILE", defaultHomeFile(".todo-list.history.json")),
+		Passphrase:     os.Getenv("TODO_PASSPHRASE"),
+		TrashRetention: getEnvDays("TODO_TRASH_RETENTION_DAYS", 30),
+	}
+}
+
+// Addr возвращает адрес, который слушает HTTP-сервер
+func (c *Config) Addr() string {
+	return net.JoinHostPort(c.Host, c.Port)
+}
+
+// IsLoopback сообщает, что host - адрес или имя этого компьютера (localhost)
+func IsLoopback(host string) bool {
+	if host == "localhost" {
+		return true
 	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
 }
 
 // GetDSN возвращает строку подключения к PostgreSQL
@@ -55,6 +100,22 @@ func (c *DatabaseConfig) GetDSN() string {
 	)
 }
 
+// defaultHomeFile возвращает путь к файлу в домашнем каталоге пользователя
+func defaultHomeFile(name string) string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, name)
+}
+
+// getEnvDays читает число дней из переменной окружения; некорректное значение
+// заменяется значением по умолчанию
+func getEnvDays(key string, defaultDays int) time.Duration {
+	days, err := strconv.Atoi(getEnv(key, strconv.Itoa(defaultDays)))
+	if err != nil || days < 0 {
+		days = defaultDays
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
 // getEnv получает значение переменной окружения или возвращает значение по умолчанию
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -55,57 +55,21 @@ func NewDatabase(cfg *config.Config) (*Database, error) {
 	return &Database{DB: db}, nil
 }
 
-// Migrate выполняет миграции базы данных
+// Migrate применяет все непримененные миграции схемы
 func Migrate(db *sql.DB) error {
 	log.Println("Running database migrations...")
 
-	// Создание таблицы категорий
-	categoryTableSQL := `
-	CREATE TABLE IF NOT EXISTS categories (
-		id SERIAL PRIMARY KEY,
-		name VARCHAR(255) NOT NULL,
-		color VARCHAR(7) DEFAULT '#007bff',
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`
-
-	// Создание таблицы задач
-	todoTableSQL := `
-	CREATE TABLE IF NOT EXISTS todos (
-		id SERIAL PRIMARY KEY,
-		title VARCHAR(255) NOT NULL,
-		description TEXT,
-		completed BOOLEAN DEFAULT FALSE,
-		priority VARCHAR(10) DEFAULT 'medium',
-		due_date TIMESTAMP,
-		category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
-	)`
-
-	// Создание индексов
-	indexesSQL := []string{
-		`CREATE INDEX IF NOT EXISTS idx_todos_category_id ON todos(category_id)`,
-		`CREATE INDEX IF NOT EXISTS idx_todos_completed ON todos(completed)`,
-		`CREATE INDEX IF NOT EXISTS idx_todos_due_date ON todos(due_date)`,
-	}
-
-	// Выполняем миграции
-	tables := []string{categoryTableSQL, todoTableSQL}
-	for _, tableSQL := range tables {
-		if _, err := db.Exec(tableSQL); err != nil {
-			return fmt.Errorf("failed to create table: %w", err)
-		}
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return err
 	}
 
-	// Создаем индексы
-	for _, indexSQL := range indexesSQL {
-		if _, err := db.Exec(indexSQL); err != nil {
-			return fmt.Errorf("failed to create index: %w", err)
-		}
+	applied, err := migrator.Up()
+	if err != nil {
+		return err
 	}
 
-	log.Println("Database migrations completed successfully")
+	log.Printf("Database migrations completed successfully (%d applied)", len(applied))
 	return nil
 }
 
@@ -0,0 +1,56 @@
+package database
+
+import (
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"m/0002_tags.up.sql":    {Data: []byte("CREATE TABLE tags ();")},
+		"m/0002_tags.down.sql":  {Data: []byte("DROP TABLE tags;")},
+		"m/0001_init.up.sql":    {Data: []byte("CREATE TABLE todos ();")},
+		"m/0010_late.up.sql":    {Data: []byte("SELECT 1;")},
+		"m/README.md":           {Data: []byte("not a migration")},
+		"m/0003_dir/0003_x.sql": {Data: []byte("ignored")},
+	}
+	migrations, err := loadMigrations(fsys, "m")
+	if err != nil {
+		t.Fatalf("loadMigrations: %v", err)
+	}
+	var versions []int
+	for _, m := range migrations {
+		versions = append(versions, m.Version)
+	}
+	if len(versions) != 3 || versions[0] != 1 || versions[1] != 2 || versions[2] != 10 {
+		t.Fatalf("versions = %v", versions)
+	}
+	if tags := migrations[1]; tags.Name != "tags" || tags.Down != "DROP TABLE tags;" {
+		t.Errorf("migration 2 = %+v", tags)
+	}
+	if migrations[2].Down != "" {
+		t.Errorf("migration without down script = %+v", migrations[2])
+	}
+
+	// Встроенные миграции тоже разбираются
+	if embedded, err := loadMigrations(migrationFiles, "migrations"); err != nil || len(embedded) == 0 {
+		t.Errorf("embedded migrations = %d, %v", len(embedded), err)
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	sql := &fstest.MapFile{Data: []byte("SELECT 1;")}
+	tests := map[string]fstest.MapFS{
+		"no version":        {"m/init.up.sql": sql},
+		"bad version":       {"m/x1_init.up.sql": sql},
+		"zero version":      {"m/0000_init.up.sql": sql},
+		"name conflict":     {"m/0001_a.up.sql": sql, "m/0001_b.up.sql": sql},
+		"down without up":   {"m/0001_a.down.sql": {Data: []byte("DROP TABLE a;")}},
+		"missing directory": {},
+	}
+	for name, fsys := range tests {
+		if _, err := loadMigrations(fsys, "m"); err == nil {
+			t.Errorf("%s: loadMigrations accepted invalid migrations", name)
+		}
+	}
+}
@@ -0,0 +1,3 @@
+-- Индексы для фильтрации и сортировки списка задач
+CREATE INDEX IF NOT EXISTS idx_todos_priority ON todos(priority);
+CREATE INDEX IF NOT EXISTS idx_todos_created_at ON todos(created_at DESC, id DESC);
@@ -0,0 +1,2 @@
+-- Правило повторения задачи (RRULE и EXDATE), пустая строка - задача не повторяется
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,2 @@
+-- Смещения напоминаний в минутах до срока, пустой массив - настройки по умолчанию
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS reminders INTEGER[] NOT NULL DEFAULT '{}';
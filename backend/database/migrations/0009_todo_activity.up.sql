@@ -0,0 +1,13 @@
+-- Журнал изменений задач. Внешнего ключа на todos нет: история остается
+-- и после окончательного удаления задачи из корзины
+CREATE TABLE IF NOT EXISTS todo_activity (
+	id BIGSERIAL PRIMARY KEY,
+	todo_id INTEGER NOT NULL,
+	title VARCHAR(255) NOT NULL DEFAULT '',
+	action VARCHAR(16) NOT NULL,
+	changes JSONB NOT NULL DEFAULT '[]',
+	source VARCHAR(16) NOT NULL DEFAULT 'app',
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_todo_activity_todo_id ON todo_activity(todo_id, id);
@@ -0,0 +1,9 @@
+-- Подписки iCalendar: задачи, отобранные фильтром, по секретной ссылке
+CREATE TABLE IF NOT EXISTS feeds (
+	id SERIAL PRIMARY KEY,
+	name VARCHAR(255) NOT NULL DEFAULT '',
+	token VARCHAR(64) NOT NULL UNIQUE,
+	filter JSONB NOT NULL DEFAULT '{}',
+	events BOOLEAN NOT NULL DEFAULT FALSE,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
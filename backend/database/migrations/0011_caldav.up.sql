@@ -0,0 +1,8 @@
+-- Имена ресурсов и UID задач, созданных клиентами CalDAV: клиент обращается к задаче
+-- по своему имени и ожидает в ней свой UID. Внешнего ключа на todos нет: по имени
+-- клиенту сообщается об удалении задачи и после ее очистки из корзины
+CREATE TABLE IF NOT EXISTS caldav_objects (
+	todo_id INTEGER PRIMARY KEY,
+	name VARCHAR(255) NOT NULL UNIQUE,
+	uid VARCHAR(255) NOT NULL UNIQUE
+);
@@ -0,0 +1,8 @@
+-- Задачи, перенесенные из JSON-файла приложения: повторный перенос того же файла
+-- пропускает их. Ключ - ID задачи в файле и время ее создания. Внешнего ключа на todos
+-- нет: задача, удаленная после переноса, не переносится снова
+CREATE TABLE IF NOT EXISTS file_imports (
+	task_key VARCHAR(64) PRIMARY KEY,
+	todo_id INTEGER NOT NULL,
+	imported_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1 @@
+ALTER TABLE todo_activity DROP COLUMN IF EXISTS actor;
@@ -0,0 +1,2 @@
+-- Кто внес изменение: пользователь ОС для приложения, пользователь API для REST API и CalDAV
+ALTER TABLE todo_activity ADD COLUMN IF NOT EXISTS actor VARCHAR(255) NOT NULL DEFAULT '';
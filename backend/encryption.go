@@ -0,0 +1,338 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"todo-list/backend/internal/fsutil"
+	"todo-list/backend/internal/vault"
+)
+
+// Ошибки шифрования файла задач
+var (
+	// ErrStoreLocked файл задач зашифрован, а парольная фраза не введена
+	ErrStoreLocked = errors.New("файл задач зашифрован: нужна парольная фраза")
+	// ErrWrongPassphrase парольная фраза не подходит к файлу задач
+	ErrWrongPassphrase = errors.New("неверная парольная фраза")
+	// ErrEncryptionUnsupported шифровать можно только JSON-файл задач
+	ErrEncryptionUnsupported = errors.New("шифрование доступно только для JSON-файла задач")
+)
+
+// errUnsealed файл задач не зашифрован, хотя хранилище открыто с ключом
+var errUnsealed = errors.New("task file is not encrypted")
+
+// sealedFormat шифрует файл формата inner ключом из парольной фразы. Незашифрованный
+// файл не читается (errUnsealed): зашифровать файл можно только через SetPassphrase,
+// иначе подложенный или расшифрованный в другом процессе файл молча шифровался бы заново.
+type sealedFormat struct {
+	inner fileFormat
+	key   *vault.Key
+}
+
+func (f sealedFormat) encode(content taskFile) ([]byte, []byte, error) {
+	data, meta, err := f.inner.encode(content)
+	if err != nil {
+		return nil, nil, err
+	}
+	if data, err = f.key.Seal(data); err != nil {
+		return nil, nil, err
+	}
+	if meta != nil {
+		if meta, err = f.key.Seal(meta); err != nil {
+			return nil, nil, err
+		}
+	}
+	return data, meta, nil
+}
+
+func (f sealedFormat) decode(data, meta []byte) (taskFile, error) {
+	data, err := f.open(data)
+	if err != nil {
+		return taskFile{}, err
+	}
+	if meta, err = f.open(meta); err != nil {
+		return taskFile{}, err
+	}
+	return f.inner.decode(data, meta)
+}
+
+// open расшифровывает data; пустые данные (нет файла метаданных) остаются пустыми.
+// Файл, зашифрованный другим ключом, - ErrStoreLocked: парольную фразу сменили
+// в другом процессе, и ее нужно ввести заново.
+func (f sealedFormat) open(data []byte) ([]byte, error) {
+	if len(data) == 0 {
+		return data, nil
+	}
+	if !vault.IsSealed(data) {
+		return nil, errUnsealed
+	}
+	plain, err := f.key.Open(data)
+	if errors.Is(err, vault.ErrWrongKey) {
+		return nil, ErrStoreLocked
+	}
+	return plain, err
+}
+
+// Encrypted сообщает, что файл задач зашифрован
+func (tm *TaskManager) Encrypted() bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	_, sealed := tm.format.(sealedFormat)
+	return tm.locked || sealed
+}
+
+// Locked сообщает, что файл задач зашифрован и задачи недоступны до Unlock
+func (tm *TaskManager) Locked() bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	return tm.locked
+}
+
+// Unlock расшифровывает файл задач парольной фразой и загружает задачи. Если основной
+// файл не расшифровывается, а резервная копия - да, файл считается поврежденным
+// и задачи восстанавливаются из копии, как при обычной загрузке.
+func (tm *TaskManager) Unlock(passphrase string) error {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if !tm.locked {
+		return nil
+	}
+
+	fileLock, err := fsutil.Lock(tm.filename + ".lock")
+	if err != nil {
+		return err
+	}
+	defer fileLock.Unlock()
+
+	var key *vault.Key
+	for n := 0; n <= dataBackups && key == nil; n++ {
+		name := tm.filename
+		if n > 0 {
+			name = backupName(tm.filename, n)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil || !vault.IsSealed(data) {
+			continue
+		}
+		if key, err = vault.Unlock(data, passphrase); errors.Is(err, vault.ErrEmptyPassphrase) {
+			return err
+		}
+	}
+	if key == nil {
+		return ErrWrongPassphrase
+	}
+
+	tm.format = sealedFormat{inner: tm.plainFormat(), key: key}
+	if err := tm.loadTasks(); err != nil {
+		tm.format = tm.plainFormat()
+		return err
+	}
+	tm.locked = false
+	return nil
+}
+
+// lockStore запирает хранилище и убирает задачи из памяти; вызывается под tm.mu
+func (tm *TaskManager) lockStore() {
+	tm.setContent(taskFile{})
+	tm.format = tm.plainFormat()
+	tm.locked = true
+}
+
+// plainFormat формат файла задач без шифрования
+func (tm *TaskManager) plainFormat() fileFormat {
+	if sealed, ok := tm.format.(sealedFormat); ok {
+		return sealed.inner
+	}
+	return tm.format
+}
+
+// SetPassphrase включает шифрование файла задач, меняет парольную фразу или, если
+// passphrase пустая, отключает шифрование. current - действующая парольная фраза,
+// для незашифрованного файла не проверяется. Файл и его резервные копии
+// перешифровываются новым ключом, а доступ к ним остается только у владельца.
+func (tm *TaskManager) SetPassphrase(current, passphrase string) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if _, ok := tm.plainFormat().(jsonFormat); !ok {
+		return ErrEncryptionUnsupported
+	}
+	var oldKey *vault.Key
+	if sealed, ok := tm.format.(sealedFormat); ok {
+		oldKey = sealed.key
+		if !oldKey.Verify(current) {
+			return ErrWrongPassphrase
+		}
+	}
+	var newKey *vault.Key
+	if passphrase != "" {
+		if newKey, err = vault.NewKey(passphrase); err != nil {
+			return err
+		}
+	}
+	if oldKey == nil && newKey == nil {
+		return nil
+	}
+
+	oldFormat := tm.format
+	tm.format = tm.plainFormat()
+	if newKey != nil {
+		tm.format = sealedFormat{inner: tm.format, key: newKey}
+	}
+	if err := tm.saveTasks(); err != nil {
+		tm.format = oldFormat
+		return err
+	}
+	if err := os.Chmod(tm.filename, dataFileMode); err != nil {
+		log.Printf("Error restricting access to %s: %v", tm.filename, err)
+	}
+	tm.resealCopies(oldKey, newKey)
+	return nil
+}
+
+// resealCopies перешифровывает копии файла задач (резервные, оригиналы до обновления
+// формата и отложенные поврежденные файлы) ключом to; nil to - расшифровывает их.
+// Копия, которую не удалось расшифровать ключом from, остается как есть.
+func (tm *TaskManager) resealCopies(from, to *vault.Key) {
+	var copies []string
+	for n := 1; n <= dataBackups; n++ {
+		copies = append(copies, backupName(tm.filename, n))
+	}
+	for _, pattern := range []string{".v*", ".corrupt-*"} {
+		matches, _ := filepath.Glob(globEscape(tm.filename) + pattern)
+		copies = append(copies, matches...)
+	}
+
+	for _, name := range copies {
+		if err := resealFile(name, from, to); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Error re-encrypting %s: %v", name, err)
+		}
+	}
+}
+
+// resealFile перешифровывает файл ключом to (nil - сохраняет расшифрованным)
+func resealFile(name string, from, to *vault.Key) error {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return err
+	}
+	if vault.IsSealed(data) {
+		if from == nil {
+			return fmt.Errorf("encrypted with an unknown key")
+		}
+		if data, err = from.Open(data); err != nil {
+			return err
+		}
+	}
+	if to != nil {
+		if data, err = to.Seal(data); err != nil {
+			return err
+		}
+	}
+	if err := fsutil.WriteFileAtomic(name, data, dataFileMode); err != nil {
+		return err
+	}
+	return os.Chmod(name, dataFileMode)
+}
+
+// globEscape экранирует метасимволы шаблона в имени файла
+func globEscape(name string) string {
+	var escaped []rune
+	for _, r := range name {
+		switch r {
+		case '*', '?', '[', '\\':
+			escaped = append(escaped, '\\')
+		}
+		escaped = append(escaped, r)
+	}
+	return string(escaped)
+}
+
+// Encryptable сообщает, что файл задач можно зашифровать: только JSON-файл
+func (tm *TaskManager) Encryptable() bool {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	_, ok := tm.plainFormat().(jsonFormat)
+	return ok
+}
+
+// encryptedStore хранилище, которое умеет шифровать свои данные
+type encryptedStore interface {
+	Encryptable() bool
+	Encrypted() bool
+	Locked() bool
+	Unlock(passphrase string) error
+	SetPassphrase(current, passphrase string) error
+}
+
+// storeEncrypted сообщает, что хранилище зашифровано
+func storeEncrypted(store Store) bool {
+	s, ok := store.(encryptedStore)
+	return ok && s.Encrypted()
+}
+
+// EncryptionStatus состояние шифрования хранилища для окна разблокировки и настроек
+type EncryptionStatus struct {
+	Supported bool `json:"supported"` // хранилище можно зашифровать
+	Enabled   bool `json:"enabled"`   // файл задач зашифрован
+	Locked    bool `json:"locked"`    // нужна парольная фраза, задачи недоступны
+}
+
+// GetEncryptionStatus возвращает состояние шифрования хранилища
+func (a *App) GetEncryptionStatus() EncryptionStatus {
+	s, ok := a.store.(encryptedStore)
+	if !ok {
+		return EncryptionStatus{}
+	}
+	return EncryptionStatus{Supported: s.Encryptable(), Enabled: s.Encrypted(), Locked: s.Locked()}
+}
+
+// UnlockStore расшифровывает файл задач введенной парольной фразой.
+// После разблокировки окно загружает задачи заново.
+func (a *App) UnlockStore(passphrase string) bool {
+	s, ok := a.store.(encryptedStore)
+	if !ok {
+		return false
+	}
+	if err := s.Unlock(passphrase); err != nil {
+		log.Printf("Error unlocking store: %v", err)
+		return false
+	}
+	return true
+}
+
+// SetStorePassphrase включает шифрование файла задач, меняет парольную фразу или,
+// если passphrase пустая, отключает шифрование; current - действующая фраза.
+// Пока файл зашифрован, история отмены хранится только в памяти: в ней есть
+// названия и описания задач.
+func (a *App) SetStorePassphrase(current, passphrase string) bool {
+	s, ok := a.store.(encryptedStore)
+	if !ok {
+		log.Printf("Error setting store passphrase: %v", ErrEncryptionUnsupported)
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := s.SetPassphrase(current, passphrase); err != nil {
+		log.Printf("Error setting store passphrase: %v", err)
+		return false
+	}
+
+	if passphrase != "" {
+		a.history.filename = ""
+		if err := os.Remove(a.historyFile); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Error removing history: %v", err)
+		}
+	} else {
+		a.history.filename = a.historyFile
+		a.history.save()
+	}
+	return true
+}
@@ -0,0 +1,195 @@
+package backend
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"todo-list/backend/internal/vault"
+)
+
+// fastKDF ускоряет получение ключей в тестах; файлы хранят параметры, поэтому
+// они открываются и после восстановления DefaultKDF
+func fastKDF(t *testing.T) {
+	t.Helper()
+	saved := vault.DefaultKDF
+	vault.DefaultKDF.Time, vault.DefaultKDF.Memory, vault.DefaultKDF.Threads = 1, 64, 1
+	t.Cleanup(func() { vault.DefaultKDF = saved })
+}
+
+// newEncryptedManager создает файл с задачей и резервной копией и включает шифрование
+func newEncryptedManager(t *testing.T, passphrase string) (*TaskManager, string) {
+	t.Helper()
+	fastKDF(t)
+	tm, filename := newTestManager(t)
+	for _, title := range []string{"Секретная задача", "Вторая"} {
+		if _, err := tm.Create(Task{Title: title, Priority: "medium"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tm.SetPassphrase("", passphrase); err != nil {
+		t.Fatalf("SetPassphrase: %v", err)
+	}
+	return tm, filename
+}
+
+// assertSealed проверяет, что файлы зашифрованы (или нет) и не содержат задач открытым текстом
+func assertSealed(t *testing.T, sealed bool, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Errorf("%s: %v", filepath.Base(name), err)
+			continue
+		}
+		if vault.IsSealed(data) != sealed || sealed && strings.Contains(string(data), "Секретная") {
+			t.Errorf("%s: sealed = %v, want %v", filepath.Base(name), vault.IsSealed(data), sealed)
+		}
+	}
+}
+
+func TestEnableAndUnlock(t *testing.T) {
+	tm, filename := newEncryptedManager(t, "pass")
+	tm.Close()
+	assertSealed(t, true, filename, backupName(filename, 1))
+	if info, _ := os.Stat(filename); info.Mode().Perm() != dataFileMode {
+		t.Errorf("mode = %v", info.Mode().Perm())
+	}
+
+	tm, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatalf("NewTaskManager: %v", err)
+	}
+	defer tm.Close()
+	if !tm.Locked() || !tm.Encrypted() {
+		t.Fatalf("locked = %v, encrypted = %v", tm.Locked(), tm.Encrypted())
+	}
+	if _, err := tm.List(); !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("List on a locked store = %v", err)
+	}
+	if _, err := tm.Create(Task{Title: "x", Priority: "low"}); !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("Create on a locked store = %v", err)
+	}
+
+	if err := tm.Unlock("wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Unlock with a wrong passphrase = %v", err)
+	}
+	if err := tm.Unlock("pass"); err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	tasks, err := tm.List()
+	if err != nil || len(tasks) != 2 || tasks[0].Title != "Секретная задача" {
+		t.Errorf("tasks = %+v, %v", tasks, err)
+	}
+	if _, err := tm.Create(Task{Title: "После разблокировки", Priority: "low"}); err != nil {
+		t.Errorf("Create: %v", err)
+	}
+	assertSealed(t, true, filename)
+}
+
+func TestUnsealedFileRejected(t *testing.T) {
+	tm, filename := newEncryptedManager(t, "pass")
+	defer tm.Close()
+
+	// Другой процесс (или злоумышленник) подложил незашифрованный файл
+	plain, err := encodeTaskFile(taskFile{Tasks: []Task{{ID: 1, Title: "Подложенная", Priority: "low"}}, NextID: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filename, plain, dataFileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	// Подложенные задачи не читаются, а файл не шифруется заново поверх
+	if _, err := tm.Create(Task{Title: "Новая", Priority: "low"}); !errors.Is(err, errUnsealed) {
+		t.Errorf("Create = %v, want errUnsealed", err)
+	}
+	if tasks, _ := tm.List(); len(tasks) != 2 || tasks[0].Title != "Секретная задача" {
+		t.Errorf("tasks = %+v", tasks)
+	}
+	if data, _ := os.ReadFile(filename); string(data) != string(plain) {
+		t.Error("unsealed file was rewritten")
+	}
+
+	if _, err := (sealedFormat{inner: jsonFormat{}, key: nil}).decode(plain, nil); !errors.Is(err, errUnsealed) {
+		t.Errorf("decode of unsealed data = %v", err)
+	}
+	if _, err := (sealedFormat{inner: jsonFormat{}}).open([]byte("{}")); !errors.Is(err, errUnsealed) {
+		t.Errorf("open of unsealed meta = %v", err)
+	}
+}
+
+func TestChangeAndDisablePassphrase(t *testing.T) {
+	tm, filename := newEncryptedManager(t, "old")
+	defer tm.Close()
+	// Оригинал файла до обновления формата тоже перешифровывается
+	original := filename + ".v1"
+	if err := os.WriteFile(original, []byte(`{"tasks":[{"id":1,"title":"Секретная задача"}]}`), dataFileMode); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tm.SetPassphrase("wrong", "new"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("SetPassphrase with a wrong current passphrase = %v", err)
+	}
+	if err := tm.SetPassphrase("old", "new"); err != nil {
+		t.Fatalf("SetPassphrase: %v", err)
+	}
+	copies := []string{filename, backupName(filename, 1), backupName(filename, 2), original}
+	assertSealed(t, true, copies...)
+	for _, name := range copies {
+		data, _ := os.ReadFile(name)
+		if _, err := vault.Unlock(data, "new"); err != nil {
+			t.Errorf("%s does not open with the new passphrase: %v", filepath.Base(name), err)
+		}
+	}
+
+	if err := tm.SetPassphrase("new", ""); err != nil {
+		t.Fatalf("disable: %v", err)
+	}
+	assertSealed(t, false, copies...)
+
+	reopened, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if reopened.Encrypted() {
+		t.Error("store is still encrypted")
+	}
+	if tasks, _ := reopened.List(); len(tasks) != 2 {
+		t.Errorf("tasks = %+v", tasks)
+	}
+}
+
+func TestPassphraseChangedByAnotherProcess(t *testing.T) {
+	tm, filename := newEncryptedManager(t, "pass")
+	defer tm.Close()
+
+	other, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if err := other.Unlock("pass"); err != nil {
+		t.Fatal(err)
+	}
+	if err := other.SetPassphrase("pass", "changed"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Старый ключ не подходит: хранилище запирается до ввода новой фразы
+	if _, err := tm.List(); !errors.Is(err, ErrStoreLocked) {
+		t.Errorf("List = %v, want ErrStoreLocked", err)
+	}
+	if !tm.Locked() {
+		t.Error("store is not locked")
+	}
+	if err := tm.Unlock("pass"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Unlock with the old passphrase = %v", err)
+	}
+	if err := tm.Unlock("changed"); err != nil {
+		t.Errorf("Unlock with the new passphrase: %v", err)
+	}
+}
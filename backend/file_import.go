@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+	"todo-list/backend/internal/service"
+	"todo-list/backend/internal/vault"
+)
+
+// MigrateJSONFile переносит задачи, корзину, метки и журнал изменений из JSON-файла
+// приложения (пустой filename - DefaultDataFile) в базу данных PostgreSQL. Перенос
+// выполняется одной транзакцией; задачи, перенесенные раньше, пропускаются, поэтому
+// команду можно повторять. Зашифрованный файл расшифровывается парольной фразой
+// passphrase. Файл не меняется.
+func MigrateJSONFile(db *sql.DB, filename, passphrase string, dryRun bool) (*service.FileImportReport, error) {
+	if filename == "" {
+		filename = DefaultDataFile()
+	}
+	var format fileFormat = jsonFormat{}
+	if data, err := os.ReadFile(filename); err == nil && vault.IsSealed(data) {
+		if passphrase == "" {
+			return nil, ErrStoreLocked
+		}
+		key, err := vault.Unlock(data, passphrase)
+		if errors.Is(err, vault.ErrWrongKey) {
+			return nil, ErrWrongPassphrase
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to unlock %s: %w", filename, err)
+		}
+		format = sealedFormat{inner: format, key: key}
+	}
+	content, err := readTaskFile(filename, format)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filename, err)
+	}
+
+	all := append(append([]Task{}, content.Tasks...), content.Trash...)
+	keys := make(map[int]string, len(all))
+	for _, task := range all {
+		keys[task.ID] = fileTaskKey(task)
+	}
+	activity := make(map[int][]models.Activity)
+	for _, entry := range content.Activity {
+		if _, ok := keys[entry.TaskID]; ok {
+			activity[entry.TaskID] = append(activity[entry.TaskID], models.Activity{
+				Title:     entry.Title,
+				Action:    entry.Action,
+				Changes:   entry.Changes,
+				Source:    entry.Source,
+				Actor:     entry.Actor,
+				CreatedAt: entry.At,
+			})
+		}
+	}
+
+	tasks := make([]models.FileTask, 0, len(all))
+	for _, task := range all {
+		todo := todoFromTask(task)
+		todo.ID, todo.ParentID = 0, nil
+		if !task.DeletedAt.IsZero() {
+			deleted := task.DeletedAt
+			todo.DeletedAt = &deleted
+		}
+		tasks = append(tasks, models.FileTask{
+			Key:       keys[task.ID],
+			ParentKey: keys[task.ParentID],
+			Todo:      todo,
+			Activity:  activity[task.ID],
+		})
+	}
+	tags := make([]models.Tag, 0, len(content.Tags))
+	for _, tag := range content.Tags {
+		tags = append(tags, models.Tag{Name: tag.Name, Color: tag.Color})
+	}
+
+	return service.NewFileImportService(repository.NewRepository(db)).Import(tasks, tags, dryRun)
+}
+
+// fileTaskKey ключ задачи файла в file_imports. ID задач разных файлов совпадают,
+// поэтому к нему добавляется время создания.
+func fileTaskKey(task Task) string {
+	return strconv.Itoa(task.ID) + "@" + task.CreatedAt.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,395 @@
+package backend
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"time"
+
+	"todo-list/backend/internal/fsutil"
+)
+
+// HistoryLimit число шагов отмены, которые хранит история
+const HistoryLimit = 100
+
+// ErrHistoryConflict возвращается, если задачи изменились в обход истории
+// (например, через REST API) и отмена затерла бы эти изменения
+var ErrHistoryConflict = errors.New("задачи изменились после этого действия")
+
+// TaskChange состояние задачи до и после команды; nil - задачи не было
+// (или она в корзине). Created отличает задачу, созданную командой, от возвращенной
+// из корзины: отмена удаляет ее окончательно, а не переносит в корзину.
+type TaskChange struct {
+	ID      int   `json:"id"`
+	Before  *Task `json:"before"`
+	After   *Task `json:"after"`
+	Created bool  `json:"created,omitempty"`
+}
+
+// Command обратимое изменение задач - один шаг отмены
+type Command struct {
+	Label   string       `json:"label"`
+	At      time.Time    `json:"at"`
+	Changes []TaskChange `json:"changes"`
+}
+
+// HistoryStatus состояние истории для кнопок отмены и повтора
+type HistoryStatus struct {
+	CanUndo   bool   `json:"can_undo"`
+	CanRedo   bool   `json:"can_redo"`
+	UndoLabel string `json:"undo_label"`
+	RedoLabel string `json:"redo_label"`
+}
+
+// history стек отмены и повтора, сохраняемый в файл
+type history struct {
+	filename string
+	Undo     []Command `json:"undo"`
+	Redo     []Command `json:"redo"`
+	group    *Command  // открытая группа: все изменения попадут в один шаг
+}
+
+// newHistory загружает историю из filename; пустое имя - история только в памяти
+func newHistory(filename string) *history {
+	h := &history{filename: filename}
+	if filename == "" {
+		return h
+	}
+
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return h
+	}
+	if err := json.Unmarshal(data, h); err != nil {
+		log.Printf("Error reading history: %v", err)
+	}
+	return h
+}
+
+// push добавляет команду в историю (или в открытую группу) и очищает стек повтора
+func (h *history) push(cmd Command) {
+	if h.group != nil {
+		h.group.Changes = mergeChanges(h.group.Changes, cmd.Changes)
+		return
+	}
+
+	h.Undo = append(h.Undo, cmd)
+	if len(h.Undo) > HistoryLimit {
+		h.Undo = h.Undo[len(h.Undo)-HistoryLimit:]
+	}
+	h.Redo = nil
+	h.save()
+}
+
+// save записывает историю в файл
+func (h *history) save() {
+	if h.filename == "" {
+		return
+	}
+	data, err := json.Marshal(h)
+	if err != nil {
+		log.Printf("Error marshaling history: %v", err)
+		return
+	}
+	if err := fsutil.WriteFileAtomic(h.filename, data, dataFileMode); err != nil {
+		log.Printf("Error saving history: %v", err)
+	}
+}
+
+// mergeChanges объединяет изменения группы: для каждой задачи сохраняется
+// самое раннее состояние "до" и самое позднее состояние "после"
+func mergeChanges(changes, more []TaskChange) []TaskChange {
+	index := make(map[int]int, len(changes))
+	for i, change := range changes {
+		index[change.ID] = i
+	}
+	for _, change := range more {
+		if i, ok := index[change.ID]; ok {
+			changes[i].After = change.After
+			continue
+		}
+		index[change.ID] = len(changes)
+		changes = append(changes, change)
+	}
+
+	// Задачи, созданные и удаленные внутри группы, не меняют состояния
+	result := changes[:0]
+	for _, change := range changes {
+		if change.Before != nil || change.After != nil {
+			result = append(result, change)
+		}
+	}
+	return result
+}
+
+// record выполняет изменение задач и записывает его в историю как один шаг отмены.
+// Команда меняет задачи через a.writer(), который запоминает состояние затронутых задач.
+func (a *App) record(label string, mutate func() bool) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rec = newRecorder(a.store)
+	defer func() { a.rec = nil }()
+
+	ok := mutate()
+
+	changes, err := a.rec.changes()
+	if err != nil {
+		log.Printf("Error loading tasks: %v", err)
+		return ok
+	}
+	if len(changes) > 0 {
+		a.history.push(Command{Label: label, At: time.Now(), Changes: changes})
+	}
+	return ok
+}
+
+// writer возвращает хранилище для изменения задач: внутри record - recorder команды.
+// Вызывается под a.mu.
+func (a *App) writer() Store {
+	if a.rec != nil {
+		return a.rec
+	}
+	return a.store
+}
+
+// diffTasks возвращает изменения задач между двумя снимками в порядке ID
+func diffTasks(before, after map[int]Task) []TaskChange {
+	var changes []TaskChange
+	for id, old := range before {
+		old := old
+		if current, ok := after[id]; !ok {
+			changes = append(changes, TaskChange{ID: id, Before: &old})
+		} else if !sameTask(old, current) {
+			changes = append(changes, TaskChange{ID: id, Before: &old, After: &current})
+		}
+	}
+	for id, current := range after {
+		current := current
+		if _, ok := before[id]; !ok {
+			changes = append(changes, TaskChange{ID: id, After: &current})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].ID < changes[j].ID })
+	return changes
+}
+
+// sameTask сравнивает задачи по их JSON-представлению, как они хранятся в истории
+func sameTask(a, b Task) bool {
+	dataA, errA := json.Marshal(a)
+	dataB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(dataA) == string(dataB)
+}
+
+// BeginGroup начинает групповое действие: все изменения до EndGroup отменяются одним шагом
+func (a *App) BeginGroup(label string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.history.group == nil {
+		a.history.group = &Command{Label: label, At: time.Now()}
+	}
+}
+
+// EndGroup завершает групповое действие и записывает его в историю
+func (a *App) EndGroup() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.endGroup()
+}
+
+func (a *App) endGroup() {
+	group := a.history.group
+	a.history.group = nil
+	if group != nil && len(group.Changes) > 0 {
+		a.history.push(*group)
+	}
+}
+
+// Undo отменяет последнее действие
+func (a *App) Undo() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.endGroup()
+
+	h := a.history
+	if len(h.Undo) == 0 {
+		return false
+	}
+	cmd := h.Undo[len(h.Undo)-1]
+	if err := a.apply(cmd.Changes, true); err != nil {
+		log.Printf("Error undoing %q: %v", cmd.Label, err)
+		return false
+	}
+
+	h.Undo = h.Undo[:len(h.Undo)-1]
+	h.Redo = append(h.Redo, cmd)
+	h.save()
+	return true
+}
+
+// Redo повторяет последнее отмененное действие
+func (a *App) Redo() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.endGroup()
+
+	h := a.history
+	if len(h.Redo) == 0 {
+		return false
+	}
+	cmd := h.Redo[len(h.Redo)-1]
+	if err := a.apply(cmd.Changes, false); err != nil {
+		log.Printf("Error redoing %q: %v", cmd.Label, err)
+		return false
+	}
+
+	h.Redo = h.Redo[:len(h.Redo)-1]
+	h.Undo = append(h.Undo, cmd)
+	h.save()
+	return true
+}
+
+// GetHistoryStatus возвращает, что можно отменить и повторить
+func (a *App) GetHistoryStatus() HistoryStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var status HistoryStatus
+	if n := len(a.history.Undo); n > 0 {
+		status.CanUndo = true
+		status.UndoLabel = a.history.Undo[n-1].Label
+	}
+	if n := len(a.history.Redo); n > 0 {
+		status.CanRedo = true
+		status.RedoLabel = a.history.Redo[n-1].Label
+	}
+	return status
+}
+
+// ClearHistory очищает историю отмены и повтора
+func (a *App) ClearHistory() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.history.group = nil
+	a.history.Undo = nil
+	a.history.Redo = nil
+	a.history.save()
+}
+
+// apply приводит задачи к состоянию "до" (undo) или "после" (redo).
+// Сначала проверяется, что задачи не менялись в обход истории, затем
+// сохраняются задачи (родители раньше подзадач) и удаляются лишние (подзадачи раньше родителей).
+// Отмена создания удаляет задачу окончательно, остальные удаления переносят ее в корзину.
+// Если одна из записей не удалась, уже примененные изменения откатываются,
+// чтобы хранилище осталось в состоянии, на которое рассчитана история.
+func (a *App) apply(changes []TaskChange, undo bool) error {
+	var puts, deletes []Task
+	removed := make(map[int]bool)
+	current := make(map[int]*Task) // задачи до apply, которые будут перезаписаны
+	created := make(map[int]bool)  // задачи, которых до apply не было даже в корзине
+	for _, change := range changes {
+		expected, target := change.After, change.Before
+		if !undo {
+			expected, target = change.Before, change.After
+		}
+
+		task, err := a.store.Get(change.ID)
+		exists := err == nil
+		if err != nil && !errors.Is(err, ErrTaskNotFound) {
+			return err
+		}
+		if exists != (expected != nil) || (exists && !sameTask(task, *expected)) {
+			return fmt.Errorf("%w: задача %d", ErrHistoryConflict, change.ID)
+		}
+
+		if target != nil {
+			puts = append(puts, *target)
+			if exists {
+				current[task.ID] = &task
+			}
+			created[change.ID] = !undo && change.Created
+		} else {
+			deletes = append(deletes, task)
+			removed[task.ID] = undo && change.Created
+		}
+	}
+
+	var reverts []func() error // обратные операции для уже примененных изменений
+	for _, task := range parentsFirst(puts) {
+		if err := a.store.Put(task); err != nil {
+			return a.rollback(reverts, err)
+		}
+		reverts = append(reverts, a.revertPut(task.ID, current[task.ID], created[task.ID]))
+	}
+	deletes = parentsFirst(deletes)
+	for i := len(deletes) - 1; i >= 0; i-- {
+		task := deletes[i]
+		var err error
+		if removed[task.ID] {
+			err = a.store.Remove(task.ID)
+		} else {
+			err = a.store.Delete(task.ID)
+		}
+		if err != nil && !errors.Is(err, ErrTaskNotFound) {
+			return a.rollback(reverts, err)
+		}
+		reverts = append(reverts, func() error { return a.store.Put(task) })
+	}
+	return nil
+}
+
+// revertPut возвращает операцию, отменяющую Put задачи id: прежняя версия previous
+// записывается обратно, а задача, которой не было, снова удаляется
+func (a *App) revertPut(id int, previous *Task, created bool) func() error {
+	switch {
+	case previous != nil:
+		return func() error { return a.store.Put(*previous) }
+	case created:
+		return func() error { return a.store.Remove(id) }
+	default:
+		return func() error { return a.store.Delete(id) }
+	}
+}
+
+// rollback выполняет обратные операции в обратном порядке и возвращает исходную ошибку err
+func (a *App) rollback(reverts []func() error, err error) error {
+	for i := len(reverts) - 1; i >= 0; i-- {
+		if revertErr := reverts[i](); revertErr != nil {
+			log.Printf("Error rolling back history changes: %v", revertErr)
+		}
+	}
+	return err
+}
+
+// parentsFirst упорядочивает задачи так, что родитель идет раньше своих подзадач
+func parentsFirst(tasks []Task) []Task {
+	pending := make(map[int]bool, len(tasks))
+	for _, task := range tasks {
+		pending[task.ID] = true
+	}
+
+	ordered := make([]Task, 0, len(tasks))
+	for len(ordered) < len(tasks) {
+		progress := false
+		for _, task := range tasks {
+			if pending[task.ID] && !pending[task.ParentID] {
+				ordered = append(ordered, task)
+				delete(pending, task.ID)
+				progress = true
+			}
+		}
+		if !progress {
+			// Цикл в данных: оставшиеся задачи в исходном порядке
+			for _, task := range tasks {
+				if pending[task.ID] {
+					ordered = append(ordered, task)
+				}
+			}
+			break
+		}
+	}
+	return ordered
+}
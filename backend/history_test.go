@@ -0,0 +1,282 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"todo-list/backend/config"
+)
+
+// countingStore считает полные чтения хранилища
+type countingStore struct {
+	Store
+	lists int
+}
+
+func (s *countingStore) List() ([]Task, error) {
+	s.lists++
+	return s.Store.List()
+}
+
+// newTestApp создает App поверх JSON-файла во временном каталоге
+func newTestApp(t *testing.T) (*App, *TaskManager) {
+	t.Helper()
+	dir := t.TempDir()
+	tm, err := NewTaskManager(filepath.Join(dir, "tasks.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.Config{
+		HistoryFile:   filepath.Join(dir, "history.json"),
+		RemindersFile: filepath.Join(dir, "reminders.json"),
+	}
+	return NewApp(tm, cfg), tm
+}
+
+func taskIDs(tasks []Task) []int {
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, task.ID)
+	}
+	return ids
+}
+
+func TestRecordReadsOnlyTouchedTasks(t *testing.T) {
+	a, tm := newTestApp(t)
+	for _, title := range []string{"a", "b", "c"} {
+		a.AddTask(title, "", "medium", "")
+	}
+
+	store := &countingStore{Store: tm}
+	a.store = store
+	if !a.UpdateTask(2, "b2", "", "high", "") {
+		t.Fatal("UpdateTask failed")
+	}
+	if store.lists != 0 {
+		t.Errorf("UpdateTask listed all tasks %d times", store.lists)
+	}
+
+	cmd := a.history.Undo[len(a.history.Undo)-1]
+	if len(cmd.Changes) != 1 || cmd.Changes[0].ID != 2 {
+		t.Fatalf("changes = %+v, want only task 2", cmd.Changes)
+	}
+	if cmd.Changes[0].Before.Title != "b" || cmd.Changes[0].After.Title != "b2" {
+		t.Errorf("change = %+v", cmd.Changes[0])
+	}
+}
+
+func TestUndoCreateRemovesTask(t *testing.T) {
+	a, tm := newTestApp(t)
+	task := a.AddTask("new", "", "medium", "")
+	if task.ID == 0 {
+		t.Fatal("AddTask failed")
+	}
+
+	if !a.Undo() {
+		t.Fatal("Undo failed")
+	}
+	if tasks, _ := tm.List(); len(tasks) != 0 {
+		t.Errorf("tasks after undo = %v", taskIDs(tasks))
+	}
+	if trash, _ := tm.Trash(); len(trash) != 0 {
+		t.Errorf("undo of create left %v in trash", taskIDs(trash))
+	}
+
+	if !a.Redo() {
+		t.Fatal("Redo failed")
+	}
+	if got, err := tm.Get(task.ID); err != nil || got.Title != "new" {
+		t.Errorf("Get after redo = %+v, %v", got, err)
+	}
+}
+
+func TestUndoDeleteRestoresSubtree(t *testing.T) {
+	a, tm := newTestApp(t)
+	parent := a.AddTask("parent", "", "medium", "")
+	child := a.AddSubtask(parent.ID, "child", "", "medium", "")
+
+	if !a.DeleteTask(parent.ID) {
+		t.Fatal("DeleteTask failed")
+	}
+	if trash, _ := tm.Trash(); len(trash) != 2 {
+		t.Fatalf("trash = %v, want parent and child", taskIDs(trash))
+	}
+
+	if !a.Undo() {
+		t.Fatal("Undo failed")
+	}
+	tasks, _ := tm.List()
+	if len(tasks) != 2 {
+		t.Fatalf("tasks after undo = %v", taskIDs(tasks))
+	}
+	if got, _ := tm.Get(child.ID); got.ParentID != parent.ID {
+		t.Errorf("child parent = %d, want %d", got.ParentID, parent.ID)
+	}
+	if trash, _ := tm.Trash(); len(trash) != 0 {
+		t.Errorf("trash after undo = %v", taskIDs(trash))
+	}
+
+	if !a.Redo() {
+		t.Fatal("Redo failed")
+	}
+	if trash, _ := tm.Trash(); len(trash) != 2 {
+		t.Errorf("trash after redo = %v", taskIDs(trash))
+	}
+}
+
+func TestUndoRestoreMovesBackToTrash(t *testing.T) {
+	a, tm := newTestApp(t)
+	task := a.AddTask("task", "", "medium", "")
+	a.DeleteTask(task.ID)
+	if !a.RestoreTask(task.ID) {
+		t.Fatal("RestoreTask failed")
+	}
+
+	if !a.Undo() {
+		t.Fatal("Undo failed")
+	}
+	if trash, _ := tm.Trash(); len(trash) != 1 || trash[0].ID != task.ID {
+		t.Errorf("trash after undo of restore = %v", taskIDs(trash))
+	}
+}
+
+func TestUndoRenameTag(t *testing.T) {
+	a, tm := newTestApp(t)
+	first := a.AddTask("first", "", "medium", "")
+	second := a.AddTask("second", "", "medium", "")
+	a.SetTaskTags(first.ID, []string{"work"})
+	a.SetTaskTags(second.ID, []string{"work", "home"})
+
+	if !a.RenameTag("work", "job") {
+		t.Fatal("RenameTag failed")
+	}
+	if !a.Undo() {
+		t.Fatal("Undo failed")
+	}
+	for _, id := range []int{first.ID, second.ID} {
+		task, _ := tm.Get(id)
+		if !hasTags(task, []string{"work"}, true) {
+			t.Errorf("task %d tags after undo = %v", id, task.Tags)
+		}
+	}
+}
+
+func TestUndoConflict(t *testing.T) {
+	a, tm := newTestApp(t)
+	task := a.AddTask("task", "", "medium", "")
+	a.UpdateTask(task.ID, "renamed", "", "medium", "")
+
+	// Изменение в обход App, например из командной строки другого процесса
+	changed, _ := tm.Get(task.ID)
+	changed.Title = "external"
+	if err := tm.Update(changed); err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Undo() {
+		t.Fatal("Undo overwrote an external change")
+	}
+	if got, _ := tm.Get(task.ID); got.Title != "external" {
+		t.Errorf("title = %q", got.Title)
+	}
+}
+
+// failingStore отказывает в одной записи (Put, Delete или Remove) с номером failAt, считая с нуля
+type failingStore struct {
+	Store
+	failAt int
+	writes int
+}
+
+func (s *failingStore) write() error {
+	s.writes++
+	if s.writes-1 == s.failAt {
+		return errors.New("disk full")
+	}
+	return nil
+}
+
+func (s *failingStore) Put(task Task) error {
+	if err := s.write(); err != nil {
+		return err
+	}
+	return s.Store.Put(task)
+}
+
+func (s *failingStore) Delete(id int) error {
+	if err := s.write(); err != nil {
+		return err
+	}
+	return s.Store.Delete(id)
+}
+
+func (s *failingStore) Remove(id int) error {
+	if err := s.write(); err != nil {
+		return err
+	}
+	return s.Store.Remove(id)
+}
+
+func TestUndoFailureRollsBack(t *testing.T) {
+	a, tm := newTestApp(t)
+	parent := a.AddTask("parent", "", "medium", "")
+	a.AddSubtask(parent.ID, "child", "", "medium", "")
+	a.AddSubtask(parent.ID, "child 2", "", "medium", "")
+	other := a.AddTask("other", "", "medium", "")
+
+	a.BeginGroup("Групповое изменение")
+	a.UpdateTask(other.ID, "other renamed", "", "high", "")
+	a.DeleteTask(parent.ID)
+	a.AddTask("new", "", "medium", "")
+	a.EndGroup()
+
+	snapshot := func() string {
+		tasks, _ := tm.List()
+		trash, _ := tm.Trash()
+		titles := []string{}
+		for _, task := range tasks {
+			titles = append(titles, task.Title)
+		}
+		return fmt.Sprint(titles, taskIDs(trash))
+	}
+	before := snapshot()
+
+	// Отмена записывает other и три задачи из корзины, затем удаляет new;
+	// сбой на любом шаге возвращает хранилище в исходное состояние
+	for failAt := 0; failAt < 5; failAt++ {
+		a.store = &failingStore{Store: tm, failAt: failAt}
+		if a.Undo() {
+			t.Fatalf("Undo succeeded with write %d failing", failAt)
+		}
+		if got := snapshot(); got != before {
+			t.Errorf("failure of write %d left %s, want %s", failAt, got, before)
+		}
+		if status := a.GetHistoryStatus(); !status.CanUndo || status.UndoLabel != "Групповое изменение" || status.CanRedo {
+			t.Errorf("history after failed undo = %+v", status)
+		}
+	}
+
+	// После восстановления хранилища отмена проходит целиком
+	a.store = tm
+	if !a.Undo() {
+		t.Fatal("Undo failed")
+	}
+	if got := snapshot(); got != "[other parent child child 2] []" {
+		t.Errorf("after undo = %s", got)
+	}
+
+	// Сбой повтора откатывает восстановленные задачи и не трогает историю
+	a.store = &failingStore{Store: tm, failAt: 2}
+	if a.Redo() {
+		t.Fatal("Redo succeeded")
+	}
+	if got := snapshot(); got != "[other parent child child 2] []" {
+		t.Errorf("after failed redo = %s", got)
+	}
+	a.store = tm
+	if !a.Redo() || snapshot() != before {
+		t.Errorf("after redo = %s, want %s", snapshot(), before)
+	}
+}
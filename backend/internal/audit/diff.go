@@ -0,0 +1,87 @@
+// Package audit сравнивает состояния задачи для журнала изменений
+package audit
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-list/backend/internal/models"
+)
+
+// Diff возвращает изменения отслеживаемых полей задачи. nil сравнивается как пустая
+// задача, поэтому для новой задачи (before == nil) в изменения попадают ее непустые поля.
+// Служебные поля (ID, даты создания, изменения и удаления) не сравниваются.
+func Diff(before, after *models.Todo) []models.FieldChange {
+	old, cur := fields(before), fields(after)
+
+	changes := []models.FieldChange{}
+	for i, field := range fieldNames {
+		if old[i] != cur[i] {
+			changes = append(changes, models.FieldChange{Field: field, Old: old[i], New: cur[i]})
+		}
+	}
+	return changes
+}
+
+// Action определяет действие по состояниям до и после: создание, удаление,
+// выполнение или открытие (если поменялся статус) либо обычное изменение
+func Action(before, after *models.Todo) string {
+	switch {
+	case before == nil:
+		return models.ActivityCreate
+	case after == nil:
+		return models.ActivityDelete
+	case !before.Completed && after.Completed:
+		return models.ActivityComplete
+	case before.Completed && !after.Completed:
+		return models.ActivityReopen
+	default:
+		return models.ActivityUpdate
+	}
+}
+
+// fieldNames отслеживаемые поля в порядке вывода
+var fieldNames = []string{
+	"title", "description", "completed", "priority", "due_date",
+	"category_id", "parent_id", "recurrence", "reminders", "tags",
+}
+
+// fields форматирует отслеживаемые поля задачи как текст
+func fields(todo *models.Todo) []string {
+	if todo == nil {
+		todo = &models.Todo{}
+	}
+
+	reminders := make([]string, len(todo.Reminders))
+	for i, offset := range todo.Reminders {
+		reminders[i] = strconv.FormatInt(offset, 10)
+	}
+
+	return []string{
+		todo.Title,
+		todo.Description,
+		strconv.FormatBool(todo.Completed),
+		todo.Priority,
+		formatTime(todo.DueDate),
+		formatID(todo.CategoryID),
+		formatID(todo.ParentID),
+		todo.Recurrence,
+		strings.Join(reminders, ","),
+		strings.Join(todo.Tags, ","),
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+func formatID(id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return strconv.FormatUint(uint64(*id), 10)
+}
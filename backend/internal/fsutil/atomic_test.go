@@ -0,0 +1,44 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "tasks.json")
+
+	if err := WriteFileAtomic(filename, []byte("first"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0600 {
+		t.Errorf("new file mode = %v", info.Mode().Perm())
+	}
+
+	// Права существующего файла сохраняются, содержимое заменяется целиком
+	if err := os.Chmod(filename, 0640); err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteFileAtomic(filename, []byte("second"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filename)
+	if err != nil || string(data) != "second" {
+		t.Errorf("content = %q, %v", data, err)
+	}
+	if info, _ := os.Stat(filename); info.Mode().Perm() != 0640 {
+		t.Errorf("mode after rewrite = %v", info.Mode().Perm())
+	}
+
+	// Временные файлы не остаются в каталоге
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries", len(entries))
+	}
+
+	if err := WriteFileAtomic(filepath.Join(dir, "missing", "tasks.json"), nil, 0600); err == nil {
+		t.Error("write into a missing directory succeeded")
+	}
+}
@@ -0,0 +1,32 @@
+package fsutil
+
+import "os"
+
+// FileLock рекомендательная (advisory) блокировка файла между процессами.
+// Блокировка держится на отдельном файле, а не на самих данных: файл данных
+// заменяется переименованием, и блокировка на нем терялась бы при каждой записи.
+type FileLock struct {
+	f *os.File
+}
+
+// Lock ждет и захватывает исключительную блокировку файла path, создавая его при необходимости
+func Lock(path string) (*FileLock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return &FileLock{f: f}, nil
+}
+
+// Unlock снимает блокировку
+func (l *FileLock) Unlock() error {
+	err := unlockFile(l.f)
+	if closeErr := l.f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
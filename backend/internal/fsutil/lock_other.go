@@ -0,0 +1,16 @@
+//go:build !unix && !windows
+
+package fsutil
+
+import "os"
+
+// На остальных системах блокировок файлов нет: процессы не согласуют запись между собой,
+// внутри процесса доступ по-прежнему упорядочивают блокировки TaskManager
+
+func lockFile(f *os.File) error {
+	return nil
+}
+
+func unlockFile(f *os.File) error {
+	return nil
+}
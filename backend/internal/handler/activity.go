@@ -0,0 +1,72 @@
+// handler/activity.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// GetTaskHistory возвращает журнал изменений задачи, новые записи первыми
+func (h *TaskHandler) GetTaskHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	limit, err := parseLimit(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	history, err := h.service.GetTaskHistory(id, limit)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, history)
+}
+
+// GetActivity возвращает ленту изменений всех задач; before - ID записи, с которой продолжить
+func (h *TaskHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var before uint64
+	if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
+		if before, err = strconv.ParseUint(beforeStr, 10, 64); err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid before")
+			return
+		}
+	}
+
+	activity, err := h.service.GetActivity(uint(before), limit)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, activity)
+}
+
+// parseLimit читает необязательный положительный параметр limit
+func parseLimit(r *http.Request) (int, error) {
+	limitStr := r.URL.Query().Get("limit")
+	if limitStr == "" {
+		return 0, nil
+	}
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil || limit <= 0 {
+		return 0, errors.New("Invalid limit")
+	}
+	return limit, nil
+}
@@ -0,0 +1,73 @@
+// handler/auth.go
+package handler
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// publicRoute имя маршрутов, доступных без авторизации
+const publicRoute = "public"
+
+// Credentials логин и пароль REST API и CalDAV. Пока пароль не задан, API
+// отвечает только клиентам с того же компьютера (loopback).
+type Credentials struct {
+	User     string
+	Password string
+}
+
+// Enabled сообщает, что API требует логин и пароль
+func (c Credentials) Enabled() bool {
+	return c.Password != ""
+}
+
+// valid проверяет логин и пароль за постоянное время
+func (c Credentials) valid(user, password string) bool {
+	userOK := subtle.ConstantTimeCompare([]byte(user), []byte(c.User)) == 1
+	passwordOK := subtle.ConstantTimeCompare([]byte(password), []byte(c.Password)) == 1
+	return userOK && passwordOK
+}
+
+// authMiddleware пропускает к закрытым маршрутам только авторизованные запросы:
+// с логином и паролем Basic-авторизации или, если пароль не задан, с loopback-адреса.
+// Публичны только маршруты с именем publicRoute - календари подписок, защищенные токеном.
+func authMiddleware(creds Credentials) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if route := mux.CurrentRoute(r); route != nil && route.GetName() == publicRoute {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if !creds.Enabled() {
+				if !loopbackRequest(r) {
+					writeJSON(w, http.StatusForbidden, Response{Error: "API is available only from localhost: set APP_PASSWORD"})
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			user, password, ok := r.BasicAuth()
+			if !ok || !creds.valid(user, password) {
+				w.Header().Set("WWW-Authenticate", `Basic realm="todo-list", charset="UTF-8"`)
+				writeJSON(w, http.StatusUnauthorized, Response{Error: "Unauthorized"})
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// loopbackRequest сообщает, что запрос пришел с того же компьютера
+func loopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+)
+
+// newAuthRouter роутер с одним закрытым и одним публичным маршрутом
+func newAuthRouter(creds Credentials) *mux.Router {
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	router := mux.NewRouter()
+	router.Use(authMiddleware(creds))
+	router.HandleFunc(APIPrefix+"/feeds", ok)
+	router.HandleFunc(APIPrefix+"/feeds/{token}.ics", ok).Name(publicRoute)
+	return router
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	creds := Credentials{User: "todo", Password: "secret"}
+	tests := []struct {
+		name     string
+		creds    Credentials
+		path     string
+		remote   string
+		user     string
+		password string
+		want     int
+	}{
+		{"feed without auth", creds, "/api/v1/feeds/abc.ics", "203.0.113.5:4000", "", "", http.StatusOK},
+		{"feed list without auth", creds, "/api/v1/feeds", "203.0.113.5:4000", "", "", http.StatusUnauthorized},
+		{"feed list from loopback without auth", creds, "/api/v1/feeds", "127.0.0.1:4000", "", "", http.StatusUnauthorized},
+		{"wrong password", creds, "/api/v1/feeds", "203.0.113.5:4000", "todo", "guess", http.StatusUnauthorized},
+		{"valid credentials", creds, "/api/v1/feeds", "203.0.113.5:4000", "todo", "secret", http.StatusOK},
+		{"no password, remote", Credentials{}, "/api/v1/feeds", "203.0.113.5:4000", "", "", http.StatusForbidden},
+		{"no password, loopback", Credentials{}, "/api/v1/feeds", "127.0.0.1:4000", "", "", http.StatusOK},
+		{"no password, ipv6 loopback", Credentials{}, "/api/v1/feeds", "[::1]:4000", "", "", http.StatusOK},
+		{"no password, remote feed", Credentials{}, "/api/v1/feeds/abc.ics", "203.0.113.5:4000", "", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.RemoteAddr = tt.remote
+			if tt.user != "" {
+				req.SetBasicAuth(tt.user, tt.password)
+			}
+			rec := httptest.NewRecorder()
+			newAuthRouter(tt.creds).ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if rec.Code == http.StatusUnauthorized && rec.Header().Get("WWW-Authenticate") == "" {
+				t.Error("401 without WWW-Authenticate")
+			}
+		})
+	}
+}
+
+func TestLogPathHidesFeedToken(t *testing.T) {
+	if got := logPath("/api/v1/feeds/s3cr3t.ics"); got != "/api/v1/feeds/***.ics" {
+		t.Errorf("logPath = %q", got)
+	}
+	if got := logPath("/api/v1/feeds/12"); got != "/api/v1/feeds/12" {
+		t.Errorf("logPath = %q", got)
+	}
+}
@@ -0,0 +1,636 @@
+// handler/caldav.go
+package handler
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-list/backend/internal/service"
+)
+
+// Пути CalDAV: принципал единственного пользователя и домашняя коллекция его календарей
+const (
+	CalDAVPrefix    = "/dav"
+	caldavPrincipal = CalDAVPrefix + "/principal/"
+	caldavHome      = CalDAVPrefix + "/calendars/"
+)
+
+// Пространства имен XML WebDAV и CalDAV
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+	nsApple  = "http://apple.com/ns/ical/"
+)
+
+// davPrefixes префиксы пространств имен в ответах multistatus
+var davPrefixes = map[string]string{nsDAV: "D", nsCalDAV: "C", nsCS: "CS", nsApple: "A"}
+
+const (
+	caldavAllow       = "OPTIONS, GET, HEAD, PUT, DELETE, PROPFIND, REPORT"
+	caldavContentType = "text/calendar; charset=utf-8; component=vtodo"
+	// maxCalDAVBody ограничение размера тела запроса
+	maxCalDAVBody = 1 << 20
+)
+
+// CalDAVHandler минимальный сервер CalDAV (RFC 4791) с отчетом sync-collection (RFC 6578):
+// категории - календари задач VTODO в /dav/calendars/{id}/, задачи - ресурсы {name}.ics
+type CalDAVHandler struct {
+	service service.CalDAVService
+}
+
+func NewCalDAVHandler(service service.CalDAVService) *CalDAVHandler {
+	return &CalDAVHandler{service: service}
+}
+
+// davKind вид ресурса CalDAV
+type davKind int
+
+const (
+	davRoot davKind = iota
+	davPrincipal
+	davHome
+	davCalendar
+	davObject
+)
+
+// davResource ресурс запроса или ответа multistatus
+type davResource struct {
+	kind     davKind
+	href     string
+	calendar *service.DAVCalendar
+	object   *service.DAVObject
+}
+
+// davTarget разбирает путь запроса: /dav/, /dav/principal/, /dav/calendars/,
+// /dav/calendars/{calendar}/ или /dav/calendars/{calendar}/{name}
+func davTarget(path string) (kind davKind, calendar, name string, ok bool) {
+	rest, found := strings.CutPrefix(path, CalDAVPrefix)
+	if !found {
+		return 0, "", "", false
+	}
+	parts := strings.Split(strings.Trim(rest, "/"), "/")
+	switch {
+	case rest == "" || rest == "/":
+		return davRoot, "", "", true
+	case len(parts) == 1 && parts[0] == "principal":
+		return davPrincipal, "", "", true
+	case parts[0] != "calendars":
+		return 0, "", "", false
+	case len(parts) == 1:
+		return davHome, "", "", true
+	case len(parts) == 2:
+		return davCalendar, parts[1], "", true
+	case len(parts) == 3 && !strings.HasSuffix(rest, "/"):
+		return davObject, parts[1], parts[2], true
+	}
+	return 0, "", "", false
+}
+
+func calendarHref(id string) string {
+	return caldavHome + url.PathEscape(id) + "/"
+}
+
+func objectHref(calendarID, name string) string {
+	return calendarHref(calendarID) + url.PathEscape(name)
+}
+
+func (h *CalDAVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	kind, calendarID, name, ok := davTarget(r.URL.Path)
+	if !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("DAV", "1, 3, calendar-access")
+	switch {
+	case r.Method == http.MethodOptions:
+		w.Header().Set("Allow", caldavAllow)
+		w.WriteHeader(http.StatusOK)
+	case r.Method == "PROPFIND":
+		h.propfind(w, r, kind, calendarID, name)
+	case r.Method == "REPORT" && kind == davCalendar:
+		h.report(w, r, calendarID)
+	case (r.Method == http.MethodGet || r.Method == http.MethodHead) && kind == davObject:
+		h.get(w, r, calendarID, name)
+	case r.Method == http.MethodPut && kind == davObject:
+		h.put(w, r, calendarID, name)
+	case r.Method == http.MethodDelete && kind == davObject:
+		h.delete(w, r, calendarID, name)
+	default:
+		w.Header().Set("Allow", caldavAllow)
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// davRequest тело PROPFIND и REPORT: запрошенные свойства, ссылки calendar-multiget,
+// sync-token отчета sync-collection и фильтр calendar-query
+type davRequest struct {
+	XMLName   xml.Name
+	AllProp   *struct{}     `xml:"DAV: allprop"`
+	PropName  *struct{}     `xml:"DAV: propname"`
+	Prop      *davPropNames `xml:"DAV: prop"`
+	Hrefs     []string      `xml:"DAV: href"`
+	SyncToken string        `xml:"DAV: sync-token"`
+	Filter    *davFilter    `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+type davPropNames struct {
+	Names []struct {
+		XMLName xml.Name
+	} `xml:",any"`
+}
+
+type davFilter struct {
+	Comp davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type davCompFilter struct {
+	Name  string          `xml:"name,attr"`
+	Comps []davCompFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+// readDAVRequest разбирает тело запроса; пустое тело - запрос всех свойств
+func readDAVRequest(w http.ResponseWriter, r *http.Request) (*davRequest, error) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCalDAVBody))
+	if err != nil {
+		return nil, err
+	}
+	req := &davRequest{}
+	if len(bytes.TrimSpace(body)) == 0 {
+		req.AllProp = &struct{}{}
+		return req, nil
+	}
+	if err := xml.Unmarshal(body, req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// props возвращает запрошенные свойства; nil - все свойства ресурса
+func (req *davRequest) props() []xml.Name {
+	if req.Prop == nil || req.AllProp != nil || req.PropName != nil {
+		return nil
+	}
+	names := make([]xml.Name, 0, len(req.Prop.Names))
+	for _, name := range req.Prop.Names {
+		names = append(names, name.XMLName)
+	}
+	return names
+}
+
+// onlyOtherComponents сообщает, что фильтр calendar-query отбирает не задачи (VEVENT и т.д.)
+func (req *davRequest) onlyOtherComponents() bool {
+	if req.Filter == nil || len(req.Filter.Comp.Comps) == 0 {
+		return false
+	}
+	for _, comp := range req.Filter.Comp.Comps {
+		if strings.EqualFold(comp.Name, "VTODO") {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *CalDAVHandler) propfind(w http.ResponseWriter, r *http.Request, kind davKind, calendarID, name string) {
+	req, err := readDAVRequest(w, r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	// Depth: infinity не поддерживается и считается равным 1
+	children := r.Header.Get("Depth") != "0"
+
+	var resources []davResource
+	switch kind {
+	case davRoot:
+		resources = append(resources, davResource{kind: davRoot, href: CalDAVPrefix + "/"})
+		if children {
+			resources = append(resources,
+				davResource{kind: davPrincipal, href: caldavPrincipal},
+				davResource{kind: davHome, href: caldavHome})
+		}
+	case davPrincipal:
+		resources = append(resources, davResource{kind: davPrincipal, href: caldavPrincipal})
+	case davHome:
+		resources = append(resources, davResource{kind: davHome, href: caldavHome})
+		if children {
+			calendars, err := h.service.Calendars()
+			if err != nil {
+				h.writeError(w, err)
+				return
+			}
+			for i := range calendars {
+				resources = append(resources, davResource{kind: davCalendar, href: calendarHref(calendars[i].ID), calendar: &calendars[i]})
+			}
+		}
+	case davCalendar:
+		calendar, err := h.service.Calendar(calendarID)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		resources = append(resources, davResource{kind: davCalendar, href: calendarHref(calendar.ID), calendar: calendar})
+		if children {
+			objects, err := h.service.Objects(calendarID)
+			if err != nil {
+				h.writeError(w, err)
+				return
+			}
+			resources = append(resources, objectResources(calendarID, objects)...)
+		}
+	case davObject:
+		object, err := h.service.Object(calendarID, name)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		resources = append(resources, davResource{kind: davObject, href: objectHref(calendarID, object.Name), object: object})
+	}
+
+	syncToken := ""
+	if kind == davHome || kind == davCalendar {
+		if syncToken, err = h.service.SyncToken(); err != nil {
+			h.writeError(w, err)
+			return
+		}
+	}
+
+	ms := newMultistatus()
+	for _, res := range resources {
+		ms.propResponse(res, req.props(), syncToken)
+	}
+	ms.write(w, "")
+}
+
+func objectResources(calendarID string, objects []service.DAVObject) []davResource {
+	resources := make([]davResource, 0, len(objects))
+	for i := range objects {
+		resources = append(resources, davResource{
+			kind:   davObject,
+			href:   objectHref(calendarID, objects[i].Name),
+			object: &objects[i],
+		})
+	}
+	return resources
+}
+
+// report выполняет отчеты календаря: calendar-query (фильтр учитывается только по типу
+// компонента), calendar-multiget и sync-collection
+func (h *CalDAVHandler) report(w http.ResponseWriter, r *http.Request, calendarID string) {
+	req, err := readDAVRequest(w, r)
+	if err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ms := newMultistatus()
+	syncToken := ""
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		objects, err := h.service.Objects(calendarID)
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		if !req.onlyOtherComponents() {
+			for _, res := range objectResources(calendarID, objects) {
+				ms.propResponse(res, req.props(), "")
+			}
+		}
+
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		if _, err := h.service.Calendar(calendarID); err != nil {
+			h.writeError(w, err)
+			return
+		}
+		for _, href := range req.Hrefs {
+			name, ok := hrefObjectName(href, calendarID)
+			if !ok {
+				ms.statusResponse(href, http.StatusNotFound)
+				continue
+			}
+			object, err := h.service.Object(calendarID, name)
+			if errors.Is(err, service.ErrObjectNotFound) {
+				ms.statusResponse(href, http.StatusNotFound)
+				continue
+			} else if err != nil {
+				h.writeError(w, err)
+				return
+			}
+			ms.propResponse(davResource{kind: davObject, href: objectHref(calendarID, object.Name), object: object}, req.props(), "")
+		}
+
+	case xml.Name{Space: nsDAV, Local: "sync-collection"}:
+		changes, err := h.service.Changes(calendarID, strings.TrimSpace(req.SyncToken))
+		if err != nil {
+			h.writeError(w, err)
+			return
+		}
+		for _, res := range objectResources(calendarID, changes.Changed) {
+			ms.propResponse(res, req.props(), "")
+		}
+		for _, name := range changes.Removed {
+			ms.statusResponse(objectHref(calendarID, name), http.StatusNotFound)
+		}
+		syncToken = changes.SyncToken
+
+	default:
+		writeDAVError(w, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "supported-report"})
+		return
+	}
+	ms.write(w, syncToken)
+}
+
+// hrefObjectName возвращает имя ресурса из ссылки на задачу календаря calendarID;
+// ссылка может быть путем или полным URL
+func hrefObjectName(href, calendarID string) (string, bool) {
+	u, err := url.Parse(strings.TrimSpace(href))
+	if err != nil {
+		return "", false
+	}
+	kind, calendar, name, ok := davTarget(u.Path)
+	if !ok || kind != davObject || calendar != calendarID {
+		return "", false
+	}
+	return name, true
+}
+
+func (h *CalDAVHandler) get(w http.ResponseWriter, r *http.Request, calendarID, name string) {
+	object, err := h.service.Object(calendarID, name)
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", caldavContentType)
+	w.Header().Set("ETag", object.ETag)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(object.Data))
+}
+
+// put создает или заменяет задачу. ETag в ответе не передается: сохраненная задача
+// отличается от присланной, и клиент должен перечитать ее (RFC 4791, 5.3.4).
+func (h *CalDAVHandler) put(w http.ResponseWriter, r *http.Request, calendarID, name string) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxCalDAVBody))
+	if err != nil {
+		http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	created, err := h.service.PutObject(calendarID, name, data, davCondition(r))
+	if err != nil {
+		h.writeError(w, err)
+		return
+	}
+	if created {
+		w.WriteHeader(http.StatusCreated)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *CalDAVHandler) delete(w http.ResponseWriter, r *http.Request, calendarID, name string) {
+	if err := h.service.DeleteObject(calendarID, name, davCondition(r)); err != nil {
+		h.writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func davCondition(r *http.Request) service.DAVCondition {
+	return service.DAVCondition{
+		IfMatch:     r.Header.Get("If-Match"),
+		IfNoneMatch: strings.TrimSpace(r.Header.Get("If-None-Match")),
+	}
+}
+
+// writeError переводит ошибку сервиса в ответ; нарушенные условия CalDAV передаются
+// элементом DAV:error
+func (h *CalDAVHandler) writeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrCalendarNotFound), errors.Is(err, service.ErrObjectNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrPrecondition):
+		http.Error(w, err.Error(), http.StatusPreconditionFailed)
+	case errors.Is(err, service.ErrUIDConflict):
+		writeDAVError(w, http.StatusForbidden, xml.Name{Space: nsCalDAV, Local: "no-uid-conflict"})
+	case errors.Is(err, service.ErrInvalidSyncToken):
+		writeDAVError(w, http.StatusForbidden, xml.Name{Space: nsDAV, Local: "valid-sync-token"})
+	case errors.Is(err, service.ErrInvalidObject), errors.Is(err, service.ErrInvalidRecurrence),
+		errors.Is(err, service.ErrRecurrenceDueDate), errors.Is(err, service.ErrInvalidReminders),
+		errors.Is(err, service.ErrInvalidTag), errors.Is(err, service.ErrTaskCycle):
+		log.Printf("Rejected CalDAV object: %v", err)
+		writeDAVError(w, http.StatusForbidden, xml.Name{Space: nsCalDAV, Local: "valid-calendar-data"})
+	default:
+		log.Printf("Error handling CalDAV request: %v", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+	}
+}
+
+// writeDAVError отвечает элементом DAV:error с нарушенным условием
+func writeDAVError(w http.ResponseWriter, status int, condition xml.Name) {
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(status)
+	io.WriteString(w, xml.Header+`<D:error xmlns:D="DAV:" xmlns:C="`+nsCalDAV+`">`+
+		davElement(condition, "")+`</D:error>`)
+}
+
+// davAllProps свойства, которые возвращает PROPFIND без списка свойств
+var davAllProps = []xml.Name{
+	{Space: nsDAV, Local: "resourcetype"},
+	{Space: nsDAV, Local: "displayname"},
+	{Space: nsDAV, Local: "current-user-principal"},
+	{Space: nsDAV, Local: "principal-URL"},
+	{Space: nsCalDAV, Local: "calendar-home-set"},
+	{Space: nsCalDAV, Local: "supported-calendar-component-set"},
+	{Space: nsDAV, Local: "supported-report-set"},
+	{Space: nsDAV, Local: "current-user-privilege-set"},
+	{Space: nsDAV, Local: "owner"},
+	{Space: nsDAV, Local: "sync-token"},
+	{Space: nsCS, Local: "getctag"},
+	{Space: nsApple, Local: "calendar-color"},
+	{Space: nsDAV, Local: "getetag"},
+	{Space: nsDAV, Local: "getcontenttype"},
+	{Space: nsDAV, Local: "getcontentlength"},
+}
+
+// davProp возвращает содержимое свойства ресурса в XML; false - у ресурса его нет
+func davProp(res davResource, name xml.Name, syncToken string) (string, bool) {
+	principalHref := davHref(caldavPrincipal)
+	switch name {
+	case xml.Name{Space: nsDAV, Local: "resourcetype"}:
+		switch res.kind {
+		case davPrincipal:
+			return "<D:principal/>", true
+		case davCalendar:
+			return "<D:collection/><C:calendar/>", true
+		case davObject:
+			return "", true
+		}
+		return "<D:collection/>", true
+	case xml.Name{Space: nsDAV, Local: "displayname"}:
+		switch res.kind {
+		case davPrincipal:
+			return "todo-list", true
+		case davCalendar:
+			return davText(res.calendar.Name), true
+		}
+	case xml.Name{Space: nsDAV, Local: "current-user-principal"}:
+		return principalHref, true
+	case xml.Name{Space: nsDAV, Local: "principal-URL"}:
+		if res.kind == davPrincipal {
+			return principalHref, true
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}:
+		if res.kind == davPrincipal || res.kind == davRoot {
+			return davHref(caldavHome), true
+		}
+	case xml.Name{Space: nsDAV, Local: "owner"}:
+		if res.kind == davCalendar {
+			return principalHref, true
+		}
+	case xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}:
+		if res.kind == davCalendar {
+			return `<C:comp name="VTODO"/>`, true
+		}
+	case xml.Name{Space: nsDAV, Local: "supported-report-set"}:
+		if res.kind == davCalendar {
+			return "<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>" +
+				"<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>" +
+				"<D:supported-report><D:report><D:sync-collection/></D:report></D:supported-report>", true
+		}
+	case xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}:
+		if res.kind == davCalendar || res.kind == davObject {
+			return "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>" +
+				"<D:privilege><D:write-content/></D:privilege><D:privilege><D:bind/></D:privilege>" +
+				"<D:privilege><D:unbind/></D:privilege>", true
+		}
+	case xml.Name{Space: nsDAV, Local: "sync-token"}, xml.Name{Space: nsCS, Local: "getctag"}:
+		if res.kind == davCalendar {
+			return davText(syncToken), true
+		}
+	case xml.Name{Space: nsApple, Local: "calendar-color"}:
+		if res.kind == davCalendar && res.calendar.Color != "" {
+			return davText(res.calendar.Color), true
+		}
+	case xml.Name{Space: nsDAV, Local: "getetag"}:
+		if res.kind == davObject {
+			return davText(res.object.ETag), true
+		}
+	case xml.Name{Space: nsDAV, Local: "getcontenttype"}:
+		if res.kind == davObject {
+			return caldavContentType, true
+		}
+	case xml.Name{Space: nsDAV, Local: "getcontentlength"}:
+		if res.kind == davObject {
+			return strconv.Itoa(len(res.object.Data)), true
+		}
+	case xml.Name{Space: nsCalDAV, Local: "calendar-data"}:
+		if res.kind == davObject {
+			return davText(string(res.object.Data)), true
+		}
+	}
+	return "", false
+}
+
+func davHref(href string) string {
+	return "<D:href>" + davText(href) + "</D:href>"
+}
+
+// davText экранирует текст для XML
+func davText(text string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(text))
+	return b.String()
+}
+
+// davElement записывает элемент; пространства имен без префикса объявляются на месте
+func davElement(name xml.Name, inner string) string {
+	tag := name.Local
+	open := tag
+	if prefix, ok := davPrefixes[name.Space]; ok {
+		tag = prefix + ":" + name.Local
+		open = tag
+	} else if name.Space != "" {
+		tag = "X:" + name.Local
+		open = tag + ` xmlns:X="` + davText(name.Space) + `"`
+	}
+	if inner == "" {
+		return "<" + open + "/>"
+	}
+	return "<" + open + ">" + inner + "</" + tag + ">"
+}
+
+// multistatus собирает ответ 207 Multi-Status
+type multistatus struct {
+	buf bytes.Buffer
+}
+
+func newMultistatus() *multistatus {
+	ms := &multistatus{}
+	ms.buf.WriteString(xml.Header)
+	ms.buf.WriteString(`<D:multistatus`)
+	for _, ns := range []string{nsDAV, nsCalDAV, nsCS, nsApple} {
+		ms.buf.WriteString(` xmlns:` + davPrefixes[ns] + `="` + ns + `"`)
+	}
+	ms.buf.WriteString(`>`)
+	return ms
+}
+
+// propResponse добавляет свойства ресурса: найденные со статусом 200, остальные - 404.
+// names == nil - все свойства ресурса.
+func (ms *multistatus) propResponse(res davResource, names []xml.Name, syncToken string) {
+	var found, missing strings.Builder
+	if names == nil {
+		for _, name := range davAllProps {
+			if inner, ok := davProp(res, name, syncToken); ok {
+				found.WriteString(davElement(name, inner))
+			}
+		}
+	}
+	for _, name := range names {
+		if inner, ok := davProp(res, name, syncToken); ok {
+			found.WriteString(davElement(name, inner))
+		} else {
+			missing.WriteString(davElement(name, ""))
+		}
+	}
+
+	ms.buf.WriteString("<D:response>" + davHref(res.href))
+	if found.Len() > 0 || missing.Len() == 0 {
+		ms.buf.WriteString("<D:propstat><D:prop>" + found.String() + "</D:prop>" + davStatus(http.StatusOK) + "</D:propstat>")
+	}
+	if missing.Len() > 0 {
+		ms.buf.WriteString("<D:propstat><D:prop>" + missing.String() + "</D:prop>" + davStatus(http.StatusNotFound) + "</D:propstat>")
+	}
+	ms.buf.WriteString("</D:response>")
+}
+
+// statusResponse добавляет ресурс без свойств: отсутствующий или удаленный
+func (ms *multistatus) statusResponse(href string, status int) {
+	ms.buf.WriteString("<D:response>" + davHref(href) + davStatus(status) + "</D:response>")
+}
+
+func davStatus(status int) string {
+	return "<D:status>HTTP/1.1 " + strconv.Itoa(status) + " " + http.StatusText(status) + "</D:status>"
+}
+
+// write отправляет ответ; syncToken - новый sync-token отчета sync-collection
+func (ms *multistatus) write(w http.ResponseWriter, syncToken string) {
+	if syncToken != "" {
+		ms.buf.WriteString("<D:sync-token>" + davText(syncToken) + "</D:sync-token>")
+	}
+	ms.buf.WriteString("</D:multistatus>")
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.WriteHeader(http.StatusMultiStatus)
+	w.Write(ms.buf.Bytes())
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"todo-list/backend/internal/service"
+)
+
+// fakeCalDAV календари в памяти: календарь "work" с одной задачей
+type fakeCalDAV struct {
+	objects []service.DAVObject
+	put     service.DAVCondition // условия последнего PutObject
+}
+
+func newFakeCalDAV() *fakeCalDAV {
+	return &fakeCalDAV{objects: []service.DAVObject{{
+		Name: "1.ics", TodoID: 1, ETag: `"abc"`,
+		Data: []byte("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:1@todo-list\r\nSUMMARY:A & B\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"),
+	}}}
+}
+
+var fakeCalendar = service.DAVCalendar{ID: "work", Name: "Работа", Color: "#ff0000"}
+
+func (f *fakeCalDAV) Calendars() ([]service.DAVCalendar, error) {
+	return []service.DAVCalendar{fakeCalendar}, nil
+}
+
+func (f *fakeCalDAV) Calendar(id string) (*service.DAVCalendar, error) {
+	if id != fakeCalendar.ID {
+		return nil, service.ErrCalendarNotFound
+	}
+	calendar := fakeCalendar
+	return &calendar, nil
+}
+
+func (f *fakeCalDAV) Objects(calendarID string) ([]service.DAVObject, error) {
+	if _, err := f.Calendar(calendarID); err != nil {
+		return nil, err
+	}
+	return f.objects, nil
+}
+
+func (f *fakeCalDAV) Object(calendarID, name string) (*service.DAVObject, error) {
+	objects, err := f.Objects(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range objects {
+		if objects[i].Name == name {
+			return &objects[i], nil
+		}
+	}
+	return nil, service.ErrObjectNotFound
+}
+
+func (f *fakeCalDAV) PutObject(calendarID, name string, data []byte, cond service.DAVCondition) (bool, error) {
+	f.put = cond
+	existing, err := f.Object(calendarID, name)
+	if err == nil && cond.IfNoneMatch == "*" {
+		return false, service.ErrPrecondition
+	}
+	if !strings.Contains(string(data), "VTODO") {
+		return false, service.ErrInvalidObject
+	}
+	return existing == nil, nil
+}
+
+func (f *fakeCalDAV) DeleteObject(calendarID, name string, cond service.DAVCondition) error {
+	object, err := f.Object(calendarID, name)
+	if err != nil {
+		return err
+	}
+	if cond.IfMatch != "" && cond.IfMatch != object.ETag {
+		return service.ErrPrecondition
+	}
+	return nil
+}
+
+func (f *fakeCalDAV) SyncToken() (string, error) {
+	return "urn:todo-list:sync:7", nil
+}
+
+func (f *fakeCalDAV) Changes(calendarID, token string) (*service.DAVChanges, error) {
+	if token != "" && token != "urn:todo-list:sync:5" {
+		return nil, service.ErrInvalidSyncToken
+	}
+	return &service.DAVChanges{Changed: f.objects, Removed: []string{"2.ics"}, SyncToken: "urn:todo-list:sync:7"}, nil
+}
+
+// serveDAV выполняет запрос CalDAV; headers - пары имя, значение
+func serveDAV(h http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestDAVTarget(t *testing.T) {
+	tests := []struct {
+		path     string
+		kind     davKind
+		calendar string
+		name     string
+		ok       bool
+	}{
+		{"/dav", davRoot, "", "", true},
+		{"/dav/", davRoot, "", "", true},
+		{"/dav/principal/", davPrincipal, "", "", true},
+		{"/dav/calendars", davHome, "", "", true},
+		{"/dav/calendars/work/", davCalendar, "work", "", true},
+		{"/dav/calendars/work/1.ics", davObject, "work", "1.ics", true},
+		{"/dav/calendars/work/1.ics/", 0, "", "", false},
+		{"/dav/calendars/work/a/b", 0, "", "", false},
+		{"/dav/other/", 0, "", "", false},
+		{"/api/v1/tasks", 0, "", "", false},
+	}
+	for _, tt := range tests {
+		kind, calendar, name, ok := davTarget(tt.path)
+		if kind != tt.kind || calendar != tt.calendar || name != tt.name || ok != tt.ok {
+			t.Errorf("davTarget(%q) = %v, %q, %q, %v", tt.path, kind, calendar, name, ok)
+		}
+	}
+
+	if name, ok := hrefObjectName("https://example.com/dav/calendars/work/1.ics", "work"); !ok || name != "1.ics" {
+		t.Errorf("hrefObjectName(URL) = %q, %v", name, ok)
+	}
+	if _, ok := hrefObjectName("/dav/calendars/home/1.ics", "work"); ok {
+		t.Error("hrefObjectName accepted an object of another calendar")
+	}
+}
+
+func TestCalDAVPropfind(t *testing.T) {
+	h := NewCalDAVHandler(newFakeCalDAV())
+	body := `<?xml version="1.0"?><D:propfind xmlns:D="DAV:" xmlns:CS="http://calendarserver.org/ns/">` +
+		`<D:prop><D:displayname/><CS:getctag/><D:getetag/></D:prop></D:propfind>`
+
+	rec := serveDAV(h, "PROPFIND", "/dav/calendars/work/", body, "Depth", "1")
+	if rec.Code != http.StatusMultiStatus {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body.String())
+	}
+	got := rec.Body.String()
+	for _, want := range []string{
+		"<D:href>/dav/calendars/work/</D:href>",
+		"<D:displayname>Работа</D:displayname>",
+		"<CS:getctag>urn:todo-list:sync:7</CS:getctag>",
+		"<D:href>/dav/calendars/work/1.ics</D:href>",
+		"<D:getetag>&#34;abc&#34;</D:getetag>",
+		// Календарь без ETag, задача без displayname
+		"<D:prop><D:getetag/></D:prop><D:status>HTTP/1.1 404 Not Found</D:status>",
+		"<D:prop><D:displayname/><CS:getctag/></D:prop><D:status>HTTP/1.1 404 Not Found</D:status>",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("response has no %s:\n%s", want, got)
+		}
+	}
+
+	rec = serveDAV(h, "PROPFIND", "/dav/calendars/work/", body, "Depth", "0")
+	if strings.Contains(rec.Body.String(), "1.ics") {
+		t.Errorf("Depth: 0 listed objects:\n%s", rec.Body.String())
+	}
+
+	rec = serveDAV(h, "PROPFIND", "/dav/calendars/home/", body)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("unknown calendar: status = %d", rec.Code)
+	}
+	rec = serveDAV(h, "PROPFIND", "/dav/", "<D:propfind")
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("malformed body: status = %d", rec.Code)
+	}
+}
+
+func TestCalDAVReport(t *testing.T) {
+	h := NewCalDAVHandler(newFakeCalDAV())
+
+	multiget := `<C:calendar-multiget xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+		`<D:prop><D:getetag/><C:calendar-data/></D:prop>` +
+		`<D:href>/dav/calendars/work/1.ics</D:href><D:href>/dav/calendars/work/9.ics</D:href></C:calendar-multiget>`
+	rec := serveDAV(h, "REPORT", "/dav/calendars/work/", multiget)
+	got := rec.Body.String()
+	if rec.Code != http.StatusMultiStatus || !strings.Contains(got, "SUMMARY:A &amp; B") ||
+		!strings.Contains(got, "<D:href>/dav/calendars/work/9.ics</D:href><D:status>HTTP/1.1 404 Not Found</D:status>") {
+		t.Errorf("calendar-multiget = %d:\n%s", rec.Code, got)
+	}
+
+	query := `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><D:prop><D:getetag/></D:prop>` +
+		`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="%s"/></C:comp-filter></C:filter></C:calendar-query>`
+	if rec := serveDAV(h, "REPORT", "/dav/calendars/work/", strings.Replace(query, "%s", "VTODO", 1)); !strings.Contains(rec.Body.String(), "1.ics") {
+		t.Errorf("calendar-query VTODO:\n%s", rec.Body.String())
+	}
+	if rec := serveDAV(h, "REPORT", "/dav/calendars/work/", strings.Replace(query, "%s", "VEVENT", 1)); strings.Contains(rec.Body.String(), "1.ics") {
+		t.Errorf("calendar-query VEVENT listed tasks:\n%s", rec.Body.String())
+	}
+
+	sync := `<D:sync-collection xmlns:D="DAV:"><D:sync-token>%s</D:sync-token><D:prop><D:getetag/></D:prop></D:sync-collection>`
+	rec = serveDAV(h, "REPORT", "/dav/calendars/work/", strings.Replace(sync, "%s", "urn:todo-list:sync:5", 1))
+	got = rec.Body.String()
+	if !strings.Contains(got, "<D:href>/dav/calendars/work/2.ics</D:href><D:status>HTTP/1.1 404 Not Found</D:status>") ||
+		!strings.HasSuffix(got, "<D:sync-token>urn:todo-list:sync:7</D:sync-token></D:multistatus>") {
+		t.Errorf("sync-collection:\n%s", got)
+	}
+	rec = serveDAV(h, "REPORT", "/dav/calendars/work/", strings.Replace(sync, "%s", "stale", 1))
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "<D:valid-sync-token/>") {
+		t.Errorf("invalid sync-token = %d:\n%s", rec.Code, rec.Body.String())
+	}
+
+	rec = serveDAV(h, "REPORT", "/dav/calendars/work/", `<D:expand-property xmlns:D="DAV:"/>`)
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "<D:supported-report/>") {
+		t.Errorf("unsupported report = %d:\n%s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestCalDAVObjects(t *testing.T) {
+	fake := newFakeCalDAV()
+	h := NewCalDAVHandler(fake)
+
+	rec := serveDAV(h, http.MethodGet, "/dav/calendars/work/1.ics", "")
+	if rec.Code != http.StatusOK || rec.Header().Get("ETag") != `"abc"` || rec.Header().Get("Content-Type") != caldavContentType {
+		t.Errorf("GET = %d, %v", rec.Code, rec.Header())
+	}
+	if rec := serveDAV(h, http.MethodGet, "/dav/calendars/work/1.ics", "", "If-None-Match", `"abc"`); rec.Code != http.StatusNotModified {
+		t.Errorf("GET with matching If-None-Match = %d", rec.Code)
+	}
+	if rec := serveDAV(h, http.MethodGet, "/dav/calendars/work/9.ics", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET missing = %d", rec.Code)
+	}
+
+	vtodo := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:x\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	if rec := serveDAV(h, http.MethodPut, "/dav/calendars/work/new.ics", vtodo, "If-None-Match", " * "); rec.Code != http.StatusCreated {
+		t.Errorf("PUT new = %d", rec.Code)
+	}
+	if fake.put.IfNoneMatch != "*" {
+		t.Errorf("If-None-Match = %q", fake.put.IfNoneMatch)
+	}
+	if rec := serveDAV(h, http.MethodPut, "/dav/calendars/work/1.ics", vtodo, "If-Match", `"abc"`); rec.Code != http.StatusNoContent || fake.put.IfMatch != `"abc"` {
+		t.Errorf("PUT existing = %d, If-Match %q", rec.Code, fake.put.IfMatch)
+	}
+	if rec := serveDAV(h, http.MethodPut, "/dav/calendars/work/1.ics", vtodo, "If-None-Match", "*"); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("PUT over existing with If-None-Match = %d", rec.Code)
+	}
+	rec = serveDAV(h, http.MethodPut, "/dav/calendars/work/2.ics", "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n")
+	if rec.Code != http.StatusForbidden || !strings.Contains(rec.Body.String(), "<C:valid-calendar-data/>") {
+		t.Errorf("PUT invalid = %d:\n%s", rec.Code, rec.Body.String())
+	}
+
+	if rec := serveDAV(h, http.MethodDelete, "/dav/calendars/work/1.ics", "", "If-Match", `"old"`); rec.Code != http.StatusPreconditionFailed {
+		t.Errorf("DELETE with stale If-Match = %d", rec.Code)
+	}
+	if rec := serveDAV(h, http.MethodDelete, "/dav/calendars/work/1.ics", ""); rec.Code != http.StatusNoContent {
+		t.Errorf("DELETE = %d", rec.Code)
+	}
+
+	if rec := serveDAV(h, http.MethodPut, "/dav/calendars/work/", vtodo); rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") == "" {
+		t.Errorf("PUT collection = %d", rec.Code)
+	}
+	if rec := serveDAV(h, http.MethodOptions, "/dav/", ""); rec.Code != http.StatusOK || !strings.Contains(rec.Header().Get("DAV"), "calendar-access") {
+		t.Errorf("OPTIONS = %d, %v", rec.Code, rec.Header())
+	}
+}
@@ -0,0 +1,90 @@
+// handler/category.go
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"todo-list/backend/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// CategoryHandler обслуживает корзину категорий
+type CategoryHandler struct {
+	service service.CategoryService
+}
+
+func NewCategoryHandler(service service.CategoryService) *CategoryHandler {
+	return &CategoryHandler{service: service}
+}
+
+// GetTrash возвращает удаленные категории
+func (h *CategoryHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.service.GetDeletedCategories()
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, categories)
+}
+
+// RestoreCategory возвращает категорию из корзины; ее задачи снова оказываются в ней
+func (h *CategoryHandler) RestoreCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id == 0 {
+		h.writeError(w, http.StatusBadRequest, "Invalid category ID")
+		return
+	}
+
+	category, err := h.service.RestoreCategory(uint(id))
+	if err != nil {
+		if errors.Is(err, service.ErrNotInTrash) {
+			h.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, category)
+}
+
+// PurgeTrash окончательно удаляет категории из корзины; параметр before (RFC 3339)
+// ограничивает очистку категориями, удаленными раньше этого времени
+func (h *CategoryHandler) PurgeTrash(w http.ResponseWriter, r *http.Request) {
+	before := time.Now()
+	if value := r.URL.Query().Get("before"); value != "" {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid before time")
+			return
+		}
+		before = parsed
+	}
+
+	purged, err := h.service.PurgeDeletedCategories(before)
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, map[string]int64{"purged": purged})
+}
+
+func (h *CategoryHandler) writeSuccess(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+func (h *CategoryHandler) writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, Response{
+		Success: false,
+		Error:   message,
+	})
+}
@@ -0,0 +1,132 @@
+// handler/feed.go
+package handler
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// feedCacheControl разрешает календарям кэшировать подписку до следующего обновления
+var feedCacheControl = fmt.Sprintf("private, max-age=%d", int(service.FeedRefreshInterval.Seconds()))
+
+type FeedHandler struct {
+	service service.FeedService
+}
+
+func NewFeedHandler(service service.FeedService) *FeedHandler {
+	return &FeedHandler{service: service}
+}
+
+// feedResponse подписка вместе с готовой ссылкой для календаря
+type feedResponse struct {
+	models.Feed
+	URL string `json:"url"`
+}
+
+func (h *FeedHandler) GetFeeds(w http.ResponseWriter, r *http.Request) {
+	feeds, err := h.service.GetAllFeeds()
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	result := make([]feedResponse, 0, len(feeds))
+	for _, feed := range feeds {
+		result = append(result, feedResponse{Feed: feed, URL: feedURL(r, feed.Token)})
+	}
+	h.writeSuccess(w, http.StatusOK, result)
+}
+
+// CreateFeed создает подписку: {"name": "...", "filter": {...TaskFilter}, "events": true}
+func (h *FeedHandler) CreateFeed(w http.ResponseWriter, r *http.Request) {
+	var feed models.Feed
+	if err := json.NewDecoder(r.Body).Decode(&feed); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.CreateFeed(&feed); err != nil {
+		if errors.Is(err, service.ErrInvalidFeed) || errors.Is(err, service.ErrInvalidTag) {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusCreated, feedResponse{Feed: feed, URL: feedURL(r, feed.Token)})
+}
+
+// DeleteFeed удаляет подписку; ее ссылка перестает работать
+func (h *FeedHandler) DeleteFeed(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid feed ID")
+		return
+	}
+
+	if err := h.service.DeleteFeed(uint(id)); err != nil {
+		if errors.Is(err, service.ErrFeedNotFound) {
+			h.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, map[string]string{"message": "Feed deleted successfully"})
+}
+
+// Calendar отдает подписку в формате iCalendar. ETag и Last-Modified позволяют
+// календарям получать 304 Not Modified, пока задачи не менялись.
+func (h *FeedHandler) Calendar(w http.ResponseWriter, r *http.Request) {
+	cal, err := h.service.Calendar(mux.Vars(r)["token"])
+	if err != nil {
+		if errors.Is(err, service.ErrFeedNotFound) || errors.Is(err, sql.ErrNoRows) {
+			h.writeError(w, http.StatusNotFound, "Not found")
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="calendar.ics"`)
+	w.Header().Set("Cache-Control", feedCacheControl)
+	w.Header().Set("ETag", cal.ETag)
+	http.ServeContent(w, r, "", cal.Modified, bytes.NewReader(cal.Data))
+}
+
+// feedURL собирает ссылку на подписку по адресу, на который пришел запрос
+func feedURL(r *http.Request, token string) string {
+	scheme := "http"
+	if r.TLS != nil || strings.EqualFold(r.Header.Get("X-Forwarded-Proto"), "https") {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + APIPrefix + "/feeds/" + token + ".ics"
+}
+
+func (h *FeedHandler) writeSuccess(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+func (h *FeedHandler) writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, Response{
+		Success: false,
+		Error:   message,
+	})
+}
@@ -3,8 +3,10 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"todo-list/backend/internal/models"
@@ -22,9 +24,16 @@ func NewTaskHandler(service service.TaskService) *TaskHandler {
 }
 
 type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success    bool        `json:"success"`
+	Data       interface{} `json:"data,omitempty"`
+	Pagination *Pagination `json:"pagination,omitempty"`
+	Error      string      `json:"error,omitempty"`
+}
+
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
 }
 
 func (h *TaskHandler) CreateTask(w http.ResponseWriter, r *http.Request) {
@@ -63,14 +72,49 @@ func (h *TaskHandler) GetTask(w http.ResponseWriter, r *http.Request) {
 func (h *TaskHandler) GetTasks(w http.ResponseWriter, r *http.Request) {
 	filter := h.parseFilter(r)
 	sort := h.parseSort(r)
+	page, err := h.parsePage(r)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
-	tasks, err := h.service.GetAllTasks(filter, sort)
+	result, err := h.service.GetTasksPage(filter, sort, page)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidSort) || errors.Is(err, service.ErrInvalidCursor) {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
 		h.writeError(w, http.StatusInternalServerError, err.Error())
 		return
 	}
 
-	h.writeSuccess(w, http.StatusOK, tasks)
+	h.writePage(w, http.StatusOK, result)
+}
+
+func (h *TaskHandler) SearchTasks(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := h.parseFilter(r)
+
+	limit := 0
+	if limitStr := query.Get("limit"); limitStr != "" {
+		var err error
+		if limit, err = strconv.Atoi(limitStr); err != nil || limit <= 0 {
+			h.writeError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+	}
+
+	results, err := h.service.SearchTasks(query.Get("q"), filter, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyQuery) {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, results)
 }
 
 func (h *TaskHandler) UpdateTask(w http.ResponseWriter, r *http.Request) {
@@ -138,6 +182,108 @@ func (h *TaskHandler) MarkTaskCompleted(w http.ResponseWriter, r *http.Request)
 	h.writeSuccess(w, http.StatusOK, map[string]string{"message": "Task status updated successfully"})
 }
 
+func (h *TaskHandler) GetTaskTree(w http.ResponseWriter, r *http.Request) {
+	id := 0
+	if idStr, ok := mux.Vars(r)["id"]; ok {
+		var err error
+		if id, err = strconv.Atoi(idStr); err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid task ID")
+			return
+		}
+	}
+
+	tree, err := h.service.GetTaskTree(id)
+	if err != nil {
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, tree)
+}
+
+func (h *TaskHandler) MoveTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	var req struct {
+		ParentID *uint `json:"parent_id"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	err = h.service.MoveTask(id, req.ParentID)
+	if err != nil {
+		if errors.Is(err, service.ErrTaskCycle) || errors.Is(err, service.ErrParentNotFound) {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, map[string]string{"message": "Task moved successfully"})
+}
+
+func (h *TaskHandler) SkipOccurrence(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	task, err := h.service.SkipTaskOccurrence(id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotRecurring) || errors.Is(err, service.ErrInvalidRecurrence) {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.writeError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, task)
+}
+
+// GetTrash возвращает задачи из корзины, недавно удаленные первыми
+func (h *TaskHandler) GetTrash(w http.ResponseWriter, r *http.Request) {
+	tasks, err := h.service.GetDeletedTasks()
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, tasks)
+}
+
+// RestoreTask возвращает задачу из корзины вместе с подзадачами, удаленными вместе с ней
+func (h *TaskHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid task ID")
+		return
+	}
+
+	task, err := h.service.RestoreTask(id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotInTrash) {
+			h.writeError(w, http.StatusNotFound, err.Error())
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, task)
+}
+
 func (h *TaskHandler) parseFilter(r *http.Request) *models.TaskFilter {
 	query := r.URL.Query()
 	filter := &models.TaskFilter{}
@@ -174,6 +320,32 @@ func (h *TaskHandler) parseFilter(r *http.Request) *models.TaskFilter {
 		}
 	}
 
+	if categoryStr := query.Get("category_id"); categoryStr != "" {
+		if categoryID, err := strconv.ParseUint(categoryStr, 10, 64); err == nil {
+			id := uint(categoryID)
+			filter.CategoryID = &id
+		}
+	}
+
+	if parentStr := query.Get("parent_id"); parentStr != "" {
+		if parentID, err := strconv.ParseUint(parentStr, 10, 64); err == nil {
+			id := uint(parentID)
+			filter.ParentID = &id
+		}
+	}
+
+	// tags=a,b или tags=a&tags=b; tag_mode=all требует все метки сразу
+	for _, value := range query["tags"] {
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				filter.Tags = append(filter.Tags, tag)
+			}
+		}
+	}
+	if query.Get("tag_mode") == string(models.TagModeAll) {
+		filter.TagMode = models.TagModeAll
+	}
+
 	return filter
 }
 
@@ -185,20 +357,52 @@ func (h *TaskHandler) parseSort(r *http.Request) *models.TaskSort {
 	}
 }
 
-func (h *TaskHandler) writeSuccess(w http.ResponseWriter, status int, data interface{}) {
+func (h *TaskHandler) parsePage(r *http.Request) (models.PageRequest, error) {
+	query := r.URL.Query()
+	page := models.PageRequest{Cursor: query.Get("cursor")}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return page, errors.New("Invalid limit")
+		}
+		page.Limit = limit
+	}
+
+	return page, nil
+}
+
+func (h *TaskHandler) writePage(w http.ResponseWriter, status int, page *models.TaskPage) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    page.Items,
+		Pagination: &Pagination{
+			Limit:      page.Limit,
+			NextCursor: page.NextCursor,
+			PrevCursor: page.PrevCursor,
+		},
+	})
+}
+
+func (h *TaskHandler) writeSuccess(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, Response{
 		Success: true,
 		Data:    data,
 	})
 }
 
 func (h *TaskHandler) writeError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(Response{
+	writeJSON(w, status, Response{
 		Success: false,
 		Error:   message,
 	})
 }
+
+// writeJSON пишет ответ API; общий для всех обработчиков
+func writeJSON(w http.ResponseWriter, status int, resp Response) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,127 @@
+// handler/router.go
+package handler
+
+import (
+	"log"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// APIPrefix версионированный префикс всех маршрутов REST API
+const APIPrefix = "/api/v1"
+
+// NewRouter регистрирует все маршруты REST API и сервер CalDAV. Без авторизации
+// доступны только календари подписок: их защищает секретный токен в ссылке.
+func NewRouter(auth Credentials, tasks *TaskHandler, tags *TagHandler, categories *CategoryHandler, transfers *TransferHandler, feeds *FeedHandler, caldav *CalDAVHandler) *mux.Router {
+	notFound := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tasks.writeError(w, http.StatusNotFound, "Not found")
+	})
+	methodNotAllowed := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tasks.writeError(w, http.StatusMethodNotAllowed, "Method not allowed")
+	})
+
+	router := mux.NewRouter()
+	router.Use(recoverMiddleware, loggingMiddleware, authMiddleware(auth))
+	router.NotFoundHandler = notFound
+	router.MethodNotAllowedHandler = methodNotAllowed
+
+	// Маршруты регистрируются на корневом роутере с полным путем: у подроутеров
+	// gorilla/mux ошибка метода превращается в 404 вместо 405
+	router.HandleFunc(APIPrefix+"/health", tasks.Health).Methods(http.MethodGet)
+
+	router.HandleFunc(APIPrefix+"/tasks", tasks.GetTasks).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/tasks", tasks.CreateTask).Methods(http.MethodPost)
+	router.HandleFunc(APIPrefix+"/tasks/search", tasks.SearchTasks).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/tasks/tree", tasks.GetTaskTree).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/tasks/trash", tasks.GetTrash).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}", tasks.GetTask).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}", tasks.UpdateTask).Methods(http.MethodPut, http.MethodPatch)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}", tasks.DeleteTask).Methods(http.MethodDelete)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}/complete", tasks.MarkTaskCompleted).Methods(http.MethodPut, http.MethodPatch)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}/tree", tasks.GetTaskTree).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}/move", tasks.MoveTask).Methods(http.MethodPut, http.MethodPatch)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}/skip", tasks.SkipOccurrence).Methods(http.MethodPut, http.MethodPatch)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}/restore", tasks.RestoreTask).Methods(http.MethodPut, http.MethodPatch)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}/tags", tags.SetTaskTags).Methods(http.MethodPut, http.MethodPatch)
+	router.HandleFunc(APIPrefix+"/tasks/{id:[0-9]+}/history", tasks.GetTaskHistory).Methods(http.MethodGet)
+
+	router.HandleFunc(APIPrefix+"/activity", tasks.GetActivity).Methods(http.MethodGet)
+
+	router.HandleFunc(APIPrefix+"/tags", tags.GetTags).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/tags", tags.CreateTag).Methods(http.MethodPost)
+	router.HandleFunc(APIPrefix+"/tags/{id:[0-9]+}", tags.GetTag).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/tags/{id:[0-9]+}", tags.UpdateTag).Methods(http.MethodPut, http.MethodPatch)
+	router.HandleFunc(APIPrefix+"/tags/{id:[0-9]+}", tags.DeleteTag).Methods(http.MethodDelete)
+	router.HandleFunc(APIPrefix+"/tags/{id:[0-9]+}/merge", tags.MergeTags).Methods(http.MethodPut, http.MethodPatch)
+
+	router.HandleFunc(APIPrefix+"/categories/trash", categories.GetTrash).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/categories/trash", categories.PurgeTrash).Methods(http.MethodDelete)
+	router.HandleFunc(APIPrefix+"/categories/{id:[0-9]+}/restore", categories.RestoreCategory).Methods(http.MethodPut, http.MethodPatch)
+
+	router.HandleFunc(APIPrefix+"/export", transfers.Export).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/import", transfers.Import).Methods(http.MethodPost)
+
+	router.HandleFunc(APIPrefix+"/feeds", feeds.GetFeeds).Methods(http.MethodGet)
+	router.HandleFunc(APIPrefix+"/feeds", feeds.CreateFeed).Methods(http.MethodPost)
+	router.HandleFunc(APIPrefix+"/feeds/{id:[0-9]+}", feeds.DeleteFeed).Methods(http.MethodDelete)
+	router.HandleFunc(APIPrefix+"/feeds/{token:[A-Za-z0-9_-]+}.ics", feeds.Calendar).Methods(http.MethodGet, http.MethodHead).Name(publicRoute)
+
+	// CalDAV использует свои методы (PROPFIND, REPORT), поэтому разбирает их сам
+	router.Handle("/.well-known/caldav", http.RedirectHandler(CalDAVPrefix+"/", http.StatusMovedPermanently))
+	router.Handle(CalDAVPrefix, caldav)
+	router.PathPrefix(CalDAVPrefix + "/").Handler(caldav)
+
+	return router
+}
+
+// Health сообщает, что сервер запущен
+func (h *TaskHandler) Health(w http.ResponseWriter, r *http.Request) {
+	h.writeSuccess(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// statusRecorder запоминает код ответа для логирования
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// loggingMiddleware логирует метод, путь, код ответа и длительность запроса
+func loggingMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+		log.Printf("%s %s %d %s", r.Method, logPath(r.URL.Path), rec.status, time.Since(start))
+	})
+}
+
+// feedPath путь подписки iCalendar; ее токен - секрет и в журнал не пишется
+var feedPath = regexp.MustCompile(`^(` + APIPrefix + `/feeds/)[^/]+(\.ics)$`)
+
+func logPath(path string) string {
+	return feedPath.ReplaceAllString(path, "${1}***${2}")
+}
+
+// recoverMiddleware превращает панику в обработчике в ответ 500
+func recoverMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				log.Printf("PANIC in %s %s: %v\n%s", r.Method, r.URL.Path, rec, debug.Stack())
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusInternalServerError)
+				w.Write([]byte(`{"success":false,"error":"Internal server error"}`))
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
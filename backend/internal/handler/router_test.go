@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/service"
+)
+
+// fakeTaskService отдает одну страницу задач и запоминает разобранный запрос;
+// остальные методы TaskService не реализованы
+type fakeTaskService struct {
+	service.TaskService
+	filter *models.TaskFilter
+	sort   *models.TaskSort
+	page   models.PageRequest
+}
+
+func (s *fakeTaskService) GetTasksPage(filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error) {
+	s.filter, s.sort, s.page = filter, sort, page
+	if page.Cursor == "bad" {
+		return nil, service.ErrInvalidCursor
+	}
+	return &models.TaskPage{Items: []models.Todo{{ID: 1, Title: "a"}}, NextCursor: "next", Limit: 20}, nil
+}
+
+func (s *fakeTaskService) GetTaskByID(id int) (*models.Todo, error) {
+	panic("boom")
+}
+
+// serveAPI выполняет запрос к роутеру с локального адреса, где авторизация не нужна
+func serveAPI(t *testing.T, tasks *fakeTaskService, method, target string) (*httptest.ResponseRecorder, Response) {
+	t.Helper()
+	router := NewRouter(Credentials{}, NewTaskHandler(tasks), NewTagHandler(nil), NewCategoryHandler(nil),
+		NewTransferHandler(nil), NewFeedHandler(nil), NewCalDAVHandler(newFakeCalDAV()))
+	req := httptest.NewRequest(method, target, nil)
+	req.RemoteAddr = "127.0.0.1:5000"
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	var resp Response
+	if rec.Header().Get("Content-Type") == "application/json" {
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s %s: invalid JSON %q", method, target, rec.Body)
+		}
+	}
+	return rec, resp
+}
+
+func TestRouter(t *testing.T) {
+	tests := []struct {
+		method, target string
+		want           int
+	}{
+		{http.MethodGet, "/api/v1/health", http.StatusOK},
+		{http.MethodGet, "/api/v1/nothing", http.StatusNotFound},
+		{http.MethodGet, "/api/v1/tasks/abc", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/health", http.StatusMethodNotAllowed},
+		{http.MethodDelete, "/api/v1/tasks", http.StatusMethodNotAllowed},
+		// Паника обработчика превращается в 500, сервер продолжает работу
+		{http.MethodGet, "/api/v1/tasks/13", http.StatusInternalServerError},
+		{http.MethodGet, "/.well-known/caldav", http.StatusMovedPermanently},
+	}
+	for _, tt := range tests {
+		rec, resp := serveAPI(t, &fakeTaskService{}, tt.method, tt.target)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+		if rec.Code >= 400 && (resp.Success || resp.Error == "") {
+			t.Errorf("%s %s: error response %q", tt.method, tt.target, rec.Body)
+		}
+	}
+}
+
+func TestGetTasksQuery(t *testing.T) {
+	tasks := &fakeTaskService{}
+	rec, resp := serveAPI(t, tasks, http.MethodGet,
+		"/api/v1/tasks?completed=false&priority=high&tags=work,home&tags=x&tag_mode=all&sort_by=due_date&sort_order=asc&cursor=abc&limit=5&parent_id=0")
+	if rec.Code != http.StatusOK || !resp.Success || resp.Pagination == nil || resp.Pagination.NextCursor != "next" {
+		t.Fatalf("response %d %q", rec.Code, rec.Body)
+	}
+
+	f := tasks.filter
+	if f.IsCompleted == nil || *f.IsCompleted || f.Priority == nil || *f.Priority != models.High ||
+		f.ParentID == nil || *f.ParentID != 0 || len(f.Tags) != 3 || f.TagMode != models.TagModeAll {
+		t.Errorf("filter = %+v", f)
+	}
+	if tasks.sort.Field != "due_date" || tasks.sort.Order != "asc" {
+		t.Errorf("sort = %+v", tasks.sort)
+	}
+	if tasks.page != (models.PageRequest{Cursor: "abc", Limit: 5}) {
+		t.Errorf("page = %+v", tasks.page)
+	}
+
+	for _, target := range []string{"/api/v1/tasks?limit=0", "/api/v1/tasks?limit=x", "/api/v1/tasks?cursor=bad"} {
+		if rec, _ := serveAPI(t, &fakeTaskService{}, http.MethodGet, target); rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// handler/tag.go
+package handler
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+type TagHandler struct {
+	service service.TagService
+}
+
+func NewTagHandler(service service.TagService) *TagHandler {
+	return &TagHandler{service: service}
+}
+
+func (h *TagHandler) GetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.service.GetAllTags()
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, tags)
+}
+
+func (h *TagHandler) CreateTag(w http.ResponseWriter, r *http.Request) {
+	var tag models.Tag
+	if err := json.NewDecoder(r.Body).Decode(&tag); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if err := h.service.CreateTag(&tag); err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	h.writeSuccess(w, http.StatusCreated, tag)
+}
+
+func (h *TagHandler) GetTag(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.tagID(w, r)
+	if !ok {
+		return
+	}
+
+	tag, err := h.service.GetTagByID(id)
+	if err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, tag)
+}
+
+// UpdateTag переименовывает метку и (или) меняет ее цвет
+func (h *TagHandler) UpdateTag(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.tagID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name  *string `json:"name"`
+		Color *string `json:"color"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tag, err := h.service.GetTagByID(id)
+	if err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+	if req.Name != nil {
+		tag.Name = *req.Name
+	}
+	if req.Color != nil {
+		tag.Color = *req.Color
+	}
+
+	if err := h.service.UpdateTag(tag); err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, tag)
+}
+
+func (h *TagHandler) DeleteTag(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.tagID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteTag(id); err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, map[string]string{"message": "Tag deleted successfully"})
+}
+
+// MergeTags переносит задачи с меток source_ids на метку {id} и удаляет исходные метки
+func (h *TagHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.tagID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		SourceIDs []uint `json:"source_ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.SourceIDs) == 0 {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	tag, err := h.service.MergeTags(req.SourceIDs, id)
+	if err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, tag)
+}
+
+// SetTaskTags заменяет метки задачи; отсутствующие метки создаются
+func (h *TagHandler) SetTaskTags(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.tagID(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Tags []string `json:"tags"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	task, err := h.service.SetTodoTags(id, req.Tags)
+	if err != nil {
+		h.writeTagError(w, err)
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, task)
+}
+
+func (h *TagHandler) tagID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(mux.Vars(r)["id"], 10, 64)
+	if err != nil || id == 0 {
+		h.writeError(w, http.StatusBadRequest, "Invalid ID")
+		return 0, false
+	}
+	return uint(id), true
+}
+
+func (h *TagHandler) writeTagError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidTag):
+		h.writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, service.ErrTagExists):
+		h.writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, service.ErrTagNotFound), errors.Is(err, sql.ErrNoRows):
+		h.writeError(w, http.StatusNotFound, err.Error())
+	default:
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+	}
+}
+
+func (h *TagHandler) writeSuccess(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+func (h *TagHandler) writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, Response{
+		Success: false,
+		Error:   message,
+	})
+}
@@ -0,0 +1,122 @@
+// handler/transfer.go
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"todo-list/backend/internal/service"
+	"todo-list/backend/internal/transfer"
+)
+
+// maxImportSize наибольший размер импортируемого файла
+const maxImportSize = 10 << 20
+
+type TransferHandler struct {
+	service service.TransferService
+}
+
+func NewTransferHandler(service service.TransferService) *TransferHandler {
+	return &TransferHandler{service: service}
+}
+
+// Export отдает все задачи файлом; format - json (по умолчанию), csv, markdown или org,
+// columns - сопоставление колонок CSV вида "title=Название,due_date=Срок"
+func (h *TransferHandler) Export(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format := transfer.FormatJSON
+	if name := query.Get("format"); name != "" {
+		var err error
+		if format, err = transfer.ParseFormat(name); err != nil {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	columns, err := transfer.ParseColumns(query.Get("columns"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	doc, err := h.service.Export()
+	if err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	var buf bytes.Buffer
+	if err := transfer.Encode(&buf, format, doc, transfer.Options{Columns: columns}); err != nil {
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	filename := "tasks-" + time.Now().Format("2006-01-02") + transfer.Extension(format)
+	w.Header().Set("Content-Type", transfer.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(buf.Bytes())
+}
+
+// Import загружает задачи из тела запроса; format обязателен, duplicates - skip (по умолчанию),
+// update или create, dry_run=true только возвращает отчет без изменений
+func (h *TransferHandler) Import(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format, err := transfer.ParseFormat(query.Get("format"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	columns, err := transfer.ParseColumns(query.Get("columns"))
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts := transfer.ImportOptions{Duplicates: query.Get("duplicates")}
+	if dryRun := query.Get("dry_run"); dryRun != "" {
+		if opts.DryRun, err = strconv.ParseBool(dryRun); err != nil {
+			h.writeError(w, http.StatusBadRequest, "Invalid dry_run")
+			return
+		}
+	}
+
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+	doc, err := transfer.Decode(body, format, transfer.Options{Columns: columns})
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.writeError(w, http.StatusRequestEntityTooLarge, "Request body too large")
+			return
+		}
+		h.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	report, err := h.service.Import(doc, opts)
+	if err != nil {
+		if errors.Is(err, transfer.ErrInvalidDuplicates) {
+			h.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.writeError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.writeSuccess(w, http.StatusOK, report)
+}
+
+func (h *TransferHandler) writeSuccess(w http.ResponseWriter, status int, data interface{}) {
+	writeJSON(w, status, Response{
+		Success: true,
+		Data:    data,
+	})
+}
+
+func (h *TransferHandler) writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, Response{
+		Success: false,
+		Error:   message,
+	})
+}
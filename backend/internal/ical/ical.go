@@ -0,0 +1,385 @@
+// Package ical читает и записывает iCalendar (RFC 5545): компоненты BEGIN/END, строки
+// содержимого с параметрами, перенос длинных строк, экранирование текста, даты с часовыми
+// поясами и длительности
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxLineOctets наибольшая длина строки без переноса, в байтах
+const maxLineOctets = 75
+
+// ErrSyntax возвращается для файла, который не удалось разобрать как iCalendar
+var ErrSyntax = errors.New("некорректный файл iCalendar")
+
+// Property свойство компонента. Value хранится как в файле: текст экранирован,
+// его читают Text и TextList.
+type Property struct {
+	Name   string
+	Params map[string]string // имена в верхнем регистре; несколько значений - через запятую
+	Value  string
+}
+
+// Param возвращает значение параметра
+func (p Property) Param(name string) string {
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Component компонент iCalendar: VCALENDAR, VTODO, VALARM, VTIMEZONE и т.д.
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+// NewComponent создает пустой компонент
+func NewComponent(name string) *Component {
+	return &Component{Name: strings.ToUpper(name)}
+}
+
+// Prop возвращает первое свойство name или nil
+func (c *Component) Prop(name string) *Property {
+	name = strings.ToUpper(name)
+	for i := range c.Properties {
+		if c.Properties[i].Name == name {
+			return &c.Properties[i]
+		}
+	}
+	return nil
+}
+
+// Props возвращает все свойства name
+func (c *Component) Props(name string) []Property {
+	name = strings.ToUpper(name)
+	var props []Property
+	for _, prop := range c.Properties {
+		if prop.Name == name {
+			props = append(props, prop)
+		}
+	}
+	return props
+}
+
+// Value возвращает значение первого свойства name как есть; пустая строка - свойства нет
+func (c *Component) Value(name string) string {
+	if prop := c.Prop(name); prop != nil {
+		return prop.Value
+	}
+	return ""
+}
+
+// Text возвращает текстовое значение первого свойства name без экранирования
+func (c *Component) Text(name string) string {
+	return UnescapeText(c.Value(name))
+}
+
+// TextList возвращает значения всех свойств-списков name (CATEGORIES)
+func (c *Component) TextList(name string) []string {
+	var values []string
+	for _, prop := range c.Props(name) {
+		values = append(values, SplitText(prop.Value)...)
+	}
+	return values
+}
+
+// Add добавляет свойство; params - пары имя, значение
+func (c *Component) Add(name, value string, params ...string) {
+	prop := Property{Name: strings.ToUpper(name), Value: value}
+	if len(params) > 0 {
+		prop.Params = map[string]string{}
+		for i := 0; i+1 < len(params); i += 2 {
+			prop.Params[strings.ToUpper(params[i])] = params[i+1]
+		}
+	}
+	c.Properties = append(c.Properties, prop)
+}
+
+// AddText добавляет текстовое свойство, экранируя значение
+func (c *Component) AddText(name, text string, params ...string) {
+	c.Add(name, EscapeText(text), params...)
+}
+
+// AddTextList добавляет свойство-список (CATEGORIES)
+func (c *Component) AddTextList(name string, values []string) {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = EscapeText(value)
+	}
+	c.Add(name, strings.Join(escaped, ","))
+}
+
+// Children возвращает вложенные компоненты name
+func (c *Component) Children(name string) []*Component {
+	name = strings.ToUpper(name)
+	var children []*Component
+	for _, child := range c.Components {
+		if child.Name == name {
+			children = append(children, child)
+		}
+	}
+	return children
+}
+
+// AddChild добавляет вложенный компонент
+func (c *Component) AddChild(child *Component) {
+	c.Components = append(c.Components, child)
+}
+
+// Decode читает все компоненты верхнего уровня (обычно один VCALENDAR).
+// Принимаются строки с CRLF и с LF; пустые строки пропускаются.
+func Decode(r io.Reader) ([]*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var roots, stack []*Component
+	for _, line := range lines {
+		prop, err := parseLine(line.text)
+		if err != nil {
+			return nil, fmt.Errorf("%w: строка %d: %v", ErrSyntax, line.number, err)
+		}
+
+		switch prop.Name {
+		case "BEGIN":
+			stack = append(stack, NewComponent(prop.Value))
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(prop.Value) {
+				return nil, fmt.Errorf("%w: строка %d: лишний END:%s", ErrSyntax, line.number, prop.Value)
+			}
+			done := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				roots = append(roots, done)
+			} else {
+				stack[len(stack)-1].AddChild(done)
+			}
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("%w: строка %d: свойство %s вне компонента", ErrSyntax, line.number, prop.Name)
+			}
+			current := stack[len(stack)-1]
+			current.Properties = append(current.Properties, prop)
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("%w: нет END:%s", ErrSyntax, stack[len(stack)-1].Name)
+	}
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("%w: нет ни одного компонента", ErrSyntax)
+	}
+	return roots, nil
+}
+
+type contentLine struct {
+	number int // номер первой физической строки
+	text   string
+}
+
+// unfold склеивает перенесенные строки: строка, начинающаяся с пробела или табуляции,
+// продолжает предыдущую
+func unfold(r io.Reader) ([]contentLine, error) {
+	var lines []contentLine
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimSuffix(scanner.Text(), "\r")
+		if n == 1 {
+			text = strings.TrimPrefix(text, "\uFEFF")
+		}
+		if text == "" {
+			continue
+		}
+		if (text[0] == ' ' || text[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1].text += text[1:]
+			continue
+		}
+		lines = append(lines, contentLine{number: n, text: text})
+	}
+	return lines, scanner.Err()
+}
+
+// parseLine разбирает строку содержимого: name *(";" param) ":" value.
+// Значения параметров в кавычках могут содержать ":", ";" и ",".
+func parseLine(line string) (Property, error) {
+	i := strings.IndexAny(line, ";:")
+	if i <= 0 {
+		return Property{}, errors.New("нет имени свойства или двоеточия")
+	}
+	prop := Property{Name: strings.ToUpper(line[:i])}
+
+	for line[i] == ';' {
+		line = line[i+1:]
+		eq := strings.IndexByte(line, '=')
+		if eq <= 0 {
+			return Property{}, fmt.Errorf("некорректный параметр свойства %s", prop.Name)
+		}
+		name := strings.ToUpper(line[:eq])
+		line = line[eq+1:]
+
+		var values []string
+		for {
+			var value string
+			if strings.HasPrefix(line, `"`) {
+				end := strings.IndexByte(line[1:], '"')
+				if end < 0 {
+					return Property{}, fmt.Errorf("незакрытая кавычка в параметре %s", name)
+				}
+				value, line = line[1:end+1], line[end+2:]
+			} else {
+				end := strings.IndexAny(line, ",;:")
+				if end < 0 {
+					return Property{}, fmt.Errorf("нет значения свойства %s", prop.Name)
+				}
+				value, line = line[:end], line[end:]
+			}
+			values = append(values, value)
+			if !strings.HasPrefix(line, ",") {
+				break
+			}
+			line = line[1:]
+		}
+		if prop.Params == nil {
+			prop.Params = map[string]string{}
+		}
+		prop.Params[name] = strings.Join(values, ",")
+
+		if line == "" || (line[0] != ';' && line[0] != ':') {
+			return Property{}, fmt.Errorf("нет значения свойства %s", prop.Name)
+		}
+		i = 0
+	}
+	prop.Value = line[i+1:]
+	return prop, nil
+}
+
+// Encode записывает компонент со всеми вложенными: строки через CRLF,
+// длиннее 75 байт переносятся
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	writeComponent(bw, c)
+	return bw.Flush()
+}
+
+func writeComponent(w *bufio.Writer, c *Component) {
+	writeLine(w, "BEGIN:"+c.Name)
+	for _, prop := range c.Properties {
+		writeLine(w, formatLine(prop))
+	}
+	for _, child := range c.Components {
+		writeComponent(w, child)
+	}
+	writeLine(w, "END:"+c.Name)
+}
+
+// formatLine собирает строку содержимого; параметры записываются в алфавитном порядке
+func formatLine(prop Property) string {
+	var b strings.Builder
+	b.WriteString(prop.Name)
+	for _, name := range sortedKeys(prop.Params) {
+		b.WriteString(";" + name + "=")
+		value := prop.Params[name]
+		if strings.ContainsAny(value, ":;,") {
+			value = `"` + strings.ReplaceAll(value, `"`, "") + `"`
+		}
+		b.WriteString(value)
+	}
+	b.WriteString(":" + prop.Value)
+	return b.String()
+}
+
+// writeLine записывает строку, перенося ее по 75 байт без разрыва символов UTF-8
+func writeLine(w *bufio.Writer, line string) {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !isRuneStart(line[cut]) {
+			cut--
+		}
+		w.WriteString(line[:cut])
+		w.WriteString("\r\n ")
+		line = line[cut:]
+		limit = maxLineOctets - 1 // пробел в начале строки продолжения
+	}
+	w.WriteString(line)
+	w.WriteString("\r\n")
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
+
+// EscapeText экранирует текстовое значение: "\", ";", "," и перевод строки
+func EscapeText(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ReplaceAll(text, "\r\n", "\n") {
+		switch r {
+		case '\\', ';', ',':
+			b.WriteRune('\\')
+			b.WriteRune(r)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// UnescapeText снимает экранирование текстового значения
+func UnescapeText(text string) string {
+	if !strings.Contains(text, `\`) {
+		return text
+	}
+	var b strings.Builder
+	escaped := false
+	for _, r := range text {
+		switch {
+		case escaped:
+			if r == 'n' || r == 'N' {
+				b.WriteRune('\n')
+			} else {
+				b.WriteRune(r)
+			}
+			escaped = false
+		case r == '\\':
+			escaped = true
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// SplitText разбирает список текстовых значений, разделенных неэкранированными запятыми;
+// пустые значения пропускаются
+func SplitText(value string) []string {
+	var values []string
+	start, escaped := 0, false
+	for i := 0; i <= len(value); i++ {
+		if i < len(value) {
+			if escaped {
+				escaped = false
+				continue
+			}
+			if value[i] == '\\' {
+				escaped = true
+				continue
+			}
+			if value[i] != ',' {
+				continue
+			}
+		}
+		if item := strings.TrimSpace(UnescapeText(value[start:i])); item != "" {
+			values = append(values, item)
+		}
+		start = i + 1
+	}
+	return values
+}
@@ -0,0 +1,238 @@
+package ical
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDecode(t *testing.T) {
+	input := "\uFEFFBEGIN:VCALENDAR\r\n" +
+		"VERSION:2.0\r\n" +
+		"BEGIN:VTODO\n" +
+		"UID:1@todo-list\n" +
+		"SUMMARY:Длинный заголовок, \n" +
+		" перенесенный\\; на две строки\n" +
+		"\n" +
+		"DUE;TZID=\"Europe/Moscow\";VALUE=DATE-TIME:20261020T120000\n" +
+		"categories:a\\,b,c\n" +
+		"BEGIN:VALARM\n" +
+		"TRIGGER;RELATED=END:-PT15M\n" +
+		"END:VALARM\n" +
+		"END:VTODO\n" +
+		"END:VCALENDAR\n"
+
+	roots, err := Decode(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(roots) != 1 || roots[0].Name != "VCALENDAR" || roots[0].Value("VERSION") != "2.0" {
+		t.Fatalf("roots = %+v", roots)
+	}
+	todos := roots[0].Children("VTODO")
+	if len(todos) != 1 {
+		t.Fatalf("VTODO = %+v", roots[0].Components)
+	}
+	todo := todos[0]
+
+	if got := todo.Text("SUMMARY"); got != "Длинный заголовок, перенесенный; на две строки" {
+		t.Errorf("SUMMARY = %q", got)
+	}
+	due := todo.Prop("DUE")
+	if due == nil || due.Param("tzid") != "Europe/Moscow" || due.Param("VALUE") != "DATE-TIME" || due.Value != "20261020T120000" {
+		t.Errorf("DUE = %+v", due)
+	}
+	if got := todo.TextList("CATEGORIES"); !reflect.DeepEqual(got, []string{"a,b", "c"}) {
+		t.Errorf("CATEGORIES = %q", got)
+	}
+	if alarms := todo.Children("VALARM"); len(alarms) != 1 || alarms[0].Prop("TRIGGER").Param("RELATED") != "END" {
+		t.Errorf("VALARM = %+v", alarms)
+	}
+	if todo.Prop("LOCATION") != nil || todo.Value("LOCATION") != "" {
+		t.Error("missing property should be nil")
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":            "",
+		"no END":           "BEGIN:VCALENDAR\nVERSION:2.0\n",
+		"extra END":        "BEGIN:VCALENDAR\nEND:VTODO\n",
+		"property outside": "VERSION:2.0\n",
+		"no colon":         "BEGIN:VCALENDAR\nVERSION\nEND:VCALENDAR\n",
+		"bad param":        "BEGIN:VCALENDAR\nDUE;TZID:1\nEND:VCALENDAR\n",
+		"unclosed quote":   "BEGIN:VCALENDAR\nDUE;TZID=\"Europe/Moscow:1\nEND:VCALENDAR\n",
+	}
+	for name, input := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := Decode(strings.NewReader(input)); !errors.Is(err, ErrSyntax) {
+				t.Errorf("Decode = %v, want ErrSyntax", err)
+			}
+		})
+	}
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	todo := NewComponent("VTODO")
+	title := strings.Repeat("задача ", 30)
+	todo.AddText("SUMMARY", title)
+	todo.Add("RELATED-TO", "1@todo-list", "RELTYPE", "PARENT", "X-NOTE", "a:b")
+
+	var buf bytes.Buffer
+	if err := Encode(&buf, todo); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line of %d octets: %q", len(line), line)
+		}
+	}
+	if !strings.Contains(buf.String(), "RELATED-TO;RELTYPE=PARENT;X-NOTE=\"a:b\":1@todo-list\r\n") {
+		t.Errorf("params are not sorted or quoted:\n%s", buf.String())
+	}
+
+	roots, err := Decode(&buf)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if got := roots[0].Text("SUMMARY"); got != title {
+		t.Errorf("SUMMARY = %q, want %q", got, title)
+	}
+	if got := roots[0].Prop("RELATED-TO").Param("X-NOTE"); got != "a:b" {
+		t.Errorf("X-NOTE = %q", got)
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	text := "a\\b; c,d\r\nстрока"
+	escaped := EscapeText(text)
+	if escaped != `a\\b\; c\,d\nстрока` {
+		t.Errorf("EscapeText = %q", escaped)
+	}
+	if got := UnescapeText(escaped); got != "a\\b; c,d\nстрока" {
+		t.Errorf("UnescapeText = %q", got)
+	}
+	if got := UnescapeText(`a\Nb`); got != "a\nb" {
+		t.Errorf("UnescapeText(\\N) = %q", got)
+	}
+	if got := SplitText(`a\,b, ,c,`); !reflect.DeepEqual(got, []string{"a,b", "c"}) {
+		t.Errorf("SplitText = %q", got)
+	}
+}
+
+func TestZonesTime(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	cal := NewComponent("VCALENDAR")
+	vtz := NewComponent("VTIMEZONE")
+	vtz.Add("TZID", "Russian Standard Time")
+	standard := NewComponent("STANDARD")
+	standard.Add("DTSTART", "16010101T000000")
+	standard.Add("TZOFFSETTO", "+0300")
+	vtz.AddChild(standard)
+	cal.AddChild(vtz)
+	zones := CalendarZones(cal)
+
+	tests := []struct {
+		name   string
+		prop   Property
+		want   time.Time
+		allDay bool
+	}{
+		{"utc", Property{Value: "20261020T120000Z"}, time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC), false},
+		{"iana", Property{Value: "20261020T120000", Params: map[string]string{"TZID": "Europe/Moscow"}},
+			time.Date(2026, 10, 20, 12, 0, 0, 0, moscow), false},
+		{"mozilla prefix", Property{Value: "20261020T120000", Params: map[string]string{"TZID": "/mozilla.org/20050126_1/Europe/Moscow"}},
+			time.Date(2026, 10, 20, 12, 0, 0, 0, moscow), false},
+		{"vtimezone offset", Property{Value: "20261020T120000", Params: map[string]string{"TZID": "Russian Standard Time"}},
+			time.Date(2026, 10, 20, 9, 0, 0, 0, time.UTC), false},
+		{"floating", Property{Value: "20261020T120000"}, time.Date(2026, 10, 20, 12, 0, 0, 0, time.Local), false},
+		{"date", Property{Value: "20261020", Params: map[string]string{"VALUE": "DATE"}},
+			time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, allDay, err := zones.Time(&tt.prop)
+			if err != nil {
+				t.Fatalf("Time: %v", err)
+			}
+			if !got.Equal(tt.want) || allDay != tt.allDay {
+				t.Errorf("Time = %v, %v; want %v, %v", got, allDay, tt.want, tt.allDay)
+			}
+		})
+	}
+
+	if _, _, err := zones.Time(&Property{Value: "2026-10-20"}); err == nil {
+		t.Error("Time accepted a malformed value")
+	}
+}
+
+func TestZonesTimes(t *testing.T) {
+	times, allDay, err := Zones{}.Times(&Property{Value: "20261020T120000Z, 20261027T120000Z"})
+	if err != nil || allDay || len(times) != 2 {
+		t.Fatalf("Times = %v, %v, %v", times, allDay, err)
+	}
+	if got := FormatUTC(times[1]); got != "20261027T120000Z" {
+		t.Errorf("second time = %s", got)
+	}
+
+	dates, allDay, err := Zones{}.Times(&Property{Value: "20261020,20261021", Params: map[string]string{"VALUE": "DATE"}})
+	if err != nil || !allDay || len(dates) != 2 || FormatDate(dates[1]) != "20261021" {
+		t.Errorf("Times(DATE) = %v, %v, %v", dates, allDay, err)
+	}
+
+	if _, _, err := (Zones{}).Times(&Property{Value: "20261020T120000Z,bad"}); err == nil {
+		t.Error("Times accepted a malformed value")
+	}
+}
+
+func TestFormatTime(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*3600)
+	at := time.Date(2026, 10, 20, 1, 30, 0, 0, moscow)
+	if got := FormatUTC(at); got != "20261019T223000Z" {
+		t.Errorf("FormatUTC = %s", got)
+	}
+	if got := FormatDate(at); got != "20261020" {
+		t.Errorf("FormatDate = %s", got)
+	}
+}
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		d     time.Duration
+	}{
+		{"PT0S", 0},
+		{"-PT15M", -15 * time.Minute},
+		{"P1D", 24 * time.Hour},
+		{"-P1W", -7 * 24 * time.Hour},
+		{"P1DT2H30M", 26*time.Hour + 30*time.Minute},
+		{"PT1H0M5S", time.Hour + 5*time.Second},
+	}
+	for _, tt := range tests {
+		d, err := ParseDuration(tt.value)
+		if err != nil || d != tt.d {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", tt.value, d, err, tt.d)
+		}
+		if got, err := ParseDuration(FormatDuration(tt.d)); err != nil || got != tt.d {
+			t.Errorf("round trip of %v via %q = %v, %v", tt.d, FormatDuration(tt.d), got, err)
+		}
+	}
+	if got := FormatDuration(-15 * time.Minute); got != "-PT15M" {
+		t.Errorf("FormatDuration = %s", got)
+	}
+	if got, err := ParseDuration("+p2w"); err != nil || got != 14*24*time.Hour {
+		t.Errorf("ParseDuration(+p2w) = %v, %v", got, err)
+	}
+
+	for _, value := range []string{"", "P", "15M", "PT15", "P1H", "PT1D", "PXD"} {
+		if _, err := ParseDuration(value); err == nil {
+			t.Errorf("ParseDuration(%q) accepted", value)
+		}
+	}
+}
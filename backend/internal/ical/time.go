@@ -0,0 +1,264 @@
+package ical
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Форматы значений DATE и DATE-TIME
+const (
+	DateFormat     = "20060102"
+	DateTimeFormat = "20060102T150405"
+	UTCFormat      = "20060102T150405Z"
+)
+
+// FormatUTC записывает время в UTC: 20261020T120000Z
+func FormatUTC(t time.Time) string {
+	return t.UTC().Format(UTCFormat)
+}
+
+// FormatDate записывает дату без времени: 20261020
+func FormatDate(t time.Time) string {
+	return t.Format(DateFormat)
+}
+
+// Zones часовые пояса календаря по TZID
+type Zones map[string]*time.Location
+
+// CalendarZones собирает часовые пояса, на которые ссылается календарь. TZID из базы
+// IANA (Europe/Moscow, в том числе с префиксом вида /mozilla.org/.../Europe/Moscow)
+// берется из нее; для остальных (Outlook: "Russian Standard Time") используется
+// смещение из описания VTIMEZONE: последнего перехода STANDARD или DAYLIGHT.
+func CalendarZones(cal *Component) Zones {
+	zones := Zones{}
+	for _, vtz := range cal.Children("VTIMEZONE") {
+		tzid := vtz.Value("TZID")
+		if tzid == "" {
+			continue
+		}
+		if loc := loadLocation(tzid); loc != nil {
+			zones[tzid] = loc
+		} else if loc := fixedZone(tzid, vtz); loc != nil {
+			zones[tzid] = loc
+		}
+	}
+	return zones
+}
+
+// Location возвращает часовой пояс TZID; неизвестный пояс - местное время
+func (z Zones) Location(tzid string) *time.Location {
+	if tzid == "" {
+		return time.Local
+	}
+	if loc, ok := z[tzid]; ok {
+		return loc
+	}
+	if loc := loadLocation(tzid); loc != nil {
+		return loc
+	}
+	return time.Local
+}
+
+// loadLocation ищет пояс в базе IANA, отбрасывая префиксы вида "/mozilla.org/20050126_1/"
+func loadLocation(tzid string) *time.Location {
+	name := strings.Trim(tzid, "/")
+	for name != "" {
+		if loc, err := time.LoadLocation(name); err == nil && name != "Local" {
+			return loc
+		}
+		_, rest, found := strings.Cut(name, "/")
+		if !found {
+			break
+		}
+		name = rest
+	}
+	return nil
+}
+
+// fixedZone строит пояс с постоянным смещением по описанию VTIMEZONE
+func fixedZone(tzid string, vtz *Component) *time.Location {
+	var observances []*Component
+	observances = append(observances, vtz.Children("STANDARD")...)
+	observances = append(observances, vtz.Children("DAYLIGHT")...)
+	sort.SliceStable(observances, func(i, j int) bool {
+		return observances[i].Value("DTSTART") < observances[j].Value("DTSTART")
+	})
+	for i := len(observances) - 1; i >= 0; i-- {
+		if offset, err := parseOffset(observances[i].Value("TZOFFSETTO")); err == nil {
+			return time.FixedZone(tzid, offset)
+		}
+	}
+	return nil
+}
+
+// parseOffset разбирает смещение UTC вида +0300 или -053000 в секунды
+func parseOffset(value string) (int, error) {
+	if len(value) != 5 && len(value) != 7 || (value[0] != '+' && value[0] != '-') {
+		return 0, fmt.Errorf("некорректное смещение %q", value)
+	}
+	seconds := 0
+	for i, unit := range []int{3600, 60, 1} {
+		if 1+2*i >= len(value) {
+			break
+		}
+		n, err := strconv.Atoi(value[1+2*i : 3+2*i])
+		if err != nil {
+			return 0, fmt.Errorf("некорректное смещение %q", value)
+		}
+		seconds += n * unit
+	}
+	if value[0] == '-' {
+		seconds = -seconds
+	}
+	return seconds, nil
+}
+
+// Time разбирает значение DATE или DATE-TIME свойства. Время с суффиксом Z - UTC,
+// с параметром TZID - в этом поясе, без них ("плавающее") и даты без времени - местное.
+// allDay сообщает, что указана только дата.
+func (z Zones) Time(prop *Property) (t time.Time, allDay bool, err error) {
+	value := strings.TrimSpace(prop.Value)
+	return z.parse(value, prop.Param("TZID"), strings.EqualFold(prop.Param("VALUE"), "DATE"))
+}
+
+// Times разбирает свойство со списком дат (EXDATE, RDATE)
+func (z Zones) Times(prop *Property) ([]time.Time, bool, error) {
+	var times []time.Time
+	allDay := false
+	for _, value := range strings.Split(prop.Value, ",") {
+		t, date, err := z.parse(strings.TrimSpace(value), prop.Param("TZID"), strings.EqualFold(prop.Param("VALUE"), "DATE"))
+		if err != nil {
+			return nil, false, err
+		}
+		times, allDay = append(times, t), date
+	}
+	return times, allDay, nil
+}
+
+func (z Zones) parse(value, tzid string, dateOnly bool) (time.Time, bool, error) {
+	switch {
+	case dateOnly || len(value) == len(DateFormat):
+		t, err := time.ParseInLocation(DateFormat, value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("некорректная дата %q", value)
+		}
+		return t, true, nil
+	case strings.HasSuffix(value, "Z"):
+		t, err := time.Parse(UTCFormat, value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("некорректное время %q", value)
+		}
+		return t, false, nil
+	default:
+		t, err := time.ParseInLocation(DateTimeFormat, value, z.Location(tzid))
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("некорректное время %q", value)
+		}
+		return t, false, nil
+	}
+}
+
+// FormatDuration записывает длительность: -PT15M, P1D, -P1W
+func FormatDuration(d time.Duration) string {
+	var b strings.Builder
+	if d < 0 {
+		b.WriteByte('-')
+		d = -d
+	}
+	b.WriteByte('P')
+	total := int64(d / time.Second)
+	if total == 0 {
+		return "PT0S"
+	}
+	if total%(7*86400) == 0 {
+		fmt.Fprintf(&b, "%dW", total/(7*86400))
+		return b.String()
+	}
+	if days := total / 86400; days > 0 {
+		fmt.Fprintf(&b, "%dD", days)
+		total %= 86400
+	}
+	if total > 0 {
+		b.WriteByte('T')
+		if hours := total / 3600; hours > 0 {
+			fmt.Fprintf(&b, "%dH", hours)
+		}
+		if minutes := total % 3600 / 60; minutes > 0 {
+			fmt.Fprintf(&b, "%dM", minutes)
+		}
+		if seconds := total % 60; seconds > 0 {
+			fmt.Fprintf(&b, "%dS", seconds)
+		}
+	}
+	return b.String()
+}
+
+// ParseDuration разбирает длительность вида [+-]P[nW][nD][T[nH][nM][nS]]
+func ParseDuration(value string) (time.Duration, error) {
+	text := strings.ToUpper(strings.TrimSpace(value))
+	sign := time.Duration(1)
+	switch {
+	case strings.HasPrefix(text, "-"):
+		sign, text = -1, text[1:]
+	case strings.HasPrefix(text, "+"):
+		text = text[1:]
+	}
+	if !strings.HasPrefix(text, "P") || len(text) < 3 {
+		return 0, fmt.Errorf("некорректная длительность %q", value)
+	}
+
+	var d time.Duration
+	inTime := false
+	number := ""
+	for _, r := range text[1:] {
+		switch {
+		case r >= '0' && r <= '9':
+			number += string(r)
+		case r == 'T' && !inTime && number == "":
+			inTime = true
+		default:
+			n, err := strconv.Atoi(number)
+			if err != nil {
+				return 0, fmt.Errorf("некорректная длительность %q", value)
+			}
+			unit, ok := durationUnit(r, inTime)
+			if !ok {
+				return 0, fmt.Errorf("некорректная длительность %q", value)
+			}
+			d += time.Duration(n) * unit
+			number = ""
+		}
+	}
+	if number != "" {
+		return 0, fmt.Errorf("некорректная длительность %q", value)
+	}
+	return sign * d, nil
+}
+
+func durationUnit(r rune, inTime bool) (time.Duration, bool) {
+	switch {
+	case !inTime && r == 'W':
+		return 7 * 24 * time.Hour, true
+	case !inTime && r == 'D':
+		return 24 * time.Hour, true
+	case inTime && r == 'H':
+		return time.Hour, true
+	case inTime && r == 'M':
+		return time.Minute, true
+	case inTime && r == 'S':
+		return time.Second, true
+	}
+	return 0, false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
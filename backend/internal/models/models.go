@@ -22,35 +22,128 @@ type Todo struct {
 	Priority    string     `json:"priority"`
 	DueDate     *time.Time `json:"due_date"`
 	CategoryID  *uint      `json:"category_id"`
+	ParentID    *uint      `json:"parent_id"`
+	Recurrence  string     `json:"recurrence"` // RRULE and optional EXDATE lines
+	Reminders   []int64    `json:"reminders"`  // minutes before the due date
+	Tags        []string   `json:"tags"`       // tag names, sorted
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"` // set while the task is in the trash
 }
 
 // Category представляет категорию задач
 type Category struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Color     string     `json:"color"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty"` // set while the category is in the trash
+}
+
+// Tag свободная метка задачи; задача может иметь несколько меток
+type Tag struct {
 	ID        uint      `json:"id"`
 	Name      string    `json:"name"`
 	Color     string    `json:"color"`
+	Count     int       `json:"count"` // number of tasks with the tag
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// Feed подписка на задачи в формате iCalendar по секретной ссылке
+type Feed struct {
+	ID        uint       `json:"id"`
+	Name      string     `json:"name"`
+	Token     string     `json:"token"`
+	Filter    TaskFilter `json:"filter"`
+	Events    bool       `json:"events"` // also publish due dates as VEVENT
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CalDAVObject имя ресурса и UID задачи, созданной клиентом CalDAV
+type CalDAVObject struct {
+	TodoID uint
+	Name   string
+	UID    string
+}
+
+// FileTask задача из JSON-файла приложения для переноса в PostgreSQL
+type FileTask struct {
+	Key       string     // task ID in the file and its creation time
+	ParentKey string     // empty - top-level task
+	Todo      Todo       // with tags, created_at and deleted_at
+	Activity  []Activity // the task's history from the file, oldest first
+}
+
+// FileImportItem результат переноса одной задачи из JSON-файла
+type FileImportItem struct {
+	Key    string `json:"key"`
+	Title  string `json:"title"`
+	Action string `json:"action"`  // create or skip
+	TodoID uint   `json:"todo_id"` // created or previously migrated task
+}
+
+// Activity запись журнала изменений задачи
+type Activity struct {
+	ID        uint          `json:"id"`
+	TodoID    uint          `json:"todo_id"`
+	Title     string        `json:"title"`  // task title at the time of the change
+	Action    string        `json:"action"` // create, update, complete, reopen, delete, restore, purge
+	Changes   []FieldChange `json:"changes"`
+	Source    string        `json:"source"`          // app, api or caldav
+	Actor     string        `json:"actor,omitempty"` // OS user for app, authenticated user for api and caldav
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+// FieldChange old and new value of a task field, formatted as text
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// Activity actions
+const (
+	ActivityCreate   = "create"
+	ActivityUpdate   = "update"
+	ActivityComplete = "complete"
+	ActivityReopen   = "reopen"
+	ActivityDelete   = "delete"
+	ActivityRestore  = "restore"
+	ActivityPurge    = "purge"
+)
+
+// Activity sources
+const (
+	SourceApp    = "app"
+	SourceAPI    = "api"
+	SourceCalDAV = "caldav"
+)
+
 // Request structs for API handlers
 type CreateTaskRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Priority    string `json:"priority"`
-	DueDate     string `json:"due_date"`
-	CategoryID  *uint  `json:"category_id"`
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Priority    string   `json:"priority"`
+	DueDate     string   `json:"due_date"`
+	CategoryID  *uint    `json:"category_id"`
+	ParentID    *uint    `json:"parent_id"`
+	Recurrence  string   `json:"recurrence"`
+	Reminders   []int64  `json:"reminders"`
+	Tags        []string `json:"tags"`
 }
 
 type UpdateTaskRequest struct {
-	Title       *string `json:"title"`
-	Description *string `json:"description"`
-	Priority    *string `json:"priority"`
-	DueDate     *string `json:"due_date"`
-	Completed   *bool   `json:"completed"`
-	CategoryID  *uint   `json:"category_id"`
+	Title       *string   `json:"title"`
+	Description *string   `json:"description"`
+	Priority    *string   `json:"priority"`
+	DueDate     *string   `json:"due_date"`
+	Completed   *bool     `json:"completed"`
+	CategoryID  *uint     `json:"category_id"`
+	Recurrence  *string   `json:"recurrence"`
+	Reminders   *[]int64  `json:"reminders"`
+	Tags        *[]string `json:"tags"`
 }
 
 // Filter and sort structs
@@ -60,9 +153,53 @@ type TaskFilter struct {
 	DateFrom    *time.Time `json:"date_from"`
 	DateTo      *time.Time `json:"date_to"`
 	CategoryID  *uint      `json:"category_id"`
+	ParentID    *uint      `json:"parent_id"` // 0 - only top-level tasks
+	Tags        []string   `json:"tags"`
+	TagMode     TagMode    `json:"tag_mode"` // any (default) or all
 }
 
+// TagMode how TaskFilter.Tags are combined
+type TagMode string
+
+const (
+	TagModeAny TagMode = "any"
+	TagModeAll TagMode = "all"
+)
+
 type TaskSort struct {
 	Field string `json:"field"` // id, title, priority, due_date, created_at
 	Order string `json:"order"` // asc, desc
 }
+
+// Pagination structs
+type PageRequest struct {
+	Cursor string `json:"cursor"`
+	Limit  int    `json:"limit"`
+}
+
+type TaskPage struct {
+	Items      []Todo `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+}
+
+// Search result with rank and highlighted fragments (matches wrapped in <mark>)
+type SearchResult struct {
+	Todo
+	Rank                 float64 `json:"rank"`
+	TitleHighlight       string  `json:"title_highlight"`
+	DescriptionHighlight string  `json:"description_highlight"`
+}
+
+// Task hierarchy
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+type TodoNode struct {
+	Todo
+	Progress Progress    `json:"progress"`
+	Children []*TodoNode `json:"children"`
+}
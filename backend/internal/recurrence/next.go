@@ -0,0 +1,261 @@
+// recurrence/next.go
+package recurrence
+
+import (
+	"sort"
+	"time"
+)
+
+// maxPeriods ограничивает перебор периодов, чтобы правило без совпадений
+// (например, BYMONTHDAY=31 с BYMONTH=2) не зациклило поиск
+const maxPeriods = 1000
+
+// Next возвращает первое повторение строго после start, где start - срок текущего
+// экземпляра. Время суток берется из start. false означает, что серия закончилась
+// (достигнут COUNT или UNTIL).
+func (r *Rule) Next(start time.Time) (time.Time, bool) {
+	if r.Count == 1 {
+		return time.Time{}, false
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		candidates := r.expand(start, period*r.Interval)
+		for _, candidate := range candidates {
+			if !candidate.After(start) || r.isExcluded(candidate) {
+				continue
+			}
+			if r.Until != nil && candidate.After(r.until(start.Location())) {
+				return time.Time{}, false
+			}
+			return candidate, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// until возвращает последний допустимый момент серии. UNTIL без времени включает
+// весь указанный день в часовом поясе задачи loc.
+func (r *Rule) until(loc *time.Location) time.Time {
+	if !r.UntilDate {
+		return *r.Until
+	}
+	u := r.Until
+	return time.Date(u.Year(), u.Month(), u.Day()+1, 0, 0, 0, 0, loc).Add(-time.Nanosecond)
+}
+
+// Advance возвращает правило для следующего экземпляра серии: COUNT уменьшается на единицу,
+// пропуски, оставшиеся в прошлом, отбрасываются
+func (r *Rule) Advance(next time.Time) *Rule {
+	advanced := *r
+	if advanced.Count > 0 {
+		advanced.Count--
+	}
+	advanced.ByDay = append([]WeekdayNum(nil), r.ByDay...)
+	advanced.ByMonthDay = append([]int(nil), r.ByMonthDay...)
+	advanced.ByMonth = append([]time.Month(nil), r.ByMonth...)
+	advanced.Exdates = nil
+	for _, t := range r.Exdates {
+		if !sameDay(t, next) && t.After(next) {
+			advanced.Exdates = append(advanced.Exdates, t)
+		}
+	}
+	return &advanced
+}
+
+// Occurrences возвращает до n следующих повторений после start
+func (r *Rule) Occurrences(start time.Time, n int) []time.Time {
+	var result []time.Time
+	current := *r
+	for len(result) < n {
+		next, ok := current.Next(start)
+		if !ok {
+			break
+		}
+		result = append(result, next)
+		current = *current.Advance(next)
+		start = next
+	}
+	return result
+}
+
+// isExcluded сообщает, пропущено ли повторение в этот день
+func (r *Rule) isExcluded(t time.Time) bool {
+	for _, ex := range r.Exdates {
+		if sameDay(ex, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// expand возвращает отсортированные кандидаты периода с номером offset относительно start
+func (r *Rule) expand(start time.Time, offset int) []time.Time {
+	var days []time.Time
+
+	switch r.Freq {
+	case Daily:
+		day := dateOf(start).AddDate(0, 0, offset)
+		if r.matchesMonth(day) && r.matchesWeekday(day) && r.matchesMonthDay(day) {
+			days = append(days, day)
+		}
+
+	case Weekly:
+		// Неделя начинается с понедельника
+		weekStart := dateOf(start).AddDate(0, 0, -((int(start.Weekday())+6)%7)+7*offset)
+		for i := 0; i < 7; i++ {
+			day := weekStart.AddDate(0, 0, i)
+			if len(r.ByDay) == 0 && day.Weekday() != start.Weekday() {
+				continue
+			}
+			if r.matchesWeekday(day) && r.matchesMonth(day) {
+				days = append(days, day)
+			}
+		}
+
+	case Monthly:
+		month := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, start.Location())
+		if r.matchesMonth(month) {
+			days = r.daysInMonth(month, start)
+		}
+
+	case Yearly:
+		year := start.Year() + offset
+		months := r.ByMonth
+		switch {
+		case len(months) > 0:
+		case len(r.ByMonthDay) > 0:
+			// BYMONTHDAY без BYMONTH относится к каждому месяцу года
+			months = allMonths
+		case len(r.ByDay) > 0:
+			// BYDAY без BYMONTH отсчитывается по всему году: 1MO - первый понедельник года,
+			// -1FR - последняя пятница года, MO - каждый понедельник
+			first := time.Date(year, time.January, 1, 0, 0, 0, 0, start.Location())
+			days = r.weekdaysIn(first, first.AddDate(1, 0, 0))
+		default:
+			months = []time.Month{start.Month()}
+		}
+		for _, m := range months {
+			month := time.Date(year, m, 1, 0, 0, 0, 0, start.Location())
+			days = append(days, r.daysInMonth(month, start)...)
+		}
+	}
+
+	result := make([]time.Time, 0, len(days))
+	for _, day := range days {
+		result = append(result, withClock(day, start))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Before(result[j]) })
+	return result
+}
+
+// daysInMonth возвращает дни месяца по BYMONTHDAY, BYDAY или дню исходной даты
+func (r *Rule) daysInMonth(month, start time.Time) []time.Time {
+	last := month.AddDate(0, 1, -1).Day()
+	var days []time.Time
+
+	switch {
+	case len(r.ByMonthDay) > 0:
+		for _, d := range r.ByMonthDay {
+			if d < 0 {
+				d = last + d + 1
+			}
+			if d >= 1 && d <= last {
+				day := month.AddDate(0, 0, d-1)
+				if r.matchesWeekday(day) {
+					days = append(days, day)
+				}
+			}
+		}
+
+	case len(r.ByDay) > 0:
+		days = r.weekdaysIn(month, month.AddDate(0, 1, 0))
+
+	default:
+		// Месяцы без такого дня пропускаются, как в RFC 5545
+		if start.Day() <= last {
+			days = append(days, month.AddDate(0, 0, start.Day()-1))
+		}
+	}
+	return days
+}
+
+// weekdaysIn возвращает дни из BYDAY в промежутке [from, to); номер дня недели
+// отсчитывается от начала промежутка, отрицательный - от конца
+func (r *Rule) weekdaysIn(from, to time.Time) []time.Time {
+	var days []time.Time
+	for _, wd := range r.ByDay {
+		var matching []time.Time
+		for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+			if day.Weekday() == wd.Weekday {
+				matching = append(matching, day)
+			}
+		}
+		switch {
+		case wd.N == 0:
+			days = append(days, matching...)
+		case wd.N > 0 && wd.N <= len(matching):
+			days = append(days, matching[wd.N-1])
+		case wd.N < 0 && -wd.N <= len(matching):
+			days = append(days, matching[len(matching)+wd.N])
+		}
+	}
+	return days
+}
+
+func (r *Rule) matchesWeekday(day time.Time) bool {
+	if len(r.ByDay) == 0 {
+		return true
+	}
+	for _, wd := range r.ByDay {
+		if wd.Weekday == day.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonth(day time.Time) bool {
+	if len(r.ByMonth) == 0 {
+		return true
+	}
+	for _, m := range r.ByMonth {
+		if m == day.Month() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchesMonthDay(day time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	last := day.AddDate(0, 1, -day.Day()).Day()
+	for _, d := range r.ByMonthDay {
+		if d == day.Day() || (d < 0 && last+d+1 == day.Day()) {
+			return true
+		}
+	}
+	return false
+}
+
+var allMonths = []time.Month{
+	time.January, time.February, time.March, time.April, time.May, time.June,
+	time.July, time.August, time.September, time.October, time.November, time.December,
+}
+
+// dateOf отбрасывает время суток
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// withClock переносит время суток из clock на дату day
+func withClock(day, clock time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(),
+		clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), clock.Location())
+}
+
+// sameDay сравнивает календарные даты без учета часового пояса
+func sameDay(a, b time.Time) bool {
+	return a.Year() == b.Year() && a.Month() == b.Month() && a.Day() == b.Day()
+}
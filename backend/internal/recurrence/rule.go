@@ -0,0 +1,299 @@
+// recurrence/rule.go
+package recurrence
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency частота повторения (FREQ в RFC 5545)
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// dateFormat формат дат UNTIL и EXDATE (UTC, как в iCalendar)
+const dateFormat = "20060102T150405Z"
+
+// dateOnlyFormat формат UNTIL без времени
+const dateOnlyFormat = "20060102"
+
+// WeekdayNum день недели с необязательным порядковым номером: 2TU - второй вторник, -1FR - последняя пятница
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+// Rule правило повторения в духе RRULE из RFC 5545 с поддержкой пропусков (EXDATE).
+// Count хранит число оставшихся повторений, включая текущее: каждое следующее
+// повторение получает правило с Count на единицу меньше.
+type Rule struct {
+	Freq       Frequency
+	Interval   int
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	Until      *time.Time
+	UntilDate  bool // UNTIL задан датой без времени: включается весь этот день в часовом поясе задачи
+	Count      int
+	Exdates    []time.Time
+}
+
+var weekdayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse разбирает правило. Принимаются строка RRULE ("FREQ=WEEKLY;BYDAY=MO,WE"),
+// та же строка с префиксом "RRULE:" и необязательная строка "EXDATE:..." на отдельной строке.
+func Parse(text string) (*Rule, error) {
+	rule := &Rule{Interval: 1}
+	seenRule := false
+
+	for _, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "":
+		case strings.HasPrefix(strings.ToUpper(line), "EXDATE"):
+			_, value, _ := strings.Cut(line, ":")
+			for _, item := range strings.Split(value, ",") {
+				t, _, err := parseDate(item)
+				if err != nil {
+					return nil, fmt.Errorf("invalid EXDATE %q: %w", item, err)
+				}
+				rule.Exdates = append(rule.Exdates, t)
+			}
+		default:
+			if seenRule {
+				return nil, errors.New("only one RRULE is allowed")
+			}
+			seenRule = true
+			if err := rule.parseRRule(strings.TrimPrefix(line, "RRULE:")); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if !seenRule {
+		return nil, errors.New("recurrence rule is empty")
+	}
+	return rule, rule.validate()
+}
+
+// parseRRule разбирает пары KEY=VALUE, разделенные точкой с запятой
+func (r *Rule) parseRRule(text string) error {
+	for _, part := range strings.Split(text, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("invalid rule part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(value))
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+		case "UNTIL":
+			var t time.Time
+			if t, r.UntilDate, err = parseDate(value); err == nil {
+				r.Until = &t
+			}
+		case "BYDAY":
+			for _, item := range strings.Split(value, ",") {
+				var wd WeekdayNum
+				if wd, err = parseWeekdayNum(item); err != nil {
+					break
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYMONTHDAY":
+			for _, item := range strings.Split(value, ",") {
+				var day int
+				if day, err = strconv.Atoi(item); err != nil {
+					break
+				}
+				r.ByMonthDay = append(r.ByMonthDay, day)
+			}
+		case "BYMONTH":
+			for _, item := range strings.Split(value, ",") {
+				var month int
+				if month, err = strconv.Atoi(item); err != nil {
+					break
+				}
+				r.ByMonth = append(r.ByMonth, time.Month(month))
+			}
+		case "WKST":
+			// Неделя всегда начинается с понедельника
+		default:
+			return fmt.Errorf("unsupported rule part %q", key)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid %s value %q", key, value)
+		}
+	}
+	return nil
+}
+
+// validate проверяет согласованность правила
+func (r *Rule) validate() error {
+	switch r.Freq {
+	case Daily, Weekly, Monthly, Yearly:
+	case "":
+		return errors.New("FREQ is required")
+	default:
+		return fmt.Errorf("unsupported FREQ %q", r.Freq)
+	}
+	if r.Interval < 1 {
+		return errors.New("INTERVAL must be positive")
+	}
+	if r.Count < 0 {
+		return errors.New("COUNT must not be negative")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return errors.New("COUNT and UNTIL cannot be used together")
+	}
+	for _, day := range r.ByMonthDay {
+		if day == 0 || day < -31 || day > 31 {
+			return fmt.Errorf("invalid BYMONTHDAY %d", day)
+		}
+	}
+	for _, month := range r.ByMonth {
+		if month < time.January || month > time.December {
+			return fmt.Errorf("invalid BYMONTH %d", month)
+		}
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return errors.New("numbered BYDAY is only allowed with MONTHLY or YEARLY")
+		}
+		// Номер недели в году (до 53) допустим только для YEARLY без BYMONTH
+		if (wd.N > 5 || wd.N < -5) && (r.Freq != Yearly || len(r.ByMonth) > 0) {
+			return fmt.Errorf("invalid BYDAY %s", wd)
+		}
+	}
+	return nil
+}
+
+// String возвращает правило в виде "RRULE:..." и, если есть пропуски, "EXDATE:..." на второй строке
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			days[i] = wd.String()
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonthDay) > 0 {
+		days := make([]string, len(r.ByMonthDay))
+		for i, day := range r.ByMonthDay {
+			days[i] = strconv.Itoa(day)
+		}
+		parts = append(parts, "BYMONTHDAY="+strings.Join(days, ","))
+	}
+	if len(r.ByMonth) > 0 {
+		months := make([]string, len(r.ByMonth))
+		for i, month := range r.ByMonth {
+			months[i] = strconv.Itoa(int(month))
+		}
+		parts = append(parts, "BYMONTH="+strings.Join(months, ","))
+	}
+	if r.Until != nil && r.UntilDate {
+		parts = append(parts, "UNTIL="+r.Until.Format(dateOnlyFormat))
+	} else if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format(dateFormat))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+
+	text := "RRULE:" + strings.Join(parts, ";")
+	if len(r.Exdates) > 0 {
+		dates := make([]string, len(r.Exdates))
+		for i, t := range r.Exdates {
+			dates[i] = t.UTC().Format(dateFormat)
+		}
+		text += "\nEXDATE:" + strings.Join(dates, ",")
+	}
+	return text
+}
+
+// RRule возвращает только значение RRULE без префикса и без EXDATE
+func (r *Rule) RRule() string {
+	line, _, _ := strings.Cut(r.String(), "\n")
+	return strings.TrimPrefix(line, "RRULE:")
+}
+
+// String возвращает день недели в формате BYDAY
+func (wd WeekdayNum) String() string {
+	if wd.N == 0 {
+		return weekdayNames[wd.Weekday]
+	}
+	return strconv.Itoa(wd.N) + weekdayNames[wd.Weekday]
+}
+
+// Skip добавляет пропущенное повторение
+func (r *Rule) Skip(occurrence time.Time) {
+	if !r.isExcluded(occurrence) {
+		r.Exdates = append(r.Exdates, occurrence)
+		sort.Slice(r.Exdates, func(i, j int) bool { return r.Exdates[i].Before(r.Exdates[j]) })
+	}
+}
+
+// parseWeekdayNum разбирает элемент BYDAY: "MO", "2TU", "-1FR"
+func parseWeekdayNum(text string) (WeekdayNum, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	if len(text) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", text)
+	}
+
+	code := text[len(text)-2:]
+	weekday, ok := weekdayCodes[code]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid weekday %q", text)
+	}
+
+	wd := WeekdayNum{Weekday: weekday}
+	if prefix := text[:len(text)-2]; prefix != "" {
+		n, err := strconv.Atoi(prefix)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid weekday %q", text)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+// parseDate разбирает дату в форматах 20060102T150405Z, 20060102T150405 и 20060102;
+// dateOnly сообщает, что время не указано
+func parseDate(text string) (t time.Time, dateOnly bool, err error) {
+	text = strings.TrimSpace(text)
+	for _, layout := range []string{dateFormat, "20060102T150405"} {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, false, nil
+		}
+	}
+	if t, err := time.Parse(dateOnlyFormat, text); err == nil {
+		return t, true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("unsupported date format %q", text)
+}
@@ -0,0 +1,145 @@
+package recurrence
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+const layout = "2006-01-02 15:04"
+
+func TestOccurrences(t *testing.T) {
+	moscow := time.FixedZone("MSK", 3*60*60)
+	tests := []struct {
+		rule  string
+		start string
+		loc   *time.Location
+		n     int
+		want  []string
+	}{
+		{"FREQ=DAILY;INTERVAL=2", "2025-01-30 09:00", time.UTC, 3,
+			[]string{"2025-02-01 09:00", "2025-02-03 09:00", "2025-02-05 09:00"}},
+		{"FREQ=WEEKLY;BYDAY=MO,FR", "2025-01-01 18:30", time.UTC, 4,
+			[]string{"2025-01-03 18:30", "2025-01-06 18:30", "2025-01-10 18:30", "2025-01-13 18:30"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=1,15", "2025-01-10 08:00", time.UTC, 3,
+			[]string{"2025-01-15 08:00", "2025-02-01 08:00", "2025-02-15 08:00"}},
+		{"FREQ=MONTHLY;BYMONTHDAY=-1", "2025-01-31 12:00", time.UTC, 3,
+			[]string{"2025-02-28 12:00", "2025-03-31 12:00", "2025-04-30 12:00"}},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "2025-01-01 12:00", time.UTC, 3,
+			[]string{"2025-01-31 12:00", "2025-02-28 12:00", "2025-03-28 12:00"}},
+		{"FREQ=MONTHLY;BYDAY=2TU", "2025-01-01 12:00", time.UTC, 2,
+			[]string{"2025-01-14 12:00", "2025-02-11 12:00"}},
+		// Месяцы без 31-го числа пропускаются
+		{"FREQ=MONTHLY", "2025-01-31 10:00", time.UTC, 3,
+			[]string{"2025-03-31 10:00", "2025-05-31 10:00", "2025-07-31 10:00"}},
+		// 29 февраля повторяется только в високосные годы
+		{"FREQ=YEARLY", "2024-02-29 10:00", time.UTC, 2,
+			[]string{"2028-02-29 10:00", "2032-02-29 10:00"}},
+		{"FREQ=YEARLY;BYMONTH=2;BYMONTHDAY=-1", "2024-02-29 10:00", time.UTC, 2,
+			[]string{"2025-02-28 10:00", "2026-02-28 10:00"}},
+		// BYDAY без BYMONTH охватывает все месяцы года
+		{"FREQ=YEARLY;BYDAY=MO", "2025-01-01 09:00", time.UTC, 3,
+			[]string{"2025-01-06 09:00", "2025-01-13 09:00", "2025-01-20 09:00"}},
+		{"FREQ=YEARLY;BYDAY=-1FR", "2025-01-01 09:00", time.UTC, 2,
+			[]string{"2025-12-26 09:00", "2026-12-25 09:00"}},
+		{"FREQ=YEARLY;BYDAY=20MO", "2025-01-01 09:00", time.UTC, 1,
+			[]string{"2025-05-19 09:00"}},
+		{"FREQ=YEARLY;BYMONTH=3;BYDAY=1SU", "2025-01-01 09:00", time.UTC, 2,
+			[]string{"2025-03-02 09:00", "2026-03-01 09:00"}},
+		// COUNT учитывает текущий экземпляр
+		{"FREQ=DAILY;COUNT=3", "2025-01-01 09:00", time.UTC, 5,
+			[]string{"2025-01-02 09:00", "2025-01-03 09:00"}},
+		{"FREQ=DAILY;UNTIL=20250103T090000Z", "2025-01-01 09:00", time.UTC, 5,
+			[]string{"2025-01-02 09:00", "2025-01-03 09:00"}},
+		// UNTIL без времени включает весь день в часовом поясе задачи
+		{"FREQ=DAILY;UNTIL=20250103", "2025-01-01 23:30", moscow, 5,
+			[]string{"2025-01-02 23:30", "2025-01-03 23:30"}},
+		{"FREQ=WEEKLY;BYDAY=FR;UNTIL=20250110", "2025-01-01 18:00", time.UTC, 5,
+			[]string{"2025-01-03 18:00", "2025-01-10 18:00"}},
+		{"FREQ=DAILY\nEXDATE:20250102T090000Z,20250104T090000Z", "2025-01-01 09:00", time.UTC, 3,
+			[]string{"2025-01-03 09:00", "2025-01-05 09:00", "2025-01-06 09:00"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.rule, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			start, err := time.ParseInLocation(layout, tt.start, tt.loc)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, occurrence := range rule.Occurrences(start, tt.n) {
+				if occurrence.Location() != tt.loc {
+					t.Errorf("%s is in %s", occurrence, occurrence.Location())
+				}
+				got = append(got, occurrence.Format(layout))
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("occurrences = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, text := range []string{
+		"",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=WEEKLY;BYDAY=XX",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=6MO",
+		"FREQ=YEARLY;BYMONTH=1;BYDAY=20MO",
+		"FREQ=DAILY;UNTIL=2025-01-01",
+		"FREQ=DAILY\nFREQ=WEEKLY",
+		"FREQ=DAILY\nEXDATE:tomorrow",
+	} {
+		if _, err := Parse(text); err == nil {
+			t.Errorf("Parse(%q) succeeded", text)
+		}
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	for _, text := range []string{
+		"RRULE:FREQ=MONTHLY;INTERVAL=2;BYDAY=MO,-1FR",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=-1;COUNT=5",
+		"RRULE:FREQ=YEARLY;BYMONTHDAY=29;BYMONTH=2;UNTIL=20300101T000000Z",
+		"RRULE:FREQ=DAILY;UNTIL=20250110",
+		"RRULE:FREQ=DAILY\nEXDATE:20250102T090000Z",
+	} {
+		rule, err := Parse(text)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", text, err)
+		}
+		if got := rule.String(); got != text {
+			t.Errorf("String() = %q, want %q", got, text)
+		}
+	}
+}
+
+func TestAdvanceAndSkip(t *testing.T) {
+	rule, err := Parse("FREQ=DAILY;COUNT=3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Date(2025, 1, 1, 9, 0, 0, 0, time.UTC)
+	rule.Skip(start.AddDate(0, 0, 1))
+	rule.Skip(start.AddDate(0, 0, 5))
+
+	next, ok := rule.Next(start)
+	if !ok || !next.Equal(start.AddDate(0, 0, 2)) {
+		t.Fatalf("Next = %v, %v", next, ok)
+	}
+	advanced := rule.Advance(next)
+	if advanced.Count != 2 || rule.Count != 3 {
+		t.Errorf("count = %d, original %d", advanced.Count, rule.Count)
+	}
+	// Прошедший пропуск отбрасывается, будущий сохраняется
+	if len(advanced.Exdates) != 1 || !advanced.Exdates[0].Equal(start.AddDate(0, 0, 5)) {
+		t.Errorf("exdates = %v", advanced.Exdates)
+	}
+}
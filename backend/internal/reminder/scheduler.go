@@ -0,0 +1,338 @@
+// reminder/scheduler.go
+package reminder
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"todo-list/backend/internal/fsutil"
+)
+
+// DefaultInterval период проверки сроков
+const DefaultInterval = 30 * time.Second
+
+// staleAfter после этого времени с момента срока напоминания о задаче больше не отправляются
+const staleAfter = 24 * time.Hour
+
+// Item открытая задача со сроком, о которой нужно напомнить
+type Item struct {
+	TaskID  int
+	Title   string
+	DueDate time.Time
+	Offsets []int // минуты до срока; пусто - Settings.DefaultOffsets
+}
+
+// Reminder напоминание, которое получает интерфейс
+type Reminder struct {
+	TaskID  int       `json:"task_id"`
+	Title   string    `json:"title"`
+	DueDate time.Time `json:"due_date"`
+	Offset  int       `json:"offset"` // за сколько минут до срока
+	FireAt  time.Time `json:"fire_at"`
+	Snoozed bool      `json:"snoozed"` // повтор после отложенного напоминания
+}
+
+// Source возвращает открытые задачи со сроками
+type Source func() ([]Item, error)
+
+// Emitter доставляет напоминание пользователю
+type Emitter func(Reminder)
+
+// state состояние планировщика, которое переживает перезапуск приложения
+type state struct {
+	Settings  Settings             `json:"settings"`
+	Delivered map[string]time.Time `json:"delivered"` // ключ напоминания -> срок задачи
+	Snoozes   map[int]time.Time    `json:"snoozes"`   // ID задачи -> время повтора
+}
+
+// Scheduler проверяет сроки задач и отправляет напоминания.
+// Отправленные напоминания и отложенные задачи сохраняются в файл,
+// поэтому после перезапуска напоминания не повторяются.
+type Scheduler struct {
+	mu       sync.Mutex
+	source   Source
+	emit     Emitter
+	filename string
+	state    state
+	interval time.Duration
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewScheduler создает планировщик и загружает его состояние из filename
+func NewScheduler(filename string, source Source) *Scheduler {
+	s := &Scheduler{
+		source:   source,
+		emit:     func(Reminder) {},
+		filename: filename,
+		interval: DefaultInterval,
+		state: state{
+			Settings:  DefaultSettings(),
+			Delivered: map[string]time.Time{},
+			Snoozes:   map[int]time.Time{},
+		},
+	}
+	s.load()
+	return s
+}
+
+// Start запускает фоновую проверку сроков до вызова Stop или отмены ctx;
+// наступившие напоминания передаются в emit
+func (s *Scheduler) Start(ctx context.Context, emit Emitter) {
+	s.mu.Lock()
+	if s.cancel != nil {
+		s.mu.Unlock()
+		return
+	}
+	s.emit = emit
+	ctx, s.cancel = context.WithCancel(ctx)
+	s.done = make(chan struct{})
+	done := s.done
+	s.mu.Unlock()
+
+	go s.run(ctx, done)
+}
+
+// Stop останавливает фоновую проверку и ждет ее завершения
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.cancel = nil
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context, done chan struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.Check(time.Now())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.Check(now)
+		}
+	}
+}
+
+// Check отправляет напоминания, время которых наступило к моменту now.
+// Если к этому моменту пропущено несколько напоминаний задачи (например, приложение
+// было закрыто), отправляется только самое позднее из них.
+func (s *Scheduler) Check(now time.Time) {
+	items, err := s.source()
+	if err != nil {
+		log.Printf("Error loading tasks for reminders: %v", err)
+		return
+	}
+
+	s.mu.Lock()
+	settings := s.state.Settings
+	if !settings.Enabled || settings.InQuietHours(now) {
+		s.mu.Unlock()
+		return
+	}
+
+	var due []Reminder
+	changed := false
+	open := make(map[int]bool, len(items))
+	for _, item := range items {
+		if item.DueDate.IsZero() {
+			continue
+		}
+		open[item.TaskID] = true
+
+		offsets := item.Offsets
+		if len(offsets) == 0 {
+			offsets = settings.DefaultOffsets
+		}
+
+		if until, ok := s.state.Snoozes[item.TaskID]; ok {
+			if now.Before(until) {
+				continue
+			}
+			delete(s.state.Snoozes, item.TaskID)
+			s.markDelivered(item, offsets, now)
+			due = append(due, Reminder{
+				TaskID:  item.TaskID,
+				Title:   item.Title,
+				DueDate: item.DueDate,
+				FireAt:  until,
+				Offset:  int(item.DueDate.Sub(until) / time.Minute),
+				Snoozed: true,
+			})
+			changed = true
+			continue
+		}
+
+		if item.DueDate.Before(now.Add(-staleAfter)) {
+			continue
+		}
+
+		var latest *Reminder
+		for _, offset := range offsets {
+			fireAt := item.DueDate.Add(-time.Duration(offset) * time.Minute)
+			key := deliveryKey(item, offset)
+			if fireAt.After(now) {
+				continue
+			}
+			if _, sent := s.state.Delivered[key]; sent {
+				continue
+			}
+			s.state.Delivered[key] = item.DueDate
+			changed = true
+			if latest == nil || fireAt.After(latest.FireAt) {
+				latest = &Reminder{
+					TaskID:  item.TaskID,
+					Title:   item.Title,
+					DueDate: item.DueDate,
+					Offset:  offset,
+					FireAt:  fireAt,
+				}
+			}
+		}
+		if latest != nil {
+			due = append(due, *latest)
+		}
+	}
+
+	// Отложенные напоминания выполненных и удаленных задач больше не нужны
+	for id := range s.state.Snoozes {
+		if !open[id] {
+			delete(s.state.Snoozes, id)
+			changed = true
+		}
+	}
+	if s.prune(now) {
+		changed = true
+	}
+	if changed {
+		s.save()
+	}
+	emit := s.emit
+	s.mu.Unlock()
+
+	for _, r := range due {
+		emit(r)
+	}
+}
+
+// Snooze откладывает напоминания о задаче на d: до этого момента о ней не напоминают,
+// а затем приходит одно повторное напоминание
+func (s *Scheduler) Snooze(taskID int, d time.Duration) error {
+	if d <= 0 {
+		return errors.New("время откладывания должно быть положительным")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Snoozes[taskID] = time.Now().Add(d)
+	s.save()
+	return nil
+}
+
+// Settings возвращает текущие настройки
+func (s *Scheduler) Settings() Settings {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	settings := s.state.Settings
+	settings.DefaultOffsets = append([]int{}, settings.DefaultOffsets...)
+	return settings
+}
+
+// SetSettings проверяет и сохраняет настройки
+func (s *Scheduler) SetSettings(settings Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state.Settings = settings
+	s.save()
+	return nil
+}
+
+// markDelivered отмечает наступившие напоминания задачи отправленными
+func (s *Scheduler) markDelivered(item Item, offsets []int, now time.Time) {
+	for _, offset := range offsets {
+		if !item.DueDate.Add(-time.Duration(offset) * time.Minute).After(now) {
+			s.state.Delivered[deliveryKey(item, offset)] = item.DueDate
+		}
+	}
+}
+
+// prune забывает отправленные напоминания задач, срок которых давно прошел
+func (s *Scheduler) prune(now time.Time) bool {
+	pruned := false
+	for key, due := range s.state.Delivered {
+		if due.Before(now.Add(-2 * staleAfter)) {
+			delete(s.state.Delivered, key)
+			pruned = true
+		}
+	}
+	return pruned
+}
+
+// deliveryKey идентифицирует напоминание; при переносе срока ключ меняется,
+// и о задаче напомнят снова
+func deliveryKey(item Item, offset int) string {
+	return fmt.Sprintf("%d/%d/%d", item.TaskID, offset, item.DueDate.Unix())
+}
+
+// load читает состояние из файла; отсутствующий файл - первый запуск
+func (s *Scheduler) load() {
+	if s.filename == "" {
+		return
+	}
+	data, err := os.ReadFile(s.filename)
+	if err != nil {
+		return
+	}
+
+	loaded := s.state
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		log.Printf("Error reading reminders state: %v", err)
+		return
+	}
+	if loaded.Delivered == nil {
+		loaded.Delivered = map[string]time.Time{}
+	}
+	if loaded.Snoozes == nil {
+		loaded.Snoozes = map[int]time.Time{}
+	}
+	if err := loaded.Settings.Validate(); err != nil {
+		log.Printf("Error reading reminder settings: %v", err)
+		loaded.Settings = DefaultSettings()
+	}
+	s.state = loaded
+}
+
+// save записывает состояние в файл; вызывается под s.mu
+func (s *Scheduler) save() {
+	if s.filename == "" {
+		return
+	}
+	data, err := json.MarshalIndent(s.state, "", "  ")
+	if err != nil {
+		log.Printf("Error marshaling reminders state: %v", err)
+		return
+	}
+	if err := fsutil.WriteFileAtomic(s.filename, data, 0600); err != nil {
+		log.Printf("Error saving reminders state: %v", err)
+	}
+}
@@ -0,0 +1,166 @@
+package reminder
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newTestScheduler создает планировщик над списком задач items и собирает отправленные напоминания
+func newTestScheduler(t *testing.T, items *[]Item) (*Scheduler, *[]Reminder, string) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "reminders.json")
+	s := NewScheduler(filename, func() ([]Item, error) { return *items, nil })
+	sent := &[]Reminder{}
+	s.emit = func(r Reminder) { *sent = append(*sent, r) }
+	return s, sent, filename
+}
+
+// offsetsOf возвращает смещения отправленных напоминаний и очищает список
+func offsetsOf(sent *[]Reminder) []int {
+	offsets := []int{}
+	for _, r := range *sent {
+		offsets = append(offsets, r.Offset)
+	}
+	*sent = nil
+	return offsets
+}
+
+func TestCheckOffsets(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	items := []Item{{TaskID: 1, Title: "Созвон", DueDate: now.Add(10 * time.Minute), Offsets: []int{60, 15, 5}}}
+	s, sent, _ := newTestScheduler(t, &items)
+
+	// Из пропущенных напоминаний за 60 и 15 минут приходит только последнее
+	s.Check(now)
+	if got := offsetsOf(sent); len(got) != 1 || got[0] != 15 {
+		t.Fatalf("first check sent %v", got)
+	}
+	s.Check(now.Add(time.Minute))
+	if got := offsetsOf(sent); len(got) != 0 {
+		t.Errorf("repeated check sent %v", got)
+	}
+	s.Check(now.Add(5 * time.Minute))
+	if got := offsetsOf(sent); len(got) != 1 || got[0] != 5 {
+		t.Errorf("check at due-5m sent %v", got)
+	}
+
+	// Перенос срока снова включает напоминания
+	items[0].DueDate = now.Add(2 * time.Hour)
+	s.Check(now.Add(time.Hour))
+	if got := offsetsOf(sent); len(got) != 1 || got[0] != 60 {
+		t.Errorf("after reschedule sent %v", got)
+	}
+
+	// Задачи, срок которых давно прошел, не напоминают о себе
+	items = []Item{{TaskID: 2, DueDate: now.Add(-2 * staleAfter), Offsets: []int{0}}}
+	s.Check(now)
+	if got := offsetsOf(sent); len(got) != 0 {
+		t.Errorf("stale task sent %v", got)
+	}
+}
+
+func TestCheckDefaultOffsetsInLocalTime(t *testing.T) {
+	// Срок 18:00 по Москве - это 15:00 UTC; напоминание за 15 минут приходит в 14:45 UTC
+	moscow := time.FixedZone("MSK", 3*60*60)
+	items := []Item{{TaskID: 1, DueDate: time.Date(2026, 3, 1, 18, 0, 0, 0, moscow)}}
+	s, sent, _ := newTestScheduler(t, &items)
+
+	s.Check(time.Date(2026, 3, 1, 14, 44, 0, 0, time.UTC))
+	if got := offsetsOf(sent); len(got) != 1 || got[0] != 24*60 {
+		t.Fatalf("sent %v, want the day-before reminder", got)
+	}
+	s.Check(time.Date(2026, 3, 1, 14, 45, 0, 0, time.UTC))
+	if got := offsetsOf(sent); len(got) != 1 || got[0] != 15 {
+		t.Errorf("sent %v, want the 15 minute reminder", got)
+	}
+}
+
+func TestQuietHours(t *testing.T) {
+	settings := Settings{Enabled: true, DefaultOffsets: []int{0}, QuietStart: "22:00", QuietEnd: "07:00"}
+	for clock, quiet := range map[string]bool{"21:59": false, "22:00": true, "03:00": true, "06:59": true, "07:00": false} {
+		at, _ := time.Parse("15:04", clock)
+		if settings.InQuietHours(at) != quiet {
+			t.Errorf("InQuietHours(%s) = %v", clock, !quiet)
+		}
+	}
+	if (Settings{QuietStart: "09:00", QuietEnd: "18:00"}).InQuietHours(time.Date(2026, 3, 1, 18, 0, 0, 0, time.UTC)) {
+		t.Error("18:00 is inside 09:00-18:00")
+	}
+
+	night := time.Date(2026, 3, 1, 23, 0, 0, 0, time.Local)
+	items := []Item{{TaskID: 1, DueDate: night}}
+	s, sent, _ := newTestScheduler(t, &items)
+	if err := s.SetSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Напоминание в тихие часы откладывается до их конца, а не теряется
+	s.Check(night.Add(time.Minute))
+	if len(*sent) != 0 {
+		t.Fatalf("sent %v during quiet hours", *sent)
+	}
+	s.Check(night.Add(8 * time.Hour))
+	if got := offsetsOf(sent); len(got) != 1 || got[0] != 0 {
+		t.Errorf("after quiet hours sent %v", got)
+	}
+}
+
+func TestSnooze(t *testing.T) {
+	now := time.Now()
+	items := []Item{{TaskID: 1, Title: "Отчет", DueDate: now.Add(-time.Minute), Offsets: []int{0}}, {TaskID: 2, DueDate: now.Add(-time.Minute), Offsets: []int{0}}}
+	s, sent, _ := newTestScheduler(t, &items)
+
+	if err := s.Snooze(1, 0); err == nil {
+		t.Error("Snooze accepted a zero duration")
+	}
+	if err := s.Snooze(1, 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Snooze(2, 10*time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	s.Check(now)
+	if len(*sent) != 0 {
+		t.Fatalf("snoozed task sent %v", *sent)
+	}
+
+	// Выполненная задача пропадает из списка вместе с отложенным напоминанием
+	items = items[:1]
+	s.Check(now.Add(11 * time.Minute))
+	if len(*sent) != 1 || !(*sent)[0].Snoozed || (*sent)[0].TaskID != 1 {
+		t.Fatalf("after snooze sent %+v", *sent)
+	}
+	*sent = nil
+	s.Check(now.Add(12 * time.Minute))
+	if len(*sent) != 0 {
+		t.Errorf("snoozed reminder repeated: %+v", *sent)
+	}
+	if len(s.state.Snoozes) != 0 {
+		t.Errorf("snoozes = %v", s.state.Snoozes)
+	}
+}
+
+func TestStateSurvivesRestart(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	items := []Item{{TaskID: 1, DueDate: now, Offsets: []int{0}}}
+	s, sent, filename := newTestScheduler(t, &items)
+	s.Check(now)
+	if len(*sent) != 1 {
+		t.Fatalf("sent %v", *sent)
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("state file mode = %v", info.Mode().Perm())
+	}
+
+	restarted := NewScheduler(filename, func() ([]Item, error) { return items, nil })
+	restarted.emit = func(r Reminder) { t.Errorf("reminder %+v sent again after restart", r) }
+	restarted.Check(now.Add(time.Minute))
+}
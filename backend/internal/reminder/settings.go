@@ -0,0 +1,104 @@
+// reminder/settings.go
+package reminder
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MaxOffsets ограничивает число напоминаний на одну задачу
+const MaxOffsets = 10
+
+// Settings настройки напоминаний
+type Settings struct {
+	Enabled        bool   `json:"enabled"`
+	DefaultOffsets []int  `json:"default_offsets"` // минуты до срока для задач без своих напоминаний
+	QuietStart     string `json:"quiet_start"`     // начало тихих часов "HH:MM", пусто - без тихих часов
+	QuietEnd       string `json:"quiet_end"`       // конец тихих часов "HH:MM"
+}
+
+// DefaultSettings напоминания за сутки и за 15 минут до срока, без тихих часов
+func DefaultSettings() Settings {
+	return Settings{
+		Enabled:        true,
+		DefaultOffsets: []int{24 * 60, 15},
+	}
+}
+
+// Validate проверяет настройки и приводит смещения к каноническому виду
+func (s *Settings) Validate() error {
+	offsets, err := NormalizeOffsets(s.DefaultOffsets)
+	if err != nil {
+		return err
+	}
+	s.DefaultOffsets = offsets
+
+	if (s.QuietStart == "") != (s.QuietEnd == "") {
+		return errors.New("нужно указать и начало, и конец тихих часов")
+	}
+	if s.QuietStart == "" {
+		return nil
+	}
+	if _, err := parseClock(s.QuietStart); err != nil {
+		return err
+	}
+	if _, err := parseClock(s.QuietEnd); err != nil {
+		return err
+	}
+	return nil
+}
+
+// InQuietHours сообщает, попадает ли момент t в тихие часы.
+// Интервал может переходить через полночь: 22:00-07:00.
+func (s Settings) InQuietHours(t time.Time) bool {
+	if s.QuietStart == "" || s.QuietEnd == "" {
+		return false
+	}
+	start, err := parseClock(s.QuietStart)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(s.QuietEnd)
+	if err != nil {
+		return false
+	}
+
+	now := t.Hour()*60 + t.Minute()
+	if start <= end {
+		return now >= start && now < end
+	}
+	return now >= start || now < end
+}
+
+// NormalizeOffsets проверяет смещения напоминаний (в минутах до срока),
+// убирает повторы и сортирует от самого раннего напоминания к самому позднему
+func NormalizeOffsets(offsets []int) ([]int, error) {
+	if len(offsets) > MaxOffsets {
+		return nil, fmt.Errorf("не больше %d напоминаний на задачу", MaxOffsets)
+	}
+
+	seen := make(map[int]bool, len(offsets))
+	result := []int{}
+	for _, offset := range offsets {
+		if offset < 0 {
+			return nil, fmt.Errorf("некорректное смещение напоминания: %d", offset)
+		}
+		if !seen[offset] {
+			seen[offset] = true
+			result = append(result, offset)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(result)))
+	return result, nil
+}
+
+// parseClock разбирает время суток "HH:MM" в минуты от полуночи
+func parseClock(text string) (int, error) {
+	t, err := time.Parse("15:04", text)
+	if err != nil {
+		return 0, fmt.Errorf("некорректное время %q, ожидается HH:MM", text)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -0,0 +1,138 @@
+// repository/activity.go
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"todo-list/backend/internal/models"
+)
+
+// ActivityRepository интерфейс для чтения журнала изменений задач.
+// Записи добавляют сами методы TodoRepository и TagRepository.
+type ActivityRepository interface {
+	List(todoID *uint, beforeID uint, limit int) ([]models.Activity, error)
+	LatestID() (uint, error)
+	ChangedSince(afterID uint) ([]uint, error)
+}
+
+// activityRepo реализация ActivityRepository
+type activityRepo struct {
+	db *sql.DB
+}
+
+// execer общий интерфейс *sql.DB и *sql.Tx
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+}
+
+// List возвращает записи журнала, новые первыми: задачи todoID или всех задач, если todoID == nil.
+// beforeID > 0 продолжает выдачу с записей старше beforeID.
+func (r *activityRepo) List(todoID *uint, beforeID uint, limit int) ([]models.Activity, error) {
+	query := `
+		SELECT id, todo_id, title, action, changes, source, actor, created_at
+		FROM todo_activity
+		WHERE ($1::INTEGER IS NULL OR todo_id = $1) AND ($2 = 0 OR id < $2)
+		ORDER BY id DESC LIMIT $3`
+
+	rows, err := r.db.Query(query, todoID, beforeID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	activity := []models.Activity{}
+	for rows.Next() {
+		var entry models.Activity
+		var changes []byte
+		err := rows.Scan(&entry.ID, &entry.TodoID, &entry.Title, &entry.Action,
+			&changes, &entry.Source, &entry.Actor, &entry.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+			return nil, err
+		}
+		activity = append(activity, entry)
+	}
+	return activity, rows.Err()
+}
+
+// LatestID возвращает номер последней записи журнала; 0 - журнал пуст
+func (r *activityRepo) LatestID() (uint, error) {
+	var id uint
+	err := r.db.QueryRow(`SELECT COALESCE(MAX(id), 0) FROM todo_activity`).Scan(&id)
+	return id, err
+}
+
+// ChangedSince возвращает ID задач, у которых есть записи журнала новее afterID
+func (r *activityRepo) ChangedSince(afterID uint) ([]uint, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT todo_id FROM todo_activity WHERE id > $1 ORDER BY todo_id`, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// recorder пишет журнал изменений от имени источника (приложение, REST API или CalDAV)
+// и пользователя
+type recorder struct {
+	source string
+	actor  string
+}
+
+// record добавляет запись журнала о задаче
+func (rec recorder) record(db execer, todoID uint, title, action string, changes []models.FieldChange, at time.Time) error {
+	if changes == nil {
+		changes = []models.FieldChange{}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.Exec(`
+		INSERT INTO todo_activity (todo_id, title, action, changes, source, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`, todoID, title, action, data, rec.source, rec.actor, at)
+	return err
+}
+
+// recordRows добавляет одинаковую запись журнала для каждой строки (id, title) из rows
+func (rec recorder) recordRows(tx *sql.Tx, rows *sql.Rows, action string, changes []models.FieldChange, at time.Time) (int64, error) {
+	type touched struct {
+		id    uint
+		title string
+	}
+
+	var todos []touched
+	for rows.Next() {
+		var t touched
+		if err := rows.Scan(&t.id, &t.title); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		todos = append(todos, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, t := range todos {
+		if err := rec.record(tx, t.id, t.title, action, changes, at); err != nil {
+			return 0, err
+		}
+	}
+	return int64(len(todos)), nil
+}
@@ -0,0 +1,59 @@
+// repository/caldav.go
+package repository
+
+import (
+	"database/sql"
+
+	"todo-list/backend/internal/models"
+)
+
+// CalDAVRepository интерфейс для работы с именами и UID задач клиентов CalDAV
+type CalDAVRepository interface {
+	GetAll() ([]models.CalDAVObject, error)
+	Save(object models.CalDAVObject) error
+}
+
+// caldavRepo реализация CalDAVRepository
+type caldavRepo struct {
+	db *sql.DB
+}
+
+func (r *caldavRepo) GetAll() ([]models.CalDAVObject, error) {
+	rows, err := r.db.Query(`SELECT todo_id, name, uid FROM caldav_objects ORDER BY todo_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	objects := []models.CalDAVObject{}
+	for rows.Next() {
+		var object models.CalDAVObject
+		if err := rows.Scan(&object.TodoID, &object.Name, &object.UID); err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, rows.Err()
+}
+
+// Save запоминает имя и UID задачи. Прежние записи с тем же именем или UID удаляются:
+// они остались от задач, которые клиент удалил и создал заново.
+func (r *caldavRepo) Save(object models.CalDAVObject) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM caldav_objects WHERE todo_id = $1 OR name = $2 OR uid = $3`,
+		object.TodoID, object.Name, object.UID)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`INSERT INTO caldav_objects (todo_id, name, uid) VALUES ($1, $2, $3)`,
+		object.TodoID, object.Name, object.UID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
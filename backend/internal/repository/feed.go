@@ -0,0 +1,86 @@
+// repository/feed.go
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"todo-list/backend/internal/models"
+)
+
+// FeedRepository интерфейс для работы с подписками iCalendar
+type FeedRepository interface {
+	Create(feed *models.Feed) error
+	GetByID(id uint) (*models.Feed, error)
+	GetByToken(token string) (*models.Feed, error)
+	GetAll() ([]models.Feed, error)
+	Delete(id uint) error
+}
+
+// feedRepo реализация FeedRepository
+type feedRepo struct {
+	db *sql.DB
+}
+
+const feedColumns = `id, name, token, filter, events, created_at`
+
+func (r *feedRepo) Create(feed *models.Feed) error {
+	filter, err := json.Marshal(feed.Filter)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO feeds (name, token, filter, events, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	feed.CreatedAt = time.Now()
+	return r.db.QueryRow(query, feed.Name, feed.Token, filter, feed.Events, feed.CreatedAt).Scan(&feed.ID)
+}
+
+func (r *feedRepo) GetByID(id uint) (*models.Feed, error) {
+	return scanFeed(r.db.QueryRow(`SELECT `+feedColumns+` FROM feeds WHERE id = $1`, id))
+}
+
+func (r *feedRepo) GetByToken(token string) (*models.Feed, error) {
+	return scanFeed(r.db.QueryRow(`SELECT `+feedColumns+` FROM feeds WHERE token = $1`, token))
+}
+
+func (r *feedRepo) GetAll() ([]models.Feed, error) {
+	rows, err := r.db.Query(`SELECT ` + feedColumns + ` FROM feeds ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feeds := []models.Feed{}
+	for rows.Next() {
+		feed, err := scanFeed(rows)
+		if err != nil {
+			return nil, err
+		}
+		feeds = append(feeds, *feed)
+	}
+	return feeds, rows.Err()
+}
+
+func (r *feedRepo) Delete(id uint) error {
+	_, err := r.db.Exec(`DELETE FROM feeds WHERE id = $1`, id)
+	return err
+}
+
+// scanFeed читает строку с колонками feedColumns
+func scanFeed(row rowScanner) (*models.Feed, error) {
+	var feed models.Feed
+	var filter []byte
+	err := row.Scan(&feed.ID, &feed.Name, &feed.Token, &filter, &feed.Events, &feed.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(filter, &feed.Filter); err != nil {
+		return nil, err
+	}
+	return &feed, nil
+}
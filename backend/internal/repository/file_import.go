@@ -0,0 +1,172 @@
+// repository/file_import.go
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"todo-list/backend/internal/models"
+)
+
+// FileImportRepository интерфейс переноса задач из JSON-файла приложения
+type FileImportRepository interface {
+	Import(tasks []models.FileTask, tags []models.Tag, dryRun bool) ([]models.FileImportItem, int, error)
+}
+
+// fileImportRepo реализация FileImportRepository
+type fileImportRepo struct {
+	db *sql.DB
+}
+
+// Import переносит задачи одной транзакцией и возвращает результат по каждой задаче
+// и число созданных меток. tags должны включать все метки задач, родители должны идти
+// раньше подзадач. Задачи, ключи которых уже есть в file_imports, пропускаются. Время
+// создания, изменения и удаления, а также журнал изменений задач сохраняются как есть.
+// dryRun выполняет перенос и откатывает его.
+func (r *fileImportRepo) Import(tasks []models.FileTask, tags []models.Tag, dryRun bool) ([]models.FileImportItem, int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer tx.Rollback()
+
+	// Одновременный перенос того же файла ждет окончания первого и пропускает его задачи
+	if _, err := tx.Exec(`LOCK TABLE file_imports IN EXCLUSIVE MODE`); err != nil {
+		return nil, 0, err
+	}
+	imported, todos, err := importedTasks(tx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	tagsCreated := 0
+	for _, tag := range tags {
+		created, err := ensureTag(tx, tag.Name, tag.Color)
+		if err != nil {
+			return nil, 0, err
+		}
+		if created {
+			tagsCreated++
+		}
+	}
+
+	items := make([]models.FileImportItem, 0, len(tasks))
+	for _, task := range tasks {
+		item := models.FileImportItem{Key: task.Key, Title: task.Todo.Title}
+		if id, ok := imported[task.Key]; ok {
+			item.Action, item.TodoID = "skip", id
+			items = append(items, item)
+			continue
+		}
+
+		todo := task.Todo
+		todo.ParentID = nil
+		if parentID, ok := todos[task.ParentKey]; ok {
+			todo.ParentID = &parentID
+		}
+		err := tx.QueryRow(`
+			INSERT INTO todos (title, description, completed, priority, due_date, parent_id, recurrence, reminders, created_at, updated_at, deleted_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			RETURNING id`, todo.Title, todo.Description, todo.Completed, todo.Priority, todo.DueDate,
+			todo.ParentID, todo.Recurrence, reminderArray(todo.Reminders), todo.CreatedAt, todo.UpdatedAt,
+			todo.DeletedAt).Scan(&todo.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		for _, name := range todo.Tags {
+			_, err = tx.Exec(`
+				INSERT INTO todo_tags (todo_id, tag_id)
+				SELECT $1, id FROM tags WHERE LOWER(name) = LOWER($2)
+				ON CONFLICT DO NOTHING`, todo.ID, name)
+			if err != nil {
+				return nil, 0, err
+			}
+		}
+
+		for _, entry := range task.Activity {
+			if err := insertActivity(tx, todo.ID, entry); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		_, err = tx.Exec(`INSERT INTO file_imports (task_key, todo_id) VALUES ($1, $2)`, task.Key, todo.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		imported[task.Key], todos[task.Key] = todo.ID, todo.ID
+		item.Action, item.TodoID = "create", todo.ID
+		items = append(items, item)
+	}
+
+	if dryRun {
+		return items, tagsCreated, nil
+	}
+	return items, tagsCreated, tx.Commit()
+}
+
+// importedTasks возвращает все ранее перенесенные задачи и те из них, что еще есть в базе:
+// только к ним можно привязать подзадачи
+func importedTasks(tx *sql.Tx) (imported, existing map[string]uint, err error) {
+	rows, err := tx.Query(`
+		SELECT f.task_key, f.todo_id, t.id IS NOT NULL
+		FROM file_imports f LEFT JOIN todos t ON t.id = f.todo_id`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	imported, existing = map[string]uint{}, map[string]uint{}
+	for rows.Next() {
+		var key string
+		var id uint
+		var exists bool
+		if err := rows.Scan(&key, &id, &exists); err != nil {
+			return nil, nil, err
+		}
+		imported[key] = id
+		if exists {
+			existing[key] = id
+		}
+	}
+	return imported, existing, rows.Err()
+}
+
+// ensureTag создает метку, если метки с таким именем (без учета регистра) еще нет;
+// у существующей метки цвет не меняется
+func ensureTag(tx *sql.Tx, name, color string) (bool, error) {
+	var id uint
+	err := tx.QueryRow(`SELECT id FROM tags WHERE LOWER(name) = LOWER($1)`, name).Scan(&id)
+	if err == nil {
+		return false, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO tags (name, color, created_at, updated_at)
+		VALUES ($1, $2, NOW(), NOW())`, name, color)
+	if err != nil {
+		return false, tagError(err)
+	}
+	return true, nil
+}
+
+// insertActivity добавляет запись журнала с ее исходными временем, источником и автором
+func insertActivity(tx *sql.Tx, todoID uint, entry models.Activity) error {
+	changes := entry.Changes
+	if changes == nil {
+		changes = []models.FieldChange{}
+	}
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO todo_activity (todo_id, title, action, changes, source, actor, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)`, todoID, entry.Title, entry.Action, data, entry.Source, entry.Actor, entry.CreatedAt)
+	return err
+}
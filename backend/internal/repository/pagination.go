@@ -0,0 +1,222 @@
+// repository/pagination.go
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-list/backend/internal/models"
+)
+
+// ErrInvalidCursor возвращается, если курсор поврежден или выдан для другой сортировки
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// pageCursor содержимое непрозрачного курсора: ключ сортировки и ID граничной строки
+type pageCursor struct {
+	Field string `json:"f"`
+	Desc  bool   `json:"d,omitempty"`
+	Key   string `json:"k,omitempty"`
+	Null  bool   `json:"n,omitempty"`
+	ID    uint   `json:"i"`
+	Back  bool   `json:"b,omitempty"`
+}
+
+// encodeCursor кодирует курсор в строку base64url
+func encodeCursor(c pageCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor разбирает курсор, выданный encodeCursor
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+	if err := json.Unmarshal(data, &c); err != nil {
+		return c, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// cursorFor строит курсор, указывающий на задачу todo в текущей сортировке
+func (q *todoQuery) cursorFor(todo models.Todo, back bool) string {
+	c := pageCursor{Field: q.sortField, Desc: q.sortDesc, ID: todo.ID, Back: back}
+
+	switch q.sortField {
+	case "id":
+	case "title":
+		c.Key = strings.ToLower(todo.Title)
+	case "priority":
+		c.Key = strconv.Itoa(priorityValue(todo.Priority))
+	case "due_date":
+		if todo.DueDate == nil {
+			c.Null = true
+		} else {
+			c.Key = todo.DueDate.Format(time.RFC3339Nano)
+		}
+	case "created_at":
+		c.Key = todo.CreatedAt.Format(time.RFC3339Nano)
+	case "updated_at":
+		c.Key = todo.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	return encodeCursor(c)
+}
+
+// applyCursor добавляет keyset-условие "после курсора" (или "до" для обратного прохода)
+func (q *todoQuery) applyCursor(c pageCursor) error {
+	if c.Field != q.sortField || c.Desc != q.sortDesc {
+		return ErrInvalidCursor
+	}
+	q.back = c.Back
+
+	// Сравнение в направлении прохода: вперед по ASC или назад по DESC - "больше"
+	cmp := ">"
+	if q.sortDesc != q.back {
+		cmp = "<"
+	}
+
+	if q.sortField == "id" {
+		q.where = append(q.where, fmt.Sprintf("id %s %s", cmp, q.arg(c.ID)))
+		return nil
+	}
+
+	column := todoSortColumns[q.sortField]
+
+	// NULL бывают только у due_date; в прямом порядке они идут последними
+	if c.Null {
+		if q.sortField != "due_date" {
+			return ErrInvalidCursor
+		}
+		cond := fmt.Sprintf("(%s IS NULL AND id %s %s)", column, cmp, q.arg(c.ID))
+		if q.back {
+			cond = fmt.Sprintf("(%s IS NOT NULL OR %s)", column, cond)
+		}
+		q.where = append(q.where, cond)
+		return nil
+	}
+
+	key, err := cursorKey(q.sortField, c.Key)
+	if err != nil {
+		return err
+	}
+
+	keyArg := q.arg(key)
+	cond := fmt.Sprintf("%s %s %s OR (%s = %s AND id %s %s)",
+		column, cmp, keyArg, column, keyArg, cmp, q.arg(c.ID))
+	if q.sortField == "due_date" && !q.back {
+		cond += fmt.Sprintf(" OR %s IS NULL", column)
+	}
+	q.where = append(q.where, "("+cond+")")
+	return nil
+}
+
+// cursorKey преобразует строковый ключ курсора в значение параметра нужного типа
+func cursorKey(field, key string) (interface{}, error) {
+	switch field {
+	case "title":
+		return key, nil
+	case "priority":
+		rank, err := strconv.Atoi(key)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return rank, nil
+	case "due_date", "created_at", "updated_at":
+		t, err := time.Parse(time.RFC3339Nano, key)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		return t, nil
+	default:
+		return nil, ErrInvalidCursor
+	}
+}
+
+// priorityValue повторяет priorityRank для значения в Go
+func priorityValue(priority string) int {
+	switch models.Priority(priority) {
+	case models.High:
+		return 3
+	case models.Medium:
+		return 2
+	case models.Low:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ListPage возвращает одну страницу задач с keyset-пагинацией.
+// Курсор содержит ключ сортировки и ID граничной задачи, поэтому страницы
+// не сдвигаются при вставке новых задач.
+func (r *todoRepo) ListPage(filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error) {
+	q := &todoQuery{}
+	q.applyFilter(filter)
+	if err := q.applySort(sort); err != nil {
+		return nil, err
+	}
+	if page.Cursor != "" {
+		c, err := decodeCursor(page.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		if err := q.applyCursor(c); err != nil {
+			return nil, err
+		}
+	}
+	q.limit = page.Limit + 1
+
+	rows, err := r.db.Query(q.sql(), q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	todos := make([]models.Todo, 0, page.Limit)
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, todo)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	hasMore := len(todos) > page.Limit
+	if hasMore {
+		todos = todos[:page.Limit]
+	}
+	if q.back {
+		for i, j := 0, len(todos)-1; i < j; i, j = i+1, j-1 {
+			todos[i], todos[j] = todos[j], todos[i]
+		}
+	}
+
+	result := &models.TaskPage{Items: todos, Limit: page.Limit}
+	if len(todos) == 0 {
+		return result, nil
+	}
+
+	// Вперед: следующая страница есть, если строк больше лимита; предыдущая - если пришли по курсору.
+	// Назад: наоборот.
+	hasNext, hasPrev := hasMore, page.Cursor != ""
+	if q.back {
+		hasNext, hasPrev = true, hasMore
+	}
+	if hasNext {
+		result.NextCursor = q.cursorFor(todos[len(todos)-1], false)
+	}
+	if hasPrev {
+		result.PrevCursor = q.cursorFor(todos[0], true)
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"todo-list/backend/internal/models"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	c := pageCursor{Field: "due_date", Desc: true, Key: "2026-03-01T10:00:00Z", ID: 42, Back: true}
+	got, err := decodeCursor(encodeCursor(c))
+	if err != nil || got != c {
+		t.Errorf("decodeCursor = %+v, %v", got, err)
+	}
+	for _, s := range []string{"!!!", "bm90IGpzb24", encodeCursor(c)[:5]} {
+		if _, err := decodeCursor(s); err != ErrInvalidCursor {
+			t.Errorf("decodeCursor(%q) = %v", s, err)
+		}
+	}
+}
+
+func TestCursorFor(t *testing.T) {
+	due := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	todo := models.Todo{ID: 5, Title: "Купить Хлеб", Priority: string(models.High), DueDate: &due}
+	tests := []struct {
+		field string
+		key   string
+		null  bool
+	}{
+		{"id", "", false},
+		{"title", "купить хлеб", false},
+		{"priority", "3", false},
+		{"due_date", "2026-03-01T10:00:00Z", false},
+	}
+	for _, tt := range tests {
+		q := todoQuery{sortField: tt.field, sortDesc: true}
+		c, err := decodeCursor(q.cursorFor(todo, true))
+		want := pageCursor{Field: tt.field, Desc: true, Key: tt.key, Null: tt.null, ID: 5, Back: true}
+		if err != nil || c != want {
+			t.Errorf("cursorFor by %s = %+v, %v", tt.field, c, err)
+		}
+	}
+
+	q := todoQuery{sortField: "due_date"}
+	if c, _ := decodeCursor(q.cursorFor(models.Todo{ID: 6}, false)); !c.Null || c.Key != "" {
+		t.Errorf("cursor of a task without due date = %+v", c)
+	}
+}
+
+func TestApplyCursor(t *testing.T) {
+	due := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	key := due.Format(time.RFC3339Nano)
+	tests := []struct {
+		name   string
+		sort   string
+		desc   bool
+		cursor pageCursor
+		where  string
+		args   []interface{}
+	}{
+		{"id forward", "id", false, pageCursor{Field: "id", ID: 7},
+			"id > $1", []interface{}{uint(7)}},
+		{"id desc back", "id", true, pageCursor{Field: "id", Desc: true, ID: 7, Back: true},
+			"id > $1", []interface{}{uint(7)}},
+		{"priority desc", "priority", true, pageCursor{Field: "priority", Desc: true, Key: "2", ID: 7},
+			"(" + priorityRank + " < $1 OR (" + priorityRank + " = $1 AND id < $2))", []interface{}{2, uint(7)}},
+		// Задачи без срока идут после всех остальных, поэтому попадают на следующие страницы
+		{"due date forward", "due_date", false, pageCursor{Field: "due_date", Key: key, ID: 7},
+			"(due_date > $1 OR (due_date = $1 AND id > $2) OR due_date IS NULL)", []interface{}{due, uint(7)}},
+		{"due date back", "due_date", false, pageCursor{Field: "due_date", Key: key, ID: 7, Back: true},
+			"(due_date < $1 OR (due_date = $1 AND id < $2))", []interface{}{due, uint(7)}},
+		{"null due date forward", "due_date", false, pageCursor{Field: "due_date", Null: true, ID: 7},
+			"(due_date IS NULL AND id > $1)", []interface{}{uint(7)}},
+		{"null due date back", "due_date", false, pageCursor{Field: "due_date", Null: true, ID: 7, Back: true},
+			"(due_date IS NOT NULL OR (due_date IS NULL AND id < $1))", []interface{}{uint(7)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := todoQuery{sortField: tt.sort, sortDesc: tt.desc}
+			if err := q.applyCursor(tt.cursor); err != nil {
+				t.Fatalf("applyCursor: %v", err)
+			}
+			if len(q.where) != 1 || q.where[0] != tt.where {
+				t.Errorf("where = %q, want %q", q.where, tt.where)
+			}
+			if !reflect.DeepEqual(q.args, tt.args) {
+				t.Errorf("args = %v, want %v", q.args, tt.args)
+			}
+			if q.back != tt.cursor.Back {
+				t.Errorf("back = %v", q.back)
+			}
+		})
+	}
+}
+
+func TestApplyCursorRejects(t *testing.T) {
+	tests := map[string]pageCursor{
+		"other field":        {Field: "title", Key: "a", ID: 1},
+		"other direction":    {Field: "priority", Desc: true, Key: "1", ID: 1},
+		"bad key":            {Field: "priority", Key: "high", ID: 1},
+		"null without dates": {Field: "priority", Null: true, ID: 1},
+	}
+	for name, c := range tests {
+		q := todoQuery{sortField: "priority"}
+		if err := q.applyCursor(c); err != ErrInvalidCursor {
+			t.Errorf("%s: applyCursor = %v", name, err)
+		}
+	}
+	q := todoQuery{sortField: "created_at"}
+	if err := q.applyCursor(pageCursor{Field: "created_at", Key: "yesterday"}); err != ErrInvalidCursor {
+		t.Errorf("bad time key: %v", err)
+	}
+}
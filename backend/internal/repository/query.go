@@ -0,0 +1,172 @@
+// repository/query.go
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"todo-list/backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// todoColumns список колонок задачи в порядке, который ожидает scanTodo.
+// Метки собираются подзапросом в массив имен.
+const todoColumns = `id, title, description, completed, priority, due_date,
+		       category_id, parent_id, recurrence, reminders, created_at, updated_at, deleted_at,
+		       ARRAY(SELECT tg.name FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id
+		             WHERE tt.todo_id = todos.id ORDER BY LOWER(tg.name)) AS tags`
+
+// priorityRank переводит приоритет в число, чтобы сортировка шла low < medium < high
+const priorityRank = `CASE priority WHEN 'high' THEN 3 WHEN 'medium' THEN 2 WHEN 'low' THEN 1 ELSE 0 END`
+
+// todoSortColumns белый список полей сортировки и соответствующих SQL-выражений
+var todoSortColumns = map[string]string{
+	"id":         "id",
+	"title":      "LOWER(title)",
+	"priority":   priorityRank,
+	"due_date":   "due_date",
+	"created_at": "created_at",
+	"updated_at": "updated_at",
+}
+
+// IsValidSortField сообщает, можно ли сортировать задачи по полю
+func IsValidSortField(field string) bool {
+	_, ok := todoSortColumns[field]
+	return ok
+}
+
+// notDeleted условие, скрывающее задачи из корзины
+const notDeleted = "deleted_at IS NULL"
+
+// todoQuery собирает параметризованный SELECT по таблице todos.
+// Задачи из корзины в выборку не попадают.
+type todoQuery struct {
+	where     []string
+	args      []interface{}
+	sortField string
+	sortDesc  bool
+	back      bool // выборка в обратном направлении (предыдущая страница)
+	limit     int
+}
+
+// arg добавляет значение параметра и возвращает его плейсхолдер ($1, $2, ...)
+func (q *todoQuery) arg(value interface{}) string {
+	q.args = append(q.args, value)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+// applyFilter добавляет условия TaskFilter в WHERE
+func (q *todoQuery) applyFilter(filter *models.TaskFilter) {
+	if filter == nil {
+		return
+	}
+	if filter.IsCompleted != nil {
+		q.where = append(q.where, "completed = "+q.arg(*filter.IsCompleted))
+	}
+	if filter.Priority != nil {
+		q.where = append(q.where, "priority = "+q.arg(string(*filter.Priority)))
+	}
+	if filter.DateFrom != nil {
+		q.where = append(q.where, "due_date >= "+q.arg(*filter.DateFrom))
+	}
+	if filter.DateTo != nil {
+		// DateTo включает весь указанный день
+		q.where = append(q.where, "due_date < "+q.arg(filter.DateTo.AddDate(0, 0, 1)))
+	}
+	if filter.CategoryID != nil {
+		q.where = append(q.where, "category_id = "+q.arg(*filter.CategoryID))
+	}
+	if filter.ParentID != nil {
+		if *filter.ParentID == 0 {
+			q.where = append(q.where, "parent_id IS NULL")
+		} else {
+			q.where = append(q.where, "parent_id = "+q.arg(*filter.ParentID))
+		}
+	}
+	if len(filter.Tags) > 0 {
+		q.applyTags(filter.Tags, filter.TagMode)
+	}
+}
+
+// applyTags отбирает задачи хотя бы с одной из меток (any) или со всеми метками (all).
+// Имена меток сравниваются без учета регистра.
+func (q *todoQuery) applyTags(tags []string, mode models.TagMode) {
+	names := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		name := strings.ToLower(tag)
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	matched := `FROM todo_tags tt JOIN tags tg ON tg.id = tt.tag_id
+		WHERE tt.todo_id = todos.id AND LOWER(tg.name) = ANY(` + q.arg(pq.Array(names)) + `)`
+	if mode == models.TagModeAll {
+		q.where = append(q.where, fmt.Sprintf("(SELECT COUNT(*) %s) = %d", matched, len(names)))
+	} else {
+		q.where = append(q.where, "EXISTS (SELECT 1 "+matched+")")
+	}
+}
+
+// applySort задает сортировку по TaskSort; по умолчанию новые задачи идут первыми
+func (q *todoQuery) applySort(sort *models.TaskSort) error {
+	if sort == nil || sort.Field == "" {
+		q.sortField, q.sortDesc = "created_at", true
+		return nil
+	}
+
+	if _, ok := todoSortColumns[sort.Field]; !ok {
+		return fmt.Errorf("unsupported sort field %q", sort.Field)
+	}
+
+	switch strings.ToLower(sort.Order) {
+	case "", "asc":
+		q.sortDesc = false
+	case "desc":
+		q.sortDesc = true
+	default:
+		return fmt.Errorf("unsupported sort order %q", sort.Order)
+	}
+
+	q.sortField = sort.Field
+	return nil
+}
+
+// orderBy возвращает ORDER BY для текущей сортировки.
+// При обратном проходе (back) направление и положение NULL инвертируются.
+func (q *todoQuery) orderBy() string {
+	if q.sortField == "" {
+		return ""
+	}
+
+	desc := q.sortDesc != q.back
+	direction, nulls := "ASC", "LAST"
+	if desc {
+		direction = "DESC"
+	}
+	if q.back {
+		nulls = "FIRST"
+	}
+
+	if q.sortField == "id" {
+		return "id " + direction
+	}
+	return fmt.Sprintf("%s %s NULLS %s, id %s", todoSortColumns[q.sortField], direction, nulls, direction)
+}
+
+// sql возвращает итоговый запрос
+func (q *todoQuery) sql() string {
+	var b strings.Builder
+	b.WriteString("SELECT " + todoColumns + " FROM todos")
+	b.WriteString(" WHERE " + strings.Join(append([]string{notDeleted}, q.where...), " AND "))
+	if order := q.orderBy(); order != "" {
+		b.WriteString(" ORDER BY " + order)
+	}
+	if q.limit > 0 {
+		b.WriteString(fmt.Sprintf(" LIMIT %d", q.limit))
+	}
+	return b.String()
+}
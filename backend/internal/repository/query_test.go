@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-list/backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+func TestApplyFilter(t *testing.T) {
+	completed := true
+	priority := models.High
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC)
+	category, parent := uint(7), uint(0)
+
+	var q todoQuery
+	q.applyFilter(&models.TaskFilter{
+		IsCompleted: &completed,
+		Priority:    &priority,
+		DateFrom:    &from,
+		DateTo:      &to,
+		CategoryID:  &category,
+		ParentID:    &parent,
+	})
+
+	wantWhere := []string{"completed = $1", "priority = $2", "due_date >= $3", "due_date < $4", "category_id = $5", "parent_id IS NULL"}
+	if !reflect.DeepEqual(q.where, wantWhere) {
+		t.Errorf("where = %q", q.where)
+	}
+	// DateTo включает весь день: граница - начало следующего дня
+	wantArgs := []interface{}{true, "high", from, to.AddDate(0, 0, 1), uint(7)}
+	if !reflect.DeepEqual(q.args, wantArgs) {
+		t.Errorf("args = %v", q.args)
+	}
+
+	var empty todoQuery
+	empty.applyFilter(nil)
+	empty.applyFilter(&models.TaskFilter{})
+	if len(empty.where) != 0 || len(empty.args) != 0 {
+		t.Errorf("empty filter added %q", empty.where)
+	}
+}
+
+func TestApplyTags(t *testing.T) {
+	var anyTags, allTags todoQuery
+	anyTags.applyFilter(&models.TaskFilter{Tags: []string{"Work", "home", "work"}})
+	allTags.applyFilter(&models.TaskFilter{Tags: []string{"Work", "home", "work"}, TagMode: models.TagModeAll})
+
+	// Метки сравниваются без учета регистра, повторы отбрасываются
+	want := []interface{}{pq.Array([]string{"work", "home"})}
+	if !reflect.DeepEqual(anyTags.args, want) || !reflect.DeepEqual(allTags.args, want) {
+		t.Errorf("args = %v, %v", anyTags.args, allTags.args)
+	}
+	if len(anyTags.where) != 1 || !strings.HasPrefix(anyTags.where[0], "EXISTS (SELECT 1 ") {
+		t.Errorf("any: %q", anyTags.where)
+	}
+	if len(allTags.where) != 1 || !strings.HasSuffix(allTags.where[0], ") = 2") {
+		t.Errorf("all: %q", allTags.where)
+	}
+}
+
+func TestApplySort(t *testing.T) {
+	tests := []struct {
+		sort  *models.TaskSort
+		back  bool
+		order string
+	}{
+		{nil, false, "created_at DESC NULLS LAST, id DESC"},
+		{&models.TaskSort{}, true, "created_at ASC NULLS FIRST, id ASC"},
+		{&models.TaskSort{Field: "priority", Order: "DESC"}, false, priorityRank + " DESC NULLS LAST, id DESC"},
+		{&models.TaskSort{Field: "due_date"}, false, "due_date ASC NULLS LAST, id ASC"},
+		// Предыдущая страница читается в обратном порядке, NULL оказываются в начале
+		{&models.TaskSort{Field: "due_date", Order: "asc"}, true, "due_date DESC NULLS FIRST, id DESC"},
+		{&models.TaskSort{Field: "id", Order: "desc"}, false, "id DESC"},
+		{&models.TaskSort{Field: "title"}, false, "LOWER(title) ASC NULLS LAST, id ASC"},
+	}
+	for _, tt := range tests {
+		q := todoQuery{back: tt.back}
+		if err := q.applySort(tt.sort); err != nil {
+			t.Errorf("applySort(%+v): %v", tt.sort, err)
+			continue
+		}
+		if got := q.orderBy(); got != tt.order {
+			t.Errorf("applySort(%+v), back %v: ORDER BY %q, want %q", tt.sort, tt.back, got, tt.order)
+		}
+	}
+
+	for _, sort := range []*models.TaskSort{{Field: "description"}, {Field: "id; DROP TABLE todos"}, {Field: "id", Order: "up"}} {
+		var q todoQuery
+		if err := q.applySort(sort); err == nil {
+			t.Errorf("applySort(%+v) accepted an invalid sort", sort)
+		}
+	}
+	if !IsValidSortField("due_date") || IsValidSortField("deleted_at") {
+		t.Error("IsValidSortField")
+	}
+}
+
+func TestTodoQuerySQL(t *testing.T) {
+	completed := false
+	q := todoQuery{limit: 21}
+	q.applyFilter(&models.TaskFilter{IsCompleted: &completed})
+	if err := q.applySort(&models.TaskSort{Field: "id"}); err != nil {
+		t.Fatal(err)
+	}
+
+	sql := q.sql()
+	// Задачи из корзины не попадают ни в одну выборку
+	if !strings.Contains(sql, " FROM todos WHERE deleted_at IS NULL AND completed = $1 ORDER BY id ASC LIMIT 21") {
+		t.Errorf("sql = %s", sql)
+	}
+}
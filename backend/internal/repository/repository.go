@@ -3,8 +3,12 @@ package repository
 
 import (
 	"database/sql"
+	"errors"
 	"time"
+	"todo-list/backend/internal/audit"
 	"todo-list/backend/internal/models"
+
+	"github.com/lib/pq"
 )
 
 // NewTaskRepository создает новый репозиторий задач (для совместимости с start.go)
@@ -18,8 +22,21 @@ type TodoRepository interface {
 	GetByID(id uint) (*models.Todo, error)
 	GetAll() ([]models.Todo, error)
 	Update(todo *models.Todo) error
+	Modify(id uint, fn func(todo *models.Todo) error) error
+	Upsert(todo *models.Todo) error
 	Delete(id uint) error
+	GetDeleted() ([]models.Todo, error)
+	Restore(id uint) error
+	Remove(id uint) error
+	Purge(before time.Time) (int64, error)
 	GetByStatus(completed bool) ([]models.Todo, error)
+	List(filter *models.TaskFilter, sort *models.TaskSort) ([]models.Todo, error)
+	ListPage(filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error)
+	Search(text string, filter *models.TaskFilter, limit int) ([]models.SearchResult, error)
+	GetSubtree(id uint) ([]models.Todo, error)
+	SetSubtreeCompleted(id uint, completed bool) error
+	ReopenAncestors(id uint) error
+	Move(id uint, parentID *uint) error
 }
 
 // CategoryRepository интерфейс для работы с категориями
@@ -29,17 +46,26 @@ type CategoryRepository interface {
 	GetAll() ([]models.Category, error)
 	Update(category *models.Category) error
 	Delete(id uint) error
+	GetDeleted() ([]models.Category, error)
+	Restore(id uint) error
+	Purge(before time.Time) (int64, error)
 }
 
 // Repository объединяет все репозитории
 type Repository struct {
-	Todo     TodoRepository
-	Category CategoryRepository
+	Todo       TodoRepository
+	Category   CategoryRepository
+	Tag        TagRepository
+	Activity   ActivityRepository
+	Feed       FeedRepository
+	CalDAV     CalDAVRepository
+	FileImport FileImportRepository
 }
 
 // todoRepo реализация TodoRepository
 type todoRepo struct {
 	db *sql.DB
+	recorder
 }
 
 // categoryRepo реализация CategoryRepository
@@ -47,11 +73,24 @@ type categoryRepo struct {
 	db *sql.DB
 }
 
-// NewRepository создает новый экземпляр Repository
+// NewRepository создает новый экземпляр Repository; изменения задач попадают
+// в журнал с источником models.SourceApp
 func NewRepository(db *sql.DB) *Repository {
+	return NewSourceRepository(db, models.SourceApp, "")
+}
+
+// NewSourceRepository создает Repository, записывающий изменения задач в журнал
+// с источником source от имени actor (пользователь ОС или API)
+func NewSourceRepository(db *sql.DB, source, actor string) *Repository {
+	rec := recorder{source: source, actor: actor}
 	return &Repository{
-		Todo:     &todoRepo{db: db},
-		Category: &categoryRepo{db: db},
+		Todo:       &todoRepo{db: db, recorder: rec},
+		Category:   &categoryRepo{db: db},
+		Tag:        &tagRepo{db: db, recorder: rec},
+		Activity:   &activityRepo{db: db},
+		Feed:       &feedRepo{db: db},
+		CalDAV:     &caldavRepo{db: db},
+		FileImport: &fileImportRepo{db: db},
 	}
 }
 
@@ -59,91 +98,238 @@ func NewRepository(db *sql.DB) *Repository {
 
 func (r *todoRepo) Create(todo *models.Todo) error {
 	query := `
-		INSERT INTO todos (title, description, completed, priority, due_date, category_id, created_at, updated_at) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
+		INSERT INTO todos (title, description, completed, priority, due_date, category_id, parent_id, recurrence, reminders, created_at, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) 
 		RETURNING id`
 
 	now := time.Now()
 	todo.CreatedAt = now
 	todo.UpdatedAt = now
 
-	return r.db.QueryRow(query, todo.Title, todo.Description, todo.Completed,
-		todo.Priority, todo.DueDate, todo.CategoryID,
-		todo.CreatedAt, todo.UpdatedAt).Scan(&todo.ID)
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(query, todo.Title, todo.Description, todo.Completed,
+		todo.Priority, todo.DueDate, todo.CategoryID, todo.ParentID, todo.Recurrence,
+		reminderArray(todo.Reminders), todo.CreatedAt, todo.UpdatedAt).Scan(&todo.ID)
+	if err != nil {
+		return err
+	}
+
+	// Метки задаются отдельно через SetTodoTags и попадают в журнал отдельной записью
+	created := *todo
+	created.Tags = nil
+	if err := r.record(tx, todo.ID, todo.Title, models.ActivityCreate, audit.Diff(nil, &created), now); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
 func (r *todoRepo) GetByID(id uint) (*models.Todo, error) {
-	todo := &models.Todo{}
-	query := `
-		SELECT id, title, description, completed, priority, due_date, 
-		       category_id, created_at, updated_at 
-		FROM todos WHERE id = $1`
-
-	err := r.db.QueryRow(query, id).Scan(
-		&todo.ID, &todo.Title, &todo.Description, &todo.Completed,
-		&todo.Priority, &todo.DueDate, &todo.CategoryID,
-		&todo.CreatedAt, &todo.UpdatedAt)
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE id = $1 AND deleted_at IS NULL`
 
+	todo, err := scanTodo(r.db.QueryRow(query, id))
 	if err != nil {
 		return nil, err
 	}
-	return todo, nil
+	return &todo, nil
 }
 
 func (r *todoRepo) GetAll() ([]models.Todo, error) {
-	query := `
-		SELECT id, title, description, completed, priority, due_date, 
-		       category_id, created_at, updated_at 
-		FROM todos ORDER BY created_at DESC`
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE deleted_at IS NULL ORDER BY created_at DESC`
+	return r.queryTodos(query)
+}
 
-	rows, err := r.db.Query(query)
+// updateTodoQuery сохраняет поля задачи, кроме меток и времени создания
+const updateTodoQuery = `
+		UPDATE todos SET title = $1, description = $2, completed = $3, 
+		                 priority = $4, due_date = $5, category_id = $6, 
+		                 parent_id = $7, recurrence = $8, reminders = $9, updated_at = $10 
+		WHERE id = $11 AND deleted_at IS NULL`
+
+func (r *todoRepo) Update(todo *models.Todo) error {
+	query := updateTodoQuery
+
+	tx, err := r.db.Begin()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer rows.Close()
+	defer tx.Rollback()
 
-	var todos []models.Todo
-	for rows.Next() {
-		var todo models.Todo
-		err := rows.Scan(
-			&todo.ID, &todo.Title, &todo.Description, &todo.Completed,
-			&todo.Priority, &todo.DueDate, &todo.CategoryID,
-			&todo.CreatedAt, &todo.UpdatedAt)
-		if err != nil {
-			return nil, err
-		}
-		todos = append(todos, todo)
+	before, err := scanTodo(tx.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, todo.ID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
 	}
-	return todos, rows.Err()
+	if err != nil {
+		return err
+	}
+
+	todo.UpdatedAt = time.Now()
+	_, err = tx.Exec(query, todo.Title, todo.Description, todo.Completed,
+		todo.Priority, todo.DueDate, todo.CategoryID, todo.ParentID, todo.Recurrence,
+		reminderArray(todo.Reminders), todo.UpdatedAt, todo.ID)
+	if err != nil {
+		return err
+	}
+
+	// Update не меняет метки
+	after := *todo
+	after.Tags = before.Tags
+	if err := r.recordChange(tx, &before, &after, todo.UpdatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (r *todoRepo) Update(todo *models.Todo) error {
+// Modify читает задачу с блокировкой строки (FOR UPDATE), меняет ее функцией fn и
+// сохраняет в той же транзакции: параллельное изменение дождется ее конца и не затрется.
+// sql.ErrNoRows, если задачи нет; ошибка fn откатывает транзакцию.
+func (r *todoRepo) Modify(id uint, fn func(todo *models.Todo) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := scanTodo(tx.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = $1 AND deleted_at IS NULL FOR UPDATE`, id))
+	if err != nil {
+		return err
+	}
+
+	todo := before
+	todo.Reminders = append([]int64(nil), before.Reminders...)
+	todo.Tags = append([]string(nil), before.Tags...)
+	if err := fn(&todo); err != nil {
+		return err
+	}
+
+	todo.ID = id
+	todo.UpdatedAt = time.Now()
+	_, err = tx.Exec(updateTodoQuery, todo.Title, todo.Description, todo.Completed,
+		todo.Priority, todo.DueDate, todo.CategoryID, todo.ParentID, todo.Recurrence,
+		reminderArray(todo.Reminders), todo.UpdatedAt, todo.ID)
+	if err != nil {
+		return err
+	}
+
+	after := todo
+	after.Tags = before.Tags
+	if err := r.recordChange(tx, &before, &after, todo.UpdatedAt); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// recordChange записывает в журнал разницу между состояниями задачи, если она есть
+func (r *todoRepo) recordChange(db execer, before, after *models.Todo, at time.Time) error {
+	changes := audit.Diff(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+	return r.record(db, after.ID, after.Title, audit.Action(before, after), changes, at)
+}
+
+// Upsert сохраняет задачу с заданным ID: создает ее, если такой нет, или заменяет целиком.
+// Категория сохраняется, если у todo она не задана; задача из корзины восстанавливается.
+func (r *todoRepo) Upsert(todo *models.Todo) error {
 	query := `
-		UPDATE todos SET title = $1, description = $2, completed = $3, 
-		                 priority = $4, due_date = $5, category_id = $6, 
-		                 updated_at = $7 
-		WHERE id = $8`
+		INSERT INTO todos (id, title, description, completed, priority, due_date, category_id, parent_id, recurrence, reminders, created_at, updated_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) 
+		ON CONFLICT (id) DO UPDATE SET title = EXCLUDED.title, description = EXCLUDED.description,
+		                               completed = EXCLUDED.completed, priority = EXCLUDED.priority,
+		                               due_date = EXCLUDED.due_date,
+		                               category_id = COALESCE(EXCLUDED.category_id, todos.category_id),
+		                               parent_id = EXCLUDED.parent_id, recurrence = EXCLUDED.recurrence,
+		                               reminders = EXCLUDED.reminders, created_at = EXCLUDED.created_at,
+		                               updated_at = EXCLUDED.updated_at, deleted_at = NULL`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var before *models.Todo
+	current, err := scanTodo(tx.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = $1 FOR UPDATE`, todo.ID))
+	switch {
+	case err == nil:
+		before = &current
+	case !errors.Is(err, sql.ErrNoRows):
+		return err
+	}
 
 	todo.UpdatedAt = time.Now()
-	_, err := r.db.Exec(query, todo.Title, todo.Description, todo.Completed,
-		todo.Priority, todo.DueDate, todo.CategoryID,
-		todo.UpdatedAt, todo.ID)
-	return err
+	_, err = tx.Exec(query, todo.ID, todo.Title, todo.Description, todo.Completed,
+		todo.Priority, todo.DueDate, todo.CategoryID, todo.ParentID, todo.Recurrence,
+		reminderArray(todo.Reminders), todo.CreatedAt, todo.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	// Метки Upsert не трогает; категория без значения остается прежней
+	after := *todo
+	after.Tags = nil
+	if before != nil {
+		after.Tags = before.Tags
+		if after.CategoryID == nil {
+			after.CategoryID = before.CategoryID
+		}
+	}
+
+	if before != nil && before.DeletedAt != nil {
+		err = r.record(tx, todo.ID, todo.Title, models.ActivityRestore, audit.Diff(before, &after), todo.UpdatedAt)
+	} else {
+		err = r.recordChange(tx, before, &after, todo.UpdatedAt)
+	}
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
+// Delete переносит задачу в корзину вместе со всеми подзадачами.
+// У всего поддерева одна отметка времени, по ней Restore находит удаленное вместе.
 func (r *todoRepo) Delete(id uint) error {
-	query := `DELETE FROM todos WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	query := subtreeCTE + `
+	UPDATE todos SET deleted_at = $2
+	WHERE id IN (SELECT id FROM subtree) AND deleted_at IS NULL
+	RETURNING id, title`
+	now := time.Now()
+	_, err := r.execRecorded(models.ActivityDelete, nil, now, query, id, now)
 	return err
 }
 
+// execRecorded выполняет запрос, возвращающий (id, title) затронутых задач, и записывает
+// в журнал одинаковую запись о каждой из них
+func (r *todoRepo) execRecorded(action string, changes []models.FieldChange, at time.Time, query string, args ...interface{}) (int64, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := r.recordRows(tx, rows, action, changes, at)
+	if err != nil {
+		return 0, err
+	}
+	return n, tx.Commit()
+}
+
 func (r *todoRepo) GetByStatus(completed bool) ([]models.Todo, error) {
-	query := `
-		SELECT id, title, description, completed, priority, due_date, 
-		       category_id, created_at, updated_at 
-		FROM todos WHERE completed = $1 ORDER BY created_at DESC`
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE completed = $1 AND deleted_at IS NULL ORDER BY created_at DESC`
+	return r.queryTodos(query, completed)
+}
 
-	rows, err := r.db.Query(query, completed)
+// queryTodos выполняет запрос, возвращающий колонки todoColumns
+func (r *todoRepo) queryTodos(query string, args ...interface{}) ([]models.Todo, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -151,11 +337,7 @@ func (r *todoRepo) GetByStatus(completed bool) ([]models.Todo, error) {
 
 	var todos []models.Todo
 	for rows.Next() {
-		var todo models.Todo
-		err := rows.Scan(
-			&todo.ID, &todo.Title, &todo.Description, &todo.Completed,
-			&todo.Priority, &todo.DueDate, &todo.CategoryID,
-			&todo.CreatedAt, &todo.UpdatedAt)
+		todo, err := scanTodo(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -164,6 +346,40 @@ func (r *todoRepo) GetByStatus(completed bool) ([]models.Todo, error) {
 	return todos, rows.Err()
 }
 
+// List возвращает задачи, отобранные и отсортированные на стороне базы данных
+func (r *todoRepo) List(filter *models.TaskFilter, sort *models.TaskSort) ([]models.Todo, error) {
+	q := &todoQuery{}
+	q.applyFilter(filter)
+	if err := q.applySort(sort); err != nil {
+		return nil, err
+	}
+	return r.queryTodos(q.sql(), q.args...)
+}
+
+// rowScanner общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanTodo читает строку с колонками todoColumns
+func scanTodo(row rowScanner) (models.Todo, error) {
+	var todo models.Todo
+	err := row.Scan(
+		&todo.ID, &todo.Title, &todo.Description, &todo.Completed,
+		&todo.Priority, &todo.DueDate, &todo.CategoryID, &todo.ParentID, &todo.Recurrence,
+		pq.Array(&todo.Reminders), &todo.CreatedAt, &todo.UpdatedAt, &todo.DeletedAt, pq.Array(&todo.Tags))
+	return todo, err
+}
+
+// reminderArray передает смещения напоминаний как массив; nil сохраняется пустым массивом,
+// потому что колонка reminders NOT NULL
+func reminderArray(reminders []int64) interface{} {
+	if reminders == nil {
+		reminders = []int64{}
+	}
+	return pq.Array(reminders)
+}
+
 // Реализация CategoryRepository
 
 func (r *categoryRepo) Create(category *models.Category) error {
@@ -184,7 +400,7 @@ func (r *categoryRepo) GetByID(id uint) (*models.Category, error) {
 	category := &models.Category{}
 	query := `
 		SELECT id, name, color, created_at, updated_at 
-		FROM categories WHERE id = $1`
+		FROM categories WHERE id = $1 AND deleted_at IS NULL`
 
 	err := r.db.QueryRow(query, id).Scan(
 		&category.ID, &category.Name, &category.Color,
@@ -199,7 +415,7 @@ func (r *categoryRepo) GetByID(id uint) (*models.Category, error) {
 func (r *categoryRepo) GetAll() ([]models.Category, error) {
 	query := `
 		SELECT id, name, color, created_at, updated_at 
-		FROM categories ORDER BY name`
+		FROM categories WHERE deleted_at IS NULL ORDER BY name`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -224,7 +440,7 @@ func (r *categoryRepo) GetAll() ([]models.Category, error) {
 func (r *categoryRepo) Update(category *models.Category) error {
 	query := `
 		UPDATE categories SET name = $1, color = $2, updated_at = $3 
-		WHERE id = $4`
+		WHERE id = $4 AND deleted_at IS NULL`
 
 	category.UpdatedAt = time.Now()
 	_, err := r.db.Exec(query, category.Name, category.Color,
@@ -232,8 +448,9 @@ func (r *categoryRepo) Update(category *models.Category) error {
 	return err
 }
 
+// Delete переносит категорию в корзину; задачи категории остаются на месте
 func (r *categoryRepo) Delete(id uint) error {
-	query := `DELETE FROM categories WHERE id = $1`
-	_, err := r.db.Exec(query, id)
+	query := `UPDATE categories SET deleted_at = $1 WHERE id = $2 AND deleted_at IS NULL`
+	_, err := r.db.Exec(query, time.Now(), id)
 	return err
 }
@@ -0,0 +1,73 @@
+// repository/search.go
+package repository
+
+import (
+	"fmt"
+	"strings"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/search"
+
+	"github.com/lib/pq"
+)
+
+// headlineOptions параметры ts_headline: те же маркеры, что и у поиска в памяти
+const headlineOptions = "StartSel=" + search.HighlightStart + ", StopSel=" + search.HighlightStop
+
+// prefixTSQuery превращает пользовательский ввод в tsquery вида "слово:* & слово:*".
+// В запрос попадают только буквы и цифры, поэтому синтаксис tsquery не нарушится.
+func prefixTSQuery(text string) string {
+	words := search.Words(text)
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
+}
+
+// Search ищет задачи по заголовку и описанию с учетом русской и английской морфологии.
+// Результаты отсортированы по релевантности, совпадения подсвечены.
+func (r *todoRepo) Search(text string, filter *models.TaskFilter, limit int) ([]models.SearchResult, error) {
+	tsquery := prefixTSQuery(text)
+	if tsquery == "" {
+		return []models.SearchResult{}, nil
+	}
+
+	q := &todoQuery{}
+	queryArg := q.arg(tsquery)
+	q.where = append(q.where, notDeleted, "search_vector @@ s.tsq")
+	q.applyFilter(filter)
+
+	query := fmt.Sprintf(`
+		SELECT %s,
+		       ts_rank_cd(search_vector, s.tsq) AS rank,
+		       ts_headline('russian', title, s.tsq, 'HighlightAll=true, %s'),
+		       ts_headline('russian', coalesce(description, ''), s.tsq, 'MaxFragments=2, %s')
+		FROM todos,
+		     (SELECT to_tsquery('russian', %s) || to_tsquery('english', %s) AS tsq) AS s
+		WHERE %s
+		ORDER BY rank DESC, id DESC
+		LIMIT %d`,
+		todoColumns, headlineOptions, headlineOptions, queryArg, queryArg,
+		strings.Join(q.where, " AND "), limit)
+
+	rows, err := r.db.Query(query, q.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.SearchResult{}
+	for rows.Next() {
+		var res models.SearchResult
+		err := rows.Scan(
+			&res.ID, &res.Title, &res.Description, &res.Completed,
+			&res.Priority, &res.DueDate, &res.CategoryID, &res.ParentID, &res.Recurrence,
+			pq.Array(&res.Reminders), &res.CreatedAt, &res.UpdatedAt, &res.DeletedAt, pq.Array(&res.Tags),
+			&res.Rank, &res.TitleHighlight, &res.DescriptionHighlight)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,17 @@
+package repository
+
+import "testing"
+
+func TestPrefixTSQuery(t *testing.T) {
+	tests := map[string]string{
+		"Купить хлеб":             "купить:* & хлеб:*",
+		"  ":                      "",
+		"a & b | !c:*":            "a:* & b:* & c:*",
+		"'); DROP TABLE todos --": "drop:* & table:* & todos:*",
+	}
+	for text, want := range tests {
+		if got := prefixTSQuery(text); got != want {
+			t.Errorf("prefixTSQuery(%q) = %q, want %q", text, got, want)
+		}
+	}
+}
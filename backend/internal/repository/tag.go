@@ -0,0 +1,254 @@
+// repository/tag.go
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"todo-list/backend/internal/audit"
+	"todo-list/backend/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ErrTagExists возвращается, если метка с таким именем (без учета регистра) уже есть
+var ErrTagExists = errors.New("tag already exists")
+
+// uniqueViolation код ошибки PostgreSQL при нарушении уникального индекса
+const uniqueViolation = "23505"
+
+// TagRepository интерфейс для работы с метками
+type TagRepository interface {
+	Create(tag *models.Tag) error
+	GetByID(id uint) (*models.Tag, error)
+	GetByName(name string) (*models.Tag, error)
+	GetAll() ([]models.Tag, error)
+	Update(tag *models.Tag) error
+	Delete(id uint) error
+	Merge(sourceIDs []uint, targetID uint) error
+	SetTodoTags(todoID uint, names []string) error
+}
+
+// tagRepo реализация TagRepository
+type tagRepo struct {
+	db *sql.DB
+	recorder
+}
+
+// tagColumns колонки метки вместе с числом задач (без задач из корзины)
+const tagColumns = `id, name, color,
+		       (SELECT COUNT(*) FROM todo_tags tt JOIN todos t ON t.id = tt.todo_id
+		        WHERE tt.tag_id = tags.id AND t.deleted_at IS NULL),
+		       created_at, updated_at`
+
+func (r *tagRepo) Create(tag *models.Tag) error {
+	query := `
+		INSERT INTO tags (name, color, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id`
+
+	now := time.Now()
+	tag.CreatedAt = now
+	tag.UpdatedAt = now
+
+	err := r.db.QueryRow(query, tag.Name, tag.Color, tag.CreatedAt, tag.UpdatedAt).Scan(&tag.ID)
+	return tagError(err)
+}
+
+func (r *tagRepo) GetByID(id uint) (*models.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE id = $1`
+	return scanTag(r.db.QueryRow(query, id))
+}
+
+// GetByName ищет метку по имени без учета регистра
+func (r *tagRepo) GetByName(name string) (*models.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags WHERE LOWER(name) = LOWER($1)`
+	return scanTag(r.db.QueryRow(query, name))
+}
+
+func (r *tagRepo) GetAll() ([]models.Tag, error) {
+	query := `SELECT ` + tagColumns + ` FROM tags ORDER BY LOWER(name)`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []models.Tag{}
+	for rows.Next() {
+		tag, err := scanTag(rows)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, *tag)
+	}
+	return tags, rows.Err()
+}
+
+// Update сохраняет имя и цвет метки; переименование попадает в журнал каждой задачи с меткой
+func (r *tagRepo) Update(tag *models.Tag) error {
+	query := `UPDATE tags SET name = $1, color = $2, updated_at = $3 WHERE id = $4`
+
+	tag.UpdatedAt = time.Now()
+	return r.changeTagged([]int64{int64(tag.ID)}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, tag.Name, tag.Color, tag.UpdatedAt, tag.ID)
+		return tagError(err)
+	})
+}
+
+// Delete удаляет метку и снимает ее с задач; у каждой задачи изменение попадает в журнал
+func (r *tagRepo) Delete(id uint) error {
+	return r.changeTagged([]int64{int64(id)}, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`DELETE FROM tags WHERE id = $1`, id)
+		return err
+	})
+}
+
+// Merge переносит задачи с меток sourceIDs на targetID и удаляет исходные метки
+func (r *tagRepo) Merge(sourceIDs []uint, targetID uint) error {
+	ids := make([]int64, 0, len(sourceIDs))
+	for _, id := range sourceIDs {
+		if id != targetID {
+			ids = append(ids, int64(id))
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	return r.changeTagged(ids, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			INSERT INTO todo_tags (todo_id, tag_id)
+			SELECT DISTINCT todo_id, $1 FROM todo_tags WHERE tag_id = ANY($2)
+			ON CONFLICT DO NOTHING`, targetID, pq.Array(ids))
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(`DELETE FROM tags WHERE id = ANY($1)`, pq.Array(ids))
+		return err
+	})
+}
+
+// changeTagged выполняет change в транзакции и записывает в журнал изменение меток
+// каждой задачи, у которой была одна из меток tagIDs
+func (r *tagRepo) changeTagged(tagIDs []int64, change func(tx *sql.Tx) error) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT `+todoColumns+` FROM todos
+		WHERE id IN (SELECT todo_id FROM todo_tags WHERE tag_id = ANY($1))
+		ORDER BY id FOR UPDATE`, pq.Array(tagIDs))
+	if err != nil {
+		return err
+	}
+	var before []models.Todo
+	for rows.Next() {
+		todo, err := scanTodo(rows)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+		before = append(before, todo)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := change(tx); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range before {
+		after, err := scanTodo(tx.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = $1`, before[i].ID))
+		if err != nil {
+			return err
+		}
+		if changes := audit.Diff(&before[i], &after); len(changes) > 0 {
+			if err := r.record(tx, after.ID, after.Title, models.ActivityUpdate, changes, now); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// SetTodoTags заменяет метки задачи; метки, которых еще нет, создаются.
+// Изменение меток задачи попадает в журнал.
+func (r *tagRepo) SetTodoTags(todoID uint, names []string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := scanTodo(tx.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = $1 FOR UPDATE`, todoID))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM todo_tags WHERE todo_id = $1`, todoID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, name := range names {
+		var tagID uint
+		err := tx.QueryRow(`SELECT id FROM tags WHERE LOWER(name) = LOWER($1)`, name).Scan(&tagID)
+		if errors.Is(err, sql.ErrNoRows) {
+			err = tx.QueryRow(`
+				INSERT INTO tags (name, created_at, updated_at) VALUES ($1, $2, $2)
+				RETURNING id`, name, now).Scan(&tagID)
+		}
+		if err != nil {
+			return tagError(err)
+		}
+
+		_, err = tx.Exec(`INSERT INTO todo_tags (todo_id, tag_id) VALUES ($1, $2) ON CONFLICT DO NOTHING`,
+			todoID, tagID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if before.ID != 0 {
+		after, err := scanTodo(tx.QueryRow(`SELECT `+todoColumns+` FROM todos WHERE id = $1`, todoID))
+		if err != nil {
+			return err
+		}
+		changes := audit.Diff(&before, &after)
+		if len(changes) > 0 {
+			if err := r.record(tx, todoID, after.Title, models.ActivityUpdate, changes, now); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+// scanTag читает строку с колонками tagColumns
+func scanTag(row rowScanner) (*models.Tag, error) {
+	tag := &models.Tag{}
+	var color sql.NullString
+	err := row.Scan(&tag.ID, &tag.Name, &color, &tag.Count, &tag.CreatedAt, &tag.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	tag.Color = color.String
+	return tag, nil
+}
+
+// tagError переводит нарушение уникального имени метки в ErrTagExists
+func tagError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code == uniqueViolation {
+		return ErrTagExists
+	}
+	return err
+}
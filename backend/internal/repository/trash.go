@@ -0,0 +1,134 @@
+// repository/trash.go
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"todo-list/backend/internal/audit"
+	"todo-list/backend/internal/models"
+)
+
+// GetDeleted возвращает задачи из корзины, недавно удаленные первыми
+func (r *todoRepo) GetDeleted() ([]models.Todo, error) {
+	query := `SELECT ` + todoColumns + ` FROM todos WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id`
+	return r.queryTodos(query)
+}
+
+// Restore возвращает задачу из корзины вместе с подзадачами, удаленными вместе с ней.
+// Если родитель задачи все еще в корзине, задача становится задачей верхнего уровня.
+// Возвращает sql.ErrNoRows, если задачи нет в корзине.
+func (r *todoRepo) Restore(id uint) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var deletedAt time.Time
+	var title string
+	var parentID *uint
+	err = tx.QueryRow(`SELECT deleted_at, title, parent_id FROM todos WHERE id = $1 AND deleted_at IS NOT NULL FOR UPDATE`, id).
+		Scan(&deletedAt, &title, &parentID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	query := subtreeCTE + `
+	UPDATE todos SET deleted_at = NULL, updated_at = $3
+	WHERE id IN (SELECT id FROM subtree) AND deleted_at = $2
+	RETURNING id, title`
+	rows, err := tx.Query(query, id, deletedAt, now)
+	if err != nil {
+		return err
+	}
+	if _, err := r.recordRows(tx, rows, models.ActivityRestore, nil, now); err != nil {
+		return err
+	}
+
+	res, err := tx.Exec(`
+		UPDATE todos SET parent_id = NULL
+		WHERE id = $1 AND parent_id IN (SELECT id FROM todos WHERE deleted_at IS NOT NULL)`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n > 0 {
+		before := &models.Todo{ParentID: parentID}
+		after := &models.Todo{}
+		if err := r.record(tx, id, title, models.ActivityUpdate, audit.Diff(before, after), now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// Remove окончательно удаляет задачу вместе с подзадачами, минуя корзину
+func (r *todoRepo) Remove(id uint) error {
+	query := `DELETE FROM todos WHERE id = $1 RETURNING id, title`
+	n, err := r.execRecorded(models.ActivityPurge, nil, time.Now(), query, id)
+	if err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return err
+}
+
+// Purge окончательно удаляет задачи, попавшие в корзину раньше before
+// История удаленных задач остается в журнале.
+func (r *todoRepo) Purge(before time.Time) (int64, error) {
+	query := `DELETE FROM todos WHERE deleted_at < $1 RETURNING id, title`
+	return r.execRecorded(models.ActivityPurge, nil, time.Now(), query, before)
+}
+
+// GetDeleted возвращает категории из корзины, недавно удаленные первыми
+func (r *categoryRepo) GetDeleted() ([]models.Category, error) {
+	query := `
+		SELECT id, name, color, created_at, updated_at, deleted_at
+		FROM categories WHERE deleted_at IS NOT NULL ORDER BY deleted_at DESC, id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []models.Category{}
+	for rows.Next() {
+		var category models.Category
+		err := rows.Scan(
+			&category.ID, &category.Name, &category.Color,
+			&category.CreatedAt, &category.UpdatedAt, &category.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, category)
+	}
+	return categories, rows.Err()
+}
+
+// Restore возвращает категорию из корзины; sql.ErrNoRows, если ее там нет
+func (r *categoryRepo) Restore(id uint) error {
+	query := `UPDATE categories SET deleted_at = NULL, updated_at = $1 WHERE id = $2 AND deleted_at IS NOT NULL`
+	res, err := r.db.Exec(query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Purge окончательно удаляет категории, попавшие в корзину раньше before.
+// У задач этих категорий category_id сбрасывается (ON DELETE SET NULL).
+func (r *categoryRepo) Purge(before time.Time) (int64, error) {
+	res, err := r.db.Exec(`DELETE FROM categories WHERE deleted_at < $1`, before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,111 @@
+// repository/tree.go
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"todo-list/backend/internal/audit"
+	"todo-list/backend/internal/models"
+)
+
+// ErrCycle возвращается при попытке сделать задачу потомком самой себя
+var ErrCycle = errors.New("task cannot be moved into its own subtree")
+
+// subtreeCTE рекурсивно собирает ID задачи $1 и всех ее потомков
+const subtreeCTE = `
+	WITH RECURSIVE subtree AS (
+		SELECT id FROM todos WHERE id = $1
+		UNION ALL
+		SELECT t.id FROM todos t JOIN subtree s ON t.parent_id = s.id
+	)`
+
+// ancestorsCTE рекурсивно собирает ID всех предков задачи $1 (без нее самой)
+const ancestorsCTE = `
+	WITH RECURSIVE ancestors AS (
+		SELECT parent_id AS id FROM todos WHERE id = $1 AND parent_id IS NOT NULL
+		UNION ALL
+		SELECT t.parent_id FROM todos t JOIN ancestors a ON t.id = a.id WHERE t.parent_id IS NOT NULL
+	)`
+
+// GetSubtree возвращает задачу и всех ее потомков
+func (r *todoRepo) GetSubtree(id uint) ([]models.Todo, error) {
+	query := subtreeCTE + `
+	SELECT ` + todoColumns + ` FROM todos WHERE id IN (SELECT id FROM subtree) AND deleted_at IS NULL
+	ORDER BY created_at, id`
+	return r.queryTodos(query, id)
+}
+
+// SetSubtreeCompleted меняет статус задачи и всех ее потомков
+func (r *todoRepo) SetSubtreeCompleted(id uint, completed bool) error {
+	query := subtreeCTE + `
+	UPDATE todos SET completed = $2, updated_at = $3
+	WHERE id IN (SELECT id FROM subtree) AND completed <> $2 AND deleted_at IS NULL
+	RETURNING id, title`
+
+	action := models.ActivityReopen
+	if completed {
+		action = models.ActivityComplete
+	}
+	now := time.Now()
+	_, err := r.execRecorded(action, completedChange(!completed, completed), now, query, id, completed, now)
+	return err
+}
+
+// completedChange изменение статуса задачи для журнала
+func completedChange(old, cur bool) []models.FieldChange {
+	return audit.Diff(&models.Todo{Completed: old}, &models.Todo{Completed: cur})
+}
+
+// ReopenAncestors снимает отметку о выполнении со всех предков задачи
+func (r *todoRepo) ReopenAncestors(id uint) error {
+	query := ancestorsCTE + `
+	UPDATE todos SET completed = FALSE, updated_at = $2
+	WHERE id IN (SELECT id FROM ancestors) AND completed
+	RETURNING id, title`
+	now := time.Now()
+	_, err := r.execRecorded(models.ActivityReopen, completedChange(true, false), now, query, id, now)
+	return err
+}
+
+// Move переносит задачу вместе с поддеревом под нового родителя (nil - на верхний уровень)
+func (r *todoRepo) Move(id uint, parentID *uint) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if parentID != nil {
+		var inSubtree bool
+		query := subtreeCTE + `SELECT EXISTS (SELECT 1 FROM subtree WHERE id = $2)`
+		if err := tx.QueryRow(query, id, *parentID).Scan(&inSubtree); err != nil {
+			return err
+		}
+		if inSubtree {
+			return ErrCycle
+		}
+	}
+
+	var title string
+	var oldParentID *uint
+	err = tx.QueryRow(`SELECT title, parent_id FROM todos WHERE id = $1 FOR UPDATE`, id).Scan(&title, &oldParentID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	_, err = tx.Exec(`UPDATE todos SET parent_id = $1, updated_at = $2 WHERE id = $3`,
+		parentID, now, id)
+	if err != nil {
+		return err
+	}
+
+	changes := audit.Diff(&models.Todo{ParentID: oldParentID}, &models.Todo{ParentID: parentID})
+	if len(changes) > 0 {
+		if err := r.record(tx, id, title, models.ActivityUpdate, changes, now); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
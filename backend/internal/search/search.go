@@ -0,0 +1,228 @@
+// search/search.go
+package search
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Маркеры подсветки совпадений, такие же, как в ts_headline на стороне PostgreSQL
+const (
+	HighlightStart = "<mark>"
+	HighlightStop  = "</mark>"
+)
+
+// Веса совпадений: точная основа, префикс, опечатка
+const (
+	exactWeight  = 1.0
+	prefixWeight = 0.8
+	typoWeight   = 0.5
+)
+
+// titleWeight во сколько раз совпадение в заголовке важнее совпадения в описании
+const titleWeight = 2.0
+
+// term слово запроса или документа вместе с основой
+type term struct {
+	word string
+	stem string
+}
+
+// Query разобранный поисковый запрос для поиска в памяти
+type Query struct {
+	terms []term
+}
+
+// ParseQuery разбивает запрос на слова. Пустой запрос не совпадает ни с чем.
+func ParseQuery(text string) *Query {
+	return &Query{terms: tokenize(text)}
+}
+
+// Empty сообщает, что в запросе нет ни одного слова
+func (q *Query) Empty() bool {
+	return len(q.terms) == 0
+}
+
+// Rank оценивает документ из заголовка и описания. Все слова запроса должны
+// найтись хотя бы в одном из полей, иначе возвращается 0.
+func (q *Query) Rank(title, description string) float64 {
+	if q.Empty() {
+		return 0
+	}
+
+	titleTerms := tokenize(title)
+	descriptionTerms := tokenize(description)
+
+	var total float64
+	for _, qt := range q.terms {
+		score := max(titleWeight*bestMatch(qt, titleTerms), bestMatch(qt, descriptionTerms))
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+	return total / float64(len(q.terms))
+}
+
+// Highlight оборачивает совпавшие слова текста в HighlightStart/HighlightStop
+func (q *Query) Highlight(text string) string {
+	if q.Empty() {
+		return text
+	}
+
+	var b strings.Builder
+	for _, span := range wordSpans(text) {
+		if !span.word {
+			b.WriteString(span.text)
+			continue
+		}
+		t := newTerm(span.text)
+		matched := false
+		for _, qt := range q.terms {
+			if matchScore(qt, t) > 0 {
+				matched = true
+				break
+			}
+		}
+		if matched {
+			b.WriteString(HighlightStart + span.text + HighlightStop)
+		} else {
+			b.WriteString(span.text)
+		}
+	}
+	return b.String()
+}
+
+// bestMatch возвращает лучший вес совпадения слова запроса среди слов документа
+func bestMatch(qt term, doc []term) float64 {
+	var best float64
+	for _, t := range doc {
+		if score := matchScore(qt, t); score > best {
+			best = score
+			if best == exactWeight {
+				break
+			}
+		}
+	}
+	return best
+}
+
+// matchScore сравнивает слово запроса со словом документа
+func matchScore(qt, t term) float64 {
+	switch {
+	case qt.stem == t.stem || qt.word == t.word:
+		return exactWeight
+	case utf8.RuneCountInString(qt.word) >= 2 &&
+		(strings.HasPrefix(t.word, qt.word) || strings.HasPrefix(t.stem, qt.stem)):
+		return prefixWeight
+	}
+
+	allowed := maxTypos(qt.stem)
+	if allowed > 0 && levenshtein(qt.stem, t.stem, allowed) <= allowed {
+		return typoWeight
+	}
+	return 0
+}
+
+// maxTypos допустимое число опечаток в зависимости от длины слова
+func maxTypos(word string) int {
+	switch n := utf8.RuneCountInString(word); {
+	case n >= 8:
+		return 2
+	case n >= 4:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// levenshtein считает расстояние редактирования между строками по рунам.
+// Если расстояние заведомо больше limit, возвращает limit+1.
+func levenshtein(a, b string, limit int) int {
+	ra, rb := []rune(a), []rune(b)
+	if abs(len(ra)-len(rb)) > limit {
+		return limit + 1
+	}
+
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// span фрагмент текста: слово или разделитель между словами
+type span struct {
+	text string
+	word bool
+}
+
+// wordSpans делит текст на слова и разделители, сохраняя исходный текст целиком
+func wordSpans(text string) []span {
+	var spans []span
+	start := 0
+	inWord := false
+	for i, r := range text {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		if i > start && isWord != inWord {
+			spans = append(spans, span{text: text[start:i], word: inWord})
+			start = i
+		}
+		inWord = isWord
+	}
+	if start < len(text) {
+		spans = append(spans, span{text: text[start:], word: inWord})
+	}
+	return spans
+}
+
+// Words возвращает слова текста (последовательности букв и цифр) в нижнем регистре
+func Words(text string) []string {
+	var words []string
+	for _, t := range tokenize(text) {
+		words = append(words, t.word)
+	}
+	return words
+}
+
+// tokenize возвращает слова текста в нижнем регистре вместе с основами
+func tokenize(text string) []term {
+	var terms []term
+	for _, s := range wordSpans(text) {
+		if s.word {
+			terms = append(terms, newTerm(s.text))
+		}
+	}
+	return terms
+}
+
+func newTerm(word string) term {
+	lower := strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+	return term{word: lower, stem: Stem(lower)}
+}
@@ -0,0 +1,102 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestStem(t *testing.T) {
+	// Формы одного слова сводятся к одной основе
+	groups := [][]string{
+		{"купить", "купила", "Купили", "купился"},
+		{"задача", "задачи", "задачами"},
+		{"ёлка", "елки"},
+		{"task", "tasks"},
+	}
+	for _, words := range groups {
+		stem := Stem(words[0])
+		for _, word := range words[1:] {
+			if got := Stem(word); got != stem {
+				t.Errorf("Stem(%q) = %q, Stem(%q) = %q", word, got, words[0], stem)
+			}
+		}
+	}
+	// Короткие слова не обрезаются короче minStemLength
+	for _, word := range []string{"ток", "dog", "да"} {
+		if got := Stem(word); got != word {
+			t.Errorf("Stem(%q) = %q", word, got)
+		}
+	}
+}
+
+func TestRank(t *testing.T) {
+	q := ParseQuery("Купить хлеб")
+	tests := []struct {
+		title, description string
+		want               float64
+	}{
+		{"Купила хлеба", "", titleWeight * exactWeight},
+		{"хлеб", "купить", (titleWeight + exactWeight) / 2},
+		{"", "купить хлеб", exactWeight},
+		// Все слова запроса должны найтись
+		{"хлеб", "", 0},
+		{"", "", 0},
+	}
+	for _, tt := range tests {
+		if got := q.Rank(tt.title, tt.description); got != tt.want {
+			t.Errorf("Rank(%q, %q) = %v, want %v", tt.title, tt.description, got, tt.want)
+		}
+	}
+
+	if got := ParseQuery("отч").Rank("", "Отчеты за март"); got != prefixWeight {
+		t.Errorf("prefix rank = %v", got)
+	}
+	if got := ParseQuery("програмирование").Rank("", "программирование"); got != typoWeight {
+		t.Errorf("typo rank = %v", got)
+	}
+	// В коротких словах опечатки не допускаются
+	if got := ParseQuery("кот").Rank("", "кит"); got != 0 {
+		t.Errorf("short word typo rank = %v", got)
+	}
+	if q := ParseQuery("  ,.! "); !q.Empty() || q.Rank("что угодно", "") != 0 {
+		t.Error("empty query matched")
+	}
+}
+
+func TestHighlight(t *testing.T) {
+	q := ParseQuery("купить")
+	got := q.Highlight("Надо купила, <b>Купить!</b> молоко")
+	want := "Надо <mark>купила</mark>, <b><mark>Купить</mark>!</b> молоко"
+	if got != want {
+		t.Errorf("Highlight = %q", got)
+	}
+	if got := ParseQuery("").Highlight("текст"); got != "текст" {
+		t.Errorf("empty query highlight = %q", got)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		limit int
+		want  int
+	}{
+		{"кот", "кот", 1, 0},
+		{"кот", "кит", 1, 1},
+		{"кот", "котик", 2, 2},
+		{"кот", "котенок", 2, 3},
+		{"abcdef", "ghijkl", 2, 3},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b, tt.limit); got != tt.want {
+			t.Errorf("levenshtein(%q, %q, %d) = %d, want %d", tt.a, tt.b, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestWords(t *testing.T) {
+	got := Words("Ёлка-2026, купить: ПОДАРКИ!")
+	if want := []string{"елка", "2026", "купить", "подарки"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Words = %q", got)
+	}
+}
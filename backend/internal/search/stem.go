@@ -0,0 +1,82 @@
+// search/stem.go
+package search
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// minStemLength минимальная длина основы в рунах, короче слово не обрезается
+const minStemLength = 3
+
+// russianEndings окончания русских слов (упрощенный Snowball):
+// глагольные, прилагательные и существительные. Возвратные ся/сь снимаются отдельно.
+var russianEndings = sortByLength([]string{
+	// глаголы
+	"ившись", "ывшись", "вшись", "ивши", "ывши", "вши",
+	"ила", "ыла", "ена", "ейте", "уйте", "ите", "или", "ыли", "ило", "ыло", "ено",
+	"ует", "уют", "ены", "ить", "ыть", "ишь", "ете", "йте", "ешь", "нно",
+	"ил", "ыл", "ен", "ят", "ит", "ыт", "ла", "на", "ли", "ем", "ло", "но",
+	"ет", "ют", "ны", "ть", "уй", "ей",
+	// прилагательные
+	"ими", "ыми", "его", "ого", "ему", "ому",
+	"ее", "ие", "ые", "ое", "ий", "ый", "ой", "им", "ым", "ом", "их", "ых",
+	"ую", "юю", "ая", "яя", "ою", "ею",
+	// существительные
+	"иями", "ями", "ами", "ией", "иям", "ием", "иях",
+	"ев", "ов", "ье", "еи", "ии", "ям", "ам", "ах", "ях", "ию", "ью", "ия", "ья",
+	"а", "е", "и", "й", "о", "у", "ы", "ь", "ю", "я",
+})
+
+// englishEndings распространенные английские окончания
+var englishEndings = sortByLength([]string{
+	"ingly", "edly", "ing", "ies", "ied", "ed", "es", "ly", "s",
+})
+
+// Stem возвращает приближенную основу слова для русского или английского языка.
+// Это не полноценный морфологический анализатор: задача - чтобы "купить"
+// и "купила" сводились к одной основе.
+func Stem(word string) string {
+	word = strings.ReplaceAll(strings.ToLower(word), "ё", "е")
+
+	endings := englishEndings
+	if isCyrillic(word) {
+		endings = russianEndings
+		// Возвратная частица снимается отдельно: "купился" -> "купил" -> "куп"
+		word = trimEnding(word, []string{"ся", "сь"})
+	}
+	return trimEnding(word, endings)
+}
+
+// trimEnding отрезает самое длинное подходящее окончание, сохраняя основу не короче minStemLength
+func trimEnding(word string, endings []string) string {
+	length := utf8.RuneCountInString(word)
+	for _, ending := range endings {
+		if !strings.HasSuffix(word, ending) {
+			continue
+		}
+		if length-utf8.RuneCountInString(ending) >= minStemLength {
+			return strings.TrimSuffix(word, ending)
+		}
+	}
+	return word
+}
+
+// isCyrillic сообщает, состоит ли слово в основном из кириллицы
+func isCyrillic(word string) bool {
+	for _, r := range word {
+		if r >= 'а' && r <= 'я' || r == 'ё' {
+			return true
+		}
+	}
+	return false
+}
+
+// sortByLength сортирует окончания по убыванию длины, чтобы сначала пробовать самые длинные
+func sortByLength(endings []string) []string {
+	sort.SliceStable(endings, func(i, j int) bool {
+		return utf8.RuneCountInString(endings[i]) > utf8.RuneCountInString(endings[j])
+	})
+	return endings
+}
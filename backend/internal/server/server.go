@@ -0,0 +1,62 @@
+// server/server.go
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"time"
+)
+
+// Server HTTP-сервер REST API с управляемым жизненным циклом
+type Server struct {
+	http *http.Server
+	done chan error
+}
+
+// New создает сервер, который будет слушать addr
+func New(addr string, handler http.Handler) *Server {
+	return &Server{
+		http: &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       30 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       120 * time.Second,
+		},
+		done: make(chan error, 1),
+	}
+}
+
+// Start открывает порт и обслуживает запросы в отдельной горутине.
+// Ошибка привязки к порту возвращается сразу.
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.http.Addr)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("HTTP API listening on %s", listener.Addr())
+	go func() {
+		err := s.http.Serve(listener)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+		s.done <- err
+	}()
+	return nil
+}
+
+// Done возвращает канал, в который придет результат работы сервера после остановки
+func (s *Server) Done() <-chan error {
+	return s.done
+}
+
+// Shutdown останавливает сервер, дожидаясь завершения активных запросов
+func (s *Server) Shutdown(ctx context.Context) error {
+	log.Println("HTTP API shutting down...")
+	return s.http.Shutdown(ctx)
+}
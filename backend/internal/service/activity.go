@@ -0,0 +1,34 @@
+// service/activity.go
+package service
+
+import (
+	"errors"
+
+	"todo-list/backend/internal/models"
+)
+
+// GetTodoHistory возвращает журнал изменений задачи, новые записи первыми.
+// История остается доступной и после удаления задачи из корзины.
+func (s *todoService) GetTodoHistory(id uint, limit int) ([]models.Activity, error) {
+	if id == 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	return s.repo.Activity.List(&id, 0, pageSize(limit))
+}
+
+// GetActivity возвращает общую ленту изменений задач; beforeID > 0 - записи старше beforeID
+func (s *todoService) GetActivity(beforeID uint, limit int) ([]models.Activity, error) {
+	return s.repo.Activity.List(nil, beforeID, pageSize(limit))
+}
+
+func (s *taskService) GetTaskHistory(id int, limit int) ([]models.Activity, error) {
+	if id <= 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	todoID := uint(id)
+	return s.repo.Activity.List(&todoID, 0, pageSize(limit))
+}
+
+func (s *taskService) GetActivity(beforeID uint, limit int) ([]models.Activity, error) {
+	return s.repo.Activity.List(nil, beforeID, pageSize(limit))
+}
@@ -0,0 +1,611 @@
+// service/caldav.go
+package service
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-list/backend/internal/ical"
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+	"todo-list/backend/internal/transfer"
+)
+
+// Ошибки CalDAV
+var (
+	ErrCalendarNotFound = errors.New("календарь не найден")
+	ErrObjectNotFound   = errors.New("задача календаря не найдена")
+	ErrInvalidObject    = errors.New("некорректная задача iCalendar")
+	ErrPrecondition     = errors.New("задача изменилась: ETag не совпадает")
+	ErrUIDConflict      = errors.New("задача с таким UID уже есть")
+	ErrInvalidSyncToken = errors.New("некорректный sync-token")
+)
+
+// Календари CalDAV
+const (
+	// DefaultCalendarID календарь задач без категории
+	DefaultCalendarID   = "default"
+	defaultCalendarName = "Без категории"
+	// syncTokenPrefix sync-token - URI с номером последней записи журнала изменений
+	syncTokenPrefix = "urn:todo-list:sync:"
+)
+
+// CalDAVService интерфейс календарей CalDAV: каждая категория - коллекция задач VTODO,
+// задачи без категории - календарь DefaultCalendarID. Ресурс задачи называется "<ID>.ics",
+// если клиент не создал ее под своим именем.
+type CalDAVService interface {
+	Calendars() ([]DAVCalendar, error)
+	Calendar(id string) (*DAVCalendar, error)
+	Objects(calendarID string) ([]DAVObject, error)
+	Object(calendarID, name string) (*DAVObject, error)
+	// PutObject создает или заменяет задачу ресурса name; created - задача новая
+	PutObject(calendarID, name string, data []byte, cond DAVCondition) (created bool, err error)
+	// DeleteObject переносит задачу ресурса в корзину
+	DeleteObject(calendarID, name string, cond DAVCondition) error
+	// SyncToken общий для всех календарей: номер последней записи журнала изменений
+	SyncToken() (string, error)
+	// Changes возвращает изменения календаря после token; пустой token - все задачи
+	Changes(calendarID, token string) (*DAVChanges, error)
+}
+
+// DAVCalendar календарь CalDAV
+type DAVCalendar struct {
+	ID         string
+	Name       string
+	Color      string
+	CategoryID *uint // nil - задачи без категории
+}
+
+// DAVObject задача календаря в формате iCalendar
+type DAVObject struct {
+	Name   string
+	TodoID uint
+	ETag   string // sha256 содержимого в кавычках
+	Data   []byte
+}
+
+// DAVCondition условия If-Match и If-None-Match запроса на изменение
+type DAVCondition struct {
+	IfMatch     string
+	IfNoneMatch string
+}
+
+// DAVChanges изменения календаря для отчета sync-collection
+type DAVChanges struct {
+	Changed   []DAVObject
+	Removed   []string // имена ресурсов удаленных и перенесенных в другие календари задач
+	SyncToken string
+}
+
+// caldavService реализация CalDAVService
+type caldavService struct {
+	repo  *repository.Repository
+	todos *todoService
+}
+
+// NewCalDAVService создает сервис календарей CalDAV
+func NewCalDAVService(repo *repository.Repository) CalDAVService {
+	return &caldavService{repo: repo, todos: &todoService{repo: repo}}
+}
+
+func (s *caldavService) Calendars() ([]DAVCalendar, error) {
+	categories, err := s.repo.Category.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	calendars := make([]DAVCalendar, 0, len(categories)+1)
+	for _, category := range categories {
+		id := category.ID
+		calendars = append(calendars, DAVCalendar{
+			ID:         strconv.FormatUint(uint64(id), 10),
+			Name:       category.Name,
+			Color:      category.Color,
+			CategoryID: &id,
+		})
+	}
+	return append(calendars, DAVCalendar{ID: DefaultCalendarID, Name: defaultCalendarName}), nil
+}
+
+func (s *caldavService) Calendar(id string) (*DAVCalendar, error) {
+	c, err := s.load(id)
+	if err != nil {
+		return nil, err
+	}
+	return &c.calendar, nil
+}
+
+func (s *caldavService) Objects(calendarID string) ([]DAVObject, error) {
+	c, err := s.load(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	return c.objects()
+}
+
+func (s *caldavService) Object(calendarID, name string) (*DAVObject, error) {
+	c, err := s.load(calendarID)
+	if err != nil {
+		return nil, err
+	}
+	todo, err := c.find(name)
+	if err != nil {
+		return nil, err
+	}
+	object, err := c.object(*todo)
+	if err != nil {
+		return nil, err
+	}
+	return &object, nil
+}
+
+// PutObject сохраняет задачу из календаря с одним VTODO. Категория задачи - категория
+// календаря; родитель ищется по UID из RELATED-TO, неизвестный родитель пропускается.
+// Отметка о выполнении повторяющейся задачи создает ее следующий экземпляр, как в приложении.
+func (s *caldavService) PutObject(calendarID, name string, data []byte, cond DAVCondition) (bool, error) {
+	c, err := s.load(calendarID)
+	if err != nil {
+		return false, err
+	}
+	existing, err := c.find(name)
+	if err != nil && !errors.Is(err, ErrObjectNotFound) {
+		return false, err
+	}
+	if err := c.check(existing, cond); err != nil {
+		return false, err
+	}
+
+	vtodo, zones, err := parseVTodo(data)
+	if err != nil {
+		return false, err
+	}
+	uid := strings.TrimSpace(vtodo.Value("UID"))
+	if uid == "" {
+		return false, fmt.Errorf("%w: нет UID", ErrInvalidObject)
+	}
+	if id, ok := c.index.lookupUID(uid); ok && (existing == nil || id != existing.ID) {
+		if _, err := s.repo.Todo.GetByID(id); err == nil {
+			return false, ErrUIDConflict
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return false, err
+		}
+	}
+
+	task, err := transfer.TaskFromVTodo(vtodo, zones)
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidObject, err)
+	}
+	if task.Title == "" {
+		return false, fmt.Errorf("%w: нет SUMMARY", ErrInvalidObject)
+	}
+	todo := models.Todo{
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		Priority:    task.Priority,
+		DueDate:     task.DueDate,
+		CategoryID:  c.calendar.CategoryID,
+		Recurrence:  task.Recurrence,
+	}
+	if todo.Priority == "" {
+		todo.Priority = string(models.Medium)
+	}
+	for _, offset := range task.Reminders {
+		todo.Reminders = append(todo.Reminders, int64(offset))
+	}
+	parentID, err := s.parentID(c.index, transfer.ParentUID(vtodo))
+	if err != nil {
+		return false, err
+	}
+
+	if existing == nil {
+		todo.ParentID = parentID
+		todo.Tags = task.Tags
+		if err := s.todos.CreateTodo(&todo); err != nil {
+			return false, err
+		}
+		return true, s.repo.CalDAV.Save(models.CalDAVObject{TodoID: todo.ID, Name: name, UID: uid})
+	}
+
+	todo.ID = existing.ID
+	todo.CreatedAt = existing.CreatedAt
+	if parentID != nil && *parentID == todo.ID {
+		parentID = existing.ParentID
+	}
+	if err := s.update(&todo, existing, parentID, task.Tags); err != nil {
+		return false, err
+	}
+	if c.index.name(todo.ID) != name || c.index.uid(todo.ID) != uid {
+		return false, s.repo.CalDAV.Save(models.CalDAVObject{TodoID: todo.ID, Name: name, UID: uid})
+	}
+	return false, nil
+}
+
+// update сохраняет поля задачи из календаря, ее родителя и метки
+func (s *caldavService) update(todo, existing *models.Todo, parentID *uint, tags []string) error {
+	if err := normalizeRecurrence(todo); err != nil {
+		return err
+	}
+	if err := normalizeReminders(todo); err != nil {
+		return err
+	}
+	tags, err := NormalizeTags(tags)
+	if err != nil {
+		return err
+	}
+	if !sameID(parentID, existing.ParentID) {
+		if err := moveTodo(s.repo, todo.ID, parentID); err != nil {
+			return err
+		}
+	}
+
+	todo.ParentID = parentID
+	todo.Tags = existing.Tags
+	todo.UpdatedAt = time.Now()
+	if err := completeWithRecurrence(s.repo, todo, existing.Completed); err != nil {
+		return err
+	}
+	if slices.Equal(tags, existing.Tags) {
+		return nil
+	}
+	return saveTodoTags(s.repo, todo, tags)
+}
+
+// parentID находит задачу по UID родителя; неизвестный или удаленный родитель - nil
+func (s *caldavService) parentID(index *davIndex, uid string) (*uint, error) {
+	if uid == "" {
+		return nil, nil
+	}
+	id, ok := index.lookupUID(uid)
+	if !ok {
+		return nil, nil
+	}
+	if _, err := s.repo.Todo.GetByID(id); errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (s *caldavService) DeleteObject(calendarID, name string, cond DAVCondition) error {
+	c, err := s.load(calendarID)
+	if err != nil {
+		return err
+	}
+	todo, err := c.find(name)
+	if err != nil {
+		return err
+	}
+	if err := c.check(todo, cond); err != nil {
+		return err
+	}
+	return s.todos.DeleteTodo(todo.ID)
+}
+
+func (s *caldavService) SyncToken() (string, error) {
+	latest, err := s.repo.Activity.LatestID()
+	if err != nil {
+		return "", err
+	}
+	return formatSyncToken(latest), nil
+}
+
+// Changes сообщает о задачах, которые попали в журнал изменений после token: задача,
+// которая сейчас есть в календаре, считается измененной, остальные - удаленными из него.
+func (s *caldavService) Changes(calendarID, token string) (*DAVChanges, error) {
+	after, err := parseSyncToken(token)
+	if err != nil {
+		return nil, err
+	}
+	// Номер читается до задач: изменения, сделанные во время запроса, попадут в следующий
+	latest, err := s.repo.Activity.LatestID()
+	if err != nil {
+		return nil, err
+	}
+	if after > latest {
+		return nil, ErrInvalidSyncToken
+	}
+	c, err := s.load(calendarID)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := &DAVChanges{SyncToken: formatSyncToken(latest)}
+	if token == "" {
+		if changes.Changed, err = c.objects(); err != nil {
+			return nil, err
+		}
+		return changes, nil
+	}
+
+	ids, err := s.repo.Activity.ChangedSince(after)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		todo, err := s.repo.Todo.GetByID(id)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		if err != nil || !c.contains(*todo) {
+			changes.Removed = append(changes.Removed, c.index.name(id))
+			continue
+		}
+		object, err := c.object(*todo)
+		if err != nil {
+			return nil, err
+		}
+		changes.Changed = append(changes.Changed, object)
+	}
+	return changes, nil
+}
+
+func formatSyncToken(id uint) string {
+	return syncTokenPrefix + strconv.FormatUint(uint64(id), 10)
+}
+
+// parseSyncToken возвращает номер записи журнала из sync-token; пустой token - 0
+func parseSyncToken(token string) (uint, error) {
+	if token == "" {
+		return 0, nil
+	}
+	number, found := strings.CutPrefix(token, syncTokenPrefix)
+	if !found {
+		return 0, ErrInvalidSyncToken
+	}
+	id, err := strconv.ParseUint(number, 10, 0)
+	if err != nil {
+		return 0, ErrInvalidSyncToken
+	}
+	return uint(id), nil
+}
+
+// parseVTodo разбирает календарь с задачей. Из нескольких VTODO с одним UID (исключения
+// повторяющейся задачи) берется основной, без RECURRENCE-ID.
+func parseVTodo(data []byte) (*ical.Component, ical.Zones, error) {
+	cals, err := ical.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidObject, err)
+	}
+	for _, cal := range cals {
+		for _, vtodo := range cal.Children("VTODO") {
+			if vtodo.Prop("RECURRENCE-ID") == nil {
+				return vtodo, ical.CalendarZones(cal), nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("%w: календарь задач принимает только VTODO", ErrInvalidObject)
+}
+
+func sameID(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// davContext календарь запроса вместе с данными, нужными для записи его задач
+type davContext struct {
+	repo     *repository.Repository
+	calendar DAVCalendar
+	names    map[uint]string // имена категорий по ID
+	index    *davIndex
+}
+
+// load собирает календарь id; ErrCalendarNotFound, если его нет
+func (s *caldavService) load(id string) (*davContext, error) {
+	calendars, err := s.Calendars()
+	if err != nil {
+		return nil, err
+	}
+	c := &davContext{repo: s.repo, names: map[uint]string{}}
+	found := false
+	for _, calendar := range calendars {
+		if calendar.CategoryID != nil {
+			c.names[*calendar.CategoryID] = calendar.Name
+		}
+		if calendar.ID == id {
+			c.calendar, found = calendar, true
+		}
+	}
+	if !found {
+		return nil, ErrCalendarNotFound
+	}
+
+	objects, err := s.repo.CalDAV.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	c.index = newDAVIndex(objects)
+	return c, nil
+}
+
+// contains сообщает, что задача относится к календарю. Задачи категорий из корзины
+// показываются в календаре без категории.
+func (c *davContext) contains(todo models.Todo) bool {
+	if c.calendar.CategoryID != nil {
+		return todo.CategoryID != nil && *todo.CategoryID == *c.calendar.CategoryID
+	}
+	if todo.CategoryID == nil {
+		return true
+	}
+	_, ok := c.names[*todo.CategoryID]
+	return !ok
+}
+
+func (c *davContext) objects() ([]DAVObject, error) {
+	var filter *models.TaskFilter
+	if c.calendar.CategoryID != nil {
+		filter = &models.TaskFilter{CategoryID: c.calendar.CategoryID}
+	}
+	todos, err := c.repo.Todo.List(filter, &models.TaskSort{Field: "id", Order: "asc"})
+	if err != nil {
+		return nil, err
+	}
+
+	objects := []DAVObject{}
+	for _, todo := range todos {
+		if !c.contains(todo) {
+			continue
+		}
+		object, err := c.object(todo)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// find возвращает задачу календаря по имени ресурса
+func (c *davContext) find(name string) (*models.Todo, error) {
+	id, ok := c.index.lookupName(name)
+	if !ok {
+		return nil, ErrObjectNotFound
+	}
+	todo, err := c.repo.Todo.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrObjectNotFound
+	} else if err != nil {
+		return nil, err
+	}
+	if !c.contains(*todo) {
+		return nil, ErrObjectNotFound
+	}
+	return todo, nil
+}
+
+// check проверяет условия запроса для задачи ресурса; todo == nil - ресурса нет
+func (c *davContext) check(todo *models.Todo, cond DAVCondition) error {
+	if cond.IfNoneMatch == "*" && todo != nil {
+		return ErrPrecondition
+	}
+	if cond.IfMatch == "" {
+		return nil
+	}
+	if todo == nil {
+		return ErrPrecondition
+	}
+	if cond.IfMatch == "*" {
+		return nil
+	}
+	object, err := c.object(*todo)
+	if err != nil {
+		return err
+	}
+	for _, etag := range strings.Split(cond.IfMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(etag), "W/") == object.ETag {
+			return nil
+		}
+	}
+	return ErrPrecondition
+}
+
+// object записывает задачу календарем iCalendar с одним VTODO. DTSTAMP - время
+// последнего изменения задачи, поэтому без изменений содержимое и ETag не меняются.
+func (c *davContext) object(todo models.Todo) (DAVObject, error) {
+	vtodo := transfer.VTodo(transferTask(todo, c.names), ical.FormatUTC(todo.UpdatedAt))
+	vtodo.Prop("UID").Value = c.index.uid(todo.ID)
+	if todo.ParentID != nil {
+		vtodo.Prop("RELATED-TO").Value = ical.EscapeText(c.index.uid(*todo.ParentID))
+	}
+
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", transfer.ProductID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.AddChild(vtodo)
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return DAVObject{}, err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return DAVObject{
+		Name:   c.index.name(todo.ID),
+		TodoID: todo.ID,
+		ETag:   `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Data:   buf.Bytes(),
+	}, nil
+}
+
+// davIndex имена ресурсов и UID задач, созданных клиентами CalDAV
+type davIndex struct {
+	byTodo map[uint]models.CalDAVObject
+	byName map[string]uint
+	byUID  map[string]uint
+}
+
+func newDAVIndex(objects []models.CalDAVObject) *davIndex {
+	index := &davIndex{
+		byTodo: make(map[uint]models.CalDAVObject, len(objects)),
+		byName: make(map[string]uint, len(objects)),
+		byUID:  make(map[string]uint, len(objects)),
+	}
+	for _, object := range objects {
+		index.byTodo[object.TodoID] = object
+		index.byName[object.Name] = object.TodoID
+		index.byUID[object.UID] = object.TodoID
+	}
+	return index
+}
+
+// name возвращает имя ресурса задачи
+func (x *davIndex) name(id uint) string {
+	if object, ok := x.byTodo[id]; ok {
+		return object.Name
+	}
+	return strconv.FormatUint(uint64(id), 10) + ".ics"
+}
+
+// uid возвращает UID задачи
+func (x *davIndex) uid(id uint) string {
+	if object, ok := x.byTodo[id]; ok {
+		return object.UID
+	}
+	return transfer.TaskUID(int(id))
+}
+
+// lookupName находит задачу по имени ресурса: имени клиента или "<ID>.ics"
+func (x *davIndex) lookupName(name string) (uint, bool) {
+	if id, ok := x.byName[name]; ok {
+		return id, true
+	}
+	number, found := strings.CutSuffix(name, ".ics")
+	if !found {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(number, 10, 0)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	// У задачи есть имя клиента - "<ID>.ics" ее не называет
+	if _, ok := x.byTodo[uint(id)]; ok {
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// lookupUID находит задачу по UID: UID клиента или записанному transfer.TaskUID
+func (x *davIndex) lookupUID(uid string) (uint, bool) {
+	if id, ok := x.byUID[uid]; ok {
+		return id, true
+	}
+	id, ok := transfer.ParseTaskUID(uid)
+	if !ok {
+		return 0, false
+	}
+	if _, named := x.byTodo[uint(id)]; named {
+		return 0, false
+	}
+	return uint(id), true
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-list/backend/internal/models"
+)
+
+func TestSyncToken(t *testing.T) {
+	token := formatSyncToken(42)
+	if id, err := parseSyncToken(token); err != nil || id != 42 {
+		t.Errorf("parseSyncToken(%q) = %d, %v", token, id, err)
+	}
+	if id, err := parseSyncToken(""); err != nil || id != 0 {
+		t.Errorf("parseSyncToken(\"\") = %d, %v", id, err)
+	}
+	for _, token := range []string{"42", "urn:todo-list:sync:", "urn:todo-list:sync:-1", "urn:other:sync:42"} {
+		if _, err := parseSyncToken(token); !errors.Is(err, ErrInvalidSyncToken) {
+			t.Errorf("parseSyncToken(%q) = %v, want ErrInvalidSyncToken", token, err)
+		}
+	}
+}
+
+func TestDAVIndex(t *testing.T) {
+	index := newDAVIndex([]models.CalDAVObject{{TodoID: 5, Name: "client.ics", UID: "abc-123"}})
+
+	if index.name(5) != "client.ics" || index.uid(5) != "abc-123" {
+		t.Errorf("client task: name %q, uid %q", index.name(5), index.uid(5))
+	}
+	if index.name(7) != "7.ics" || index.uid(7) != "7@todo-list" {
+		t.Errorf("app task: name %q, uid %q", index.name(7), index.uid(7))
+	}
+
+	names := map[string]uint{"client.ics": 5, "7.ics": 7, "5.ics": 0, "0.ics": 0, "7": 0, "x.ics": 0}
+	for name, want := range names {
+		if id, ok := index.lookupName(name); id != want || ok != (want > 0) {
+			t.Errorf("lookupName(%q) = %d, %v", name, id, ok)
+		}
+	}
+	// Задачу с UID клиента не находит UID, который записал бы для нее экспорт
+	uids := map[string]uint{"abc-123": 5, "7@todo-list": 7, "5@todo-list": 0, "other": 0}
+	for uid, want := range uids {
+		if id, ok := index.lookupUID(uid); id != want || ok != (want > 0) {
+			t.Errorf("lookupUID(%q) = %d, %v", uid, id, ok)
+		}
+	}
+}
+
+func newTestDAVContext(categoryID *uint) *davContext {
+	return &davContext{
+		calendar: DAVCalendar{ID: "c", CategoryID: categoryID},
+		names:    map[uint]string{1: "Работа"},
+		index:    newDAVIndex([]models.CalDAVObject{{TodoID: 2, Name: "parent.ics", UID: "parent-uid"}}),
+	}
+}
+
+func TestDAVContextContains(t *testing.T) {
+	work, deleted := uint(1), uint(9)
+	inWork := models.Todo{ID: 1, CategoryID: &work}
+	inDeleted := models.Todo{ID: 2, CategoryID: &deleted}
+	uncategorized := models.Todo{ID: 3}
+
+	category, defaultCalendar := newTestDAVContext(&work), newTestDAVContext(nil)
+	if !category.contains(inWork) || category.contains(inDeleted) || category.contains(uncategorized) {
+		t.Error("category calendar should contain only its tasks")
+	}
+	// Задачи категорий из корзины показываются в календаре без категории
+	if defaultCalendar.contains(inWork) || !defaultCalendar.contains(inDeleted) || !defaultCalendar.contains(uncategorized) {
+		t.Error("default calendar should contain tasks without a live category")
+	}
+}
+
+func TestDAVObjectETag(t *testing.T) {
+	c := newTestDAVContext(nil)
+	work, parent := uint(1), uint(2)
+	updated := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	todo := models.Todo{ID: 3, Title: "Отчет", CategoryID: &work, ParentID: &parent, UpdatedAt: updated, CreatedAt: updated}
+
+	object, err := c.object(todo)
+	if err != nil {
+		t.Fatalf("object: %v", err)
+	}
+	data := string(object.Data)
+	for _, want := range []string{"UID:3@todo-list\r\n", "RELATED-TO;RELTYPE=PARENT:parent-uid\r\n",
+		"DTSTAMP:20261001T120000Z\r\n", "X-TODO-LIST-CATEGORY:Работа\r\n"} {
+		if !strings.Contains(data, want) {
+			t.Errorf("object has no %q:\n%s", want, data)
+		}
+	}
+	if object.Name != "3.ics" || object.TodoID != 3 || !strings.HasPrefix(object.ETag, `"`) {
+		t.Errorf("object = %q, %d, %s", object.Name, object.TodoID, object.ETag)
+	}
+
+	// ETag зависит только от содержимого задачи
+	again, _ := c.object(todo)
+	if again.ETag != object.ETag {
+		t.Errorf("ETag changed without changes: %s, %s", object.ETag, again.ETag)
+	}
+	todo.Title, todo.UpdatedAt = "Отчет за год", updated.Add(time.Minute)
+	if changed, _ := c.object(todo); changed.ETag == object.ETag {
+		t.Error("ETag did not change with the task")
+	}
+}
+
+func TestDAVContextCheck(t *testing.T) {
+	c := newTestDAVContext(nil)
+	todo := &models.Todo{ID: 3, Title: "Отчет"}
+	object, err := c.object(*todo)
+	if err != nil {
+		t.Fatalf("object: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		todo *models.Todo
+		cond DAVCondition
+		ok   bool
+	}{
+		{"no conditions", todo, DAVCondition{}, true},
+		{"create", nil, DAVCondition{IfNoneMatch: "*"}, true},
+		{"create over existing", todo, DAVCondition{IfNoneMatch: "*"}, false},
+		{"any existing", todo, DAVCondition{IfMatch: "*"}, true},
+		{"any missing", nil, DAVCondition{IfMatch: "*"}, false},
+		{"matching etag", todo, DAVCondition{IfMatch: object.ETag}, true},
+		{"weak etag in list", todo, DAVCondition{IfMatch: `"old", W/` + object.ETag}, true},
+		{"stale etag", todo, DAVCondition{IfMatch: `"old"`}, false},
+	}
+	for _, tt := range tests {
+		err := c.check(tt.todo, tt.cond)
+		if tt.ok && err != nil || !tt.ok && !errors.Is(err, ErrPrecondition) {
+			t.Errorf("%s: check = %v", tt.name, err)
+		}
+	}
+}
+
+func TestParseVTodo(t *testing.T) {
+	data := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\nUID:a\r\nRECURRENCE-ID:20261020T120000Z\r\nSUMMARY:Исключение\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:a\r\nSUMMARY:Основная\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+	todo, _, err := parseVTodo([]byte(data))
+	if err != nil || todo.Text("SUMMARY") != "Основная" {
+		t.Fatalf("parseVTodo = %v, %v", todo, err)
+	}
+
+	for _, data := range []string{
+		"not a calendar",
+		"BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:e\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n",
+	} {
+		if _, _, err := parseVTodo([]byte(data)); !errors.Is(err, ErrInvalidObject) {
+			t.Errorf("parseVTodo(%q) = %v, want ErrInvalidObject", data, err)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// service/feed.go
+package service
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"todo-list/backend/internal/ical"
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+	"todo-list/backend/internal/transfer"
+)
+
+// Ошибки подписок
+var (
+	ErrInvalidFeed  = errors.New("некорректные параметры подписки")
+	ErrFeedNotFound = errors.New("подписка не найдена")
+)
+
+// Параметры подписок
+const (
+	// feedTokenBytes длина случайной части секретной ссылки
+	feedTokenBytes = 24
+	// FeedRefreshInterval как часто календарям предлагается обновлять подписку
+	FeedRefreshInterval = 15 * time.Minute
+	defaultFeedName     = "Задачи"
+)
+
+// FeedService интерфейс подписок iCalendar
+type FeedService interface {
+	CreateFeed(feed *models.Feed) error
+	GetAllFeeds() ([]models.Feed, error)
+	DeleteFeed(id uint) error
+	// Calendar собирает календарь подписки по ее секретному токену
+	Calendar(token string) (*FeedCalendar, error)
+}
+
+// FeedCalendar содержимое подписки для ответа с заголовками кэширования
+type FeedCalendar struct {
+	Data     []byte
+	ETag     string    // sha256 содержимого в кавычках
+	Modified time.Time // последнее изменение задач подписки
+}
+
+// feedService реализация FeedService
+type feedService struct {
+	repo *repository.Repository
+}
+
+// NewFeedService создает сервис подписок iCalendar
+func NewFeedService(repo *repository.Repository) FeedService {
+	return &feedService{repo: repo}
+}
+
+// CreateFeed проверяет фильтр и создает подписку с новым секретным токеном.
+// Без имени подписка называется по категории фильтра.
+func (s *feedService) CreateFeed(feed *models.Feed) error {
+	if err := s.checkFilter(&feed.Filter); err != nil {
+		return err
+	}
+	feed.Name = strings.TrimSpace(feed.Name)
+	if feed.Name == "" {
+		feed.Name = defaultFeedName
+		if feed.Filter.CategoryID != nil {
+			category, err := s.repo.Category.GetByID(*feed.Filter.CategoryID)
+			if err != nil {
+				return err
+			}
+			feed.Name = category.Name
+		}
+	}
+
+	token := make([]byte, feedTokenBytes)
+	if _, err := rand.Read(token); err != nil {
+		return fmt.Errorf("failed to generate feed token: %w", err)
+	}
+	feed.Token = base64.RawURLEncoding.EncodeToString(token)
+	return s.repo.Feed.Create(feed)
+}
+
+// checkFilter проверяет значения фильтра подписки и существование категории
+func (s *feedService) checkFilter(filter *models.TaskFilter) error {
+	if filter.Priority != nil {
+		switch *filter.Priority {
+		case models.Low, models.Medium, models.High:
+		default:
+			return fmt.Errorf("%w: приоритет %q", ErrInvalidFeed, *filter.Priority)
+		}
+	}
+	switch filter.TagMode {
+	case "", models.TagModeAny, models.TagModeAll:
+	default:
+		return fmt.Errorf("%w: tag_mode %q", ErrInvalidFeed, filter.TagMode)
+	}
+	tags, err := NormalizeTags(filter.Tags)
+	if err != nil {
+		return err
+	}
+	filter.Tags = tags
+
+	if filter.CategoryID != nil {
+		if _, err := s.repo.Category.GetByID(*filter.CategoryID); errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("%w: категория %d не найдена", ErrInvalidFeed, *filter.CategoryID)
+		} else if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *feedService) GetAllFeeds() ([]models.Feed, error) {
+	return s.repo.Feed.GetAll()
+}
+
+func (s *feedService) DeleteFeed(id uint) error {
+	if _, err := s.repo.Feed.GetByID(id); errors.Is(err, sql.ErrNoRows) {
+		return ErrFeedNotFound
+	} else if err != nil {
+		return err
+	}
+	return s.repo.Feed.Delete(id)
+}
+
+// Calendar собирает календарь подписки: задачи фильтра компонентами VTODO и, если подписка
+// это включает, их сроки компонентами VEVENT. DTSTAMP - время последнего изменения задачи,
+// поэтому без изменений содержимое и ETag не меняются.
+func (s *feedService) Calendar(token string) (*FeedCalendar, error) {
+	feed, err := s.repo.Feed.GetByToken(token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	todos, err := s.repo.Todo.List(&feed.Filter, &models.TaskSort{Field: "id", Order: "asc"})
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.repo.Category.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	modified := feed.CreatedAt
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+		if category.UpdatedAt.After(modified) {
+			modified = category.UpdatedAt
+		}
+	}
+	// Удаление задачи не оставляет в подписке следа, кроме записи в журнале
+	if latest, err := s.repo.Activity.List(nil, 0, 1); err != nil {
+		return nil, err
+	} else if len(latest) > 0 && latest[0].CreatedAt.After(modified) {
+		modified = latest[0].CreatedAt
+	}
+
+	refresh := ical.FormatDuration(FeedRefreshInterval)
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", transfer.ProductID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	cal.Add("METHOD", "PUBLISH")
+	cal.AddText("X-WR-CALNAME", feed.Name)
+	cal.Add("REFRESH-INTERVAL", refresh, "VALUE", "DURATION")
+	cal.Add("X-PUBLISHED-TTL", refresh)
+	for _, todo := range todos {
+		if todo.UpdatedAt.After(modified) {
+			modified = todo.UpdatedAt
+		}
+		task := transferTask(todo, names)
+		stamp := ical.FormatUTC(todo.UpdatedAt)
+		cal.AddChild(transfer.VTodo(task, stamp))
+		if feed.Events && task.DueDate != nil {
+			cal.AddChild(transfer.VEvent(task, stamp))
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, cal); err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return &FeedCalendar{
+		Data:     buf.Bytes(),
+		ETag:     `"` + hex.EncodeToString(sum[:16]) + `"`,
+		Modified: modified,
+	}, nil
+}
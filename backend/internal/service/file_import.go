@@ -0,0 +1,201 @@
+// service/file_import.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+)
+
+// ErrInvalidFileTask возвращается, если задачу из файла нельзя сохранить в PostgreSQL
+var ErrInvalidFileTask = errors.New("задачу из файла нельзя перенести")
+
+// maxTitleLength длина колонки todos.title
+const maxTitleLength = 255
+
+// FileImportService перенос задач из JSON-файла приложения в PostgreSQL
+type FileImportService interface {
+	Import(tasks []models.FileTask, tags []models.Tag, dryRun bool) (*FileImportReport, error)
+}
+
+// FileImportReport отчет о переносе задач из файла
+type FileImportReport struct {
+	Items       []models.FileImportItem `json:"items"`
+	Created     int                     `json:"created"`
+	Skipped     int                     `json:"skipped"` // перенесены раньше
+	TagsCreated int                     `json:"tags_created"`
+	DryRun      bool                    `json:"dry_run"`
+}
+
+// fileImportService реализация FileImportService
+type fileImportService struct {
+	repo *repository.Repository
+}
+
+// NewFileImportService создает сервис переноса задач из JSON-файла
+func NewFileImportService(repo *repository.Repository) FileImportService {
+	return &fileImportService{repo: repo}
+}
+
+// Import проверяет задачи так же, как при создании, упорядочивает их родителями вперед
+// и переносит одной транзакцией: при любой ошибке в базе ничего не меняется. Задачи,
+// перенесенные раньше, пропускаются, поэтому перенос того же файла можно повторять.
+// Метки задач, которых нет в tags, создаются с цветом по умолчанию.
+func (s *fileImportService) Import(tasks []models.FileTask, tags []models.Tag, dryRun bool) (*FileImportReport, error) {
+	tags, err := s.checkTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		known[strings.ToLower(tag.Name)] = true
+	}
+
+	checked := make([]models.FileTask, 0, len(tasks))
+	for _, task := range tasks {
+		if err := checkFileTask(&task); err != nil {
+			return nil, err
+		}
+		for _, name := range task.Todo.Tags {
+			if !known[strings.ToLower(name)] {
+				known[strings.ToLower(name)] = true
+				tags = append(tags, models.Tag{Name: name, Color: DefaultTagColor})
+			}
+		}
+		checked = append(checked, task)
+	}
+	ordered, err := parentsFirst(checked)
+	if err != nil {
+		return nil, err
+	}
+
+	items, tagsCreated, err := s.repo.FileImport.Import(ordered, tags, dryRun)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &FileImportReport{Items: items, TagsCreated: tagsCreated, DryRun: dryRun}
+	for _, item := range items {
+		if item.Action == "create" {
+			report.Created++
+		} else {
+			report.Skipped++
+		}
+	}
+	return report, nil
+}
+
+// checkTags проверяет имена меток из реестра файла и убирает повторы
+func (s *fileImportService) checkTags(tags []models.Tag) ([]models.Tag, error) {
+	seen := make(map[string]bool, len(tags))
+	result := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		name, err := NormalizeTagName(tag.Name)
+		if err != nil {
+			return nil, err
+		}
+		if seen[strings.ToLower(name)] {
+			continue
+		}
+		seen[strings.ToLower(name)] = true
+		if tag.Color == "" {
+			tag.Color = DefaultTagColor
+		}
+		tag.Name = name
+		result = append(result, tag)
+	}
+	return result, nil
+}
+
+// checkFileTask проверяет и нормализует задачу из файла; время изменения задачи - самое
+// позднее из времени создания, удаления и записей ее журнала
+func checkFileTask(task *models.FileTask) error {
+	todo := &task.Todo
+	invalid := func(err error) error {
+		return fmt.Errorf("%w: %s %q: %v", ErrInvalidFileTask, task.Key, todo.Title, err)
+	}
+
+	todo.Title = strings.TrimSpace(todo.Title)
+	if todo.Title == "" {
+		return invalid(errors.New("название задачи обязательно"))
+	}
+	if utf8.RuneCountInString(todo.Title) > maxTitleLength {
+		return invalid(fmt.Errorf("название длиннее %d символов", maxTitleLength))
+	}
+	switch models.Priority(todo.Priority) {
+	case models.Low, models.Medium, models.High:
+	default:
+		todo.Priority = string(models.Medium)
+	}
+	if err := normalizeRecurrence(todo); err != nil {
+		return invalid(err)
+	}
+	if err := normalizeReminders(todo); err != nil {
+		return invalid(err)
+	}
+	tags, err := NormalizeTags(todo.Tags)
+	if err != nil {
+		return invalid(err)
+	}
+	todo.Tags = tags
+
+	todo.UpdatedAt = todo.CreatedAt
+	if todo.DeletedAt != nil && todo.DeletedAt.After(todo.UpdatedAt) {
+		todo.UpdatedAt = *todo.DeletedAt
+	}
+	for _, entry := range task.Activity {
+		if entry.CreatedAt.After(todo.UpdatedAt) {
+			todo.UpdatedAt = entry.CreatedAt
+		}
+	}
+	return nil
+}
+
+// parentsFirst упорядочивает задачи так, чтобы родитель шел раньше подзадач,
+// сохраняя порядок файла. Задачи, родителя которых нет среди tasks, остаются
+// на своих местах: родителя найдет репозиторий среди перенесенных раньше.
+func parentsFirst(tasks []models.FileTask) ([]models.FileTask, error) {
+	byKey := make(map[string]int, len(tasks))
+	for i, task := range tasks {
+		if _, ok := byKey[task.Key]; ok {
+			return nil, fmt.Errorf("%w: задача %s встречается дважды", ErrInvalidFileTask, task.Key)
+		}
+		byKey[task.Key] = i
+	}
+
+	const (
+		pending = iota
+		visiting
+		done
+	)
+	state := make([]int, len(tasks))
+	ordered := make([]models.FileTask, 0, len(tasks))
+	var visit func(i int) error
+	visit = func(i int) error {
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: задача %s входит в цикл подзадач", ErrInvalidFileTask, tasks[i].Key)
+		}
+		state[i] = visiting
+		if parent, ok := byKey[tasks[i].ParentKey]; ok && tasks[i].ParentKey != "" {
+			if err := visit(parent); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		ordered = append(ordered, tasks[i])
+		return nil
+	}
+	for i := range tasks {
+		if err := visit(i); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+)
+
+// fakeFileImportRepo запоминает, что сервис передал в репозиторий; задачи с ключами
+// из migrated считаются перенесенными раньше
+type fakeFileImportRepo struct {
+	migrated map[string]uint
+	tasks    []models.FileTask
+	tags     []models.Tag
+	dryRun   bool
+}
+
+func (r *fakeFileImportRepo) Import(tasks []models.FileTask, tags []models.Tag, dryRun bool) ([]models.FileImportItem, int, error) {
+	r.tasks, r.tags, r.dryRun = tasks, tags, dryRun
+	items := make([]models.FileImportItem, 0, len(tasks))
+	for i, task := range tasks {
+		item := models.FileImportItem{Key: task.Key, Title: task.Todo.Title, Action: "create", TodoID: uint(100 + i)}
+		if id, ok := r.migrated[task.Key]; ok {
+			item.Action, item.TodoID = "skip", id
+		}
+		items = append(items, item)
+	}
+	return items, len(tags), nil
+}
+
+func newTestFileImport(migrated map[string]uint) (FileImportService, *fakeFileImportRepo) {
+	repo := &fakeFileImportRepo{migrated: migrated}
+	return NewFileImportService(&repository.Repository{FileImport: repo}), repo
+}
+
+func fileTask(key, parentKey, title string) models.FileTask {
+	return models.FileTask{Key: key, ParentKey: parentKey, Todo: models.Todo{Title: title, Priority: "high"}}
+}
+
+func TestFileImport(t *testing.T) {
+	created := time.Date(2026, 1, 10, 9, 0, 0, 0, time.UTC)
+	deleted := created.Add(24 * time.Hour)
+	logged := created.Add(48 * time.Hour)
+	due := created.Add(72 * time.Hour)
+
+	child := fileTask("2", "1", "  Подзадача ")
+	child.Todo.Priority = "urgent"
+	child.Todo.Tags = []string{"work", "Дом", "WORK"}
+	child.Todo.CreatedAt, child.Todo.DeletedAt = created, &deleted
+	child.Activity = []models.Activity{{Action: "update", CreatedAt: logged}}
+	parent := fileTask("1", "", "Задача")
+	parent.Todo.CreatedAt, parent.Todo.DueDate, parent.Todo.Recurrence = created, &due, "FREQ=WEEKLY"
+	orphan := fileTask("3", "7", "Подзадача перенесенной раньше")
+
+	svc, repo := newTestFileImport(map[string]uint{"3": 42})
+	report, err := svc.Import([]models.FileTask{child, parent, orphan}, []models.Tag{{Name: " work ", Color: "#ff0000"}, {Name: "Work"}}, true)
+	if err != nil {
+		t.Fatalf("Import: %v", err)
+	}
+
+	// Родитель идет раньше подзадачи, задача с родителем вне файла остается на месте
+	var keys []string
+	for _, task := range repo.tasks {
+		keys = append(keys, task.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"1", "2", "3"}) {
+		t.Errorf("order = %v", keys)
+	}
+	if !repo.dryRun || !report.DryRun {
+		t.Error("dry run was not passed through")
+	}
+
+	got := repo.tasks[1].Todo
+	if got.Title != "Подзадача" || got.Priority != "medium" || !reflect.DeepEqual(got.Tags, []string{"work", "Дом"}) {
+		t.Errorf("normalized child = %q, %q, %q", got.Title, got.Priority, got.Tags)
+	}
+	// Время изменения - самое позднее из создания, удаления и журнала
+	if !got.UpdatedAt.Equal(logged) {
+		t.Errorf("updated at = %v, want %v", got.UpdatedAt, logged)
+	}
+	if rule := repo.tasks[0].Todo.Recurrence; !strings.HasPrefix(rule, "RRULE:") {
+		t.Errorf("recurrence = %q", rule)
+	}
+
+	// Метки реестра без повторов, недостающие метки задач - с цветом по умолчанию
+	wantTags := []models.Tag{{Name: "work", Color: "#ff0000"}, {Name: "Дом", Color: DefaultTagColor}}
+	if !reflect.DeepEqual(repo.tags, wantTags) {
+		t.Errorf("tags = %+v", repo.tags)
+	}
+	if report.Created != 2 || report.Skipped != 1 || report.TagsCreated != 2 || len(report.Items) != 3 {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestFileImportRejects(t *testing.T) {
+	due := time.Now()
+	tests := map[string][]models.FileTask{
+		"empty title":    {fileTask("1", "", "  ")},
+		"long title":     {fileTask("1", "", strings.Repeat("я", maxTitleLength+1))},
+		"duplicate key":  {fileTask("1", "", "a"), fileTask("1", "", "b")},
+		"cycle":          {fileTask("1", "2", "a"), fileTask("2", "1", "b")},
+		"bad recurrence": {{Key: "1", Todo: models.Todo{Title: "a", DueDate: &due, Recurrence: "FREQ=SOMETIMES"}}},
+		"bad tag":        {{Key: "1", Todo: models.Todo{Title: "a", Tags: []string{" "}}}},
+	}
+	for name, tasks := range tests {
+		t.Run(name, func(t *testing.T) {
+			svc, repo := newTestFileImport(nil)
+			if _, err := svc.Import(tasks, nil, false); !errors.Is(err, ErrInvalidFileTask) {
+				t.Errorf("Import = %v, want ErrInvalidFileTask", err)
+			}
+			if repo.tasks != nil {
+				t.Error("invalid tasks reached the repository")
+			}
+		})
+	}
+
+	svc, _ := newTestFileImport(nil)
+	if _, err := svc.Import(nil, []models.Tag{{Name: ""}}, false); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("Import with an empty tag name = %v, want ErrInvalidTag", err)
+	}
+}
@@ -0,0 +1,171 @@
+// service/recurrence.go
+package service
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/recurrence"
+	"todo-list/backend/internal/repository"
+)
+
+// Ошибки повторяющихся задач
+var (
+	ErrInvalidRecurrence = errors.New("некорректное правило повторения")
+	ErrRecurrenceDueDate = errors.New("для повторяющейся задачи нужен срок выполнения")
+	ErrNotRecurring      = errors.New("задача не повторяется")
+)
+
+// Правила повторения:
+//   - правило хранится у текущего (открытого) экземпляра серии вместе со сроком выполнения;
+//   - выполнение экземпляра создает следующий с тем же заголовком, описанием, приоритетом,
+//     категорией, родителем, метками и напоминаниями, сроком rule.Next(срок)
+//     и правилом rule.Advance(срок);
+//   - у выполненного экземпляра правило снимается, поэтому повторное переключение статуса
+//     не создает дубликатов;
+//   - серия заканчивается, когда исчерпан COUNT или следующий срок позже UNTIL.
+
+// normalizeRecurrence проверяет правило задачи и приводит его к каноническому виду
+func normalizeRecurrence(todo *models.Todo) error {
+	if todo.Recurrence == "" {
+		return nil
+	}
+	rule, err := recurrence.Parse(todo.Recurrence)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+	if todo.DueDate == nil {
+		return ErrRecurrenceDueDate
+	}
+	todo.Recurrence = rule.String()
+	return nil
+}
+
+// nextOccurrence возвращает следующий экземпляр серии или nil, если серия закончилась
+func nextOccurrence(todo *models.Todo) (*models.Todo, error) {
+	if todo.Recurrence == "" || todo.DueDate == nil {
+		return nil, nil
+	}
+	rule, err := recurrence.Parse(todo.Recurrence)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRecurrence, err)
+	}
+
+	due, ok := rule.Next(*todo.DueDate)
+	if !ok {
+		return nil, nil
+	}
+
+	return &models.Todo{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Priority:    todo.Priority,
+		DueDate:     &due,
+		CategoryID:  todo.CategoryID,
+		ParentID:    todo.ParentID,
+		Recurrence:  rule.Advance(due).String(),
+		Reminders:   todo.Reminders,
+		Tags:        todo.Tags,
+	}, nil
+}
+
+// completeWithRecurrence сохраняет смену статуса и, если повторяющаяся задача
+// только что выполнена, создает следующий экземпляр серии
+func completeWithRecurrence(repo *repository.Repository, todo *models.Todo, wasCompleted bool) error {
+	var next *models.Todo
+	if todo.Completed && !wasCompleted && todo.Recurrence != "" {
+		var err error
+		if next, err = nextOccurrence(todo); err != nil {
+			return err
+		}
+		todo.Recurrence = ""
+	}
+
+	if err := saveWithCompletion(repo, todo, wasCompleted); err != nil {
+		return err
+	}
+	if next == nil {
+		return nil
+	}
+
+	now := time.Now()
+	next.CreatedAt = now
+	next.UpdatedAt = now
+	if err := repo.Todo.Create(next); err != nil {
+		return err
+	}
+	if len(next.Tags) > 0 {
+		if err := saveTodoTags(repo, next, next.Tags); err != nil {
+			return err
+		}
+	}
+	return reopenParents(repo, next)
+}
+
+// setRecurrence задает правило повторения задачи; пустое правило отключает повторение
+func setRecurrence(repo *repository.Repository, id uint, rule string) (*models.Todo, error) {
+	todo, err := repo.Todo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("задача не найдена: %w", err)
+	}
+
+	todo.Recurrence = rule
+	if err := normalizeRecurrence(todo); err != nil {
+		return nil, err
+	}
+	if err := repo.Todo.Update(todo); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// skipOccurrence переносит текущий экземпляр серии на следующий срок без выполнения.
+// Если серия закончилась, задача перестает повторяться и сохраняет текущий срок.
+func skipOccurrence(repo *repository.Repository, id uint) (*models.Todo, error) {
+	todo, err := repo.Todo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("задача не найдена: %w", err)
+	}
+	if todo.Recurrence == "" {
+		return nil, ErrNotRecurring
+	}
+
+	next, err := nextOccurrence(todo)
+	if err != nil {
+		return nil, err
+	}
+	if next == nil {
+		todo.Recurrence = ""
+	} else {
+		todo.DueDate = next.DueDate
+		todo.Recurrence = next.Recurrence
+	}
+
+	if err := repo.Todo.Update(todo); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+func (s *todoService) SetTodoRecurrence(id uint, rule string) (*models.Todo, error) {
+	if id == 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	return setRecurrence(s.repo, id, rule)
+}
+
+func (s *todoService) SkipTodoOccurrence(id uint) (*models.Todo, error) {
+	if id == 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	return skipOccurrence(s.repo, id)
+}
+
+func (s *taskService) SkipTaskOccurrence(id int) (*models.Todo, error) {
+	if id <= 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	return skipOccurrence(s.repo, uint(id))
+}
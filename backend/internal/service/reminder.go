@@ -0,0 +1,56 @@
+// service/reminder.go
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/reminder"
+)
+
+// ErrInvalidReminders возвращается при некорректных смещениях напоминаний
+var ErrInvalidReminders = errors.New("некорректные напоминания")
+
+// normalizeReminders проверяет смещения напоминаний задачи, убирает повторы
+// и сортирует их от самого раннего напоминания к самому позднему
+func normalizeReminders(todo *models.Todo) error {
+	if len(todo.Reminders) == 0 {
+		todo.Reminders = nil
+		return nil
+	}
+
+	offsets := make([]int, len(todo.Reminders))
+	for i, offset := range todo.Reminders {
+		offsets[i] = int(offset)
+	}
+	normalized, err := reminder.NormalizeOffsets(offsets)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidReminders, err)
+	}
+
+	todo.Reminders = make([]int64, len(normalized))
+	for i, offset := range normalized {
+		todo.Reminders[i] = int64(offset)
+	}
+	return nil
+}
+
+func (s *todoService) SetTodoReminders(id uint, reminders []int64) (*models.Todo, error) {
+	if id == 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	todo, err := s.repo.Todo.GetByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("задача не найдена: %w", err)
+	}
+
+	todo.Reminders = reminders
+	if err := normalizeReminders(todo); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Todo.Update(todo); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
@@ -4,12 +4,26 @@ package service
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"todo-list/backend/internal/models"
 	"todo-list/backend/internal/repository"
 )
 
+// Ошибки валидации параметров списка задач
+var (
+	ErrInvalidSort   = errors.New("некорректные параметры сортировки")
+	ErrInvalidCursor = errors.New("некорректный курсор пагинации")
+	ErrEmptyQuery    = errors.New("поисковый запрос пуст")
+)
+
+// Размеры страницы при постраничной выдаче задач
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+)
+
 // NewTaskService создает новый сервис задач (для совместимости с main.go)
 func NewTaskService(repo *repository.Repository) *Service {
 	return NewService(repo)
@@ -21,10 +35,27 @@ type TodoService interface {
 	GetTodoByID(id uint) (*models.Todo, error)
 	GetAllTodos() ([]models.Todo, error)
 	UpdateTodo(todo *models.Todo) error
+	ModifyTodo(id uint, fn func(todo *models.Todo) error) error
+	PutTodo(todo *models.Todo) error
 	DeleteTodo(id uint) error
 	ToggleTodoStatus(id uint) error
 	GetCompletedTodos() ([]models.Todo, error)
 	GetPendingTodos() ([]models.Todo, error)
+	GetTodosPage(filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error)
+	SearchTodos(query string, limit int) ([]models.SearchResult, error)
+	GetTodoTree(rootID uint) ([]*models.TodoNode, error)
+	GetTodoSubtree(id uint) ([]models.Todo, error)
+	MoveTodo(id uint, parentID *uint) error
+	GetTodoProgress(id uint) (models.Progress, error)
+	SetTodoRecurrence(id uint, rule string) (*models.Todo, error)
+	SkipTodoOccurrence(id uint) (*models.Todo, error)
+	SetTodoReminders(id uint, reminders []int64) (*models.Todo, error)
+	GetDeletedTodos() ([]models.Todo, error)
+	RestoreTodo(id uint) (*models.Todo, error)
+	RemoveTodo(id uint) error
+	PurgeDeletedTodos(before time.Time) (int64, error)
+	GetTodoHistory(id uint, limit int) ([]models.Activity, error)
+	GetActivity(beforeID uint, limit int) ([]models.Activity, error)
 }
 
 // CategoryService интерфейс для бизнес-логики категорий
@@ -34,6 +65,9 @@ type CategoryService interface {
 	GetAllCategories() ([]models.Category, error)
 	UpdateCategory(category *models.Category) error
 	DeleteCategory(id uint) error
+	GetDeletedCategories() ([]models.Category, error)
+	RestoreCategory(id uint) (*models.Category, error)
+	PurgeDeletedCategories(before time.Time) (int64, error)
 }
 
 // TaskService interface for HTTP handlers (different from TodoService for Wails)
@@ -44,12 +78,23 @@ type TaskService interface {
 	UpdateTask(id int, req *models.UpdateTaskRequest) (*models.Todo, error)
 	DeleteTask(id int) error
 	MarkTaskCompleted(id int, completed bool) error
+	GetTasksPage(filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error)
+	SearchTasks(query string, filter *models.TaskFilter, limit int) ([]models.SearchResult, error)
+	GetTaskTree(id int) ([]*models.TodoNode, error)
+	MoveTask(id int, parentID *uint) error
+	SkipTaskOccurrence(id int) (*models.Todo, error)
+	GetDeletedTasks() ([]models.Todo, error)
+	RestoreTask(id int) (*models.Todo, error)
+	GetTaskHistory(id int, limit int) ([]models.Activity, error)
+	GetActivity(beforeID uint, limit int) ([]models.Activity, error)
 }
 
 // Service объединяет все сервисы
 type Service struct {
 	Todo     TodoService
 	Category CategoryService
+	Tag      TagService
+	Transfer TransferService
 }
 
 // todoService реализация TodoService
@@ -72,9 +117,16 @@ func NewService(repo *repository.Repository) *Service {
 	return &Service{
 		Todo:     &todoService{repo: repo},
 		Category: &categoryService{repo: repo},
+		Tag:      &tagService{repo: repo},
+		Transfer: &transferService{repo: repo},
 	}
 }
 
+// NewCategoryService создает сервис категорий для HTTP-обработчиков
+func NewCategoryService(repo *repository.Repository) CategoryService {
+	return &categoryService{repo: repo}
+}
+
 // NewTaskService создает новый TaskService instance
 func NewTaskServiceHandler(repo *repository.Repository) TaskService {
 	return &taskService{repo: repo}
@@ -86,10 +138,33 @@ func (s *todoService) CreateTodo(todo *models.Todo) error {
 		return errors.New("название задачи обязательно")
 	}
 
+	if err := checkParent(s.repo, todo.ParentID); err != nil {
+		return err
+	}
+	if err := normalizeRecurrence(todo); err != nil {
+		return err
+	}
+	if err := normalizeReminders(todo); err != nil {
+		return err
+	}
+
+	tags, err := NormalizeTags(todo.Tags)
+	if err != nil {
+		return err
+	}
+
 	todo.CreatedAt = time.Now()
 	todo.UpdatedAt = time.Now()
 
-	return s.repo.Todo.Create(todo)
+	if err := s.repo.Todo.Create(todo); err != nil {
+		return err
+	}
+	if len(tags) > 0 {
+		if err := saveTodoTags(s.repo, todo, tags); err != nil {
+			return err
+		}
+	}
+	return reopenParents(s.repo, todo)
 }
 
 func (s *todoService) GetTodoByID(id uint) (*models.Todo, error) {
@@ -111,8 +186,82 @@ func (s *todoService) UpdateTodo(todo *models.Todo) error {
 		return errors.New("название задачи обязательно")
 	}
 
+	existing, err := s.repo.Todo.GetByID(todo.ID)
+	if err != nil {
+		return fmt.Errorf("задача не найдена: %w", err)
+	}
+
+	// Родитель меняется только через MoveTodo, где проверяются циклы,
+	// метки - через TagService.SetTodoTags
+	todo.ParentID = existing.ParentID
+	todo.Tags = existing.Tags
+	if err := normalizeRecurrence(todo); err != nil {
+		return err
+	}
+	if err := normalizeReminders(todo); err != nil {
+		return err
+	}
 	todo.UpdatedAt = time.Now()
-	return s.repo.Todo.Update(todo)
+	return saveWithCompletion(s.repo, todo, existing.Completed)
+}
+
+// ModifyTodo читает задачу, меняет ее функцией fn и сохраняет в одной транзакции,
+// проверяя результат по тем же правилам, что и UpdateTodo
+func (s *todoService) ModifyTodo(id uint, fn func(todo *models.Todo) error) error {
+	if id == 0 {
+		return errors.New("некорректный ID задачи")
+	}
+
+	var wasCompleted, completed bool
+	err := s.repo.Todo.Modify(id, func(todo *models.Todo) error {
+		wasCompleted = todo.Completed
+		parentID, tags := todo.ParentID, todo.Tags
+		if err := fn(todo); err != nil {
+			return err
+		}
+		if todo.Title == "" {
+			return errors.New("название задачи обязательно")
+		}
+		todo.ParentID, todo.Tags = parentID, tags
+		if err := normalizeRecurrence(todo); err != nil {
+			return err
+		}
+		if err := normalizeReminders(todo); err != nil {
+			return err
+		}
+		completed = todo.Completed
+		return nil
+	})
+	if err != nil || completed == wasCompleted {
+		return err
+	}
+	if completed {
+		return s.repo.Todo.SetSubtreeCompleted(id, true)
+	}
+	return s.repo.Todo.ReopenAncestors(id)
+}
+
+// PutTodo сохраняет задачу с заданным ID целиком, вместе с родителем и метками.
+// Используется для восстановления прежнего состояния (отмена действий), поэтому
+// правила иерархии и повторений здесь не применяются.
+func (s *todoService) PutTodo(todo *models.Todo) error {
+	if todo.ID == 0 {
+		return errors.New("некорректный ID задачи")
+	}
+	if todo.Title == "" {
+		return errors.New("название задачи обязательно")
+	}
+	if err := checkParent(s.repo, todo.ParentID); err != nil {
+		return err
+	}
+	if err := normalizeReminders(todo); err != nil {
+		return err
+	}
+
+	if err := s.repo.Todo.Upsert(todo); err != nil {
+		return err
+	}
+	return saveTodoTags(s.repo, todo, todo.Tags)
 }
 
 func (s *todoService) DeleteTodo(id uint) error {
@@ -128,10 +277,11 @@ func (s *todoService) ToggleTodoStatus(id uint) error {
 		return fmt.Errorf("задача не найдена: %w", err)
 	}
 
+	wasCompleted := todo.Completed
 	todo.Completed = !todo.Completed
 	todo.UpdatedAt = time.Now()
 
-	return s.repo.Todo.Update(todo)
+	return completeWithRecurrence(s.repo, todo, wasCompleted)
 }
 
 func (s *todoService) GetCompletedTodos() ([]models.Todo, error) {
@@ -142,6 +292,14 @@ func (s *todoService) GetPendingTodos() ([]models.Todo, error) {
 	return s.repo.Todo.GetByStatus(false)
 }
 
+func (s *todoService) GetTodosPage(filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error) {
+	return listPage(s.repo, filter, sort, page)
+}
+
+func (s *todoService) SearchTodos(query string, limit int) ([]models.SearchResult, error) {
+	return searchTodos(s.repo, query, nil, limit)
+}
+
 // Реализация CategoryService
 func (s *categoryService) CreateCategory(category *models.Category) error {
 	if category.Name == "" {
@@ -196,10 +354,17 @@ func (s *taskService) CreateTask(req *models.CreateTaskRequest) (*models.Todo, e
 		Priority:    req.Priority,
 		Completed:   false,
 		CategoryID:  req.CategoryID,
+		ParentID:    req.ParentID,
+		Recurrence:  req.Recurrence,
+		Reminders:   req.Reminders,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
 	}
 
+	if err := checkParent(s.repo, todo.ParentID); err != nil {
+		return nil, err
+	}
+
 	// Parse due date if provided
 	if req.DueDate != "" {
 		if dueDate, err := time.Parse("2006-01-02", req.DueDate); err == nil {
@@ -207,11 +372,31 @@ func (s *taskService) CreateTask(req *models.CreateTaskRequest) (*models.Todo, e
 		}
 	}
 
-	err := s.repo.Todo.Create(todo)
+	if err := normalizeRecurrence(todo); err != nil {
+		return nil, err
+	}
+	if err := normalizeReminders(todo); err != nil {
+		return nil, err
+	}
+
+	tags, err := NormalizeTags(req.Tags)
 	if err != nil {
 		return nil, err
 	}
 
+	err = s.repo.Todo.Create(todo)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) > 0 {
+		if err := saveTodoTags(s.repo, todo, tags); err != nil {
+			return nil, err
+		}
+	}
+	if err := reopenParents(s.repo, todo); err != nil {
+		return nil, err
+	}
+
 	return todo, nil
 }
 
@@ -223,8 +408,70 @@ func (s *taskService) GetTaskByID(id int) (*models.Todo, error) {
 }
 
 func (s *taskService) GetAllTasks(filter *models.TaskFilter, sort *models.TaskSort) ([]models.Todo, error) {
-	// For now, return all todos - can be enhanced with filtering and sorting later
-	return s.repo.Todo.GetAll()
+	if err := validateSort(sort); err != nil {
+		return nil, err
+	}
+	return s.repo.Todo.List(filter, sort)
+}
+
+func (s *taskService) GetTasksPage(filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error) {
+	return listPage(s.repo, filter, sort, page)
+}
+
+// listPage проверяет параметры и возвращает страницу задач
+func listPage(repo *repository.Repository, filter *models.TaskFilter, sort *models.TaskSort, page models.PageRequest) (*models.TaskPage, error) {
+	if err := validateSort(sort); err != nil {
+		return nil, err
+	}
+
+	page.Limit = pageSize(page.Limit)
+
+	result, err := repo.Todo.ListPage(filter, sort, page)
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return nil, ErrInvalidCursor
+	}
+	return result, err
+}
+
+func (s *taskService) SearchTasks(query string, filter *models.TaskFilter, limit int) ([]models.SearchResult, error) {
+	return searchTodos(s.repo, query, filter, limit)
+}
+
+// searchTodos выполняет полнотекстовый поиск по задачам
+func searchTodos(repo *repository.Repository, query string, filter *models.TaskFilter, limit int) ([]models.SearchResult, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptyQuery
+	}
+	return repo.Todo.Search(query, filter, pageSize(limit))
+}
+
+// pageSize приводит размер страницы к диапазону 1..MaxPageSize
+func pageSize(limit int) int {
+	switch {
+	case limit <= 0:
+		return DefaultPageSize
+	case limit > MaxPageSize:
+		return MaxPageSize
+	default:
+		return limit
+	}
+}
+
+// validateSort проверяет поле и направление сортировки по белому списку
+func validateSort(sort *models.TaskSort) error {
+	if sort == nil || sort.Field == "" {
+		return nil
+	}
+	if !repository.IsValidSortField(sort.Field) {
+		return fmt.Errorf("%w: поле %q", ErrInvalidSort, sort.Field)
+	}
+	switch strings.ToLower(sort.Order) {
+	case "", "asc", "desc":
+		return nil
+	default:
+		return fmt.Errorf("%w: направление %q", ErrInvalidSort, sort.Order)
+	}
 }
 
 func (s *taskService) UpdateTask(id int, req *models.UpdateTaskRequest) (*models.Todo, error) {
@@ -237,6 +484,8 @@ func (s *taskService) UpdateTask(id int, req *models.UpdateTaskRequest) (*models
 		return nil, fmt.Errorf("задача не найдена: %w", err)
 	}
 
+	wasCompleted := todo.Completed
+
 	// Update fields if provided
 	if req.Title != nil {
 		todo.Title = *req.Title
@@ -262,13 +511,36 @@ func (s *taskService) UpdateTask(id int, req *models.UpdateTaskRequest) (*models
 			todo.DueDate = nil
 		}
 	}
+	if req.Recurrence != nil {
+		todo.Recurrence = *req.Recurrence
+	}
+	var tags []string
+	if req.Tags != nil {
+		if tags, err = NormalizeTags(*req.Tags); err != nil {
+			return nil, err
+		}
+	}
+	if req.Reminders != nil {
+		todo.Reminders = *req.Reminders
+	}
+	if err := normalizeRecurrence(todo); err != nil {
+		return nil, err
+	}
+	if err := normalizeReminders(todo); err != nil {
+		return nil, err
+	}
 
 	todo.UpdatedAt = time.Now()
 
-	err = s.repo.Todo.Update(todo)
+	err = completeWithRecurrence(s.repo, todo, wasCompleted)
 	if err != nil {
 		return nil, err
 	}
+	if req.Tags != nil {
+		if err := saveTodoTags(s.repo, todo, tags); err != nil {
+			return nil, err
+		}
+	}
 
 	return todo, nil
 }
@@ -290,8 +562,9 @@ func (s *taskService) MarkTaskCompleted(id int, completed bool) error {
 		return fmt.Errorf("задача не найдена: %w", err)
 	}
 
+	wasCompleted := todo.Completed
 	todo.Completed = completed
 	todo.UpdatedAt = time.Now()
 
-	return s.repo.Todo.Update(todo)
+	return completeWithRecurrence(s.repo, todo, wasCompleted)
 }
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"todo-list/backend/internal/models"
+)
+
+func TestValidateSort(t *testing.T) {
+	for _, sort := range []*models.TaskSort{nil, {}, {Field: "priority"}, {Field: "due_date", Order: "DESC"}, {Field: "title", Order: "asc"}} {
+		if err := validateSort(sort); err != nil {
+			t.Errorf("validateSort(%+v) = %v", sort, err)
+		}
+	}
+	for _, sort := range []*models.TaskSort{{Field: "password"}, {Field: "id", Order: "sideways"}, {Order: "asc", Field: "created_at; --"}} {
+		if err := validateSort(sort); !errors.Is(err, ErrInvalidSort) {
+			t.Errorf("validateSort(%+v) = %v, want ErrInvalidSort", sort, err)
+		}
+	}
+}
+
+func TestNormalizeReminders(t *testing.T) {
+	todo := &models.Todo{Reminders: []int64{15, 1440, 15, 0}}
+	if err := normalizeReminders(todo); err != nil || fmt.Sprint(todo.Reminders) != "[1440 15 0]" {
+		t.Errorf("reminders = %v, %v", todo.Reminders, err)
+	}
+	todo.Reminders = []int64{}
+	if err := normalizeReminders(todo); err != nil || todo.Reminders != nil {
+		t.Errorf("empty reminders = %#v, %v", todo.Reminders, err)
+	}
+	todo.Reminders = []int64{-5}
+	if err := normalizeReminders(todo); !errors.Is(err, ErrInvalidReminders) {
+		t.Errorf("negative reminder = %v", err)
+	}
+}
+
+func TestNormalizeRecurrence(t *testing.T) {
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	todo := &models.Todo{Recurrence: "freq=weekly;byday=mo", DueDate: &due}
+	if err := normalizeRecurrence(todo); err != nil || todo.Recurrence != "RRULE:FREQ=WEEKLY;BYDAY=MO" {
+		t.Errorf("recurrence = %q, %v", todo.Recurrence, err)
+	}
+	if err := normalizeRecurrence(&models.Todo{Recurrence: "FREQ=DAILY"}); !errors.Is(err, ErrRecurrenceDueDate) {
+		t.Errorf("rule without a due date = %v", err)
+	}
+	if err := normalizeRecurrence(&models.Todo{Recurrence: "FREQ=HOURLY", DueDate: &due}); !errors.Is(err, ErrInvalidRecurrence) {
+		t.Errorf("invalid rule = %v", err)
+	}
+	if err := normalizeRecurrence(&models.Todo{}); err != nil {
+		t.Errorf("no rule = %v", err)
+	}
+}
+
+// Некорректный ввод отклоняется до обращения к базе данных
+func TestInputValidation(t *testing.T) {
+	todos, tasks := &todoService{}, &taskService{}
+	for name, err := range map[string]error{
+		"CreateTodo without title": todos.CreateTodo(&models.Todo{}),
+		"UpdateTodo without ID":    todos.UpdateTodo(&models.Todo{Title: "x"}),
+		"UpdateTodo without title": todos.UpdateTodo(&models.Todo{ID: 1}),
+	} {
+		if err == nil {
+			t.Errorf("%s succeeded", name)
+		}
+	}
+	if _, err := todos.GetTodoByID(0); err == nil {
+		t.Error("GetTodoByID(0) succeeded")
+	}
+	if _, err := tasks.GetTaskByID(-1); err == nil {
+		t.Error("GetTaskByID(-1) succeeded")
+	}
+	if _, err := tasks.RestoreTask(0); err == nil {
+		t.Error("RestoreTask(0) succeeded")
+	}
+	if _, err := tasks.GetAllTasks(nil, &models.TaskSort{Field: "password"}); !errors.Is(err, ErrInvalidSort) {
+		t.Errorf("GetAllTasks with an invalid sort = %v", err)
+	}
+}
@@ -0,0 +1,201 @@
+// service/tag.go
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+)
+
+// Ошибки меток
+var (
+	ErrInvalidTag  = errors.New("некорректное имя метки")
+	ErrTagExists   = errors.New("метка с таким именем уже существует")
+	ErrTagNotFound = errors.New("метка не найдена")
+)
+
+// Ограничения имени метки
+const (
+	MaxTagLength    = 64
+	DefaultTagColor = "#6c757d"
+)
+
+// TagService интерфейс для бизнес-логики меток
+type TagService interface {
+	CreateTag(tag *models.Tag) error
+	GetTagByID(id uint) (*models.Tag, error)
+	GetAllTags() ([]models.Tag, error)
+	UpdateTag(tag *models.Tag) error
+	DeleteTag(id uint) error
+	MergeTags(sourceIDs []uint, targetID uint) (*models.Tag, error)
+	SetTodoTags(todoID uint, names []string) (*models.Todo, error)
+}
+
+// tagService реализация TagService
+type tagService struct {
+	repo *repository.Repository
+}
+
+// NewTagService создает сервис меток для HTTP-обработчиков
+func NewTagService(repo *repository.Repository) TagService {
+	return &tagService{repo: repo}
+}
+
+// NormalizeTagName проверяет имя метки: без пробелов и запятых (они разделяют
+// метки в фильтрах), не длиннее MaxTagLength символов. Регистр сохраняется,
+// но имена сравниваются без учета регистра.
+func NormalizeTagName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > MaxTagLength {
+		return "", fmt.Errorf("%w: %q", ErrInvalidTag, name)
+	}
+	for _, r := range name {
+		if unicode.IsSpace(r) || r == ',' {
+			return "", fmt.Errorf("%w: %q", ErrInvalidTag, name)
+		}
+	}
+	return name, nil
+}
+
+// NormalizeTags проверяет имена меток, убирает повторы без учета регистра
+// и сортирует их по алфавиту
+func NormalizeTags(names []string) ([]string, error) {
+	seen := make(map[string]bool, len(names))
+	result := []string{}
+	for _, name := range names {
+		normalized, err := NormalizeTagName(name)
+		if err != nil {
+			return nil, err
+		}
+		key := strings.ToLower(normalized)
+		if !seen[key] {
+			seen[key] = true
+			result = append(result, normalized)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return strings.ToLower(result[i]) < strings.ToLower(result[j])
+	})
+	return result, nil
+}
+
+// saveTodoTags сохраняет метки задачи и подставляет их имена в том виде,
+// в каком они хранятся (у существующих меток регистр сохраняется)
+func saveTodoTags(repo *repository.Repository, todo *models.Todo, names []string) error {
+	names, err := NormalizeTags(names)
+	if err != nil {
+		return err
+	}
+	if err := repo.Tag.SetTodoTags(todo.ID, names); err != nil {
+		return tagError(err)
+	}
+
+	saved, err := repo.Todo.GetByID(todo.ID)
+	if err != nil {
+		return err
+	}
+	todo.Tags = saved.Tags
+	return nil
+}
+
+func (s *tagService) CreateTag(tag *models.Tag) error {
+	name, err := NormalizeTagName(tag.Name)
+	if err != nil {
+		return err
+	}
+	tag.Name = name
+	if tag.Color == "" {
+		tag.Color = DefaultTagColor
+	}
+	return tagError(s.repo.Tag.Create(tag))
+}
+
+func (s *tagService) GetTagByID(id uint) (*models.Tag, error) {
+	if id == 0 {
+		return nil, ErrTagNotFound
+	}
+	tag, err := s.repo.Tag.GetByID(id)
+	return tag, tagError(err)
+}
+
+func (s *tagService) GetAllTags() ([]models.Tag, error) {
+	return s.repo.Tag.GetAll()
+}
+
+// UpdateTag переименовывает метку или меняет ее цвет. Переименование в имя
+// существующей метки возвращает ErrTagExists: такие метки объединяются через MergeTags.
+func (s *tagService) UpdateTag(tag *models.Tag) error {
+	existing, err := s.GetTagByID(tag.ID)
+	if err != nil {
+		return err
+	}
+
+	name, err := NormalizeTagName(tag.Name)
+	if err != nil {
+		return err
+	}
+	tag.Name = name
+	if tag.Color == "" {
+		tag.Color = existing.Color
+	}
+	tag.Count = existing.Count
+	tag.CreatedAt = existing.CreatedAt
+	tag.UpdatedAt = time.Now()
+	return tagError(s.repo.Tag.Update(tag))
+}
+
+func (s *tagService) DeleteTag(id uint) error {
+	if _, err := s.GetTagByID(id); err != nil {
+		return err
+	}
+	return s.repo.Tag.Delete(id)
+}
+
+// MergeTags переносит задачи с меток sourceIDs на метку targetID и удаляет исходные метки
+func (s *tagService) MergeTags(sourceIDs []uint, targetID uint) (*models.Tag, error) {
+	if _, err := s.GetTagByID(targetID); err != nil {
+		return nil, err
+	}
+	for _, id := range sourceIDs {
+		if _, err := s.GetTagByID(id); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Tag.Merge(sourceIDs, targetID); err != nil {
+		return nil, err
+	}
+	return s.repo.Tag.GetByID(targetID)
+}
+
+// SetTodoTags заменяет метки задачи; отсутствующие метки создаются
+func (s *tagService) SetTodoTags(todoID uint, names []string) (*models.Todo, error) {
+	todo, err := s.repo.Todo.GetByID(todoID)
+	if err != nil {
+		return nil, fmt.Errorf("задача не найдена: %w", err)
+	}
+	if err := saveTodoTags(s.repo, todo, names); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+// tagError переводит ошибки репозитория меток в ошибки сервиса
+func tagError(err error) error {
+	switch {
+	case errors.Is(err, repository.ErrTagExists):
+		return ErrTagExists
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrTagNotFound
+	default:
+		return err
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"todo-list/backend/internal/repository"
+)
+
+func TestNormalizeTagName(t *testing.T) {
+	if name, err := NormalizeTagName("  Работа "); err != nil || name != "Работа" {
+		t.Errorf("NormalizeTagName = %q, %v", name, err)
+	}
+	if _, err := NormalizeTagName(strings.Repeat("я", MaxTagLength)); err != nil {
+		t.Errorf("tag of %d runes rejected: %v", MaxTagLength, err)
+	}
+	for _, name := range []string{"", "  ", "two words", "a,b", "tab\there", strings.Repeat("я", MaxTagLength+1)} {
+		if _, err := NormalizeTagName(name); !errors.Is(err, ErrInvalidTag) {
+			t.Errorf("NormalizeTagName(%q) = %v, want ErrInvalidTag", name, err)
+		}
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := NormalizeTags([]string{"work", "Дом", "home", "WORK", " дом "})
+	if err != nil || fmt.Sprint(tags) != "[home work Дом]" {
+		t.Errorf("NormalizeTags = %v, %v", tags, err)
+	}
+	if tags, err := NormalizeTags(nil); err != nil || tags == nil || len(tags) != 0 {
+		t.Errorf("NormalizeTags(nil) = %#v, %v", tags, err)
+	}
+	if _, err := NormalizeTags([]string{"ok", "not ok"}); !errors.Is(err, ErrInvalidTag) {
+		t.Errorf("NormalizeTags with an invalid name = %v", err)
+	}
+}
+
+func TestTagError(t *testing.T) {
+	other := errors.New("connection refused")
+	for err, want := range map[error]error{
+		fmt.Errorf("insert: %w", repository.ErrTagExists): ErrTagExists,
+		sql.ErrNoRows: ErrTagNotFound,
+		other:         other,
+		nil:           nil,
+	} {
+		if got := tagError(err); got != want {
+			t.Errorf("tagError(%v) = %v, want %v", err, got, want)
+		}
+	}
+}
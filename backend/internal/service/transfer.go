@@ -0,0 +1,254 @@
+// service/transfer.go
+package service
+
+import (
+	"sort"
+	"strings"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+	"todo-list/backend/internal/transfer"
+)
+
+// DefaultCategoryColor цвет категории, созданной импортом без указания цвета
+const DefaultCategoryColor = "#007bff"
+
+// TransferService импорт и экспорт задач вместе с категориями
+type TransferService interface {
+	Export() (transfer.Document, error)
+	Import(doc transfer.Document, opts transfer.ImportOptions) (transfer.Report, error)
+}
+
+// transferService реализация TransferService
+type transferService struct {
+	repo *repository.Repository
+}
+
+// NewTransferService создает сервис импорта и экспорта
+func NewTransferService(repo *repository.Repository) TransferService {
+	return &transferService{repo: repo}
+}
+
+// Export возвращает все задачи (без корзины) и категории; задачи идут в порядке ID
+func (s *transferService) Export() (transfer.Document, error) {
+	categories, err := s.repo.Category.GetAll()
+	if err != nil {
+		return transfer.Document{}, err
+	}
+	todos, err := s.repo.Todo.GetAll()
+	if err != nil {
+		return transfer.Document{}, err
+	}
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ID < todos[j].ID })
+
+	doc := transfer.Document{
+		Categories: make([]transfer.Category, 0, len(categories)),
+		Tasks:      make([]transfer.Task, 0, len(todos)),
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+		doc.Categories = append(doc.Categories, transfer.Category{Name: category.Name, Color: category.Color})
+	}
+
+	for _, todo := range todos {
+		doc.Tasks = append(doc.Tasks, transferTask(todo, names))
+	}
+	return doc, nil
+}
+
+// transferTask переводит задачу в задачу файла обмена; names - имена категорий по ID
+func transferTask(todo models.Todo, names map[uint]string) transfer.Task {
+	task := transfer.Task{
+		ID:          int(todo.ID),
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		Priority:    todo.Priority,
+		DueDate:     todo.DueDate,
+		Tags:        todo.Tags,
+		Recurrence:  todo.Recurrence,
+	}
+	if todo.ParentID != nil {
+		task.ParentID = int(*todo.ParentID)
+	}
+	if todo.CategoryID != nil {
+		task.Category = names[*todo.CategoryID]
+	}
+	for _, offset := range todo.Reminders {
+		task.Reminders = append(task.Reminders, int(offset))
+	}
+	createdAt := todo.CreatedAt
+	task.CreatedAt = &createdAt
+	return task
+}
+
+// Import переносит задачи документа в базу; отсутствующие категории создаются по имени
+func (s *transferService) Import(doc transfer.Document, opts transfer.ImportOptions) (transfer.Report, error) {
+	return transfer.Import(&transferTarget{repo: s.repo, todos: &todoService{repo: s.repo}}, doc, opts)
+}
+
+// transferTarget реализует transfer.Store поверх репозитория
+type transferTarget struct {
+	repo       *repository.Repository
+	todos      *todoService
+	categories map[string]uint // ID категорий по имени в нижнем регистре
+}
+
+func (t *transferTarget) Existing() ([]transfer.Existing, error) {
+	todos, err := t.repo.Todo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	existing := make([]transfer.Existing, 0, len(todos))
+	for _, todo := range todos {
+		task := transfer.Existing{ID: int(todo.ID), Title: todo.Title}
+		if todo.ParentID != nil {
+			task.ParentID = int(*todo.ParentID)
+		}
+		existing = append(existing, task)
+	}
+	return existing, nil
+}
+
+func (t *transferTarget) Check(task *transfer.Task) error {
+	todo := todoFromTransfer(*task)
+	if err := normalizeRecurrence(&todo); err != nil {
+		return err
+	}
+	if err := normalizeReminders(&todo); err != nil {
+		return err
+	}
+	tags, err := NormalizeTags(task.Tags)
+	if err != nil {
+		return err
+	}
+
+	task.Recurrence, task.Tags = todo.Recurrence, tags
+	task.Reminders = nil
+	for _, offset := range todo.Reminders {
+		task.Reminders = append(task.Reminders, int(offset))
+	}
+	return nil
+}
+
+func (t *transferTarget) Create(task transfer.Task, parentID int) (int, error) {
+	todo := todoFromTransfer(task)
+	if todo.Priority == "" {
+		todo.Priority = string(models.Medium)
+	}
+	if parentID > 0 {
+		parent := uint(parentID)
+		todo.ParentID = &parent
+	}
+	categoryID, err := t.categoryID(task.Category)
+	if err != nil {
+		return 0, err
+	}
+	todo.CategoryID = categoryID
+
+	if err := t.todos.CreateTodo(&todo); err != nil {
+		return 0, err
+	}
+	return int(todo.ID), nil
+}
+
+func (t *transferTarget) Update(id int, task transfer.Task) error {
+	todo, err := t.repo.Todo.GetByID(uint(id))
+	if err != nil {
+		return err
+	}
+
+	todo.Completed = task.Completed
+	if task.Description != "" {
+		todo.Description = task.Description
+	}
+	if task.Priority != "" {
+		todo.Priority = task.Priority
+	}
+	if task.DueDate != nil {
+		todo.DueDate = task.DueDate
+	}
+	if task.Recurrence != "" {
+		todo.Recurrence = task.Recurrence
+	}
+	if len(task.Reminders) > 0 {
+		todo.Reminders = todoFromTransfer(task).Reminders
+	}
+	if task.Category != "" {
+		if todo.CategoryID, err = t.categoryID(task.Category); err != nil {
+			return err
+		}
+	}
+
+	if err := t.todos.UpdateTodo(todo); err != nil {
+		return err
+	}
+	if len(task.Tags) > 0 {
+		return saveTodoTags(t.repo, todo, task.Tags)
+	}
+	return nil
+}
+
+func (t *transferTarget) SaveCategory(category transfer.Category) error {
+	_, err := t.ensureCategory(category)
+	return err
+}
+
+// categoryID возвращает ID категории по имени, создавая ее при необходимости;
+// пустое имя - без категории
+func (t *transferTarget) categoryID(name string) (*uint, error) {
+	if name == "" {
+		return nil, nil
+	}
+	id, err := t.ensureCategory(transfer.Category{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+// ensureCategory ищет категорию по имени без учета регистра и создает ее, если ее нет
+func (t *transferTarget) ensureCategory(category transfer.Category) (uint, error) {
+	if t.categories == nil {
+		categories, err := t.repo.Category.GetAll()
+		if err != nil {
+			return 0, err
+		}
+		t.categories = make(map[string]uint, len(categories))
+		for _, existing := range categories {
+			t.categories[strings.ToLower(existing.Name)] = existing.ID
+		}
+	}
+
+	key := strings.ToLower(category.Name)
+	if id, ok := t.categories[key]; ok {
+		return id, nil
+	}
+	created := models.Category{Name: category.Name, Color: category.Color}
+	if created.Color == "" {
+		created.Color = DefaultCategoryColor
+	}
+	if err := (&categoryService{repo: t.repo}).CreateCategory(&created); err != nil {
+		return 0, err
+	}
+	t.categories[key] = created.ID
+	return created.ID, nil
+}
+
+// todoFromTransfer преобразует задачу файла обмена в models.Todo без родителя и категории
+func todoFromTransfer(task transfer.Task) models.Todo {
+	todo := models.Todo{
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		Priority:    task.Priority,
+		DueDate:     task.DueDate,
+		Recurrence:  task.Recurrence,
+		Tags:        task.Tags,
+	}
+	for _, offset := range task.Reminders {
+		todo.Reminders = append(todo.Reminders, int64(offset))
+	}
+	return todo
+}
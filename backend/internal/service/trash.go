@@ -0,0 +1,95 @@
+// service/trash.go
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+)
+
+// ErrNotInTrash возвращается при восстановлении задачи или категории, которой нет в корзине
+var ErrNotInTrash = errors.New("не найдено в корзине")
+
+// Правила корзины:
+//   - удаление задачи переносит в корзину ее и все поддерево с одной отметкой времени;
+//   - восстановление возвращает задачу и подзадачи, удаленные вместе с ней; если родитель
+//     еще в корзине, задача становится задачей верхнего уровня;
+//   - удаленная категория скрывается, но остается у своих задач до окончательного удаления;
+//   - PurgeDeletedTodos и PurgeDeletedCategories окончательно удаляют то, что пролежало
+//     в корзине дольше срока хранения.
+
+func (s *todoService) GetDeletedTodos() ([]models.Todo, error) {
+	return s.repo.Todo.GetDeleted()
+}
+
+func (s *todoService) RestoreTodo(id uint) (*models.Todo, error) {
+	return restoreTodo(s.repo, id)
+}
+
+func (s *taskService) GetDeletedTasks() ([]models.Todo, error) {
+	return s.repo.Todo.GetDeleted()
+}
+
+func (s *taskService) RestoreTask(id int) (*models.Todo, error) {
+	if id <= 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	return restoreTodo(s.repo, uint(id))
+}
+
+// restoreTodo возвращает задачу из корзины и открывает предков, если она открыта
+func restoreTodo(repo *repository.Repository, id uint) (*models.Todo, error) {
+	if id == 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	if err := repo.Todo.Restore(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotInTrash
+		}
+		return nil, err
+	}
+
+	todo, err := repo.Todo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := reopenParents(repo, todo); err != nil {
+		return nil, err
+	}
+	return todo, nil
+}
+
+func (s *categoryService) GetDeletedCategories() ([]models.Category, error) {
+	return s.repo.Category.GetDeleted()
+}
+
+func (s *categoryService) RestoreCategory(id uint) (*models.Category, error) {
+	if id == 0 {
+		return nil, errors.New("некорректный ID категории")
+	}
+	if err := s.repo.Category.Restore(id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotInTrash
+		}
+		return nil, err
+	}
+	return s.repo.Category.GetByID(id)
+}
+
+// RemoveTodo окончательно удаляет задачу, минуя корзину; sql.ErrNoRows, если ее нет
+func (s *todoService) RemoveTodo(id uint) error {
+	return s.repo.Todo.Remove(id)
+}
+
+// PurgeDeletedTodos окончательно удаляет задачи, попавшие в корзину раньше before
+func (s *todoService) PurgeDeletedTodos(before time.Time) (int64, error) {
+	return s.repo.Todo.Purge(before)
+}
+
+// PurgeDeletedCategories окончательно удаляет категории, попавшие в корзину раньше before
+func (s *categoryService) PurgeDeletedCategories(before time.Time) (int64, error) {
+	return s.repo.Category.Purge(before)
+}
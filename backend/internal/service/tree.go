@@ -0,0 +1,180 @@
+// service/tree.go
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+)
+
+// Ошибки иерархии задач
+var (
+	ErrParentNotFound = errors.New("родительская задача не найдена")
+	ErrTaskCycle      = errors.New("нельзя переместить задачу внутрь ее собственного поддерева")
+)
+
+// Правила иерархии:
+//   - выполнение задачи отмечает выполненными всех ее потомков;
+//   - открытие задачи (или создание открытой подзадачи) снимает отметку со всех предков,
+//     поэтому выполненный родитель никогда не содержит открытых подзадач;
+//   - удаление задачи переносит в корзину все поддерево (см. trash.go).
+
+// checkParent проверяет, что родительская задача существует
+func checkParent(repo *repository.Repository, parentID *uint) error {
+	if parentID == nil {
+		return nil
+	}
+	if _, err := repo.Todo.GetByID(*parentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrParentNotFound
+		}
+		return err
+	}
+	return nil
+}
+
+// reopenParents открывает предков новой открытой подзадачи
+func reopenParents(repo *repository.Repository, todo *models.Todo) error {
+	if todo.ParentID == nil || todo.Completed {
+		return nil
+	}
+	return repo.Todo.ReopenAncestors(todo.ID)
+}
+
+// saveWithCompletion сохраняет задачу и распространяет смену статуса по иерархии
+func saveWithCompletion(repo *repository.Repository, todo *models.Todo, wasCompleted bool) error {
+	if err := repo.Todo.Update(todo); err != nil {
+		return err
+	}
+	if todo.Completed == wasCompleted {
+		return nil
+	}
+	if todo.Completed {
+		return repo.Todo.SetSubtreeCompleted(todo.ID, true)
+	}
+	return repo.Todo.ReopenAncestors(todo.ID)
+}
+
+// getTree возвращает дерево задачи rootID или лес всех задач, если rootID == 0
+func getTree(repo *repository.Repository, rootID uint) ([]*models.TodoNode, error) {
+	var todos []models.Todo
+	var err error
+	if rootID == 0 {
+		todos, err = repo.Todo.GetAll()
+	} else {
+		todos, err = repo.Todo.GetSubtree(rootID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if rootID != 0 && len(todos) == 0 {
+		return nil, fmt.Errorf("задача не найдена: %w", sql.ErrNoRows)
+	}
+	return buildTree(todos, rootID), nil
+}
+
+// buildTree собирает узлы в дерево и считает прогресс по всем потомкам.
+// Корнями считаются rootID либо, если он 0, задачи без родителя.
+func buildTree(todos []models.Todo, rootID uint) []*models.TodoNode {
+	nodes := make(map[uint]*models.TodoNode, len(todos))
+	for _, todo := range todos {
+		nodes[todo.ID] = &models.TodoNode{Todo: todo, Children: []*models.TodoNode{}}
+	}
+
+	roots := []*models.TodoNode{}
+	for _, todo := range todos {
+		node := nodes[todo.ID]
+		isRoot := todo.ID == rootID || (rootID == 0 && todo.ParentID == nil)
+		if isRoot {
+			roots = append(roots, node)
+			continue
+		}
+		if todo.ParentID != nil {
+			if parent, ok := nodes[*todo.ParentID]; ok {
+				parent.Children = append(parent.Children, node)
+			}
+		}
+	}
+
+	for _, root := range roots {
+		computeProgress(root)
+	}
+	return roots
+}
+
+// computeProgress заполняет Progress узла и возвращает его для родителя
+func computeProgress(node *models.TodoNode) models.Progress {
+	var progress models.Progress
+	for _, child := range node.Children {
+		childProgress := computeProgress(child)
+		progress.Total += childProgress.Total + 1
+		progress.Done += childProgress.Done
+		if child.Completed {
+			progress.Done++
+		}
+	}
+	node.Progress = progress
+	return progress
+}
+
+// moveTodo переносит задачу под нового родителя (nil - на верхний уровень)
+func moveTodo(repo *repository.Repository, id uint, parentID *uint) error {
+	if id == 0 {
+		return errors.New("некорректный ID задачи")
+	}
+	todo, err := repo.Todo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("задача не найдена: %w", err)
+	}
+	if err := checkParent(repo, parentID); err != nil {
+		return err
+	}
+
+	if err := repo.Todo.Move(id, parentID); err != nil {
+		if errors.Is(err, repository.ErrCycle) {
+			return ErrTaskCycle
+		}
+		return err
+	}
+
+	todo.ParentID = parentID
+	return reopenParents(repo, todo)
+}
+
+func (s *todoService) GetTodoTree(rootID uint) ([]*models.TodoNode, error) {
+	return getTree(s.repo, rootID)
+}
+
+// GetTodoSubtree возвращает задачу и всех ее потомков списком; пустой, если задачи нет
+func (s *todoService) GetTodoSubtree(id uint) ([]models.Todo, error) {
+	return s.repo.Todo.GetSubtree(id)
+}
+
+func (s *todoService) MoveTodo(id uint, parentID *uint) error {
+	return moveTodo(s.repo, id, parentID)
+}
+
+func (s *todoService) GetTodoProgress(id uint) (models.Progress, error) {
+	roots, err := getTree(s.repo, id)
+	if err != nil {
+		return models.Progress{}, err
+	}
+	return roots[0].Progress, nil
+}
+
+func (s *taskService) GetTaskTree(id int) ([]*models.TodoNode, error) {
+	if id < 0 {
+		return nil, errors.New("некорректный ID задачи")
+	}
+	return getTree(s.repo, uint(id))
+}
+
+func (s *taskService) MoveTask(id int, parentID *uint) error {
+	if id <= 0 {
+		return errors.New("некорректный ID задачи")
+	}
+	return moveTodo(s.repo, uint(id), parentID)
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"testing"
+
+	"todo-list/backend/internal/models"
+)
+
+func TestBuildTree(t *testing.T) {
+	parent := func(id uint) *uint { return &id }
+	todos := []models.Todo{
+		{ID: 1, Title: "Ремонт"},
+		{ID: 2, Title: "Кухня", ParentID: parent(1), Completed: true},
+		{ID: 3, Title: "Плитка", ParentID: parent(2), Completed: true},
+		{ID: 4, Title: "Ванная", ParentID: parent(1)},
+		{ID: 5, Title: "Отдельная"},
+	}
+
+	roots := buildTree(todos, 0)
+	if len(roots) != 2 || roots[0].ID != 1 || roots[1].ID != 5 {
+		t.Fatalf("roots = %+v", roots)
+	}
+	if got := roots[0].Progress; got != (models.Progress{Done: 2, Total: 3}) {
+		t.Errorf("root progress = %+v", got)
+	}
+	if kitchen := roots[0].Children[0]; kitchen.ID != 2 || kitchen.Progress != (models.Progress{Done: 1, Total: 1}) {
+		t.Errorf("kitchen = %+v", kitchen)
+	}
+	if roots[1].Children == nil || len(roots[1].Children) != 0 {
+		t.Errorf("leaf children = %v, want an empty list", roots[1].Children)
+	}
+
+	// Поддерево задачи: корень - сама задача, хотя у нее есть родитель
+	sub := buildTree(todos[1:3], 2)
+	if len(sub) != 1 || sub[0].ID != 2 || len(sub[0].Children) != 1 {
+		t.Errorf("subtree = %+v", sub)
+	}
+}
@@ -0,0 +1,323 @@
+// Package todotxt разбирает и записывает строки формата todo.txt
+// (https://github.com/todotxt/todo.txt): "x 2026-10-17 2026-10-01 (A) Текст +проект @контекст due:2026-10-20"
+package todotxt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DateFormat формат дат todo.txt
+const DateFormat = "2006-01-02"
+
+// Task строка todo.txt
+type Task struct {
+	Completed      bool
+	Priority       string    // буква A-Z, пусто - без приоритета
+	CompletionDate time.Time // нулевая - не указана
+	CreationDate   time.Time // нулевая - не указана
+	Text           string    // текст без проектов, контекстов и расширений
+	Projects       []string  // +проект без знака
+	Contexts       []string  // @контекст без знака
+	Extensions     []Extension
+}
+
+// Extension расширение вида key:value
+type Extension struct {
+	Key   string
+	Value string
+}
+
+var (
+	priorityToken = regexp.MustCompile(`^\(([A-Z])\)$`)
+	extensionKey  = regexp.MustCompile(`^[^\s:]+$`)
+)
+
+// Parse разбирает строку; ok - false для пустой строки
+func Parse(line string) (task Task, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return Task{}, false
+	}
+
+	if fields[0] == "x" {
+		task.Completed = true
+		fields = fields[1:]
+		if len(fields) > 0 {
+			if date, err := time.ParseInLocation(DateFormat, fields[0], time.Local); err == nil {
+				task.CompletionDate = date
+				fields = fields[1:]
+			}
+		}
+	}
+	if len(fields) > 0 {
+		if m := priorityToken.FindStringSubmatch(fields[0]); m != nil {
+			task.Priority = m[1]
+			fields = fields[1:]
+		}
+	}
+	if len(fields) > 0 {
+		if date, err := time.ParseInLocation(DateFormat, fields[0], time.Local); err == nil {
+			task.CreationDate = date
+			fields = fields[1:]
+		}
+	}
+	// "x 2026-10-17 Текст": единственная дата выполненной задачи - дата выполнения
+	if task.Completed && task.CompletionDate.IsZero() && !task.CreationDate.IsZero() {
+		task.CompletionDate, task.CreationDate = task.CreationDate, time.Time{}
+	}
+
+	var text []string
+	for _, field := range fields {
+		switch {
+		case len(field) > 1 && field[0] == '+':
+			task.Projects = append(task.Projects, field[1:])
+		case len(field) > 1 && field[0] == '@':
+			task.Contexts = append(task.Contexts, field[1:])
+		default:
+			if key, value, found := strings.Cut(field, ":"); found && isExtension(key, value) {
+				task.Extensions = append(task.Extensions, Extension{Key: key, Value: value})
+				continue
+			}
+			text = append(text, field)
+		}
+	}
+	task.Text = strings.Join(text, " ")
+	return task, true
+}
+
+// isExtension отличает расширение key:value от обычного текста; ссылки (https://...) - текст
+func isExtension(key, value string) bool {
+	return key != "" && value != "" && extensionKey.MatchString(key) && !strings.HasPrefix(value, "//")
+}
+
+// String записывает задачу строкой todo.txt. Дата создания выполненной задачи записывается
+// только вместе с датой выполнения, как требует формат.
+func (t Task) String() string {
+	var parts []string
+	if t.Completed {
+		parts = append(parts, "x")
+		if !t.CompletionDate.IsZero() {
+			parts = append(parts, t.CompletionDate.Format(DateFormat))
+		}
+	}
+	if t.Priority != "" {
+		parts = append(parts, "("+t.Priority+")")
+	}
+	if !t.CreationDate.IsZero() && (!t.Completed || !t.CompletionDate.IsZero()) {
+		parts = append(parts, t.CreationDate.Format(DateFormat))
+	}
+	if text := strings.Join(strings.Fields(t.Text), " "); text != "" {
+		parts = append(parts, text)
+	}
+	for _, project := range t.Projects {
+		parts = append(parts, "+"+project)
+	}
+	for _, context := range t.Contexts {
+		parts = append(parts, "@"+context)
+	}
+	for _, ext := range t.Extensions {
+		parts = append(parts, ext.Key+":"+ext.Value)
+	}
+	return strings.Join(parts, " ")
+}
+
+// Get возвращает значение первого расширения key
+func (t Task) Get(key string) string {
+	for _, ext := range t.Extensions {
+		if ext.Key == key {
+			return ext.Value
+		}
+	}
+	return ""
+}
+
+// All возвращает значения всех расширений key
+func (t Task) All(key string) []string {
+	var values []string
+	for _, ext := range t.Extensions {
+		if ext.Key == key {
+			values = append(values, ext.Value)
+		}
+	}
+	return values
+}
+
+// Set добавляет расширение; пустое значение не записывается
+func (t *Task) Set(key, value string) {
+	if value != "" {
+		t.Extensions = append(t.Extensions, Extension{Key: key, Value: value})
+	}
+}
+
+// ReadAll читает все непустые строки файла
+func ReadAll(r io.Reader) ([]Task, error) {
+	var tasks []Task
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if task, ok := Parse(scanner.Text()); ok {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks, scanner.Err()
+}
+
+// WriteAll записывает задачи по одной на строку
+func WriteAll(w io.Writer, tasks []Task) error {
+	bw := bufio.NewWriter(w)
+	for _, task := range tasks {
+		bw.WriteString(task.String())
+		bw.WriteByte('\n')
+	}
+	return bw.Flush()
+}
+
+// PriorityName переводит приоритет todo.txt в low/medium/high: A - high, B - medium,
+// C и ниже - low; без приоритета - пустая строка
+func PriorityName(letter string) string {
+	switch letter {
+	case "":
+		return ""
+	case "A":
+		return "high"
+	case "B":
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+// PriorityLetter переводит low/medium/high в приоритет todo.txt
+func PriorityLetter(priority string) string {
+	switch priority {
+	case "high":
+		return "A"
+	case "medium":
+		return "B"
+	case "low":
+		return "C"
+	}
+	return ""
+}
+
+// Rank возвращает приоритет задачи как low/medium/high; у выполненной задачи
+// приоритет по соглашению todo.txt хранится в расширении pri:
+func (t Task) Rank() string {
+	if t.Priority != "" {
+		return PriorityName(t.Priority)
+	}
+	return PriorityName(strings.ToUpper(t.Get("pri")))
+}
+
+// SetRank записывает приоритет low/medium/high; выполненной задаче - расширением pri:,
+// чтобы строка начиналась с "x"
+func (t *Task) SetRank(priority string) {
+	letter := PriorityLetter(priority)
+	if t.Completed {
+		t.Set("pri", letter)
+		return
+	}
+	t.Priority = letter
+}
+
+// ParseDue разбирает срок due:2026-10-20 или due:2026-10-20T15:04 (местное время)
+func ParseDue(value string) (time.Time, error) {
+	if due, err := time.ParseInLocation("2006-01-02T15:04", value, time.Local); err == nil {
+		return due, nil
+	}
+	due, err := time.ParseInLocation(DateFormat, value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("некорректный срок due:%s", value)
+	}
+	return due, nil
+}
+
+// FormatDue записывает срок; время указывается, только если это не полночь
+func FormatDue(due time.Time) string {
+	due = due.Local()
+	if due.Hour() == 0 && due.Minute() == 0 {
+		return due.Format(DateFormat)
+	}
+	return due.Format("2006-01-02T15:04")
+}
+
+var recUnits = map[string]string{"d": "DAILY", "w": "WEEKLY", "m": "MONTHLY", "y": "YEARLY"}
+
+// Recurrence собирает правило повторения из расширений rrule:, exdate: и распространенного
+// сокращения rec: (rec:1w, rec:+2d - каждые 2 дня)
+func (t Task) Recurrence() (string, error) {
+	rule := t.Get("rrule")
+	if rec := t.Get("rec"); rule == "" && rec != "" {
+		rec = strings.TrimPrefix(rec, "+")
+		if rec == "" {
+			return "", fmt.Errorf("некорректное повторение rec:%s", t.Get("rec"))
+		}
+		freq, ok := recUnits[strings.ToLower(rec[len(rec)-1:])]
+		interval := 1
+		if n := rec[:len(rec)-1]; n != "" {
+			var err error
+			interval, err = strconv.Atoi(n)
+			ok = ok && err == nil && interval > 0
+		}
+		if !ok {
+			return "", fmt.Errorf("некорректное повторение rec:%s", t.Get("rec"))
+		}
+		rule = "FREQ=" + freq
+		if interval > 1 {
+			rule += ";INTERVAL=" + strconv.Itoa(interval)
+		}
+	}
+	if rule == "" {
+		return "", nil
+	}
+	if exdates := t.All("exdate"); len(exdates) > 0 {
+		rule += "\nEXDATE:" + strings.Join(exdates, ",")
+	}
+	return rule, nil
+}
+
+// SetRecurrence записывает правило повторения: простое правило - сокращением rec:,
+// остальные - расширениями rrule: и exdate:
+func (t *Task) SetRecurrence(rule string) {
+	if rule == "" {
+		return
+	}
+	line, exdate, _ := strings.Cut(rule, "\n")
+	line = strings.TrimPrefix(strings.TrimSpace(line), "RRULE:")
+	exdate = strings.TrimPrefix(strings.TrimSpace(exdate), "EXDATE:")
+
+	if rec, ok := recShorthand(line); ok && exdate == "" {
+		t.Set("rec", rec)
+		return
+	}
+	t.Set("rrule", line)
+	t.Set("exdate", exdate)
+}
+
+// recShorthand переводит правило вида FREQ=WEEKLY;INTERVAL=2 в сокращение 2w
+func recShorthand(rule string) (string, bool) {
+	freq, interval := "", "1"
+	for _, part := range strings.Split(rule, ";") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "FREQ":
+			freq = value
+		case "INTERVAL":
+			interval = value
+		default:
+			return "", false
+		}
+	}
+	for unit, name := range recUnits {
+		if name == freq {
+			return interval + unit, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,125 @@
+package todotxt
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	task, ok := Parse("x 2026-10-17 2026-10-01 Позвонить маме +семья @телефон due:2026-10-20 https://example.com")
+	if !ok {
+		t.Fatal("line not parsed")
+	}
+	if !task.Completed || task.CompletionDate.Format(DateFormat) != "2026-10-17" || task.CreationDate.Format(DateFormat) != "2026-10-01" {
+		t.Errorf("dates = %+v", task)
+	}
+	if task.Text != "Позвонить маме https://example.com" {
+		t.Errorf("text = %q", task.Text)
+	}
+	if !reflect.DeepEqual(task.Projects, []string{"семья"}) || !reflect.DeepEqual(task.Contexts, []string{"телефон"}) {
+		t.Errorf("projects = %v, contexts = %v", task.Projects, task.Contexts)
+	}
+	if task.Get("due") != "2026-10-20" {
+		t.Errorf("due = %q", task.Get("due"))
+	}
+
+	// Единственная дата выполненной задачи - дата выполнения
+	task, _ = Parse("x 2026-10-17 Готово")
+	if task.CompletionDate.IsZero() || !task.CreationDate.IsZero() {
+		t.Errorf("single date: %+v", task)
+	}
+	// "x" в середине строки и одиночные знаки - обычный текст
+	task, _ = Parse("(B) Купить x + @ хлеб")
+	if task.Completed || task.Priority != "B" || task.Text != "Купить x + @ хлеб" {
+		t.Errorf("plain text: %+v", task)
+	}
+	if _, ok := Parse("   "); ok {
+		t.Error("blank line parsed")
+	}
+}
+
+func TestStringRoundTrip(t *testing.T) {
+	lines := []string{
+		"(A) 2026-10-01 Отчет +work @office due:2026-10-20 id:1",
+		"x 2026-10-17 2026-10-01 Готово pri:C",
+		"Подзадача parent:1 rec:2w",
+	}
+	for _, line := range lines {
+		task, _ := Parse(line)
+		if got := task.String(); got != line {
+			t.Errorf("String() = %q, want %q", got, line)
+		}
+	}
+
+	// Дата создания выполненной задачи без даты выполнения не записывается
+	task := Task{Completed: true, Text: "Готово", CreationDate: time.Date(2026, 10, 1, 0, 0, 0, 0, time.Local)}
+	if got := task.String(); got != "x Готово" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestRank(t *testing.T) {
+	for _, priority := range []string{"high", "medium", "low"} {
+		open := Task{}
+		open.SetRank(priority)
+		done := Task{Completed: true}
+		done.SetRank(priority)
+		if open.Rank() != priority || done.Rank() != priority {
+			t.Errorf("%s: open %q, completed %q", priority, open.Rank(), done.Rank())
+		}
+		if done.Priority != "" {
+			t.Errorf("completed task got priority %q instead of pri:", done.Priority)
+		}
+	}
+	if PriorityName("D") != "low" || PriorityName("") != "" {
+		t.Error("PriorityName fallback")
+	}
+}
+
+func TestRecurrence(t *testing.T) {
+	tests := []struct {
+		line, want string
+	}{
+		{"a rec:1w", "FREQ=WEEKLY"},
+		{"a rec:m", "FREQ=MONTHLY"},
+		{"a rec:+3d", "FREQ=DAILY;INTERVAL=3"},
+		{"a rrule:FREQ=MONTHLY;BYMONTHDAY=-1 exdate:20261231", "FREQ=MONTHLY;BYMONTHDAY=-1\nEXDATE:20261231"},
+		{"a", ""},
+	}
+	for _, tt := range tests {
+		task, _ := Parse(tt.line)
+		got, err := task.Recurrence()
+		if err != nil || got != tt.want {
+			t.Errorf("Recurrence(%q) = %q, %v; want %q", tt.line, got, err, tt.want)
+		}
+
+		// SetRecurrence записывает правило обратно в то же расширение
+		var written Task
+		written.SetRecurrence(got)
+		if again, _ := written.Recurrence(); again != tt.want {
+			t.Errorf("SetRecurrence(%q) read back as %q", got, again)
+		}
+	}
+
+	for _, line := range []string{"a rec:+", "a rec:0d", "a rec:2q"} {
+		task, _ := Parse(line)
+		if _, err := task.Recurrence(); err == nil {
+			t.Errorf("Recurrence(%q) accepted", line)
+		}
+	}
+}
+
+func TestDue(t *testing.T) {
+	due, err := ParseDue("2026-10-20T15:04")
+	if err != nil || due.Hour() != 15 || FormatDue(due) != "2026-10-20T15:04" {
+		t.Errorf("ParseDue with time = %v, %v", due, err)
+	}
+	due, err = ParseDue("2026-10-20")
+	if err != nil || FormatDue(due) != "2026-10-20" {
+		t.Errorf("ParseDue date = %v, %v", due, err)
+	}
+	if _, err := ParseDue("завтра"); err == nil {
+		t.Error("ParseDue accepted text")
+	}
+}
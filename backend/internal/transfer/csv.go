@@ -0,0 +1,203 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// encodeCSV записывает задачи таблицей с заголовком; метки и напоминания - через запятую
+func encodeCSV(w io.Writer, doc Document, opts Options) error {
+	cw := csv.NewWriter(w)
+
+	header := make([]string, len(Fields))
+	for i, field := range Fields {
+		header[i] = columnName(field, opts)
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, task := range doc.Tasks {
+		parent := ""
+		if task.ParentID != 0 {
+			parent = strconv.Itoa(task.ParentID)
+		}
+
+		record := []string{
+			strconv.Itoa(task.ID),
+			parent,
+			task.Title,
+			task.Description,
+			strconv.FormatBool(task.Completed),
+			task.Priority,
+			formatTime(task.DueDate),
+			task.Category,
+			strings.Join(task.Tags, ","),
+			task.Recurrence,
+			joinInts(task.Reminders),
+			formatTime(task.CreatedAt),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// decodeCSV читает таблицу с заголовком. Колонки ищутся без учета регистра по именам полей
+// или по сопоставлению opts.Columns; лишние колонки пропускаются, колонка title обязательна.
+// Разделитель (запятая или точка с запятой, как в Excel) определяется по заголовку.
+func decodeCSV(r io.Reader, opts Options) (Document, error) {
+	br := bufio.NewReader(r)
+	first, err := br.Peek(4096)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return Document{}, err
+	}
+	first = bytes.TrimPrefix(first, []byte(bom))
+	header, _, _ := bytes.Cut(first, []byte("\n"))
+	if len(bytes.TrimSpace(header)) == 0 {
+		return Document{}, errors.New("пустой CSV-файл")
+	}
+
+	cr := csv.NewReader(skipBOM(br))
+	if bytes.Count(header, []byte(";")) > bytes.Count(header, []byte(",")) {
+		cr.Comma = ';'
+	}
+	cr.FieldsPerRecord = -1
+
+	columns, err := cr.Read()
+	if err != nil {
+		return Document{}, fmt.Errorf("не удалось прочитать заголовок CSV: %w", err)
+	}
+	index := map[string]int{}
+	for _, field := range Fields {
+		name := strings.ToLower(columnName(field, opts))
+		for i, column := range columns {
+			if strings.ToLower(strings.TrimSpace(column)) == name {
+				index[field] = i
+				break
+			}
+		}
+	}
+	if _, ok := index["title"]; !ok {
+		return Document{}, fmt.Errorf("в CSV нет колонки %q", columnName("title", opts))
+	}
+
+	var doc Document
+	for {
+		record, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return Document{}, err
+		}
+		line, _ := cr.FieldPos(0)
+
+		value := func(field string) string {
+			if i, ok := index[field]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		if value("title") == "" && strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue // пустая строка таблицы
+		}
+
+		task, err := csvTask(value)
+		if err != nil {
+			return Document{}, fmt.Errorf("строка %d: %w", line, err)
+		}
+		doc.Tasks = append(doc.Tasks, task)
+	}
+	return doc, nil
+}
+
+// csvTask собирает задачу из значений колонок
+func csvTask(value func(field string) string) (Task, error) {
+	task := Task{
+		Title:       value("title"),
+		Description: value("description"),
+		Priority:    strings.ToLower(value("priority")),
+		Category:    value("category"),
+		Tags:        splitList(value("tags")),
+		Recurrence:  value("recurrence"),
+	}
+
+	var err error
+	if task.ID, err = parseInt(value("id")); err != nil {
+		return task, err
+	}
+	if task.ParentID, err = parseInt(value("parent_id")); err != nil {
+		return task, err
+	}
+	if task.Completed, err = parseBool(value("completed")); err != nil {
+		return task, err
+	}
+	if task.DueDate, err = parseTime(value("due_date")); err != nil {
+		return task, err
+	}
+	if task.CreatedAt, err = parseTime(value("created_at")); err != nil {
+		return task, err
+	}
+	if task.Reminders, err = splitInts(value("reminders")); err != nil {
+		return task, err
+	}
+	return task, nil
+}
+
+// columnName возвращает заголовок колонки поля с учетом сопоставления
+func columnName(field string, opts Options) string {
+	if name, ok := opts.Columns[field]; ok {
+		return name
+	}
+	return field
+}
+
+func parseInt(s string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("некорректное число %q", s)
+	}
+	return n, nil
+}
+
+// parseBool понимает true/false, 1/0, yes/no, да/нет и x (отметка в таблице)
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "", "false", "0", "no", "n", "нет":
+		return false, nil
+	case "true", "1", "yes", "y", "x", "да", "done":
+		return true, nil
+	}
+	return false, fmt.Errorf("некорректное значение статуса %q", s)
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil || t.IsZero() {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
+
+// bom метка порядка байтов UTF-8, которую Excel добавляет в начало CSV
+const bom = "\uFEFF"
+
+// skipBOM пропускает метку порядка байтов в начале файла
+func skipBOM(br *bufio.Reader) io.Reader {
+	if r, _, err := br.ReadRune(); err == nil && string(r) != bom {
+		br.UnreadRune()
+	}
+	return br
+}
@@ -0,0 +1,352 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-list/backend/internal/ical"
+)
+
+// ProductID значение PRODID календарей, которые пишет приложение
+const ProductID = "-//todo-list//todo-list//RU"
+
+// Свойства iCalendar, которые пишет экспорт
+const (
+	icsUIDSuffix = "@todo-list"
+	// icsCategory категория задачи; CATEGORIES в iCalendar - это метки
+	icsCategory = "X-TODO-LIST-CATEGORY"
+)
+
+// Приоритеты iCalendar: 1-4 - высокий, 5 - средний, 6-9 - низкий, 0 - не задан
+var icsPriorities = map[string]string{"high": "1", "medium": "5", "low": "9"}
+
+// encodeICS записывает задачи компонентами VTODO. Срок в полночь по местному времени
+// записывается датой без времени, остальные - в UTC; напоминания - компонентами VALARM
+// относительно срока, подзадачи ссылаются на родителя через RELATED-TO.
+func encodeICS(w io.Writer, doc Document) error {
+	now := ical.FormatUTC(time.Now())
+	cal := ical.NewComponent("VCALENDAR")
+	cal.Add("VERSION", "2.0")
+	cal.Add("PRODID", ProductID)
+	cal.Add("CALSCALE", "GREGORIAN")
+	for _, task := range doc.Tasks {
+		cal.AddChild(VTodo(task, now))
+	}
+	return ical.Encode(w, cal)
+}
+
+// VTodo переводит задачу в компонент VTODO; stamp - значение DTSTAMP
+func VTodo(task Task, stamp string) *ical.Component {
+	todo := ical.NewComponent("VTODO")
+	todo.Add("UID", TaskUID(task.ID))
+	todo.Add("DTSTAMP", stamp)
+	if task.CreatedAt != nil {
+		todo.Add("CREATED", ical.FormatUTC(*task.CreatedAt))
+	}
+	todo.AddText("SUMMARY", task.Title)
+	if task.Description != "" {
+		todo.AddText("DESCRIPTION", task.Description)
+	}
+	if priority, ok := icsPriorities[task.Priority]; ok {
+		todo.Add("PRIORITY", priority)
+	}
+	if task.Completed {
+		todo.Add("STATUS", "COMPLETED")
+		todo.Add("PERCENT-COMPLETE", "100")
+	} else {
+		todo.Add("STATUS", "NEEDS-ACTION")
+	}
+	if task.Category != "" {
+		todo.AddText(icsCategory, task.Category)
+	}
+	if len(task.Tags) > 0 {
+		todo.AddTextList("CATEGORIES", task.Tags)
+	}
+	if task.ParentID != 0 {
+		todo.Add("RELATED-TO", TaskUID(task.ParentID), "RELTYPE", "PARENT")
+	}
+
+	if task.DueDate != nil {
+		due := task.DueDate.Local()
+		if due.Equal(startOfDay(due)) {
+			todo.Add("DUE", ical.FormatDate(due), "VALUE", "DATE")
+		} else {
+			todo.Add("DUE", ical.FormatUTC(due))
+		}
+		if task.Recurrence != "" {
+			// Повторение отсчитывается от DTSTART: он совпадает со сроком
+			start := todo.Prop("DUE")
+			todo.Properties = append(todo.Properties, ical.Property{Name: "DTSTART", Params: start.Params, Value: start.Value})
+			addRecurrence(todo, task.Recurrence)
+		}
+		addAlarms(todo, task, "END")
+	}
+	return todo
+}
+
+// VEvent переводит срок задачи в событие для календарей, которые не показывают VTODO.
+// Срок-дата становится событием на весь день, срок со временем - событием длиной
+// DueEventDuration; task.DueDate должен быть задан.
+func VEvent(task Task, stamp string) *ical.Component {
+	event := ical.NewComponent("VEVENT")
+	event.Add("UID", strconv.Itoa(task.ID)+"-due"+icsUIDSuffix)
+	event.Add("DTSTAMP", stamp)
+	event.AddText("SUMMARY", task.Title)
+	if task.Description != "" {
+		event.AddText("DESCRIPTION", task.Description)
+	}
+	if len(task.Tags) > 0 {
+		event.AddTextList("CATEGORIES", task.Tags)
+	}
+	event.Add("TRANSP", "TRANSPARENT")
+
+	due := task.DueDate.Local()
+	if due.Equal(startOfDay(due)) {
+		event.Add("DTSTART", ical.FormatDate(due), "VALUE", "DATE")
+		event.Add("DTEND", ical.FormatDate(due.AddDate(0, 0, 1)), "VALUE", "DATE")
+	} else {
+		event.Add("DTSTART", ical.FormatUTC(due))
+		event.Add("DTEND", ical.FormatUTC(due.Add(DueEventDuration)))
+	}
+	if task.Recurrence != "" {
+		addRecurrence(event, task.Recurrence)
+	}
+	addAlarms(event, task, "START")
+	return event
+}
+
+// DueEventDuration длина события, которым VEvent показывает срок со временем
+const DueEventDuration = 30 * time.Minute
+
+// addAlarms добавляет напоминания задачи; related - START или END, от чего отсчитывается срок
+func addAlarms(c *ical.Component, task Task, related string) {
+	for _, offset := range task.Reminders {
+		alarm := ical.NewComponent("VALARM")
+		alarm.Add("ACTION", "DISPLAY")
+		alarm.AddText("DESCRIPTION", task.Title)
+		alarm.Add("TRIGGER", ical.FormatDuration(-time.Duration(offset)*time.Minute), "RELATED", related)
+		c.AddChild(alarm)
+	}
+}
+
+// TaskUID возвращает UID задачи в iCalendar
+func TaskUID(id int) string {
+	return strconv.Itoa(id) + icsUIDSuffix
+}
+
+// ParseTaskUID возвращает номер задачи из UID, записанного TaskUID
+func ParseTaskUID(uid string) (int, bool) {
+	number, found := strings.CutSuffix(uid, icsUIDSuffix)
+	if !found {
+		return 0, false
+	}
+	id, err := strconv.Atoi(number)
+	return id, err == nil && id > 0
+}
+
+// addRecurrence записывает правило "RRULE:...\nEXDATE:..." свойствами RRULE и EXDATE
+func addRecurrence(c *ical.Component, rule string) {
+	for _, line := range strings.Split(rule, "\n") {
+		name, value, found := strings.Cut(strings.TrimSpace(line), ":")
+		if !found {
+			name, value = "RRULE", name
+		}
+		if value != "" {
+			c.Add(strings.ToUpper(name), value)
+		}
+	}
+}
+
+// decodeICS читает компоненты VTODO всех календарей файла. Категория задачи берется из
+// X-TODO-LIST-CATEGORY, а если его нет - из имени календаря (X-WR-CALNAME); CATEGORIES -
+// метки, пробелы в них заменяются на "_". Подзадачи восстанавливаются по RELATED-TO.
+func decodeICS(r io.Reader) (Document, error) {
+	cals, err := ical.Decode(r)
+	if err != nil {
+		return Document{}, err
+	}
+
+	var doc Document
+	ids := map[string]int{}     // UID -> номер задачи в документе
+	parents := map[int]string{} // номер задачи -> UID родителя
+	for _, cal := range cals {
+		zones := ical.CalendarZones(cal)
+		calName := cal.Text("X-WR-CALNAME")
+		for _, todo := range cal.Children("VTODO") {
+			task, err := TaskFromVTodo(todo, zones)
+			if err != nil {
+				return Document{}, fmt.Errorf("задача %q: %w", todo.Text("SUMMARY"), err)
+			}
+			task.ID = len(doc.Tasks) + 1
+			if task.Category == "" {
+				task.Category = calName
+			}
+			if uid := todo.Value("UID"); uid != "" {
+				ids[uid] = task.ID
+			}
+			if parent := ParentUID(todo); parent != "" {
+				parents[task.ID] = parent
+			}
+			doc.Tasks = append(doc.Tasks, task)
+		}
+	}
+	for i, task := range doc.Tasks {
+		doc.Tasks[i].ParentID = ids[parents[task.ID]]
+	}
+	return doc, nil
+}
+
+// ParentUID возвращает UID родителя из RELATED-TO (RELTYPE=PARENT по умолчанию)
+func ParentUID(todo *ical.Component) string {
+	for _, prop := range todo.Props("RELATED-TO") {
+		if reltype := prop.Param("RELTYPE"); reltype == "" || strings.EqualFold(reltype, "PARENT") {
+			return ical.UnescapeText(prop.Value)
+		}
+	}
+	return ""
+}
+
+// TaskFromVTodo переводит компонент VTODO в задачу без номера и родителя
+func TaskFromVTodo(todo *ical.Component, zones ical.Zones) (Task, error) {
+	task := Task{
+		Title:       strings.TrimSpace(todo.Text("SUMMARY")),
+		Description: todo.Text("DESCRIPTION"),
+		Category:    todo.Text(icsCategory),
+		Completed: strings.EqualFold(todo.Value("STATUS"), "COMPLETED") ||
+			todo.Prop("COMPLETED") != nil || todo.Value("PERCENT-COMPLETE") == "100",
+	}
+	if priority, err := strconv.Atoi(todo.Value("PRIORITY")); err == nil {
+		switch {
+		case priority >= 1 && priority <= 4:
+			task.Priority = "high"
+		case priority == 5:
+			task.Priority = "medium"
+		case priority >= 6 && priority <= 9:
+			task.Priority = "low"
+		}
+	}
+	for _, tag := range todo.TextList("CATEGORIES") {
+		task.Tags = append(task.Tags, strings.Join(strings.Fields(tag), "_"))
+	}
+
+	if prop := todo.Prop("CREATED"); prop != nil {
+		if created, _, err := zones.Time(prop); err == nil {
+			task.CreatedAt = &created
+		}
+	}
+
+	due, err := vtodoDue(todo, zones)
+	if err != nil {
+		return Task{}, err
+	}
+	if due == nil {
+		return task, nil
+	}
+	task.DueDate = due
+
+	if task.Recurrence, err = vtodoRecurrence(todo, zones); err != nil {
+		return Task{}, err
+	}
+	for _, alarm := range todo.Children("VALARM") {
+		if offset, ok := alarmOffset(alarm, todo, zones, *due); ok {
+			task.Reminders = append(task.Reminders, offset)
+		}
+	}
+	return task, nil
+}
+
+// vtodoDue возвращает срок: DUE, а если его нет - DTSTART + DURATION
+func vtodoDue(todo *ical.Component, zones ical.Zones) (*time.Time, error) {
+	if prop := todo.Prop("DUE"); prop != nil {
+		due, _, err := zones.Time(prop)
+		if err != nil {
+			return nil, err
+		}
+		return &due, nil
+	}
+	start, duration := todo.Prop("DTSTART"), todo.Value("DURATION")
+	if start == nil || duration == "" {
+		return nil, nil
+	}
+	due, _, err := zones.Time(start)
+	if err != nil {
+		return nil, err
+	}
+	d, err := ical.ParseDuration(duration)
+	if err != nil {
+		return nil, err
+	}
+	due = due.Add(d)
+	return &due, nil
+}
+
+// vtodoRecurrence собирает правило из RRULE и EXDATE; даты пропусков переводятся в UTC
+func vtodoRecurrence(todo *ical.Component, zones ical.Zones) (string, error) {
+	rrule := todo.Value("RRULE")
+	if rrule == "" {
+		return "", nil
+	}
+	rule := "RRULE:" + rrule
+
+	var exdates []string
+	for _, prop := range todo.Props("EXDATE") {
+		times, allDay, err := zones.Times(&prop)
+		if err != nil {
+			return "", err
+		}
+		for _, t := range times {
+			if allDay {
+				exdates = append(exdates, ical.FormatDate(t))
+			} else {
+				exdates = append(exdates, ical.FormatUTC(t))
+			}
+		}
+	}
+	if len(exdates) > 0 {
+		rule += "\nEXDATE:" + strings.Join(exdates, ",")
+	}
+	return rule, nil
+}
+
+// alarmOffset переводит TRIGGER напоминания в минуты до срока. Напоминания после срока
+// и с некорректным TRIGGER пропускаются.
+func alarmOffset(alarm, todo *ical.Component, zones ical.Zones, due time.Time) (int, bool) {
+	trigger := alarm.Prop("TRIGGER")
+	if trigger == nil {
+		return 0, false
+	}
+
+	var at time.Time
+	if strings.EqualFold(trigger.Param("VALUE"), "DATE-TIME") {
+		t, _, err := zones.Time(trigger)
+		if err != nil {
+			return 0, false
+		}
+		at = t
+	} else {
+		d, err := ical.ParseDuration(trigger.Value)
+		if err != nil {
+			return 0, false
+		}
+		base := due
+		if !strings.EqualFold(trigger.Param("RELATED"), "END") {
+			if start := todo.Prop("DTSTART"); start != nil {
+				if t, _, err := zones.Time(start); err == nil {
+					base = t
+				}
+			}
+		}
+		at = base.Add(d)
+	}
+
+	offset := int(due.Sub(at) / time.Minute)
+	return offset, offset >= 0
+}
+
+// startOfDay возвращает полночь того же дня
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
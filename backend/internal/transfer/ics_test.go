@@ -0,0 +1,176 @@
+package transfer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-list/backend/internal/ical"
+)
+
+// vtodo разбирает календарь из строк и возвращает его первый VTODO
+func vtodo(t *testing.T, lines ...string) (*ical.Component, ical.Zones) {
+	t.Helper()
+	text := "BEGIN:VCALENDAR\nBEGIN:VTODO\n" + strings.Join(lines, "\n") + "\nEND:VTODO\nEND:VCALENDAR\n"
+	cals, err := ical.Decode(strings.NewReader(text))
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return cals[0].Children("VTODO")[0], ical.CalendarZones(cals[0])
+}
+
+func TestTaskUID(t *testing.T) {
+	if uid := TaskUID(42); uid != "42@todo-list" {
+		t.Errorf("TaskUID = %q", uid)
+	}
+	tests := map[string]int{"42@todo-list": 42, "0@todo-list": 0, "x@todo-list": 0, "42@example.com": 0, "42": 0}
+	for uid, want := range tests {
+		id, ok := ParseTaskUID(uid)
+		if id != want || ok != (want > 0) {
+			t.Errorf("ParseTaskUID(%q) = %d, %v", uid, id, ok)
+		}
+	}
+}
+
+func TestVTodo(t *testing.T) {
+	due := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	task := Task{
+		ID: 7, ParentID: 3, Title: "Отчет; итог", Priority: "medium", DueDate: &due,
+		Category: "Работа", Tags: []string{"a", "b"}, Completed: true,
+		Recurrence: "RRULE:FREQ=DAILY\nEXDATE:20260315T093000Z", Reminders: []int{30},
+	}
+	todo := VTodo(task, "20260101T000000Z")
+
+	want := map[string]string{
+		"UID":              "7@todo-list",
+		"DTSTAMP":          "20260101T000000Z",
+		"SUMMARY":          `Отчет\; итог`,
+		"PRIORITY":         "5",
+		"STATUS":           "COMPLETED",
+		"PERCENT-COMPLETE": "100",
+		icsCategory:        "Работа",
+		"CATEGORIES":       "a,b",
+		"RELATED-TO":       "3@todo-list",
+		"DUE":              "20260314T093000Z",
+		"DTSTART":          "20260314T093000Z",
+		"RRULE":            "FREQ=DAILY",
+		"EXDATE":           "20260315T093000Z",
+	}
+	for name, value := range want {
+		if got := todo.Value(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+	alarms := todo.Children("VALARM")
+	if len(alarms) != 1 || alarms[0].Value("TRIGGER") != "-PT30M" || alarms[0].Prop("TRIGGER").Param("RELATED") != "END" {
+		t.Errorf("VALARM = %+v", alarms)
+	}
+
+	// Срок в полночь по местному времени записывается датой
+	midnight := time.Date(2026, 3, 14, 0, 0, 0, 0, time.Local)
+	todo = VTodo(Task{ID: 1, Title: "День", DueDate: &midnight}, "20260101T000000Z")
+	if prop := todo.Prop("DUE"); prop == nil || prop.Value != "20260314" || prop.Param("VALUE") != "DATE" {
+		t.Errorf("date DUE = %+v", prop)
+	}
+	if todo.Prop("DTSTART") != nil || todo.Value("STATUS") != "NEEDS-ACTION" {
+		t.Errorf("unexpected properties: %+v", todo.Properties)
+	}
+}
+
+func TestTaskFromVTodo(t *testing.T) {
+	todo, zones := vtodo(t,
+		"SUMMARY: Купить молоко ",
+		"DESCRIPTION:2 литра\\nобезжиренное",
+		"PRIORITY:3",
+		"PERCENT-COMPLETE:100",
+		"CATEGORIES:дом,список покупок",
+		"DTSTART:20261020T090000Z",
+		"DURATION:PT3H",
+		"RRULE:FREQ=WEEKLY",
+		"EXDATE:20261027T120000Z,20261103T120000Z",
+		"BEGIN:VALARM",
+		"TRIGGER;RELATED=END:-PT15M",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"TRIGGER:PT1H",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"TRIGGER;VALUE=DATE-TIME:20261020T110000Z",
+		"END:VALARM",
+		"BEGIN:VALARM",
+		"TRIGGER:PT5H",
+		"END:VALARM",
+	)
+	task, err := TaskFromVTodo(todo, zones)
+	if err != nil {
+		t.Fatalf("TaskFromVTodo: %v", err)
+	}
+
+	if task.Title != "Купить молоко" || task.Description != "2 литра\nобезжиренное" {
+		t.Errorf("title, description = %q, %q", task.Title, task.Description)
+	}
+	if task.Priority != "high" || !task.Completed {
+		t.Errorf("priority, completed = %q, %v", task.Priority, task.Completed)
+	}
+	if !reflect.DeepEqual(task.Tags, []string{"дом", "список_покупок"}) {
+		t.Errorf("tags = %q", task.Tags)
+	}
+	// Срок - DTSTART + DURATION
+	if want := time.Date(2026, 10, 20, 12, 0, 0, 0, time.UTC); task.DueDate == nil || !task.DueDate.Equal(want) {
+		t.Errorf("due = %v, want %v", task.DueDate, want)
+	}
+	if want := "RRULE:FREQ=WEEKLY\nEXDATE:20261027T120000Z,20261103T120000Z"; task.Recurrence != want {
+		t.Errorf("recurrence = %q, want %q", task.Recurrence, want)
+	}
+	// 15 минут до срока, через час после начала (за 2 часа до срока), в 11:00 (за час);
+	// напоминание после срока пропускается
+	if !reflect.DeepEqual(task.Reminders, []int{15, 120, 60}) {
+		t.Errorf("reminders = %v", task.Reminders)
+	}
+}
+
+func TestTaskFromVTodoWithoutDue(t *testing.T) {
+	todo, zones := vtodo(t, "SUMMARY:Без срока", "PRIORITY:0", "RRULE:FREQ=DAILY",
+		"BEGIN:VALARM", "TRIGGER:-PT15M", "END:VALARM")
+	task, err := TaskFromVTodo(todo, zones)
+	if err != nil {
+		t.Fatalf("TaskFromVTodo: %v", err)
+	}
+	// Повторение и напоминания отсчитываются от срока: без него они не переносятся
+	if task.DueDate != nil || task.Recurrence != "" || task.Reminders != nil || task.Priority != "" || task.Completed {
+		t.Errorf("task = %+v", task)
+	}
+
+	todo, zones = vtodo(t, "SUMMARY:Плохой срок", "DUE:завтра")
+	if _, err := TaskFromVTodo(todo, zones); err == nil {
+		t.Error("TaskFromVTodo accepted a malformed DUE")
+	}
+}
+
+func TestDecodeICSHierarchy(t *testing.T) {
+	text := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"X-WR-CALNAME:Дом",
+		"BEGIN:VTODO", "UID:child", "SUMMARY:Подзадача", "RELATED-TO:parent", "END:VTODO",
+		"BEGIN:VTODO", "UID:parent", "SUMMARY:Задача", "RELATED-TO;RELTYPE=CHILD:child", "END:VTODO",
+		"BEGIN:VTODO", "UID:orphan", "SUMMARY:Сирота", "RELATED-TO;RELTYPE=PARENT:missing",
+		icsCategory + ":Работа", "END:VTODO",
+		"END:VCALENDAR",
+	}, "\r\n")
+	doc, err := Decode(strings.NewReader(text), FormatICS, Options{})
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if len(doc.Tasks) != 3 {
+		t.Fatalf("tasks = %+v", doc.Tasks)
+	}
+	child, parent, orphan := doc.Tasks[0], doc.Tasks[1], doc.Tasks[2]
+	if child.ParentID != parent.ID || parent.ParentID != 0 || orphan.ParentID != 0 {
+		t.Errorf("parent IDs = %d, %d, %d; parent ID %d", child.ParentID, parent.ParentID, orphan.ParentID, parent.ID)
+	}
+	// Категория - из X-TODO-LIST-CATEGORY, без него - из имени календаря
+	if child.Category != "Дом" || orphan.Category != "Работа" {
+		t.Errorf("categories = %q, %q", child.Category, orphan.Category)
+	}
+}
@@ -0,0 +1,239 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Режимы обработки дубликатов при импорте. Дубликат - задача с тем же заголовком
+// (без учета регистра) у того же родителя.
+const (
+	DuplicatesSkip   = "skip"   // оставить существующую задачу
+	DuplicatesUpdate = "update" // переписать существующую задачу данными из файла
+	DuplicatesCreate = "create" // создать еще одну задачу
+)
+
+// Действия импорта над задачей файла
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionSkip   = "skip"
+	ActionError  = "error"
+)
+
+// ErrInvalidDuplicates возвращается для неизвестного режима обработки дубликатов
+var ErrInvalidDuplicates = errors.New("неизвестный режим обработки дубликатов")
+
+// ImportOptions параметры импорта
+type ImportOptions struct {
+	Duplicates string `json:"duplicates"` // skip (по умолчанию), update или create
+	DryRun     bool   `json:"dry_run"`    // только показать, что будет сделано
+}
+
+// ReportItem результат импорта одной задачи файла
+type ReportItem struct {
+	Ref    int    `json:"ref"` // номер задачи в файле
+	Title  string `json:"title"`
+	Action string `json:"action"`            // create, update, skip, error
+	TaskID int    `json:"task_id,omitempty"` // ID задачи в хранилище; у задач, создаваемых пробным импортом, его нет
+	Error  string `json:"error,omitempty"`
+}
+
+// Report итог импорта
+type Report struct {
+	DryRun  bool         `json:"dry_run"`
+	Created int          `json:"created"`
+	Updated int          `json:"updated"`
+	Skipped int          `json:"skipped"`
+	Failed  int          `json:"failed"`
+	Items   []ReportItem `json:"items"`
+}
+
+// Existing задача хранилища, с которой сравниваются задачи файла при поиске дубликатов
+type Existing struct {
+	ID       int
+	ParentID int // 0 - задача верхнего уровня
+	Title    string
+}
+
+// Store хранилище, в которое импортируются задачи
+type Store interface {
+	// Existing возвращает задачи хранилища (без корзины)
+	Existing() ([]Existing, error)
+	// Check проверяет и нормализует задачу, ничего не сохраняя
+	Check(task *Task) error
+	// Create сохраняет новую задачу под parentID (0 - верхний уровень) и возвращает ее ID
+	Create(task Task, parentID int) (int, error)
+	// Update переписывает задачу id непустыми полями task; статус выполнения переносится всегда
+	Update(id int, task Task) error
+	// SaveCategory создает категорию, если ее еще нет
+	SaveCategory(category Category) error
+}
+
+// Import переносит задачи документа в хранилище: родители создаются раньше подзадач,
+// дубликаты обрабатываются по opts.Duplicates. Ошибка отдельной задачи попадает в отчет
+// и не прерывает импорт; подзадачи задачи, которую не удалось импортировать, пропускаются.
+// При opts.DryRun хранилище не меняется, а отчет показывает, что было бы сделано.
+func Import(store Store, doc Document, opts ImportOptions) (Report, error) {
+	mode := strings.ToLower(strings.TrimSpace(opts.Duplicates))
+	switch mode {
+	case "":
+		mode = DuplicatesSkip
+	case DuplicatesSkip, DuplicatesUpdate, DuplicatesCreate:
+	default:
+		return Report{}, fmt.Errorf("%w: %q", ErrInvalidDuplicates, opts.Duplicates)
+	}
+
+	existing, err := store.Existing()
+	if err != nil {
+		return Report{}, fmt.Errorf("failed to load tasks: %w", err)
+	}
+	known := make(map[string]int, len(existing))
+	for _, task := range existing {
+		key := duplicateKey(task.Title, task.ParentID)
+		if _, ok := known[key]; !ok {
+			known[key] = task.ID
+		}
+	}
+
+	report := Report{DryRun: opts.DryRun, Items: []ReportItem{}}
+	if !opts.DryRun {
+		for _, category := range doc.Categories {
+			if strings.TrimSpace(category.Name) == "" {
+				continue
+			}
+			if err := store.SaveCategory(category); err != nil {
+				return report, fmt.Errorf("failed to save category %q: %w", category.Name, err)
+			}
+		}
+	}
+
+	tasks := append([]Task(nil), doc.Tasks...)
+	numberTasks(tasks)
+
+	ids := map[int]int{}     // номер задачи в файле -> ID в хранилище (при пробном импорте новые задачи получают отрицательные номера)
+	failed := map[int]bool{} // номера задач, которые не удалось импортировать
+	placeholder := 0
+
+	for _, task := range parentsFirst(tasks) {
+		item := ReportItem{Ref: task.ID, Title: task.Title}
+		fail := func(err error) {
+			failed[task.ID] = true
+			item.Action, item.Error = ActionError, err.Error()
+			report.Failed++
+			report.Items = append(report.Items, item)
+		}
+
+		if failed[task.ParentID] {
+			fail(errors.New("родительская задача не импортирована"))
+			continue
+		}
+		parentID := ids[task.ParentID]
+
+		if err := checkTask(store, &task); err != nil {
+			fail(err)
+			continue
+		}
+		item.Title = task.Title
+
+		key := duplicateKey(task.Title, parentID)
+		if id, ok := known[key]; ok && mode != DuplicatesCreate {
+			ids[task.ID] = id
+			item.TaskID = max(id, 0)
+			if mode == DuplicatesSkip {
+				item.Action = ActionSkip
+				report.Skipped++
+				report.Items = append(report.Items, item)
+				continue
+			}
+			if !opts.DryRun {
+				if err := store.Update(id, task); err != nil {
+					fail(err)
+					continue
+				}
+			}
+			item.Action = ActionUpdate
+			report.Updated++
+			report.Items = append(report.Items, item)
+			continue
+		}
+
+		id := 0
+		if opts.DryRun {
+			placeholder--
+			id = placeholder
+		} else {
+			id, err = store.Create(task, parentID)
+			if err != nil {
+				fail(err)
+				continue
+			}
+		}
+		ids[task.ID] = id
+		if _, ok := known[key]; !ok {
+			known[key] = id
+		}
+		item.Action, item.TaskID = ActionCreate, max(id, 0)
+		report.Created++
+		report.Items = append(report.Items, item)
+	}
+	return report, nil
+}
+
+// checkTask проверяет общие для всех хранилищ поля и передает задачу хранилищу
+func checkTask(store Store, task *Task) error {
+	task.Title = strings.TrimSpace(task.Title)
+	if task.Title == "" {
+		return errors.New("название задачи обязательно")
+	}
+	task.Category = strings.TrimSpace(task.Category)
+
+	switch priority := strings.ToLower(strings.TrimSpace(task.Priority)); priority {
+	case "", "low", "medium", "high":
+		task.Priority = priority
+	default:
+		return fmt.Errorf("некорректный приоритет %q", task.Priority)
+	}
+	return store.Check(task)
+}
+
+// duplicateKey ключ поиска дубликатов: заголовок без учета регистра и родитель
+func duplicateKey(title string, parentID int) string {
+	return fmt.Sprintf("%d\x00%s", parentID, strings.ToLower(strings.TrimSpace(title)))
+}
+
+// parentsFirst упорядочивает задачи так, чтобы родитель шел раньше подзадач.
+// Задачи с отсутствующим в файле родителем и задачи из циклов становятся задачами
+// верхнего уровня.
+func parentsFirst(tasks []Task) []Task {
+	tree := children(tasks)
+	result := make([]Task, 0, len(tasks))
+	visited := make(map[int]bool, len(tasks))
+
+	var walk func(parent int)
+	walk = func(parent int) {
+		for _, task := range tree[parent] {
+			if visited[task.ID] {
+				continue
+			}
+			visited[task.ID] = true
+			if parent == 0 {
+				task.ParentID = 0
+			}
+			result = append(result, task)
+			walk(task.ID)
+		}
+	}
+
+	walk(0)
+	for _, task := range tasks {
+		if !visited[task.ID] {
+			visited[task.ID] = true
+			task.ParentID = 0
+			result = append(result, task)
+			walk(task.ID)
+		}
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Формат обмена JSON
+const (
+	jsonFormatName = "todo-list"
+	jsonVersion    = 1
+)
+
+// jsonFile файл обмена JSON:
+//
+//	{
+//	  "format": "todo-list",
+//	  "version": 1,
+//	  "exported_at": "2026-10-17T10:00:00Z",
+//	  "categories": [{"name": "Работа", "color": "#007bff"}],
+//	  "tasks": [{"id": 1, "title": "Отчет", "category": "Работа", "tags": ["q3"]},
+//	            {"id": 2, "parent_id": 1, "title": "Собрать данные", "completed": true}]
+//	}
+//
+// Поля задачи описаны в Task; обязательно только title.
+type jsonFile struct {
+	Format     string     `json:"format"`
+	Version    int        `json:"version"`
+	ExportedAt time.Time  `json:"exported_at"`
+	Categories []Category `json:"categories"`
+	Tasks      []Task     `json:"tasks"`
+}
+
+func encodeJSON(w io.Writer, doc Document) error {
+	file := jsonFile{
+		Format:     jsonFormatName,
+		Version:    jsonVersion,
+		ExportedAt: time.Now().UTC(),
+		Categories: doc.Categories,
+		Tasks:      doc.Tasks,
+	}
+	if file.Categories == nil {
+		file.Categories = []Category{}
+	}
+	if file.Tasks == nil {
+		file.Tasks = []Task{}
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(file)
+}
+
+func decodeJSON(r io.Reader) (Document, error) {
+	var file jsonFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return Document{}, fmt.Errorf("некорректный JSON: %w", err)
+	}
+	if file.Format != "" && file.Format != jsonFormatName {
+		return Document{}, fmt.Errorf("неизвестный формат JSON %q", file.Format)
+	}
+	if file.Version > jsonVersion {
+		return Document{}, fmt.Errorf("версия файла %d новее поддерживаемой (%d)", file.Version, jsonVersion)
+	}
+	return Document{Categories: file.Categories, Tasks: file.Tasks}, nil
+}
@@ -0,0 +1,191 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var (
+	mdHeading = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*\s*$`)
+	mdItem    = regexp.MustCompile(`^(\s*)[-*+]\s+\[([ xX])\]\s*(.*)$`)
+)
+
+// encodeMarkdown записывает задачи чеклистом: категории - заголовками "# Категория",
+// подзадачи - вложенными пунктами, описание - строками под пунктом
+func encodeMarkdown(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	tree := children(doc.Tasks)
+
+	var write func(task Task, depth int)
+	write = func(task Task, depth int) {
+		indent := strings.Repeat("  ", depth)
+		mark := " "
+		if task.Completed {
+			mark = "x"
+		}
+		fmt.Fprintf(bw, "%s- [%s] %s\n", indent, mark, oneLine(task.Title))
+		for _, line := range descriptionLines(task.Description) {
+			if line == "" {
+				fmt.Fprintln(bw)
+				continue
+			}
+			fmt.Fprintf(bw, "%s  %s\n", indent, line)
+		}
+		for _, child := range tree[task.ID] {
+			write(child, depth+1)
+		}
+	}
+
+	for i, group := range groupByCategory(doc.Categories, tree[0]) {
+		if i > 0 {
+			fmt.Fprintln(bw)
+		}
+		if group.name != "" {
+			fmt.Fprintf(bw, "# %s\n\n", oneLine(group.name))
+		}
+		for _, task := range group.tasks {
+			write(task, 0)
+		}
+	}
+	return bw.Flush()
+}
+
+// decodeMarkdown читает пункты "- [ ]" и "- [x]". Вложенность пункта по отступу задает
+// родителя, заголовок - категорию последующих задач, строки с отступом под пунктом - описание.
+// Остальной текст пропускается.
+func decodeMarkdown(r io.Reader) (Document, error) {
+	var doc Document
+	type level struct{ indent, index int }
+	var stack []level
+	lines := map[int][]string{}
+	category := ""
+	inTask := false
+
+	scanner := newLineScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if m := mdHeading.FindStringSubmatch(line); m != nil {
+			category = m[1]
+			stack, inTask = nil, false
+			continue
+		}
+
+		if m := mdItem.FindStringSubmatch(line); m != nil {
+			indent := indentWidth(m[1])
+			for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+				stack = stack[:len(stack)-1]
+			}
+			task := Task{
+				ID:        len(doc.Tasks) + 1,
+				Title:     strings.TrimSpace(m[3]),
+				Completed: m[2] != " ",
+				Category:  category,
+			}
+			if len(stack) > 0 {
+				task.ParentID = doc.Tasks[stack[len(stack)-1].index].ID
+			}
+			doc.Tasks = append(doc.Tasks, task)
+			stack = append(stack, level{indent: indent, index: len(doc.Tasks) - 1})
+			inTask = true
+			continue
+		}
+
+		if !inTask {
+			continue
+		}
+		top := stack[len(stack)-1]
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && indentWidth(line[:len(line)-len(strings.TrimLeft(line, " \t"))]) <= top.indent {
+			inTask = false // текст без отступа не относится к задаче
+			continue
+		}
+		lines[top.index] = append(lines[top.index], trimmed)
+	}
+	if err := scanner.Err(); err != nil {
+		return Document{}, err
+	}
+
+	for index, text := range lines {
+		doc.Tasks[index].Description = joinDescription(text)
+	}
+	return doc, nil
+}
+
+// categoryGroup задачи верхнего уровня одной категории
+type categoryGroup struct {
+	name  string
+	tasks []Task
+}
+
+// groupByCategory группирует задачи верхнего уровня по категориям: сначала задачи
+// без категории, затем категории в порядке categories, затем остальные по порядку появления
+func groupByCategory(categories []Category, tasks []Task) []categoryGroup {
+	order := []string{""}
+	for _, category := range categories {
+		order = append(order, category.Name)
+	}
+
+	byName := map[string][]Task{}
+	for _, task := range tasks {
+		if _, ok := byName[task.Category]; !ok {
+			order = append(order, task.Category)
+		}
+		byName[task.Category] = append(byName[task.Category], task)
+	}
+
+	var result []categoryGroup
+	for _, name := range order {
+		if group, ok := byName[name]; ok {
+			result = append(result, categoryGroup{name: name, tasks: group})
+			delete(byName, name) // имя может повториться в order
+		}
+	}
+	return result
+}
+
+// newLineScanner читает файл построчно, допуская длинные строки
+func newLineScanner(r io.Reader) *bufio.Scanner {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	return scanner
+}
+
+// indentWidth ширина отступа; табуляция считается за четыре пробела
+func indentWidth(indent string) int {
+	width := 0
+	for _, r := range indent {
+		if r == '\t' {
+			width += 4
+		} else {
+			width++
+		}
+	}
+	return width
+}
+
+// descriptionLines разбивает описание на строки для вывода под задачей
+func descriptionLines(description string) []string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return nil
+	}
+	lines := strings.Split(description, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return lines
+}
+
+// joinDescription собирает описание из строк, убирая пустые строки по краям
+func joinDescription(lines []string) string {
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// oneLine сводит текст к одной строке, например для заголовка пункта
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,228 @@
+package transfer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	orgHeading  = regexp.MustCompile(`^(\*+)\s+(.*)$`)
+	orgKeyword  = regexp.MustCompile(`^(TODO|DONE)(?:\s+|$)`)
+	orgPriority = regexp.MustCompile(`^\[#([A-Za-z])\]\s*`)
+	orgTags     = regexp.MustCompile(`(?:^|\s+)(:[^\s:]+(?::[^\s:]+)*:)\s*$`)
+	orgDeadline = regexp.MustCompile(`DEADLINE:\s*<(\d{4}-\d{2}-\d{2})(?:\s+[^\s\d>]+)?(?:\s+(\d{1,2}:\d{2}))?[^>]*>`)
+	orgProperty = regexp.MustCompile(`^:([^:\s]+):\s*(.*)$`)
+)
+
+// encodeOrg записывает задачи заголовками "* TODO" и "* DONE": категории - заголовками
+// без ключевого слова, подзадачи - вложенными заголовками, срок - строкой DEADLINE,
+// категория подзадачи, отличная от категории родителя, - свойством CATEGORY
+func encodeOrg(w io.Writer, doc Document) error {
+	bw := bufio.NewWriter(w)
+	tree := children(doc.Tasks)
+
+	var write func(task Task, level int, category string)
+	write = func(task Task, level int, category string) {
+		heading := strings.Repeat("*", level) + " TODO"
+		if task.Completed {
+			heading = strings.Repeat("*", level) + " DONE"
+		}
+		if cookie := orgCookie(task.Priority); cookie != "" {
+			heading += " [#" + cookie + "]"
+		}
+		heading += " " + oneLine(task.Title)
+		if len(task.Tags) > 0 {
+			heading += " :" + strings.Join(task.Tags, ":") + ":"
+		}
+		fmt.Fprintln(bw, heading)
+
+		indent := strings.Repeat(" ", level+1)
+		if task.DueDate != nil {
+			fmt.Fprintf(bw, "%sDEADLINE: %s\n", indent, orgTimestamp(*task.DueDate))
+		}
+		if task.Category != category {
+			fmt.Fprintf(bw, "%s:PROPERTIES:\n%s:CATEGORY: %s\n%s:END:\n", indent, indent, task.Category, indent)
+		}
+		for _, line := range descriptionLines(task.Description) {
+			if line == "" {
+				fmt.Fprintln(bw)
+				continue
+			}
+			fmt.Fprintf(bw, "%s%s\n", indent, line)
+		}
+		for _, child := range tree[task.ID] {
+			write(child, level+1, task.Category)
+		}
+	}
+
+	for _, group := range groupByCategory(doc.Categories, tree[0]) {
+		level := 1
+		if group.name != "" {
+			fmt.Fprintf(bw, "* %s\n", oneLine(group.name))
+			level = 2
+		}
+		for _, task := range group.tasks {
+			write(task, level, group.name)
+		}
+	}
+	return bw.Flush()
+}
+
+// decodeOrg читает заголовки с ключевыми словами TODO и DONE. Вложенность заголовков задает
+// родителя; заголовок без ключевого слова только группирует задачи и задает их категорию.
+// Категорию также задают свойство CATEGORY (наследуется вложенными заголовками) и строка
+// #+CATEGORY в начале файла. Приоритеты [#A], [#B], [#C] - high, medium, low.
+func decodeOrg(r io.Reader) (Document, error) {
+	var doc Document
+	type node struct {
+		level    int
+		index    int // индекс задачи в doc.Tasks, -1 - группирующий заголовок
+		category string
+	}
+	var stack []node
+	lines := map[int][]string{}
+	fileCategory := ""
+	inProperties := false
+
+	scanner := newLineScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+
+		if m := orgHeading.FindStringSubmatch(line); m != nil {
+			level := len(m[1])
+			for len(stack) > 0 && stack[len(stack)-1].level >= level {
+				stack = stack[:len(stack)-1]
+			}
+			inProperties = false
+
+			category, parentID := fileCategory, 0
+			if len(stack) > 0 {
+				category = stack[len(stack)-1].category
+			}
+			for i := len(stack) - 1; i >= 0; i-- {
+				if stack[i].index >= 0 {
+					parentID = doc.Tasks[stack[i].index].ID
+					break
+				}
+			}
+
+			text := m[2]
+			keyword := orgKeyword.FindStringSubmatch(text)
+			text = orgKeyword.ReplaceAllString(text, "")
+			var tags []string
+			if t := orgTags.FindStringSubmatch(text); t != nil {
+				tags = strings.FieldsFunc(t[1], func(r rune) bool { return r == ':' })
+				text = strings.TrimSuffix(text, t[0])
+			}
+
+			if keyword == nil {
+				stack = append(stack, node{level: level, index: -1, category: strings.TrimSpace(text)})
+				continue
+			}
+
+			priority := ""
+			if p := orgPriority.FindStringSubmatch(text); p != nil {
+				priority = orgPriorityName(p[1])
+				text = text[len(p[0]):]
+			}
+			doc.Tasks = append(doc.Tasks, Task{
+				ID:        len(doc.Tasks) + 1,
+				ParentID:  parentID,
+				Title:     strings.TrimSpace(text),
+				Completed: keyword[1] == "DONE",
+				Priority:  priority,
+				Category:  category,
+				Tags:      tags,
+			})
+			stack = append(stack, node{level: level, index: len(doc.Tasks) - 1, category: category})
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if len(stack) == 0 {
+			if key, value, ok := strings.Cut(trimmed, ":"); ok && strings.EqualFold(key, "#+CATEGORY") {
+				fileCategory = strings.TrimSpace(value)
+			}
+			continue
+		}
+
+		top := &stack[len(stack)-1]
+		switch {
+		case inProperties:
+			if strings.EqualFold(trimmed, ":END:") {
+				inProperties = false
+			} else if p := orgProperty.FindStringSubmatch(trimmed); p != nil && strings.EqualFold(p[1], "CATEGORY") {
+				top.category = strings.TrimSpace(p[2])
+				if top.index >= 0 {
+					doc.Tasks[top.index].Category = top.category
+				}
+			}
+		case strings.EqualFold(trimmed, ":PROPERTIES:"):
+			inProperties = true
+		case isPlanningLine(trimmed):
+			if m := orgDeadline.FindStringSubmatch(trimmed); m != nil && top.index >= 0 {
+				due, err := parseTime(strings.TrimSpace(m[1] + " " + m[2]))
+				if err != nil {
+					return Document{}, fmt.Errorf("строка %d: %w", lineNo, err)
+				}
+				doc.Tasks[top.index].DueDate = due
+			}
+		case top.index >= 0:
+			lines[top.index] = append(lines[top.index], trimmed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Document{}, err
+	}
+
+	for index, text := range lines {
+		doc.Tasks[index].Description = joinDescription(text)
+	}
+	return doc, nil
+}
+
+// isPlanningLine строка планирования org-mode (DEADLINE, SCHEDULED, CLOSED)
+func isPlanningLine(line string) bool {
+	for _, keyword := range []string{"DEADLINE:", "SCHEDULED:", "CLOSED:"} {
+		if strings.HasPrefix(line, keyword) {
+			return true
+		}
+	}
+	return false
+}
+
+// orgTimestamp форматирует срок как активную метку времени org-mode;
+// полночь записывается датой без времени
+func orgTimestamp(t time.Time) string {
+	t = t.Local()
+	if t.Hour() == 0 && t.Minute() == 0 {
+		return t.Format("<2006-01-02 Mon>")
+	}
+	return t.Format("<2006-01-02 Mon 15:04>")
+}
+
+// orgCookie возвращает приоритет org-mode; средний приоритет (B) в org-mode по умолчанию
+// и не записывается
+func orgCookie(priority string) string {
+	switch priority {
+	case "high":
+		return "A"
+	case "low":
+		return "C"
+	}
+	return ""
+}
+
+func orgPriorityName(cookie string) string {
+	switch strings.ToUpper(cookie) {
+	case "A":
+		return "high"
+	case "C":
+		return "low"
+	}
+	return "medium"
+}
@@ -0,0 +1,125 @@
+package transfer
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"todo-list/backend/internal/todotxt"
+)
+
+// encodeTodoTxt записывает задачи строками todo.txt так же, как хранилище todo.txt:
+// метки с "+" и "@" - проектами и контекстами, остальные - расширениями tag:. Категория
+// записывается расширением category: (пробелы заменяются на "_"). Номера задач и подзадачи
+// переносятся расширениями id: и parent:, описание в todo.txt не записывается.
+func encodeTodoTxt(w io.Writer, doc Document) error {
+	items := make([]todotxt.Task, 0, len(doc.Tasks))
+	for _, task := range doc.Tasks {
+		item := todotxt.Task{Completed: task.Completed, Text: oneLine(task.Title)}
+		if task.CreatedAt != nil {
+			item.CreationDate = task.CreatedAt.Local()
+		}
+		item.SetRank(task.Priority)
+		for _, tag := range task.Tags {
+			if tag == "" {
+				continue
+			}
+			switch tag[0] {
+			case '+':
+				item.Projects = append(item.Projects, tag[1:])
+			case '@':
+				item.Contexts = append(item.Contexts, tag[1:])
+			default:
+				item.Set("tag", tag)
+			}
+		}
+
+		if task.Category != "" {
+			item.Set("category", strings.ReplaceAll(oneLine(task.Category), " ", "_"))
+		}
+		item.Set("id", strconv.Itoa(task.ID))
+		if task.ParentID != 0 {
+			item.Set("parent", strconv.Itoa(task.ParentID))
+		}
+		if task.DueDate != nil {
+			item.Set("due", todotxt.FormatDue(*task.DueDate))
+		}
+		item.SetRecurrence(task.Recurrence)
+		item.Set("remind", joinInts(task.Reminders))
+		items = append(items, item)
+	}
+	return todotxt.WriteAll(w, items)
+}
+
+// decodeTodoTxt читает строки todo.txt так же, как хранилище todo.txt: проекты и контексты
+// становятся метками со своим знаком, категория берется из расширения category:.
+// Приоритет (A) - high, (B) - medium, (C) и ниже - low.
+func decodeTodoTxt(r io.Reader) (Document, error) {
+	items, err := todotxt.ReadAll(r)
+	if err != nil {
+		return Document{}, err
+	}
+
+	var doc Document
+	for _, item := range items {
+		task := Task{
+			Title:     item.Text,
+			Completed: item.Completed,
+			Priority:  item.Rank(),
+		}
+		task.ID, _ = strconv.Atoi(item.Get("id"))
+		task.ParentID, _ = strconv.Atoi(item.Get("parent"))
+		if !item.CreationDate.IsZero() {
+			created := item.CreationDate
+			task.CreatedAt = &created
+		}
+
+		task.Category = strings.ReplaceAll(item.Get("category"), "_", " ")
+		for _, project := range item.Projects {
+			task.Tags = append(task.Tags, "+"+project)
+		}
+		for _, context := range item.Contexts {
+			task.Tags = append(task.Tags, "@"+context)
+		}
+		task.Tags = append(task.Tags, item.All("tag")...)
+
+		if due := item.Get("due"); due != "" {
+			t, err := todotxt.ParseDue(due)
+			if err != nil {
+				return Document{}, fmt.Errorf("задача %q: %w", item.Text, err)
+			}
+			task.DueDate = &t
+		}
+		if task.Recurrence, err = item.Recurrence(); err != nil {
+			return Document{}, fmt.Errorf("задача %q: %w", item.Text, err)
+		}
+		if task.Reminders, err = splitInts(item.Get("remind")); err != nil {
+			return Document{}, fmt.Errorf("задача %q: %w", item.Text, err)
+		}
+		doc.Tasks = append(doc.Tasks, task)
+	}
+	return doc, nil
+}
+
+// joinInts записывает числа через запятую
+func joinInts(values []int) string {
+	parts := make([]string, len(values))
+	for i, value := range values {
+		parts[i] = strconv.Itoa(value)
+	}
+	return strings.Join(parts, ",")
+}
+
+// splitInts разбирает числа, перечисленные через запятую
+func splitInts(s string) ([]int, error) {
+	var values []int
+	for _, part := range splitList(s) {
+		value, err := strconv.Atoi(part)
+		if err != nil {
+			return nil, fmt.Errorf("некорректное число %q", part)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
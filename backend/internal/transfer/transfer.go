@@ -0,0 +1,261 @@
+// Package transfer импортирует и экспортирует задачи в форматах JSON, CSV,
+// Markdown-чеклистов, org-mode, todo.txt и iCalendar
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Форматы файлов обмена
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+	FormatOrg      = "org"
+	FormatTodoTxt  = "todotxt"
+	FormatICS      = "ics"
+)
+
+// ErrUnknownFormat возвращается для неподдерживаемого формата файла
+var ErrUnknownFormat = errors.New("неизвестный формат файла")
+
+// Task задача в файле обмена. ID и ParentID - номера задач внутри файла, а не ID
+// в хранилище: по ним восстанавливается иерархия подзадач.
+type Task struct {
+	ID          int        `json:"id"`
+	ParentID    int        `json:"parent_id,omitempty"` // 0 - задача верхнего уровня
+	Title       string     `json:"title"`
+	Description string     `json:"description,omitempty"`
+	Completed   bool       `json:"completed"`
+	Priority    string     `json:"priority,omitempty"` // low, medium, high
+	DueDate     *time.Time `json:"due_date,omitempty"`
+	Category    string     `json:"category,omitempty"` // имя категории
+	Tags        []string   `json:"tags,omitempty"`
+	Recurrence  string     `json:"recurrence,omitempty"` // правило RRULE
+	Reminders   []int      `json:"reminders,omitempty"`  // минуты до срока
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+}
+
+// Category категория в файле обмена
+type Category struct {
+	Name  string `json:"name"`
+	Color string `json:"color,omitempty"`
+}
+
+// Document содержимое файла обмена
+type Document struct {
+	Categories []Category
+	Tasks      []Task
+}
+
+// Options параметры кодирования
+type Options struct {
+	// Columns сопоставляет поля задачи (title, due_date, ...) с заголовками колонок CSV;
+	// поле без сопоставления ищется в колонке со своим именем
+	Columns map[string]string
+}
+
+// Fields поля задачи в порядке колонок CSV
+var Fields = []string{
+	"id", "parent_id", "title", "description", "completed", "priority",
+	"due_date", "category", "tags", "recurrence", "reminders", "created_at",
+}
+
+// ParseFormat приводит имя формата или расширение файла (md, txt) к константе формата
+func ParseFormat(name string) (string, error) {
+	name = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(name), "."))
+	switch name {
+	case "json":
+		return FormatJSON, nil
+	case "csv":
+		return FormatCSV, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "org", "org-mode":
+		return FormatOrg, nil
+	case "todotxt", "todo.txt", "txt":
+		return FormatTodoTxt, nil
+	case "ics", "ical", "icalendar":
+		return FormatICS, nil
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownFormat, name)
+}
+
+// FormatFromFilename определяет формат по расширению файла
+func FormatFromFilename(filename string) (string, error) {
+	return ParseFormat(filepath.Ext(filename))
+}
+
+// ContentType возвращает MIME-тип файла в формате format
+func ContentType(format string) string {
+	switch format {
+	case FormatJSON:
+		return "application/json"
+	case FormatCSV:
+		return "text/csv; charset=utf-8"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatICS:
+		return "text/calendar; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Extension возвращает расширение файла в формате format
+func Extension(format string) string {
+	switch format {
+	case FormatMarkdown:
+		return ".md"
+	case FormatTodoTxt:
+		return ".txt"
+	}
+	return "." + format
+}
+
+// Encode записывает документ в w в формате format
+func Encode(w io.Writer, format string, doc Document, opts Options) error {
+	switch format {
+	case FormatJSON:
+		return encodeJSON(w, doc)
+	case FormatCSV:
+		return encodeCSV(w, doc, opts)
+	case FormatMarkdown:
+		return encodeMarkdown(w, doc)
+	case FormatOrg:
+		return encodeOrg(w, doc)
+	case FormatTodoTxt:
+		return encodeTodoTxt(w, doc)
+	case FormatICS:
+		return encodeICS(w, doc)
+	}
+	return fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+}
+
+// Decode читает документ в формате format. Задачам без номера (Markdown, org-mode,
+// CSV без колонки id) номера присваиваются по порядку.
+func Decode(r io.Reader, format string, opts Options) (Document, error) {
+	var doc Document
+	var err error
+	switch format {
+	case FormatJSON:
+		doc, err = decodeJSON(r)
+	case FormatCSV:
+		doc, err = decodeCSV(r, opts)
+	case FormatMarkdown:
+		doc, err = decodeMarkdown(r)
+	case FormatOrg:
+		doc, err = decodeOrg(r)
+	case FormatTodoTxt:
+		doc, err = decodeTodoTxt(r)
+	case FormatICS:
+		doc, err = decodeICS(r)
+	default:
+		return doc, fmt.Errorf("%w: %q", ErrUnknownFormat, format)
+	}
+	if err != nil {
+		return doc, err
+	}
+	numberTasks(doc.Tasks)
+	return doc, nil
+}
+
+// ParseColumns разбирает сопоставление колонок CSV вида "title=Название,due_date=Срок"
+func ParseColumns(spec string) (map[string]string, error) {
+	columns := map[string]string{}
+	for _, pair := range strings.Split(spec, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		field, column, ok := strings.Cut(pair, "=")
+		field, column = strings.ToLower(strings.TrimSpace(field)), strings.TrimSpace(column)
+		if !ok || column == "" || !isField(field) {
+			return nil, fmt.Errorf("некорректное сопоставление колонки %q", pair)
+		}
+		columns[field] = column
+	}
+	return columns, nil
+}
+
+func isField(name string) bool {
+	for _, field := range Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// numberTasks присваивает номера задачам без номера или с повторяющимся номером
+func numberTasks(tasks []Task) {
+	next := 1
+	for _, task := range tasks {
+		next = max(next, task.ID+1)
+	}
+	seen := make(map[int]bool, len(tasks))
+	for i := range tasks {
+		if tasks[i].ID <= 0 || seen[tasks[i].ID] {
+			tasks[i].ID = next
+			next++
+		}
+		seen[tasks[i].ID] = true
+	}
+}
+
+// children группирует задачи по родителю; задачи, чьего родителя нет в документе,
+// считаются задачами верхнего уровня (ключ 0)
+func children(tasks []Task) map[int][]Task {
+	known := make(map[int]bool, len(tasks))
+	for _, task := range tasks {
+		known[task.ID] = true
+	}
+	result := map[int][]Task{}
+	for _, task := range tasks {
+		parent := task.ParentID
+		if !known[parent] || parent == task.ID {
+			parent = 0
+		}
+		result[parent] = append(result[parent], task)
+	}
+	return result
+}
+
+// dateLayouts форматы дат, которые понимает импорт; даты без часового пояса - местное время
+var dateLayouts = []string{
+	"2006-01-02T15:04",
+	"2006-01-02 15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"02.01.2006 15:04",
+	"02.01.2006",
+}
+
+// parseTime разбирает дату; пустая строка - даты нет
+func parseTime(s string) (*time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return &t, nil
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("некорректная дата %q", s)
+}
+
+// splitList разбирает список, разделенный запятыми, точками с запятой или пробелами
+func splitList(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n'
+	})
+}
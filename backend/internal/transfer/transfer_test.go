@@ -0,0 +1,323 @@
+package transfer
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"todo-list/backend/internal/recurrence"
+)
+
+func sampleDocument() Document {
+	due := time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+	return Document{
+		Categories: []Category{{Name: "Работа", Color: "#ff0000"}},
+		Tasks: []Task{
+			{
+				ID: 1, Title: "Отчет", Description: "строка 1\nстрока 2", Priority: "high",
+				DueDate: &due, Category: "Работа", Tags: []string{"q1", "urgent"},
+				Recurrence: "FREQ=WEEKLY;BYDAY=MO", Reminders: []int{60, 15},
+			},
+			{ID: 2, ParentID: 1, Title: "Таблицы", Completed: true, Priority: "low"},
+		},
+	}
+}
+
+// roundTrip кодирует и снова читает документ
+func roundTrip(t *testing.T, format string, doc Document) Document {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Encode(&buf, format, doc, Options{}); err != nil {
+		t.Fatalf("Encode(%s): %v", format, err)
+	}
+	got, err := Decode(&buf, format, Options{})
+	if err != nil {
+		t.Fatalf("Decode(%s): %v\n%s", format, err, buf.String())
+	}
+	return got
+}
+
+// sameRule сравнивает правила повторения без учета записи (с префиксом RRULE: или без)
+func sameRule(t *testing.T, got, want string) bool {
+	t.Helper()
+	if got == "" || want == "" {
+		return got == want
+	}
+	g, err := recurrence.Parse(got)
+	if err != nil {
+		t.Errorf("invalid rule %q: %v", got, err)
+		return false
+	}
+	w, _ := recurrence.Parse(want)
+	return g.String() == w.String()
+}
+
+func TestRoundTrip(t *testing.T) {
+	// Что сохраняет каждый формат, кроме заголовка, статуса и иерархии
+	type fields struct{ description, priority, due, tags, recurrence, reminders bool }
+	formats := map[string]fields{
+		FormatJSON:     {true, true, true, true, true, true},
+		FormatCSV:      {true, true, true, true, true, true},
+		FormatMarkdown: {description: true},
+		FormatOrg:      {description: true, priority: true, due: true, tags: true},
+		FormatTodoTxt:  {priority: true, due: true, tags: true, recurrence: true, reminders: true},
+		FormatICS:      {true, true, true, true, true, true},
+	}
+
+	want := sampleDocument()
+	for format, keeps := range formats {
+		t.Run(format, func(t *testing.T) {
+			got := roundTrip(t, format, want)
+			if len(got.Tasks) != 2 {
+				t.Fatalf("tasks = %+v", got.Tasks)
+			}
+			parent, child := got.Tasks[0], got.Tasks[1]
+
+			if parent.Title != "Отчет" || child.Title != "Таблицы" {
+				t.Errorf("titles = %q, %q", parent.Title, child.Title)
+			}
+			if parent.Completed || !child.Completed {
+				t.Errorf("completed = %v, %v", parent.Completed, child.Completed)
+			}
+			if parent.ParentID != 0 || child.ParentID != parent.ID {
+				t.Errorf("parent IDs = %d, %d; want 0, %d", parent.ParentID, child.ParentID, parent.ID)
+			}
+			if parent.Category != "Работа" {
+				t.Errorf("category = %q", parent.Category)
+			}
+
+			if keeps.description && parent.Description != want.Tasks[0].Description {
+				t.Errorf("description = %q", parent.Description)
+			}
+			if keeps.priority && (parent.Priority != "high" || child.Priority != "low") {
+				t.Errorf("priorities = %q, %q", parent.Priority, child.Priority)
+			}
+			if keeps.due && (parent.DueDate == nil || !parent.DueDate.Equal(*want.Tasks[0].DueDate)) {
+				t.Errorf("due = %v, want %v", parent.DueDate, want.Tasks[0].DueDate)
+			}
+			if keeps.tags && !reflect.DeepEqual(parent.Tags, want.Tasks[0].Tags) {
+				t.Errorf("tags = %v", parent.Tags)
+			}
+			if keeps.recurrence && !sameRule(t, parent.Recurrence, want.Tasks[0].Recurrence) {
+				t.Errorf("recurrence = %q", parent.Recurrence)
+			}
+			if keeps.reminders && !reflect.DeepEqual(parent.Reminders, want.Tasks[0].Reminders) {
+				t.Errorf("reminders = %v", parent.Reminders)
+			}
+		})
+	}
+}
+
+func TestDecodeCSVExcel(t *testing.T) {
+	// Excel: BOM, точка с запятой, свои заголовки колонок и лишняя колонка
+	input := bom + "Название;Срок;Готово;Заметки\r\n" +
+		"Купить молоко;2026-03-01;да;\r\n" +
+		";;;\r\n" +
+		"Позвонить;01.03.2026 18:00;нет;срочно\r\n"
+	columns, err := ParseColumns("title=Название, due_date=Срок, completed=Готово")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := Decode(strings.NewReader(input), FormatCSV, Options{Columns: columns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Tasks) != 2 {
+		t.Fatalf("tasks = %+v", doc.Tasks)
+	}
+	first, second := doc.Tasks[0], doc.Tasks[1]
+	if first.Title != "Купить молоко" || !first.Completed || first.DueDate == nil || first.DueDate.Day() != 1 {
+		t.Errorf("first = %+v", first)
+	}
+	if second.Completed || second.DueDate == nil || second.DueDate.Hour() != 18 {
+		t.Errorf("second = %+v", second)
+	}
+	if first.ID == 0 || first.ID == second.ID {
+		t.Errorf("IDs = %d, %d", first.ID, second.ID)
+	}
+}
+
+func TestDecodeCSVErrors(t *testing.T) {
+	if _, err := Decode(strings.NewReader("name,due\nx,\n"), FormatCSV, Options{}); err == nil {
+		t.Error("CSV without title column accepted")
+	}
+	_, err := Decode(strings.NewReader("title,due_date\nx,завтра\n"), FormatCSV, Options{})
+	if err == nil || !strings.Contains(err.Error(), "строка 2") {
+		t.Errorf("bad date error = %v, want line number", err)
+	}
+	if _, err := ParseColumns("title"); err == nil {
+		t.Error("ParseColumns accepted a pair without column")
+	}
+	if _, err := ParseColumns("unknown=X"); err == nil {
+		t.Error("ParseColumns accepted an unknown field")
+	}
+}
+
+func TestDecodeMarkdown(t *testing.T) {
+	input := `Заметки вне задач пропускаются
+
+# Дом
+- [ ] Ремонт
+  покрасить стены
+  - [x] Купить краску
+  - [ ] Найти мастера
+* [X] Вынести мусор
+Обычный текст
+
+## Работа
++ [ ] Отчет
+`
+	doc, err := Decode(strings.NewReader(input), FormatMarkdown, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type row struct {
+		title     string
+		parent    string
+		completed bool
+		category  string
+	}
+	byID := map[int]string{}
+	for _, task := range doc.Tasks {
+		byID[task.ID] = task.Title
+	}
+	var got []row
+	for _, task := range doc.Tasks {
+		got = append(got, row{task.Title, byID[task.ParentID], task.Completed, task.Category})
+	}
+	want := []row{
+		{"Ремонт", "", false, "Дом"},
+		{"Купить краску", "Ремонт", true, "Дом"},
+		{"Найти мастера", "Ремонт", false, "Дом"},
+		{"Вынести мусор", "", true, "Дом"},
+		{"Отчет", "", false, "Работа"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tasks =\n%+v\nwant\n%+v", got, want)
+	}
+	if doc.Tasks[0].Description != "покрасить стены" {
+		t.Errorf("description = %q", doc.Tasks[0].Description)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]string{
+		"JSON": FormatJSON, ".md": FormatMarkdown, "org-mode": FormatOrg,
+		"todo.txt": FormatTodoTxt, "txt": FormatTodoTxt, "ical": FormatICS,
+	}
+	for name, want := range tests {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := FormatFromFilename("tasks.xlsx"); !errors.Is(err, ErrUnknownFormat) {
+		t.Errorf("xlsx error = %v", err)
+	}
+}
+
+// memoryStore хранилище импорта в памяти
+type memoryStore struct {
+	tasks   []Existing
+	updated map[int]Task
+	reject  string // заголовок задачи, которую Create отклоняет
+}
+
+func (s *memoryStore) Existing() ([]Existing, error) { return s.tasks, nil }
+func (s *memoryStore) Check(task *Task) error        { return nil }
+func (s *memoryStore) SaveCategory(Category) error   { return nil }
+
+func (s *memoryStore) Create(task Task, parentID int) (int, error) {
+	if task.Title == s.reject {
+		return 0, errors.New("отклонено")
+	}
+	id := len(s.tasks) + 1
+	s.tasks = append(s.tasks, Existing{ID: id, ParentID: parentID, Title: task.Title})
+	return id, nil
+}
+
+func (s *memoryStore) Update(id int, task Task) error {
+	if s.updated == nil {
+		s.updated = map[int]Task{}
+	}
+	s.updated[id] = task
+	return nil
+}
+
+func importDocument() Document {
+	return Document{Tasks: []Task{
+		{ID: 3, ParentID: 1, Title: "Подзадача"}, // подзадача раньше родителя
+		{ID: 1, Title: "молоко"},
+		{ID: 2, Title: "Новая", Priority: "HIGH"},
+	}}
+}
+
+func TestImportDuplicates(t *testing.T) {
+	tests := []struct {
+		mode                      string
+		created, updated, skipped int
+	}{
+		{"", 2, 0, 1},
+		{DuplicatesUpdate, 2, 1, 0},
+		{DuplicatesCreate, 3, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			store := &memoryStore{tasks: []Existing{{ID: 1, Title: "Молоко"}}}
+			report, err := Import(store, importDocument(), ImportOptions{Duplicates: tt.mode})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if report.Created != tt.created || report.Updated != tt.updated || report.Skipped != tt.skipped {
+				t.Errorf("report = %+v", report)
+			}
+			if len(store.tasks) != 1+tt.created {
+				t.Errorf("store has %d tasks, want %d", len(store.tasks), 1+tt.created)
+			}
+			// Подзадача создается под родителем, найденным или созданным раньше нее
+			for _, task := range store.tasks {
+				if task.Title == "Подзадача" && task.ParentID == 0 {
+					t.Errorf("subtask created at top level")
+				}
+			}
+		})
+	}
+}
+
+func TestImportDryRunAndFailures(t *testing.T) {
+	store := &memoryStore{}
+	report, err := Import(store, importDocument(), ImportOptions{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.DryRun || report.Created != 3 || len(store.tasks) != 0 {
+		t.Errorf("dry run report = %+v, store = %+v", report, store.tasks)
+	}
+
+	// Подзадачи задачи, которую не удалось создать, пропускаются с ошибкой
+	store = &memoryStore{reject: "молоко"}
+	report, err = Import(store, importDocument(), ImportOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 1 || report.Failed != 2 {
+		t.Errorf("report = %+v", report)
+	}
+	for _, item := range report.Items {
+		if item.Action == ActionError && item.Error == "" {
+			t.Errorf("failed item without error: %+v", item)
+		}
+	}
+
+	doc := Document{Tasks: []Task{{Title: "  "}, {Title: "x", Priority: "urgent"}}}
+	if report, _ := Import(&memoryStore{}, doc, ImportOptions{}); report.Failed != 2 {
+		t.Errorf("invalid tasks report = %+v", report)
+	}
+	if _, err := Import(&memoryStore{}, doc, ImportOptions{Duplicates: "merge"}); !errors.Is(err, ErrInvalidDuplicates) {
+		t.Errorf("unknown mode error = %v", err)
+	}
+}
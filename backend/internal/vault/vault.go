@@ -0,0 +1,196 @@
+// Package vault шифрует файлы ключом, полученным из парольной фразы: Argon2id
+// для получения ключа и XChaCha20-Poly1305 для шифрования с проверкой подлинности.
+package vault
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Ошибки расшифровки
+var (
+	// ErrWrongKey файл зашифрован другим ключом: неверная парольная фраза
+	// или фраза сменилась после получения ключа
+	ErrWrongKey = errors.New("неверная парольная фраза")
+	// ErrCorrupt данные не прошли проверку подлинности: файл поврежден или изменен
+	ErrCorrupt = errors.New("зашифрованные данные повреждены")
+	// ErrEmptyPassphrase парольная фраза не задана
+	ErrEmptyPassphrase = errors.New("парольная фраза не может быть пустой")
+)
+
+// cipherName алгоритм шифрования, записываемый в заголовок файла
+const cipherName = "xchacha20poly1305"
+
+// KDFParams параметры Argon2id
+type KDFParams struct {
+	Name    string `json:"name"` // argon2id
+	Salt    []byte `json:"salt"`
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"` // KiB
+	Threads uint8  `json:"threads"`
+}
+
+// DefaultKDF параметры Argon2id для новых ключей (рекомендация RFC 9106 для ограниченной памяти)
+var DefaultKDF = KDFParams{Name: "argon2id", Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// Допустимые параметры Argon2id в заголовке файла
+const (
+	maxTime   = 16
+	maxMemory = 1024 * 1024 // 1 GiB
+)
+
+// header параметры шифрования в начале файла; они же - дополнительные данные шифра,
+// поэтому их подмена обнаруживается при расшифровке
+type header struct {
+	Cipher string    `json:"cipher"`
+	KDF    KDFParams `json:"kdf"`
+}
+
+// sealed зашифрованный файл
+type sealed struct {
+	Encrypted header `json:"encrypted"`
+	Nonce     []byte `json:"nonce"`
+	Data      []byte `json:"data"`
+}
+
+// Key ключ шифрования вместе с солью и параметрами, из которых он получен.
+// Парольная фраза в нем не хранится.
+type Key struct {
+	header header
+	key    []byte
+}
+
+// NewKey получает новый ключ из парольной фразы со случайной солью
+func NewKey(passphrase string) (*Key, error) {
+	params := DefaultKDF
+	params.Salt = make([]byte, 16)
+	if _, err := rand.Read(params.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+	return deriveKey(passphrase, params)
+}
+
+// Unlock получает ключ из парольной фразы с солью и параметрами зашифрованного файла data
+// и проверяет его расшифровкой. Неверная фраза - ErrWrongKey: по данным ее нельзя
+// отличить от поврежденного файла.
+func Unlock(data []byte, passphrase string) (*Key, error) {
+	file, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	key, err := deriveKey(passphrase, file.Encrypted.KDF)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := key.Open(data); err != nil {
+		if errors.Is(err, ErrCorrupt) {
+			return nil, ErrWrongKey
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func deriveKey(passphrase string, params KDFParams) (*Key, error) {
+	if passphrase == "" {
+		return nil, ErrEmptyPassphrase
+	}
+	// Верхние границы не дают подложенному файлу занять всю память при разблокировке
+	if params.Name != "argon2id" || len(params.Salt) < 16 || params.Threads == 0 ||
+		params.Time == 0 || params.Time > maxTime ||
+		params.Memory < 8*uint32(params.Threads) || params.Memory > maxMemory {
+		return nil, fmt.Errorf("unsupported key derivation parameters (%s)", params.Name)
+	}
+	key := argon2.IDKey([]byte(passphrase), params.Salt, params.Time, params.Memory,
+		params.Threads, chacha20poly1305.KeySize)
+	return &Key{header: header{Cipher: cipherName, KDF: params}, key: key}, nil
+}
+
+// Verify сообщает, что из парольной фразы получается этот ключ
+func (k *Key) Verify(passphrase string) bool {
+	other, err := deriveKey(passphrase, k.header.KDF)
+	return err == nil && subtle.ConstantTimeCompare(other.key, k.key) == 1
+}
+
+// IsSealed сообщает, что data - файл, зашифрованный Seal
+func IsSealed(data []byte) bool {
+	if !bytes.Contains(data, []byte(`"encrypted"`)) {
+		return false
+	}
+	_, err := parse(data)
+	return err == nil
+}
+
+func parse(data []byte) (*sealed, error) {
+	var file sealed
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+	if file.Encrypted.Cipher == "" {
+		return nil, fmt.Errorf("%w: no encryption header", ErrCorrupt)
+	}
+	if file.Encrypted.Cipher != cipherName {
+		return nil, fmt.Errorf("unsupported cipher %q", file.Encrypted.Cipher)
+	}
+	return &file, nil
+}
+
+// Seal шифрует plain со случайным nonce
+func (k *Key) Seal(plain []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.NewX(k.key)
+	if err != nil {
+		return nil, err
+	}
+	ad, err := json.Marshal(k.header)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	file := sealed{Encrypted: k.header, Nonce: nonce, Data: aead.Seal(nil, nonce, plain, ad)}
+	return json.MarshalIndent(file, "", "  ")
+}
+
+// Open расшифровывает файл, зашифрованный этим ключом. Файл с другой солью или другими
+// параметрами - ErrWrongKey, не прошедший проверку подлинности - ErrCorrupt.
+func (k *Key) Open(data []byte) ([]byte, error) {
+	file, err := parse(data)
+	if err != nil {
+		return nil, err
+	}
+	if !k.matches(file.Encrypted) {
+		return nil, ErrWrongKey
+	}
+	aead, err := chacha20poly1305.NewX(k.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(file.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("%w: bad nonce", ErrCorrupt)
+	}
+	ad, err := json.Marshal(file.Encrypted)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, file.Nonce, file.Data, ad)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return plain, nil
+}
+
+// matches сообщает, что файл с заголовком h зашифрован ключом, полученным так же, как k
+func (k *Key) matches(h header) bool {
+	a, b := k.header.KDF, h.KDF
+	return h.Cipher == k.header.Cipher && a.Name == b.Name && a.Time == b.Time &&
+		a.Memory == b.Memory && a.Threads == b.Threads && subtle.ConstantTimeCompare(a.Salt, b.Salt) == 1
+}
@@ -0,0 +1,101 @@
+package vault
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestSealOpen(t *testing.T) {
+	key, err := NewKey("correct horse")
+	if err != nil {
+		t.Fatalf("NewKey: %v", err)
+	}
+	plain := []byte(`{"tasks":[{"title":"секрет"}]}`)
+	data, err := key.Seal(plain)
+	if err != nil {
+		t.Fatalf("Seal: %v", err)
+	}
+	if !IsSealed(data) || bytes.Contains(data, []byte("tasks")) {
+		t.Fatalf("sealed data = %s", data)
+	}
+	if IsSealed(plain) || IsSealed([]byte(`{"encrypted":{}}`)) || IsSealed(nil) {
+		t.Error("IsSealed accepted plain data")
+	}
+
+	got, err := key.Open(data)
+	if err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("Open = %s, %v", got, err)
+	}
+	// Тот же ключ с новым nonce дает другой шифротекст
+	if again, _ := key.Seal(plain); bytes.Equal(again, data) {
+		t.Error("Seal reused a nonce")
+	}
+
+	unlocked, err := Unlock(data, "correct horse")
+	if err != nil {
+		t.Fatalf("Unlock: %v", err)
+	}
+	if got, err := unlocked.Open(data); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("Open with unlocked key = %s, %v", got, err)
+	}
+	if !key.Verify("correct horse") || key.Verify("wrong") || key.Verify("") {
+		t.Error("Verify accepted a wrong passphrase")
+	}
+}
+
+func TestWrongKey(t *testing.T) {
+	key, _ := NewKey("one")
+	data, _ := key.Seal([]byte("plain"))
+
+	if _, err := Unlock(data, "two"); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Unlock with a wrong passphrase = %v", err)
+	}
+	if _, err := Unlock(data, ""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("Unlock with an empty passphrase = %v", err)
+	}
+	if _, err := NewKey(""); !errors.Is(err, ErrEmptyPassphrase) {
+		t.Errorf("NewKey(\"\") = %v", err)
+	}
+	// Та же фраза с другой солью - другой ключ
+	other, _ := NewKey("one")
+	if _, err := other.Open(data); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("Open with another salt = %v", err)
+	}
+}
+
+func TestTamperDetected(t *testing.T) {
+	key, _ := NewKey("pass")
+	data, _ := key.Seal([]byte("plain text"))
+
+	var file sealed
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	tamper := func(change func(f *sealed)) []byte {
+		f := file
+		f.Data = append([]byte(nil), file.Data...)
+		f.Nonce = append([]byte(nil), file.Nonce...)
+		change(&f)
+		out, _ := json.Marshal(f)
+		return out
+	}
+
+	if _, err := key.Open(tamper(func(f *sealed) { f.Data[0] ^= 1 })); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("changed data: %v", err)
+	}
+	if _, err := key.Open(tamper(func(f *sealed) { f.Nonce = f.Nonce[:5] })); !errors.Is(err, ErrCorrupt) {
+		t.Errorf("short nonce: %v", err)
+	}
+	// Параметры KDF входят в проверку: подмена заголовка не проходит
+	if _, err := key.Open(tamper(func(f *sealed) { f.Encrypted.KDF.Time++ })); !errors.Is(err, ErrWrongKey) {
+		t.Errorf("changed header: %v", err)
+	}
+	if _, err := Unlock(tamper(func(f *sealed) { f.Encrypted.KDF.Memory = maxMemory + 1 }), "pass"); err == nil {
+		t.Error("Unlock accepted excessive memory parameters")
+	}
+	if _, err := key.Open(tamper(func(f *sealed) { f.Encrypted.Cipher = "aes" })); err == nil || errors.Is(err, ErrCorrupt) {
+		t.Errorf("unknown cipher: %v", err)
+	}
+}
@@ -2,8 +2,10 @@ package wailsbind
 
 import (
 	"context"
+	"strings"
 	"todo-list/backend/internal/models"
 	"todo-list/backend/internal/service"
+	"todo-list/backend/internal/transfer"
 )
 
 // TaskAPI предоставляет API для работы с задачами в Wails
@@ -29,6 +31,100 @@ func (a *TaskAPI) GetAllTodos() ([]models.Todo, error) {
 	return a.service.Todo.GetAllTodos()
 }
 
+// GetTodosPage возвращает страницу задач; пустой cursor - первая страница
+func (a *TaskAPI) GetTodosPage(cursor string, limit int) (*models.TaskPage, error) {
+	return a.service.Todo.GetTodosPage(nil, nil, models.PageRequest{Cursor: cursor, Limit: limit})
+}
+
+// SearchTodos ищет задачи по заголовку и описанию
+func (a *TaskAPI) SearchTodos(query string, limit int) ([]models.SearchResult, error) {
+	return a.service.Todo.SearchTodos(query, limit)
+}
+
+// GetTodoTree возвращает дерево задачи rootID или все деревья задач, если rootID == 0
+func (a *TaskAPI) GetTodoTree(rootID uint) ([]*models.TodoNode, error) {
+	return a.service.Todo.GetTodoTree(rootID)
+}
+
+// MoveTodo переносит задачу под нового родителя; parentID == 0 - на верхний уровень
+func (a *TaskAPI) MoveTodo(id, parentID uint) error {
+	if parentID == 0 {
+		return a.service.Todo.MoveTodo(id, nil)
+	}
+	return a.service.Todo.MoveTodo(id, &parentID)
+}
+
+// GetTodoProgress возвращает число выполненных подзадач и их общее число
+func (a *TaskAPI) GetTodoProgress(id uint) (models.Progress, error) {
+	return a.service.Todo.GetTodoProgress(id)
+}
+
+// SetTodoRecurrence задает правило повторения задачи; пустая строка отключает повторение
+func (a *TaskAPI) SetTodoRecurrence(id uint, rule string) (*models.Todo, error) {
+	return a.service.Todo.SetTodoRecurrence(id, rule)
+}
+
+// SkipTodoOccurrence переносит повторяющуюся задачу на следующий срок без выполнения
+func (a *TaskAPI) SkipTodoOccurrence(id uint) (*models.Todo, error) {
+	return a.service.Todo.SkipTodoOccurrence(id)
+}
+
+// SetTodoReminders задает напоминания задачи в минутах до срока; пустой список - настройки по умолчанию
+func (a *TaskAPI) SetTodoReminders(id uint, reminders []int64) (*models.Todo, error) {
+	return a.service.Todo.SetTodoReminders(id, reminders)
+}
+
+// GetTodosByTags возвращает страницу задач хотя бы с одной из меток или, если matchAll, со всеми
+func (a *TaskAPI) GetTodosByTags(tags []string, matchAll bool, cursor string, limit int) (*models.TaskPage, error) {
+	filter := &models.TaskFilter{Tags: tags, TagMode: models.TagModeAny}
+	if matchAll {
+		filter.TagMode = models.TagModeAll
+	}
+	return a.service.Todo.GetTodosPage(filter, nil, models.PageRequest{Cursor: cursor, Limit: limit})
+}
+
+// SetTodoTags заменяет метки задачи; отсутствующие метки создаются
+func (a *TaskAPI) SetTodoTags(id uint, tags []string) (*models.Todo, error) {
+	return a.service.Tag.SetTodoTags(id, tags)
+}
+
+// GetAllTags возвращает все метки с числом задач
+func (a *TaskAPI) GetAllTags() ([]models.Tag, error) {
+	return a.service.Tag.GetAllTags()
+}
+
+// CreateTag создает метку
+func (a *TaskAPI) CreateTag(name, color string) (*models.Tag, error) {
+	tag := &models.Tag{Name: name, Color: color}
+	if err := a.service.Tag.CreateTag(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// RenameTag переименовывает метку; имя существующей метки вернет ошибку, такие метки объединяются через MergeTags
+func (a *TaskAPI) RenameTag(id uint, name string) (*models.Tag, error) {
+	tag, err := a.service.Tag.GetTagByID(id)
+	if err != nil {
+		return nil, err
+	}
+	tag.Name = name
+	if err := a.service.Tag.UpdateTag(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+// MergeTags переносит задачи с меток sourceIDs на targetID и удаляет исходные метки
+func (a *TaskAPI) MergeTags(sourceIDs []uint, targetID uint) (*models.Tag, error) {
+	return a.service.Tag.MergeTags(sourceIDs, targetID)
+}
+
+// DeleteTag удаляет метку и снимает ее со всех задач
+func (a *TaskAPI) DeleteTag(id uint) error {
+	return a.service.Tag.DeleteTag(id)
+}
+
 // CreateTodo создает новую задачу
 func (a *TaskAPI) CreateTodo(title, description string, priority string) (*models.Todo, error) {
 	todo := &models.Todo{
@@ -59,11 +155,62 @@ func (a *TaskAPI) UpdateTodo(id uint, title, description string, priority string
 	return a.service.Todo.UpdateTodo(todo)
 }
 
-// DeleteTodo удаляет задачу
+// DeleteTodo переносит задачу вместе с подзадачами в корзину
 func (a *TaskAPI) DeleteTodo(id uint) error {
 	return a.service.Todo.DeleteTodo(id)
 }
 
+// GetDeletedTodos возвращает задачи из корзины
+func (a *TaskAPI) GetDeletedTodos() ([]models.Todo, error) {
+	return a.service.Todo.GetDeletedTodos()
+}
+
+// RestoreTodo возвращает задачу из корзины вместе с подзадачами, удаленными вместе с ней
+func (a *TaskAPI) RestoreTodo(id uint) (*models.Todo, error) {
+	return a.service.Todo.RestoreTodo(id)
+}
+
+// GetTodoHistory возвращает журнал изменений задачи, новые записи первыми
+func (a *TaskAPI) GetTodoHistory(id uint, limit int) ([]models.Activity, error) {
+	return a.service.Todo.GetTodoHistory(id, limit)
+}
+
+// GetActivity возвращает ленту изменений всех задач; beforeID > 0 - записи старше beforeID
+func (a *TaskAPI) GetActivity(beforeID uint, limit int) ([]models.Activity, error) {
+	return a.service.Todo.GetActivity(beforeID, limit)
+}
+
+// ExportTodos возвращает все задачи и категории в формате format (json, csv, markdown, org);
+// columns переименовывает колонки CSV
+func (a *TaskAPI) ExportTodos(format string, columns map[string]string) (string, error) {
+	format, err := transfer.ParseFormat(format)
+	if err != nil {
+		return "", err
+	}
+	doc, err := a.service.Transfer.Export()
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err := transfer.Encode(&out, format, doc, transfer.Options{Columns: columns}); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// ImportTodos импортирует задачи из содержимого файла data; при dryRun только возвращает отчет
+func (a *TaskAPI) ImportTodos(format, data string, columns map[string]string, duplicates string, dryRun bool) (transfer.Report, error) {
+	format, err := transfer.ParseFormat(format)
+	if err != nil {
+		return transfer.Report{}, err
+	}
+	doc, err := transfer.Decode(strings.NewReader(data), format, transfer.Options{Columns: columns})
+	if err != nil {
+		return transfer.Report{}, err
+	}
+	return a.service.Transfer.Import(doc, transfer.ImportOptions{Duplicates: duplicates, DryRun: dryRun})
+}
+
 // ToggleTodoStatus переключает статус задачи
 func (a *TaskAPI) ToggleTodoStatus(id uint) error {
 	return a.service.Todo.ToggleTodoStatus(id)
@@ -98,3 +245,18 @@ func (a *TaskAPI) CreateCategory(name, color string) (*models.Category, error) {
 
 	return category, nil
 }
+
+// DeleteCategory переносит категорию в корзину; задачи категории остаются на месте
+func (a *TaskAPI) DeleteCategory(id uint) error {
+	return a.service.Category.DeleteCategory(id)
+}
+
+// GetDeletedCategories возвращает категории из корзины
+func (a *TaskAPI) GetDeletedCategories() ([]models.Category, error) {
+	return a.service.Category.GetDeletedCategories()
+}
+
+// RestoreCategory возвращает категорию из корзины
+func (a *TaskAPI) RestoreCategory(id uint) (*models.Category, error) {
+	return a.service.Category.RestoreCategory(id)
+}
@@ -0,0 +1,438 @@
+package backend
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"todo-list/backend/config"
+	"todo-list/backend/database"
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/repository"
+	"todo-list/backend/internal/service"
+)
+
+// PostgresStore реализует Store поверх слоя repository/service и PostgreSQL
+type PostgresStore struct {
+	db      *database.Database
+	service *service.Service
+}
+
+// NewPostgresStore подключается к базе данных, выполняет миграции и создает хранилище
+func NewPostgresStore(cfg *config.Config) (*PostgresStore, error) {
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := database.Migrate(db.DB); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	repo := repository.NewSourceRepository(db.DB, models.SourceApp, LocalActor())
+	return &PostgresStore{
+		db:      db,
+		service: service.NewService(repo),
+	}, nil
+}
+
+// DB возвращает подключение к базе данных, например для REST API
+func (s *PostgresStore) DB() *sql.DB {
+	return s.db.DB
+}
+
+// List возвращает все задачи
+func (s *PostgresStore) List() ([]Task, error) {
+	todos, err := s.service.Todo.GetAllTodos()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(todos))
+	for _, todo := range todos {
+		tasks = append(tasks, taskFromTodo(todo))
+	}
+	return tasks, nil
+}
+
+// ListPage возвращает страницу задач в порядке создания (новые первыми)
+func (s *PostgresStore) ListPage(completed *bool, cursor string, limit int) (TaskPage, error) {
+	filter := &models.TaskFilter{IsCompleted: completed}
+	result, err := s.service.Todo.GetTodosPage(filter, nil, models.PageRequest{Cursor: cursor, Limit: limit})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidCursor) {
+			return TaskPage{}, ErrInvalidCursor
+		}
+		return TaskPage{}, err
+	}
+
+	page := TaskPage{
+		Tasks:      make([]Task, 0, len(result.Items)),
+		NextCursor: result.NextCursor,
+		PrevCursor: result.PrevCursor,
+	}
+	for _, todo := range result.Items {
+		page.Tasks = append(page.Tasks, taskFromTodo(todo))
+	}
+	return page, nil
+}
+
+// Search выполняет полнотекстовый поиск в PostgreSQL
+func (s *PostgresStore) Search(query string, limit int) ([]SearchResult, error) {
+	found, err := s.service.Todo.SearchTodos(query, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptyQuery) {
+			return []SearchResult{}, nil
+		}
+		return nil, err
+	}
+
+	results := make([]SearchResult, 0, len(found))
+	for _, res := range found {
+		results = append(results, SearchResult{
+			Task:                 taskFromTodo(res.Todo),
+			Rank:                 res.Rank,
+			TitleHighlight:       res.TitleHighlight,
+			DescriptionHighlight: res.DescriptionHighlight,
+		})
+	}
+	return results, nil
+}
+
+// Get возвращает задачу по ID
+func (s *PostgresStore) Get(id int) (Task, error) {
+	if id <= 0 {
+		return Task{}, ErrTaskNotFound
+	}
+
+	todo, err := s.service.Todo.GetTodoByID(uint(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return Task{}, ErrTaskNotFound
+		}
+		return Task{}, err
+	}
+	return taskFromTodo(*todo), nil
+}
+
+// Create сохраняет новую задачу
+func (s *PostgresStore) Create(task Task) (Task, error) {
+	todo := todoFromTask(task)
+	if err := s.service.Todo.CreateTodo(&todo); err != nil {
+		return Task{}, err
+	}
+	return taskFromTodo(todo), nil
+}
+
+// Update сохраняет изменения задачи, сохраняя ее категорию
+func (s *PostgresStore) Update(task Task) error {
+	existing, err := s.service.Todo.GetTodoByID(uint(task.ID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrTaskNotFound
+		}
+		return err
+	}
+
+	todo := todoFromTask(task)
+	todo.CategoryID = existing.CategoryID
+	todo.CreatedAt = existing.CreatedAt
+	return s.service.Todo.UpdateTodo(&todo)
+}
+
+// Modify меняет задачу в одной транзакции с блокировкой ее строки; категория сохраняется
+func (s *PostgresStore) Modify(id int, fn func(task *Task) error) error {
+	if id <= 0 {
+		return ErrTaskNotFound
+	}
+	err := s.service.Todo.ModifyTodo(uint(id), func(todo *models.Todo) error {
+		task := taskFromTodo(*todo)
+		if err := fn(&task); err != nil {
+			return err
+		}
+		modified := todoFromTask(task)
+		modified.ID, modified.CategoryID, modified.CreatedAt = todo.ID, todo.CategoryID, todo.CreatedAt
+		*todo = modified
+		return nil
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTaskNotFound
+	}
+	return err
+}
+
+// Put сохраняет задачу с ее ID целиком; категория задачи (в том числе из корзины) сохраняется
+func (s *PostgresStore) Put(task Task) error {
+	todo := todoFromTask(task)
+	return s.service.Todo.PutTodo(&todo)
+}
+
+// Move переносит задачу под нового родителя
+func (s *PostgresStore) Move(id, parentID int) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+
+	var parent *uint
+	if parentID != 0 {
+		p := uint(parentID)
+		parent = &p
+	}
+
+	err := s.service.Todo.MoveTodo(uint(id), parent)
+	if errors.Is(err, service.ErrTaskCycle) {
+		return ErrTaskCycle
+	}
+	return err
+}
+
+// Delete удаляет задачу по ID
+func (s *PostgresStore) Delete(id int) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	return s.service.Todo.DeleteTodo(uint(id))
+}
+
+// Subtree возвращает задачу и всех ее потомков
+func (s *PostgresStore) Subtree(id int) ([]Task, error) {
+	if id <= 0 {
+		return nil, ErrTaskNotFound
+	}
+	todos, err := s.service.Todo.GetTodoSubtree(uint(id))
+	if err != nil {
+		return nil, err
+	}
+	if len(todos) == 0 {
+		return nil, ErrTaskNotFound
+	}
+	tasks := make([]Task, 0, len(todos))
+	for _, todo := range todos {
+		tasks = append(tasks, taskFromTodo(todo))
+	}
+	return tasks, nil
+}
+
+// Remove окончательно удаляет задачу, минуя корзину
+func (s *PostgresStore) Remove(id int) error {
+	if id <= 0 {
+		return ErrTaskNotFound
+	}
+	err := s.service.Todo.RemoveTodo(uint(id))
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTaskNotFound
+	}
+	return err
+}
+
+// Trash возвращает задачи из корзины
+func (s *PostgresStore) Trash() ([]Task, error) {
+	todos, err := s.service.Todo.GetDeletedTodos()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0, len(todos))
+	for _, todo := range todos {
+		tasks = append(tasks, taskFromTodo(todo))
+	}
+	return tasks, nil
+}
+
+// Restore возвращает задачу из корзины
+func (s *PostgresStore) Restore(id int) error {
+	if id <= 0 {
+		return ErrNotInTrash
+	}
+	_, err := s.service.Todo.RestoreTodo(uint(id))
+	if errors.Is(err, service.ErrNotInTrash) {
+		return ErrNotInTrash
+	}
+	return err
+}
+
+// Purge окончательно удаляет задачи и категории, попавшие в корзину раньше before
+func (s *PostgresStore) Purge(before time.Time) (int, error) {
+	purged, err := s.service.Todo.PurgeDeletedTodos(before)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := s.service.Category.PurgeDeletedCategories(before); err != nil {
+		return int(purged), err
+	}
+	return int(purged), nil
+}
+
+// Tags возвращает все метки с числом задач
+func (s *PostgresStore) Tags() ([]Tag, error) {
+	found, err := s.service.Tag.GetAllTags()
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]Tag, 0, len(found))
+	for _, tag := range found {
+		tags = append(tags, Tag{Name: tag.Name, Color: tag.Color, Count: tag.Count})
+	}
+	return tags, nil
+}
+
+// SaveTag создает метку или меняет цвет существующей
+func (s *PostgresStore) SaveTag(tag Tag) (Tag, error) {
+	existing, err := s.findTag(tag.Name)
+	switch {
+	case errors.Is(err, ErrTagNotFound):
+		created := &models.Tag{Name: tag.Name, Color: tag.Color}
+		if err := s.service.Tag.CreateTag(created); err != nil {
+			return Tag{}, tagError(err)
+		}
+		return Tag{Name: created.Name, Color: created.Color}, nil
+	case err != nil:
+		return Tag{}, err
+	}
+
+	if tag.Color != "" {
+		existing.Color = tag.Color
+		if err := s.service.Tag.UpdateTag(existing); err != nil {
+			return Tag{}, tagError(err)
+		}
+	}
+	return Tag{Name: existing.Name, Color: existing.Color, Count: existing.Count}, nil
+}
+
+// SetTaskTags заменяет метки задачи; отсутствующие метки создаются
+func (s *PostgresStore) SetTaskTags(id int, tags []string) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	_, err := s.service.Tag.SetTodoTags(uint(id), tags)
+	return tagError(err)
+}
+
+// RenameTag переименовывает метку
+func (s *PostgresStore) RenameTag(name, newName string) error {
+	tag, err := s.findTag(name)
+	if err != nil {
+		return err
+	}
+	tag.Name = newName
+	return tagError(s.service.Tag.UpdateTag(tag))
+}
+
+// MergeTags переносит задачи с меток sources на метку target и удаляет sources
+func (s *PostgresStore) MergeTags(sources []string, target string) error {
+	targetTag, err := s.findTag(target)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]uint, 0, len(sources))
+	for _, name := range sources {
+		tag, err := s.findTag(name)
+		if err != nil {
+			return err
+		}
+		ids = append(ids, tag.ID)
+	}
+
+	_, err = s.service.Tag.MergeTags(ids, targetTag.ID)
+	return tagError(err)
+}
+
+// DeleteTag удаляет метку и снимает ее со всех задач
+func (s *PostgresStore) DeleteTag(name string) error {
+	tag, err := s.findTag(name)
+	if err != nil {
+		return err
+	}
+	return tagError(s.service.Tag.DeleteTag(tag.ID))
+}
+
+// findTag ищет метку по имени без учета регистра
+func (s *PostgresStore) findTag(name string) (*models.Tag, error) {
+	tags, err := s.service.Tag.GetAllTags()
+	if err != nil {
+		return nil, err
+	}
+	for i := range tags {
+		if strings.EqualFold(tags[i].Name, name) {
+			return &tags[i], nil
+		}
+	}
+	return nil, ErrTagNotFound
+}
+
+// tagError переводит ошибки сервиса меток в ошибки хранилища
+func tagError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrTagExists):
+		return ErrTagExists
+	case errors.Is(err, service.ErrTagNotFound):
+		return ErrTagNotFound
+	default:
+		return err
+	}
+}
+
+// Close закрывает соединение с базой данных
+func (s *PostgresStore) Close() error {
+	return s.db.Close()
+}
+
+// taskFromTodo преобразует models.Todo в Task
+func taskFromTodo(todo models.Todo) Task {
+	task := Task{
+		ID:          int(todo.ID),
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completed:   todo.Completed,
+		Priority:    todo.Priority,
+		Recurrence:  todo.Recurrence,
+		Tags:        todo.Tags,
+		CreatedAt:   todo.CreatedAt,
+	}
+	if todo.DueDate != nil {
+		task.DueDate = *todo.DueDate
+	}
+	if todo.ParentID != nil {
+		task.ParentID = int(*todo.ParentID)
+	}
+	if todo.DeletedAt != nil {
+		task.DeletedAt = *todo.DeletedAt
+	}
+	for _, offset := range todo.Reminders {
+		task.Reminders = append(task.Reminders, int(offset))
+	}
+	return task
+}
+
+// todoFromTask преобразует Task в models.Todo
+func todoFromTask(task Task) models.Todo {
+	todo := models.Todo{
+		ID:          uint(task.ID),
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		Priority:    task.Priority,
+		Recurrence:  task.Recurrence,
+		Tags:        task.Tags,
+		CreatedAt:   task.CreatedAt,
+	}
+	if !task.DueDate.IsZero() {
+		due := task.DueDate
+		todo.DueDate = &due
+	}
+	if task.ParentID != 0 {
+		parentID := uint(task.ParentID)
+		todo.ParentID = &parentID
+	}
+	for _, offset := range task.Reminders {
+		todo.Reminders = append(todo.Reminders, int64(offset))
+	}
+	if todo.CreatedAt.IsZero() {
+		todo.CreatedAt = time.Now()
+	}
+	return todo
+}
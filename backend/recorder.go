@@ -0,0 +1,210 @@
+package backend
+
+import "errors"
+
+// recorder хранилище, через которое команды App меняют задачи. Перед первым изменением
+// задачи он запоминает ее состояние, и после команды в историю попадают только
+// затронутые задачи - хранилище целиком не перечитывается.
+type recorder struct {
+	Store
+	before   map[int]*Task // состояние до команды; nil - задачи не было или она в корзине
+	order    []int         // затронутые задачи в порядке первого изменения
+	restored map[int]bool  // задачи, возвращенные из корзины
+	all      bool          // команда затрагивает заранее неизвестные задачи: сравниваются все
+}
+
+func newRecorder(store Store) *recorder {
+	return &recorder{Store: store, before: make(map[int]*Task), restored: make(map[int]bool)}
+}
+
+// remember запоминает состояние задачи, если она еще не затронута командой
+func (r *recorder) remember(id int, task *Task) {
+	if _, ok := r.before[id]; ok {
+		return
+	}
+	r.before[id] = task
+	r.order = append(r.order, id)
+}
+
+// touch запоминает текущее состояние задачи перед ее изменением
+func (r *recorder) touch(id int) error {
+	if _, ok := r.before[id]; ok || r.all {
+		return nil
+	}
+	task, err := r.Store.Get(id)
+	switch {
+	case errors.Is(err, ErrTaskNotFound):
+		r.remember(id, nil)
+	case err != nil:
+		return err
+	default:
+		r.remember(id, &task)
+	}
+	return nil
+}
+
+// touchAll запоминает все задачи: для массовых изменений, затрагивающих
+// заранее неизвестные задачи (операции с метками, импорт в PostgreSQL)
+func (r *recorder) touchAll() error {
+	if r.all {
+		return nil
+	}
+	tasks, err := r.Store.List()
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		task := task
+		r.remember(task.ID, &task)
+	}
+	r.all = true
+	return nil
+}
+
+// changes возвращает изменения затронутых задач в порядке ID
+func (r *recorder) changes() ([]TaskChange, error) {
+	before := make(map[int]Task, len(r.before))
+	for id, task := range r.before {
+		if task != nil {
+			before[id] = *task
+		}
+	}
+
+	after := make(map[int]Task, len(r.order))
+	if r.all {
+		tasks, err := r.Store.List()
+		if err != nil {
+			return nil, err
+		}
+		for _, task := range tasks {
+			after[task.ID] = task
+		}
+	} else {
+		for _, id := range r.order {
+			task, err := r.Store.Get(id)
+			if errors.Is(err, ErrTaskNotFound) {
+				continue
+			} else if err != nil {
+				return nil, err
+			}
+			after[id] = task
+		}
+	}
+
+	changes := diffTasks(before, after)
+	for i := range changes {
+		changes[i].Created = changes[i].Before == nil && !r.restored[changes[i].ID]
+	}
+	return changes, nil
+}
+
+func (r *recorder) Create(task Task) (Task, error) {
+	created, err := r.Store.Create(task)
+	if err == nil {
+		r.remember(created.ID, nil)
+	}
+	return created, err
+}
+
+func (r *recorder) Update(task Task) error {
+	if err := r.touch(task.ID); err != nil {
+		return err
+	}
+	return r.Store.Update(task)
+}
+
+// Modify запоминает задачу в том состоянии, в котором ее прочитал сам Modify
+func (r *recorder) Modify(id int, fn func(task *Task) error) error {
+	return r.Store.Modify(id, func(task *Task) error {
+		before := *task
+		if err := fn(task); err != nil {
+			return err
+		}
+		if !r.all {
+			r.remember(id, &before)
+		}
+		return nil
+	})
+}
+
+func (r *recorder) Put(task Task) error {
+	if err := r.touch(task.ID); err != nil {
+		return err
+	}
+	return r.Store.Put(task)
+}
+
+func (r *recorder) Move(id, parentID int) error {
+	if err := r.touch(id); err != nil {
+		return err
+	}
+	return r.Store.Move(id, parentID)
+}
+
+// Delete запоминает все поддерево: хранилище переносит в корзину и подзадачи
+func (r *recorder) Delete(id int) error {
+	if !r.all {
+		tasks, err := r.Store.Subtree(id)
+		if err != nil {
+			return err
+		}
+		for _, task := range tasks {
+			task := task
+			r.remember(task.ID, &task)
+		}
+	}
+	return r.Store.Delete(id)
+}
+
+func (r *recorder) Remove(id int) error {
+	if err := r.touch(id); err != nil {
+		return err
+	}
+	return r.Store.Remove(id)
+}
+
+// Restore запоминает возвращенные задачи: до команды их не было среди задач
+func (r *recorder) Restore(id int) error {
+	if err := r.Store.Restore(id); err != nil {
+		return err
+	}
+	tasks, err := r.Store.Subtree(id)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if _, ok := r.before[task.ID]; !ok {
+			r.restored[task.ID] = true
+		}
+		r.remember(task.ID, nil)
+	}
+	return nil
+}
+
+func (r *recorder) SetTaskTags(id int, tags []string) error {
+	if err := r.touch(id); err != nil {
+		return err
+	}
+	return r.Store.SetTaskTags(id, tags)
+}
+
+func (r *recorder) RenameTag(name, newName string) error {
+	if err := r.touchAll(); err != nil {
+		return err
+	}
+	return r.Store.RenameTag(name, newName)
+}
+
+func (r *recorder) MergeTags(sources []string, target string) error {
+	if err := r.touchAll(); err != nil {
+		return err
+	}
+	return r.Store.MergeTags(sources, target)
+}
+
+func (r *recorder) DeleteTag(name string) error {
+	if err := r.touchAll(); err != nil {
+		return err
+	}
+	return r.Store.DeleteTag(name)
+}
@@ -0,0 +1,123 @@
+package backend
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"todo-list/backend/internal/recurrence"
+)
+
+// ErrRecurrenceDueDate возвращается, если у повторяющейся задачи нет срока выполнения
+var ErrRecurrenceDueDate = errors.New("для повторяющейся задачи нужен срок выполнения")
+
+// errNotRecurring пропуск повторения у задачи, которая не повторяется
+var errNotRecurring = errors.New("задача не повторяется")
+
+// AddRecurringTask добавляет повторяющуюся задачу. rule - правило в формате RRULE,
+// например "FREQ=WEEKLY;BYDAY=MO,WE" или "FREQ=MONTHLY;BYDAY=-1FR;COUNT=12",
+// с необязательной строкой "EXDATE:..." для пропущенных повторений.
+func (a *App) AddRecurringTask(title, description, priority, dueDate, rule string) Task {
+	if rule == "" {
+		return Task{}
+	}
+
+	var task Task
+	a.record("Добавление повторяющейся задачи", func() bool {
+		task = a.addTask(0, title, description, priority, dueDate, rule)
+		return task.ID != 0
+	})
+	return task
+}
+
+// SetTaskRecurrence задает правило повторения задачи; пустая строка отключает повторение
+func (a *App) SetTaskRecurrence(id int, rule string) bool {
+	return a.record("Изменение повторения", func() bool { return a.setTaskRecurrence(id, rule) })
+}
+
+func (a *App) setTaskRecurrence(id int, rule string) bool {
+	return a.modify(id, func(task *Task) error {
+		var err error
+		task.Recurrence, err = normalizeRule(rule, task.DueDate)
+		return err
+	})
+}
+
+// SkipOccurrence переносит повторяющуюся задачу на следующий срок без выполнения.
+// Если серия закончилась, задача перестает повторяться.
+func (a *App) SkipOccurrence(id int) bool {
+	return a.record("Пропуск повторения", func() bool { return a.skipOccurrence(id) })
+}
+
+func (a *App) skipOccurrence(id int) bool {
+	return a.modify(id, func(task *Task) error {
+		if task.Recurrence == "" {
+			return errNotRecurring
+		}
+		if next, ok := nextOccurrence(*task); ok {
+			task.DueDate = next.DueDate
+			task.Recurrence = next.Recurrence
+		} else {
+			task.Recurrence = ""
+		}
+		return nil
+	})
+}
+
+// PreviewRecurrence возвращает до count ближайших повторений после dueDate
+// (формат 2006-01-02T15:04). При ошибке в правиле возвращается пустой список.
+func (a *App) PreviewRecurrence(rule, dueDate string, count int) []time.Time {
+	due, err := parseDue(dueDate)
+	if err != nil {
+		return []time.Time{}
+	}
+	parsed, err := recurrence.Parse(rule)
+	if err != nil {
+		return []time.Time{}
+	}
+	return append([]time.Time{}, parsed.Occurrences(due, count)...)
+}
+
+// normalizeRule проверяет правило и приводит его к каноническому виду
+func normalizeRule(rule string, due time.Time) (string, error) {
+	if rule == "" {
+		return "", nil
+	}
+	parsed, err := recurrence.Parse(rule)
+	if err != nil {
+		return "", err
+	}
+	if due.IsZero() {
+		return "", ErrRecurrenceDueDate
+	}
+	return parsed.String(), nil
+}
+
+// nextOccurrence возвращает следующее повторение задачи; false - серия закончилась
+func nextOccurrence(task Task) (Task, bool) {
+	if task.Recurrence == "" || task.DueDate.IsZero() {
+		return Task{}, false
+	}
+	rule, err := recurrence.Parse(task.Recurrence)
+	if err != nil {
+		log.Printf("Error parsing recurrence of task %d: %v", task.ID, err)
+		return Task{}, false
+	}
+
+	due, ok := rule.Next(task.DueDate)
+	if !ok {
+		return Task{}, false
+	}
+
+	return Task{
+		Title:       task.Title,
+		Description: task.Description,
+		Priority:    task.Priority,
+		DueDate:     due,
+		ParentID:    task.ParentID,
+		Recurrence:  rule.Advance(due).String(),
+		Reminders:   task.Reminders,
+		Tags:        task.Tags,
+		CreatedAt:   time.Now(),
+	}, true
+}
@@ -0,0 +1,91 @@
+package backend
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"todo-list/backend/internal/reminder"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// ReminderEvent событие Wails, с которым приходит напоминание (reminder.Reminder)
+const ReminderEvent = "reminder"
+
+// ReminderSettings настройки напоминаний: смещения по умолчанию и тихие часы
+type ReminderSettings = reminder.Settings
+
+// startReminders запускает планировщик напоминаний с доставкой через события Wails
+func (a *App) startReminders(ctx context.Context) {
+	a.reminders.Start(ctx, func(r reminder.Reminder) {
+		runtime.EventsEmit(ctx, ReminderEvent, r)
+	})
+}
+
+// reminderItems возвращает открытые задачи со сроком для планировщика напоминаний
+func (a *App) reminderItems() ([]reminder.Item, error) {
+	tasks, err := a.store.List()
+	if err != nil {
+		return nil, err
+	}
+
+	var items []reminder.Item
+	for _, task := range tasks {
+		if task.Completed || task.DueDate.IsZero() {
+			continue
+		}
+		items = append(items, reminder.Item{
+			TaskID:  task.ID,
+			Title:   task.Title,
+			DueDate: task.DueDate,
+			Offsets: task.Reminders,
+		})
+	}
+	return items, nil
+}
+
+// SetTaskReminders задает напоминания задачи в минутах до срока, например [1440, 15] -
+// за сутки и за 15 минут. Пустой список - напоминания по умолчанию из настроек.
+func (a *App) SetTaskReminders(id int, offsets []int) bool {
+	return a.record("Изменение напоминаний", func() bool { return a.setTaskReminders(id, offsets) })
+}
+
+func (a *App) setTaskReminders(id int, offsets []int) bool {
+	reminders, err := reminder.NormalizeOffsets(offsets)
+	if err != nil {
+		log.Printf("Error setting reminders of task %d: %v", id, err)
+		return false
+	}
+	if len(reminders) == 0 {
+		reminders = nil
+	}
+	return a.modify(id, func(task *Task) error {
+		task.Reminders = reminders
+		return nil
+	})
+}
+
+// SnoozeReminder откладывает напоминания о задаче на minutes минут
+func (a *App) SnoozeReminder(taskID, minutes int) bool {
+	if err := a.reminders.Snooze(taskID, time.Duration(minutes)*time.Minute); err != nil {
+		log.Printf("Error snoozing reminder for task %d: %v", taskID, err)
+		return false
+	}
+	return true
+}
+
+// GetReminderSettings возвращает настройки напоминаний
+func (a *App) GetReminderSettings() ReminderSettings {
+	return a.reminders.Settings()
+}
+
+// SetReminderSettings сохраняет настройки напоминаний.
+// Тихие часы задаются как "22:00" и "07:00"; пустые строки отключают их.
+func (a *App) SetReminderSettings(settings ReminderSettings) bool {
+	if err := a.reminders.SetSettings(settings); err != nil {
+		log.Printf("Error saving reminder settings: %v", err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package backend
+
+import (
+	"testing"
+	"time"
+)
+
+// Срок из интерфейса - местное время пользователя, как и в todo.txt и при импорте
+func TestDueDateIsLocalTime(t *testing.T) {
+	a, _ := newTestApp(t)
+	want := time.Date(2026, 3, 1, 18, 0, 0, 0, time.Local)
+
+	task := a.AddTask("Созвон", "", "medium", "2026-03-01T18:00")
+	if !task.DueDate.Equal(want) || task.DueDate.Location() != time.Local {
+		t.Errorf("created due = %v", task.DueDate)
+	}
+	if !a.UpdateTask(task.ID, "Созвон", "", "medium", "2026-03-02T18:00") {
+		t.Fatal("UpdateTask failed")
+	}
+	items, err := a.reminderItems()
+	if err != nil || len(items) != 1 || !items[0].DueDate.Equal(want.AddDate(0, 0, 1)) {
+		t.Errorf("reminder items = %+v, %v", items, err)
+	}
+	if a.UpdateTask(task.ID, "Созвон", "", "medium", "завтра") {
+		t.Error("UpdateTask accepted an invalid due date")
+	}
+
+	next := a.PreviewRecurrence("FREQ=DAILY", "2026-03-01T18:00", 1)
+	if len(next) != 1 || !next[0].Equal(want.AddDate(0, 0, 1)) {
+		t.Errorf("preview = %v", next)
+	}
+}
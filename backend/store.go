@@ -0,0 +1,153 @@
+package backend
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"todo-list/backend/config"
+	"todo-list/backend/internal/service"
+)
+
+// ErrTaskNotFound возвращается хранилищем, если задачи с указанным ID нет
+var ErrTaskNotFound = errors.New("задача не найдена")
+
+// ErrNotInTrash возвращается при восстановлении задачи, которой нет в корзине
+var ErrNotInTrash = errors.New("задача не найдена в корзине")
+
+// TaskPage страница задач для постраничной загрузки
+type TaskPage struct {
+	Tasks      []Task `json:"tasks"`
+	NextCursor string `json:"next_cursor"`
+	PrevCursor string `json:"prev_cursor"`
+}
+
+// SearchResult задача, найденная поиском, с релевантностью и подсветкой совпадений
+type SearchResult struct {
+	Task
+	Rank                 float64 `json:"rank"`
+	TitleHighlight       string  `json:"title_highlight"`
+	DescriptionHighlight string  `json:"description_highlight"`
+}
+
+// Store описывает хранилище задач, с которым работает App.
+// Реализации: TaskManager (JSON-файл) и PostgresStore (PostgreSQL).
+type Store interface {
+	// List возвращает все задачи
+	List() ([]Task, error)
+	// ListPage возвращает страницу задач после (или до) курсора;
+	// completed ограничивает выборку по статусу, nil - все задачи
+	ListPage(completed *bool, cursor string, limit int) (TaskPage, error)
+	// Search ищет задачи по заголовку и описанию, лучшие совпадения первыми
+	Search(query string, limit int) ([]SearchResult, error)
+	// Get возвращает задачу по ID или ErrTaskNotFound
+	Get(id int) (Task, error)
+	// Create сохраняет новую задачу и возвращает ее с присвоенным ID
+	Create(task Task) (Task, error)
+	// Update сохраняет изменения существующей задачи; метки меняются только через SetTaskTags
+	Update(task Task) error
+	// Modify читает задачу, меняет ее функцией fn и сохраняет за одну блокировку
+	// (транзакцию), поэтому чужое изменение между чтением и записью не затирается.
+	// Ошибка fn отменяет изменение; родитель и метки задачи fn не меняет.
+	Modify(id int, fn func(task *Task) error) error
+	// Put сохраняет задачу с ее ID целиком (вместе с родителем и метками),
+	// создавая ее, если такой нет; используется для отмены действий
+	Put(task Task) error
+	// Move переносит задачу под parentID (0 - на верхний уровень)
+	Move(id, parentID int) error
+	// Subtree возвращает задачу и всех ее потомков или ErrTaskNotFound
+	Subtree(id int) ([]Task, error)
+	// Delete переносит задачу вместе с подзадачами в корзину
+	Delete(id int) error
+	// Remove окончательно удаляет задачу, минуя корзину; используется для отмены создания
+	Remove(id int) error
+	// Trash возвращает задачи из корзины, недавно удаленные первыми
+	Trash() ([]Task, error)
+	// Restore возвращает задачу из корзины вместе с подзадачами, удаленными вместе с ней;
+	// ErrNotInTrash, если ее там нет
+	Restore(id int) error
+	// Purge окончательно удаляет задачи, попавшие в корзину раньше before,
+	// и возвращает их число
+	Purge(before time.Time) (int, error)
+	// Tags возвращает все метки с числом задач
+	Tags() ([]Tag, error)
+	// SaveTag создает метку или меняет цвет существующей
+	SaveTag(tag Tag) (Tag, error)
+	// SetTaskTags заменяет метки задачи; отсутствующие метки создаются
+	SetTaskTags(id int, tags []string) error
+	// RenameTag переименовывает метку; ErrTagExists, если имя уже занято
+	RenameTag(name, newName string) error
+	// MergeTags переносит задачи с меток sources на метку target и удаляет sources
+	MergeTags(sources []string, target string) error
+	// DeleteTag удаляет метку и снимает ее со всех задач
+	DeleteTag(name string) error
+	// History возвращает журнал изменений задачи, новые записи первыми
+	History(id int, limit int) ([]Activity, error)
+	// Activity возвращает ленту изменений всех задач; beforeID > 0 - записи старше beforeID
+	Activity(beforeID, limit int) ([]Activity, error)
+	// Close освобождает ресурсы хранилища
+	Close() error
+}
+
+// NewStore создает хранилище в соответствии с конфигурацией
+func NewStore(cfg *config.Config) (Store, error) {
+	switch cfg.Storage {
+	case config.StorageJSON, "":
+		tm, err := NewTaskManager(cfg.DataFile)
+		if err != nil {
+			return nil, err
+		}
+		// Без парольной фразы зашифрованный файл остается запертым до UnlockStore
+		if cfg.Passphrase != "" {
+			if err := tm.Unlock(cfg.Passphrase); err != nil {
+				return nil, err
+			}
+		}
+		return tm, nil
+	case config.StorageTodoTxt:
+		return NewTodoTxtManager(cfg.DataFile)
+	case config.StoragePostgres:
+		return NewPostgresStore(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage)
+	}
+}
+
+// ErrInvalidCursor возвращается, если курсор страницы не удалось разобрать
+var ErrInvalidCursor = errors.New("некорректный курсор пагинации")
+
+// pageLimit приводит размер страницы к допустимому диапазону
+func pageLimit(limit int) int {
+	switch {
+	case limit <= 0:
+		return service.DefaultPageSize
+	case limit > service.MaxPageSize:
+		return service.MaxPageSize
+	default:
+		return limit
+	}
+}
+
+// encodeIDCursor кодирует курсор по ID задачи; back - курсор предыдущей страницы
+func encodeIDCursor(id int, back bool) string {
+	prefix := "a"
+	if back {
+		prefix = "b"
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(prefix + strconv.Itoa(id)))
+}
+
+// decodeIDCursor разбирает курсор, созданный encodeIDCursor
+func decodeIDCursor(cursor string) (id int, back bool, err error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(data) < 2 || (data[0] != 'a' && data[0] != 'b') {
+		return 0, false, ErrInvalidCursor
+	}
+	id, err = strconv.Atoi(string(data[1:]))
+	if err != nil {
+		return 0, false, ErrInvalidCursor
+	}
+	return id, data[0] == 'b', nil
+}
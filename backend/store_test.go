@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"path/filepath"
+	"testing"
+
+	"todo-list/backend/config"
+)
+
+func TestNewStore(t *testing.T) {
+	dir := t.TempDir()
+	for _, storage := range []string{"", config.StorageJSON, config.StorageTodoTxt} {
+		t.Run("storage "+storage, func(t *testing.T) {
+			store, err := NewStore(&config.Config{Storage: storage, DataFile: filepath.Join(dir, storage+"tasks")})
+			if err != nil {
+				t.Fatalf("NewStore: %v", err)
+			}
+			defer store.Close()
+			if _, ok := store.(*TaskManager); !ok {
+				t.Errorf("store = %T", store)
+			}
+		})
+	}
+
+	if _, err := NewStore(&config.Config{Storage: "mongo"}); err == nil {
+		t.Error("NewStore accepted an unknown backend")
+	}
+}
+
+// Поведение App одинаково для всех файловых хранилищ
+func TestAppOverStores(t *testing.T) {
+	for _, storage := range []string{config.StorageJSON, config.StorageTodoTxt} {
+		t.Run(storage, func(t *testing.T) {
+			dir := t.TempDir()
+			cfg := &config.Config{
+				Storage:       storage,
+				DataFile:      filepath.Join(dir, "tasks"),
+				HistoryFile:   filepath.Join(dir, "history.json"),
+				RemindersFile: filepath.Join(dir, "reminders.json"),
+			}
+			store, err := NewStore(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			a := NewApp(store, cfg)
+
+			first := a.AddTask("Первая", "описание", "high", "2026-03-01T10:00")
+			second := a.AddTask("Вторая", "", "low", "")
+			if first.ID == 0 || second.ID == 0 || first.ID == second.ID {
+				t.Fatalf("created IDs %d, %d", first.ID, second.ID)
+			}
+			if a.AddTask("", "", "low", "").ID != 0 {
+				t.Error("task with an empty title was created")
+			}
+			if !a.ToggleTask(first.ID) || !a.DeleteTask(second.ID) {
+				t.Fatal("ToggleTask or DeleteTask failed")
+			}
+			if a.ToggleTask(100) || a.DeleteTask(100) {
+				t.Error("missing task was changed")
+			}
+			store.Close()
+
+			// Изменения сохранены в файле и видны после повторного открытия
+			store, err = NewStore(cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+			tasks := NewApp(store, cfg).GetTasks()
+			if len(tasks) != 1 || tasks[0].Title != "Первая" || !tasks[0].Completed || tasks[0].Priority != "high" {
+				t.Errorf("tasks = %+v", tasks)
+			}
+			if trash, _ := store.Trash(); len(trash) != 1 || trash[0].ID != second.ID {
+				t.Errorf("trash = %+v", trash)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package backend
+
+import (
+	"errors"
+	"log"
+	"strings"
+)
+
+// Ошибки меток
+var (
+	ErrTagNotFound = errors.New("метка не найдена")
+	ErrTagExists   = errors.New("метка с таким именем уже существует")
+)
+
+// Tag метка задачи. Имена меток уникальны без учета регистра.
+type Tag struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+	Count int    `json:"count"` // число задач с меткой
+}
+
+// GetTags возвращает все метки с числом задач
+func (a *App) GetTags() []Tag {
+	tags, err := a.store.Tags()
+	if err != nil {
+		log.Printf("Error loading tags: %v", err)
+		return []Tag{}
+	}
+	return tags
+}
+
+// CreateTag создает метку или меняет цвет существующей
+func (a *App) CreateTag(name, color string) Tag {
+	tag, err := a.store.SaveTag(Tag{Name: name, Color: color})
+	if err != nil {
+		log.Printf("Error saving tag %q: %v", name, err)
+		return Tag{}
+	}
+	return tag
+}
+
+// SetTaskTags заменяет метки задачи; отсутствующие метки создаются
+func (a *App) SetTaskTags(id int, tags []string) bool {
+	return a.record("Изменение меток задачи", func() bool {
+		if err := a.writer().SetTaskTags(id, tags); err != nil {
+			log.Printf("Error setting tags of task %d: %v", id, err)
+			return false
+		}
+		return true
+	})
+}
+
+// RenameTag переименовывает метку. Если метка newName уже есть, переименование
+// не выполняется: такие метки объединяются через MergeTags.
+func (a *App) RenameTag(name, newName string) bool {
+	return a.record("Переименование метки", func() bool {
+		if err := a.writer().RenameTag(name, newName); err != nil {
+			log.Printf("Error renaming tag %q: %v", name, err)
+			return false
+		}
+		return true
+	})
+}
+
+// MergeTags переносит задачи с меток sources на метку target и удаляет sources
+func (a *App) MergeTags(sources []string, target string) bool {
+	return a.record("Объединение меток", func() bool {
+		if err := a.writer().MergeTags(sources, target); err != nil {
+			log.Printf("Error merging tags into %q: %v", target, err)
+			return false
+		}
+		return true
+	})
+}
+
+// DeleteTag удаляет метку и снимает ее со всех задач.
+// Отмена возвращает метку задачам, но не ее цвет в реестре меток.
+func (a *App) DeleteTag(name string) bool {
+	return a.record("Удаление метки", func() bool {
+		if err := a.writer().DeleteTag(name); err != nil {
+			log.Printf("Error deleting tag %q: %v", name, err)
+			return false
+		}
+		return true
+	})
+}
+
+// GetTasksByTags возвращает задачи хотя бы с одной из меток или, если matchAll, со всеми метками
+func (a *App) GetTasksByTags(tags []string, matchAll bool) []Task {
+	result := []Task{}
+	for _, task := range a.listTasks() {
+		if hasTags(task, tags, matchAll) {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// hasTags проверяет метки задачи без учета регистра; пустой список подходит любой задаче
+func hasTags(task Task, tags []string, matchAll bool) bool {
+	if len(tags) == 0 {
+		return true
+	}
+
+	matched := 0
+	for _, tag := range tags {
+		if indexTag(task.Tags, tag) >= 0 {
+			if !matchAll {
+				return true
+			}
+			matched++
+		} else if matchAll {
+			return false
+		}
+	}
+	return matched > 0
+}
+
+// indexTag ищет метку в списке без учета регистра
+func indexTag(tags []string, name string) int {
+	for i, tag := range tags {
+		if strings.EqualFold(tag, name) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,181 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+// tagsOf возвращает метки задач по ID и реестр меток с числом задач
+func tagsOf(t *testing.T, tm *TaskManager) (map[int][]string, string) {
+	t.Helper()
+	tasks, err := tm.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := map[int][]string{}
+	for _, task := range tasks {
+		byID[task.ID] = task.Tags
+	}
+	tags, err := tm.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry := ""
+	for _, tag := range tags {
+		registry += fmt.Sprintf("%s:%d ", tag.Name, tag.Count)
+	}
+	return byID, registry
+}
+
+// newTaggedManager создает задачи 1 [Work home], 2 [work] и 3 [urgent]
+func newTaggedManager(t *testing.T) *TaskManager {
+	t.Helper()
+	tm, _ := newTestManager(t)
+	for _, tags := range [][]string{{"home", "Work", "work"}, {"work"}, {"urgent"}} {
+		if _, err := tm.Create(Task{Title: "task", Priority: "medium", Tags: tags}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return tm
+}
+
+func TestTaskTags(t *testing.T) {
+	tm := newTaggedManager(t)
+	// Повторы без учета регистра убираются, регистр берется из реестра
+	tasks, registry := tagsOf(t, tm)
+	if fmt.Sprint(tasks[1], tasks[2]) != "[home Work] [Work]" || registry != "home:1 urgent:1 Work:2 " {
+		t.Fatalf("tasks = %v, registry = %q", tasks, registry)
+	}
+
+	if err := tm.SetTaskTags(3, []string{"bad tag"}); err == nil {
+		t.Error("SetTaskTags accepted a tag with a space")
+	}
+	if err := tm.SetTaskTags(100, []string{"work"}); err != ErrTaskNotFound {
+		t.Errorf("SetTaskTags of a missing task = %v", err)
+	}
+	if tag, err := tm.SaveTag(Tag{Name: "WORK", Color: "#ff0000"}); err != nil || tag.Name != "Work" || tag.Color != "#ff0000" {
+		t.Errorf("SaveTag = %+v, %v", tag, err)
+	}
+}
+
+func TestRenameTag(t *testing.T) {
+	tm := newTaggedManager(t)
+	if err := tm.RenameTag("work", "job"); err != nil {
+		t.Fatal(err)
+	}
+	tasks, registry := tagsOf(t, tm)
+	if fmt.Sprint(tasks[1], tasks[2]) != "[home job] [job]" || registry != "home:1 job:2 urgent:1 " {
+		t.Errorf("tasks = %v, registry = %q", tasks, registry)
+	}
+
+	// Смена регистра - переименование той же метки
+	if err := tm.RenameTag("job", "Job"); err != nil {
+		t.Errorf("RenameTag to another case: %v", err)
+	}
+	if err := tm.RenameTag("job", "urgent"); !errors.Is(err, ErrTagExists) {
+		t.Errorf("RenameTag onto an existing tag = %v", err)
+	}
+	if err := tm.RenameTag("missing", "x"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("RenameTag of a missing tag = %v", err)
+	}
+	if err := tm.RenameTag("job", "a,b"); err == nil {
+		t.Error("RenameTag accepted a comma")
+	}
+}
+
+func TestMergeTags(t *testing.T) {
+	tm := newTaggedManager(t)
+	if err := tm.MergeTags([]string{"home", "urgent", "WORK"}, "work"); err != nil {
+		t.Fatal(err)
+	}
+	tasks, registry := tagsOf(t, tm)
+	if fmt.Sprint(tasks[1], tasks[2], tasks[3]) != "[Work] [Work] [Work]" || registry != "Work:3 " {
+		t.Errorf("tasks = %v, registry = %q", tasks, registry)
+	}
+	if err := tm.MergeTags([]string{"missing"}, "work"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("MergeTags of a missing source = %v", err)
+	}
+	if err := tm.MergeTags([]string{"work"}, "missing"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("MergeTags into a missing target = %v", err)
+	}
+}
+
+func TestDeleteTag(t *testing.T) {
+	tm := newTaggedManager(t)
+	if err := tm.DeleteTag("WORK"); err != nil {
+		t.Fatal(err)
+	}
+	tasks, registry := tagsOf(t, tm)
+	if fmt.Sprint(tasks[1], tasks[2]) != "[home] []" || registry != "home:1 urgent:1 " {
+		t.Errorf("tasks = %v, registry = %q", tasks, registry)
+	}
+	if err := tm.DeleteTag("work"); !errors.Is(err, ErrTagNotFound) {
+		t.Errorf("second DeleteTag = %v", err)
+	}
+}
+
+func TestGetTasksByTags(t *testing.T) {
+	a, tm := newTestApp(t)
+	for _, tags := range [][]string{{"home", "work"}, {"work"}, {"urgent"}} {
+		if _, err := tm.Create(Task{Title: "task", Priority: "medium", Tags: tags}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tt := range []struct {
+		tags     []string
+		matchAll bool
+		want     string
+	}{
+		{nil, false, "[1 2 3]"},
+		{[]string{"WORK", "urgent"}, false, "[1 2 3]"},
+		{[]string{"work", "home"}, true, "[1]"},
+		{[]string{"work", "missing"}, true, "[]"},
+	} {
+		if got := fmt.Sprint(taskIDs(a.GetTasksByTags(tt.tags, tt.matchAll))); got != tt.want {
+			t.Errorf("GetTasksByTags(%v, %v) = %s, want %s", tt.tags, tt.matchAll, got, tt.want)
+		}
+	}
+}
+
+// Массовые изменения меток попадают в журнал каждой затронутой задачи
+func TestTagChangesRecordActivity(t *testing.T) {
+	tm := newTaggedManager(t)
+	if err := tm.RenameTag("work", "job"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.MergeTags([]string{"urgent"}, "home"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tm.DeleteTag("job"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[int][]string{
+		1: {"home,Work -> home,job", "home,job -> home"},
+		2: {"Work -> job", "job -> "},
+		3: {"urgent -> home"},
+	}
+	for id, changes := range want {
+		history, err := tm.History(id, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for i := len(history) - 1; i >= 0; i-- {
+			entry := history[i]
+			if entry.Action == "create" {
+				continue
+			}
+			if entry.Action != "update" || len(entry.Changes) != 1 || entry.Changes[0].Field != "tags" {
+				t.Errorf("task %d entry = %+v", id, entry)
+				continue
+			}
+			got = append(got, entry.Changes[0].Old+" -> "+entry.Changes[0].New)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(changes) {
+			t.Errorf("task %d tag changes = %q, want %q", id, got, changes)
+		}
+	}
+}
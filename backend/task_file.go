@@ -0,0 +1,264 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"todo-list/backend/internal/fsutil"
+	"todo-list/backend/internal/vault"
+)
+
+// dataBackups сколько предыдущих версий файла задач хранится рядом с ним
+// (.bak1 - самая свежая)
+const dataBackups = 3
+
+// dataFileMode права новых файлов задач и их копий: читать их может только владелец
+const dataFileMode = 0600
+
+// ErrCorruptData возвращается, если файл задач поврежден и ни одна резервная копия не подошла
+var ErrCorruptData = errors.New("файл задач поврежден")
+
+// errChecksum контрольная сумма данных не совпала с записанной
+var errChecksum = errors.New("checksum mismatch")
+
+// taskFile содержимое файла задач
+type taskFile struct {
+	Tasks    []Task     `json:"tasks"`
+	Trash    []Task     `json:"trash"`
+	Tags     []Tag      `json:"tags"`
+	Activity []Activity `json:"activity"`
+	NextID   int        `json:"next_id"`
+
+	upgradedFrom int // версия формата, из которой данные переведены при чтении; 0 - не переводились
+}
+
+// fileFormat формат файла задач. Сведения, которых нет в основном формате, записываются
+// в файл метаданных рядом с ним (filename.meta); nil meta - файл метаданных не нужен.
+type fileFormat interface {
+	encode(content taskFile) (data, meta []byte, err error)
+	decode(data, meta []byte) (taskFile, error)
+}
+
+// jsonFormat основной формат: JSON-файл с контрольной суммой
+type jsonFormat struct{}
+
+func (jsonFormat) encode(content taskFile) ([]byte, []byte, error) {
+	data, err := encodeTaskFile(content)
+	return data, nil, err
+}
+
+func (jsonFormat) decode(data, _ []byte) (taskFile, error) {
+	if vault.IsSealed(data) {
+		return taskFile{}, ErrStoreLocked
+	}
+	return decodeTaskFile(data)
+}
+
+// fileEnvelope обертка файла задач с версией формата и контрольной суммой данных.
+// Файлы старых версий без обертки или без версии читаются как версия 1.
+type fileEnvelope struct {
+	FormatVersion int             `json:"format_version"`
+	Checksum      string          `json:"checksum"` // sha256 данных в компактном виде
+	Data          json.RawMessage `json:"data"`
+}
+
+// encodeTaskFile кодирует задачи в обертку с версией формата и контрольной суммой
+func encodeTaskFile(content taskFile) ([]byte, error) {
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+	envelope := fileEnvelope{FormatVersion: fileFormatVersion, Checksum: checksum(data), Data: data}
+	return json.MarshalIndent(envelope, "", "  ")
+}
+
+// decodeTaskFile разбирает файл задач, проверяет контрольную сумму и переводит данные
+// старых версий в текущую. Файл более новой версии не читается: ErrNewerFormat.
+func decodeTaskFile(raw []byte) (taskFile, error) {
+	var content taskFile
+
+	var envelope fileEnvelope
+	if err := json.Unmarshal(raw, &envelope); err != nil {
+		return content, err
+	}
+	// Файл без обертки - формат до появления контрольной суммы
+	data, version := raw, 1
+	if envelope.Data != nil {
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, envelope.Data); err != nil {
+			return content, err
+		}
+		if checksum(compact.Bytes()) != envelope.Checksum {
+			return content, errChecksum
+		}
+		data, version = envelope.Data, max(envelope.FormatVersion, 1)
+	}
+
+	if version > fileFormatVersion {
+		return content, fmt.Errorf("%w: версия формата %d, поддерживается до %d",
+			ErrNewerFormat, version, fileFormatVersion)
+	}
+	if version < fileFormatVersion {
+		upgraded, err := upgradeTaskData(data, version)
+		if err != nil {
+			return content, err
+		}
+		data = upgraded
+		content.upgradedFrom = version
+	}
+	err := json.Unmarshal(data, &content)
+	return content, err
+}
+
+// keepsFile сообщает, что файл задач, который не удалось прочитать, исправен и его
+// нельзя ни заменять резервной копией, ни перезаписывать
+func keepsFile(err error) bool {
+	return errors.Is(err, ErrNewerFormat) || errors.Is(err, ErrStoreLocked) || errors.Is(err, errUnsealed)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func metaName(filename string) string {
+	return filename + ".meta"
+}
+
+// readTaskFile читает и проверяет файл задач вместе с файлом метаданных, если он есть
+func readTaskFile(filename string, format fileFormat) (taskFile, error) {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return taskFile{}, err
+	}
+	meta, err := os.ReadFile(metaName(filename))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return taskFile{}, err
+	}
+	return format.decode(raw, meta)
+}
+
+// loadTaskFile читает файл задач. Если он поврежден, данные берутся из самой свежей
+// исправной резервной копии, а поврежденный файл откладывается с суффиксом .corrupt-*,
+// чтобы следующее сохранение его не затерло. recovered сообщает, что данные взяты из копии.
+// Отсутствующий файл - пустой список задач.
+func loadTaskFile(filename string, format fileFormat) (content taskFile, recovered bool, err error) {
+	content, err = readTaskFile(filename, format)
+	if err == nil || errors.Is(err, os.ErrNotExist) {
+		return content, false, nil
+	}
+	if keepsFile(err) {
+		// Файл исправен, но эта версия его не понимает, у нее нет ключа или файл
+		// не зашифрован: резервные копии не подставляются
+		return taskFile{}, false, fmt.Errorf("%s: %w", filename, err)
+	}
+	log.Printf("Error loading tasks from %s: %v", filename, err)
+
+	for n := 1; n <= dataBackups; n++ {
+		backup := backupName(filename, n)
+		backupContent, backupErr := readTaskFile(backup, format)
+		if backupErr != nil {
+			continue
+		}
+
+		corrupt := filename + ".corrupt-" + time.Now().Format("20060102-150405")
+		if renameErr := os.Rename(filename, corrupt); renameErr != nil {
+			return taskFile{}, false, fmt.Errorf("failed to set aside corrupt %s: %w", filename, renameErr)
+		}
+		log.Printf("recovered tasks from %s, corrupt file saved as %s", backup, corrupt)
+		return backupContent, true, nil
+	}
+	return taskFile{}, false, fmt.Errorf("%w: %s: %v", ErrCorruptData, filename, err)
+}
+
+// saveTaskFile атомарно записывает файл задач, предварительно сдвигая резервные копии.
+// Метаданные записываются первыми: тот, кто перечитает файл после его замены, увидит и их.
+func saveTaskFile(filename string, format fileFormat, content taskFile) error {
+	data, meta, err := format.encode(content)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tasks: %w", err)
+	}
+	if meta != nil {
+		if err := fsutil.WriteFileAtomic(metaName(filename), meta, dataFileMode); err != nil {
+			return fmt.Errorf("failed to save task metadata: %w", err)
+		}
+	}
+	if err := rotateBackups(filename); err != nil {
+		return fmt.Errorf("failed to rotate backups of %s: %w", filename, err)
+	}
+	if err := fsutil.WriteFileAtomic(filename, data, dataFileMode); err != nil {
+		return fmt.Errorf("failed to save tasks: %w", err)
+	}
+	return nil
+}
+
+// keepOriginal сохраняет копию файла задач старой версии формата (filename.v1 и т.д.)
+// перед тем, как его перезапишет текущая версия. Уже сохраненная копия не заменяется:
+// в ней остается самый первый оригинал.
+func keepOriginal(filename string, version int) (string, error) {
+	original := fmt.Sprintf("%s.v%d", filename, version)
+	if _, err := os.Stat(original); err == nil {
+		return original, nil
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	if err := copyFile(filename, original); err != nil {
+		return "", err
+	}
+	return original, nil
+}
+
+func backupName(filename string, n int) string {
+	return fmt.Sprintf("%s.bak%d", filename, n)
+}
+
+// rotateBackups сдвигает резервные копии (.bak1 -> .bak2 ...) и делает текущий файл копией .bak1.
+// Текущий файл не переписывается: новая версия заменяет его переименованием.
+func rotateBackups(filename string) error {
+	if _, err := os.Stat(filename); errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	for n := dataBackups; n > 1; n-- {
+		err := os.Rename(backupName(filename, n-1), backupName(filename, n))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+
+	first := backupName(filename, 1)
+	if err := os.Remove(first); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	// Жесткая ссылка ничего не копирует; там, где ссылки не поддерживаются, файл копируется
+	if err := os.Link(filename, first); err == nil {
+		return nil
+	}
+	return copyFile(filename, first)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, dataFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
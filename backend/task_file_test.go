@@ -0,0 +1,219 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeFile записывает файл задач в обход TaskManager
+func writeFile(t *testing.T, filename, data string) {
+	t.Helper()
+	if err := os.WriteFile(filename, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// envelope записывает данные в обертку заданной версии с верной контрольной суммой
+func envelope(t *testing.T, version int, data string) string {
+	t.Helper()
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(data)); err != nil {
+		t.Fatal(err)
+	}
+	raw, err := json.Marshal(fileEnvelope{FormatVersion: version, Checksum: checksum(compact.Bytes()), Data: compact.Bytes()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(raw)
+}
+
+func TestUpgradeLegacyFile(t *testing.T) {
+	// Файл первых версий: без обертки, без корзины и меток, с устаревшим next_id
+	legacy := `{"tasks":[{"id":3,"title":"Старая задача","completed":false},{"id":1,"title":"Вторая","priority":"high"}],"trash":null,"next_id":2}`
+	tests := map[string]string{
+		"no envelope":          legacy,
+		"envelope, no version": envelope(t, 0, legacy),
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "tasks.json")
+			writeFile(t, filename, data)
+
+			tm, err := NewTaskManager(filename)
+			if err != nil {
+				t.Fatalf("NewTaskManager: %v", err)
+			}
+			defer tm.Close()
+
+			task, err := tm.Get(3)
+			if err != nil || task.Title != "Старая задача" || task.Priority != "medium" {
+				t.Errorf("upgraded task = %+v, %v", task, err)
+			}
+			// next_id исправлен: новая задача не получает ID существующей
+			created, err := tm.Create(Task{Title: "Новая", Priority: "low"})
+			if err != nil || created.ID != 4 {
+				t.Errorf("created task ID = %d, %v; want 4", created.ID, err)
+			}
+
+			// Оригинал сохранен рядом, файл переписан текущей версией
+			if original, err := os.ReadFile(filename + ".v1"); err != nil || string(original) != data {
+				t.Errorf("original = %q, %v", original, err)
+			}
+			var saved fileEnvelope
+			raw, _ := os.ReadFile(filename)
+			if err := json.Unmarshal(raw, &saved); err != nil || saved.FormatVersion != fileFormatVersion {
+				t.Errorf("saved format version = %d, %v", saved.FormatVersion, err)
+			}
+		})
+	}
+}
+
+func TestUpgradeKeepsFirstOriginal(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "tasks.json")
+	first := `{"tasks":[{"id":1,"title":"Первый оригинал"}],"next_id":2}`
+	writeFile(t, filename, first)
+	tm, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm.Close()
+
+	// Старая версия приложения снова записала файл без версии
+	writeFile(t, filename, `{"tasks":[{"id":1,"title":"Второй"}],"next_id":2}`)
+	tm, err = NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tm.Close()
+
+	if task, _ := tm.Get(1); task.Title != "Второй" {
+		t.Errorf("task = %+v", task)
+	}
+	if original, _ := os.ReadFile(filename + ".v1"); string(original) != first {
+		t.Errorf("original was replaced: %q", original)
+	}
+}
+
+func TestUpgradeRejectsMalformedData(t *testing.T) {
+	for _, data := range []string{
+		`{"tasks":{"id":1}}`,
+		`{"tasks":["task"]}`,
+		`{"tasks":[{"id":"one"}]}`,
+		`{"tasks":[],"next_id":"2"}`,
+	} {
+		if _, err := decodeTaskFile([]byte(data)); err == nil {
+			t.Errorf("decodeTaskFile(%s) accepted malformed data", data)
+		}
+	}
+}
+
+func TestNewerFormatIsNotTouched(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "tasks.json")
+	newer := envelope(t, fileFormatVersion+1, `{"tasks":[],"future":true}`)
+	writeFile(t, filename, newer)
+
+	if _, err := NewTaskManager(filename); !errors.Is(err, ErrNewerFormat) {
+		t.Fatalf("NewTaskManager = %v, want ErrNewerFormat", err)
+	}
+	assertUnchanged(t, filename, newer)
+}
+
+func TestNewerFormatWrittenByAnotherProcess(t *testing.T) {
+	tm, filename := newTestManager(t)
+	defer tm.Close()
+	if _, err := tm.Create(Task{Title: "Задача", Priority: "medium"}); err != nil {
+		t.Fatal(err)
+	}
+
+	newer := envelope(t, fileFormatVersion+1, `{"tasks":[]}`)
+	writeFile(t, filename, newer)
+
+	// Изменение затерло бы данные новой версии: TaskManager отказывается сохранять
+	if _, err := tm.Create(Task{Title: "Еще одна", Priority: "medium"}); !errors.Is(err, ErrNewerFormat) {
+		t.Errorf("Create = %v, want ErrNewerFormat", err)
+	}
+	assertUnchanged(t, filename, newer)
+}
+
+// assertUnchanged проверяет, что файл не переписан и резервные копии не подставлены
+func assertUnchanged(t *testing.T, filename, want string) {
+	t.Helper()
+	if data, _ := os.ReadFile(filename); string(data) != want {
+		t.Errorf("file was rewritten: %s", data)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(filename))
+	for _, entry := range entries {
+		if strings.Contains(entry.Name(), ".corrupt-") || strings.HasSuffix(entry.Name(), ".v1") {
+			t.Errorf("unexpected file %s", entry.Name())
+		}
+	}
+}
+
+func TestCorruptFileRecoveredFromBackup(t *testing.T) {
+	tm, filename := newTestManager(t)
+	if _, err := tm.Create(Task{Title: "Из копии", Priority: "medium"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tm.Create(Task{Title: "Потерянная", Priority: "medium"}); err != nil {
+		t.Fatal(err)
+	}
+	tm.Close()
+
+	// Данные не сходятся с контрольной суммой
+	data, _ := os.ReadFile(filename)
+	writeFile(t, filename, strings.Replace(string(data), "Потерянная", "Испорченная", 1))
+
+	tm, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatalf("NewTaskManager: %v", err)
+	}
+	defer tm.Close()
+	tasks, _ := tm.List()
+	if len(tasks) != 1 || tasks[0].Title != "Из копии" {
+		t.Errorf("recovered tasks = %+v", tasks)
+	}
+	if corrupt, _ := filepath.Glob(filename + ".corrupt-*"); len(corrupt) != 1 {
+		t.Errorf("corrupt copies = %v", corrupt)
+	}
+}
+
+func TestBackupRotation(t *testing.T) {
+	tm, filename := newTestManager(t)
+	defer tm.Close()
+	for i := 1; i <= 5; i++ {
+		if _, err := tm.Create(Task{Title: fmt.Sprintf("task %d", i), Priority: "medium"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Каждая копия на одну запись старше предыдущей: в .bak1 нет последней задачи и т.д.
+	for n := 1; n <= dataBackups; n++ {
+		data, err := os.ReadFile(backupName(filename, n))
+		if err != nil {
+			t.Fatalf("backup %d: %v", n, err)
+		}
+		last := 5 - n
+		if !strings.Contains(string(data), fmt.Sprintf("task %d", last)) || strings.Contains(string(data), fmt.Sprintf("task %d", last+1)) {
+			t.Errorf("backup %d does not end with task %d: %s", n, last, data)
+		}
+	}
+	if _, err := os.Stat(backupName(filename, dataBackups+1)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("extra backup: %v", err)
+	}
+
+	for _, name := range []string{filename, backupName(filename, 1), backupName(filename, dataBackups)} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != dataFileMode {
+			t.Errorf("%s: mode %v", filepath.Base(name), info.Mode().Perm())
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// fileFormatVersion версия данных JSON-файла задач, которую пишет приложение.
+// Файлы без версии (до ее появления) считаются версией 1. При несовместимом изменении
+// формата версия увеличивается, а в fileUpgraders добавляется функция, переводящая
+// данные предыдущей версии в новую.
+const fileFormatVersion = 2
+
+// ErrNewerFormat возвращается, если файл задач записан более новой версией приложения
+var ErrNewerFormat = errors.New("файл задач записан более новой версией приложения")
+
+// fileData данные файла задач в общем виде: upgraders не зависят от текущих структур
+type fileData = map[string]interface{}
+
+// fileUpgraders[i] переводит данные версии i+1 в версию i+2
+var fileUpgraders = []func(data fileData) error{
+	upgradeToV2,
+}
+
+// upgradeTaskData переводит данные файла задач из версии from в fileFormatVersion
+func upgradeTaskData(raw []byte, from int) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var data fileData
+	if err := dec.Decode(&data); err != nil {
+		return nil, err
+	}
+	if data == nil {
+		data = fileData{}
+	}
+
+	for version := from; version < fileFormatVersion; version++ {
+		if err := fileUpgraders[version-1](data); err != nil {
+			return nil, fmt.Errorf("failed to upgrade task file from version %d: %w", version, err)
+		}
+	}
+	return json.Marshal(data)
+}
+
+// upgradeToV2 файлы без версии: списки, которых в старых версиях не было, могут
+// отсутствовать или быть null, у задач может не быть приоритета, а next_id, исправленный
+// вручную или потерянный, может совпадать с ID существующей задачи
+func upgradeToV2(data fileData) error {
+	nextID := 1
+	for _, list := range []string{"tasks", "trash"} {
+		tasks, err := listField(data, list)
+		if err != nil {
+			return err
+		}
+		for i, item := range tasks {
+			task, ok := item.(map[string]interface{})
+			if !ok {
+				return fmt.Errorf("%s[%d] is not an object", list, i)
+			}
+			if priority, _ := task["priority"].(string); priority == "" {
+				task["priority"] = "medium"
+			}
+			id, err := intField(task, "id")
+			if err != nil {
+				return fmt.Errorf("%s[%d]: %w", list, i, err)
+			}
+			nextID = max(nextID, id+1)
+		}
+	}
+	for _, list := range []string{"tags", "activity"} {
+		if _, err := listField(data, list); err != nil {
+			return err
+		}
+	}
+
+	current, err := intField(data, "next_id")
+	if err != nil {
+		return err
+	}
+	data["next_id"] = max(current, nextID)
+	return nil
+}
+
+// listField возвращает список data[name], заменяя отсутствующий или null пустым списком
+func listField(data fileData, name string) ([]interface{}, error) {
+	switch value := data[name].(type) {
+	case nil:
+		data[name] = []interface{}{}
+		return nil, nil
+	case []interface{}:
+		return value, nil
+	default:
+		return nil, fmt.Errorf("%s is not a list", name)
+	}
+}
+
+// intField возвращает целое число data[name]; отсутствующее или null - 0
+func intField(data map[string]interface{}, name string) (int, error) {
+	switch value := data[name].(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		n, err := strconv.Atoi(value.String())
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", name, err)
+		}
+		return n, nil
+	default:
+		return 0, fmt.Errorf("%s is not a number", name)
+	}
+}
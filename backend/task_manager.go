@@ -0,0 +1,889 @@
+package backend
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"todo-list/backend/internal/fsutil"
+	"todo-list/backend/internal/models"
+	"todo-list/backend/internal/search"
+	"todo-list/backend/internal/service"
+)
+
+// TaskManager управляет задачами, хранящимися в JSON-файле или в файле todo.txt.
+// Методы безопасны для одновременного вызова; изменения из нескольких процессов
+// (окно приложения и CLI) разделяются рекомендательной блокировкой файла filename.lock,
+// а перед каждым обращением файл перечитывается, если его изменил другой процесс
+// или программа синхронизации.
+type TaskManager struct {
+	mu       sync.Mutex  // защищает поля ниже
+	saved    os.FileInfo // файл задач после последней загрузки или записи
+	external bool        // задачи перечитаны после другого процесса, Watch еще не сообщил об этом
+
+	tasks    []Task
+	trash    []Task     // удаленные задачи до окончательной очистки
+	tags     []Tag      // реестр меток: имя и цвет
+	activity []Activity // журнал изменений, старые записи первыми
+	nextID   int
+	filename string
+	format   fileFormat // sealedFormat, если файл зашифрован
+	locked   bool       // файл зашифрован, а ключа нет: задачи не загружены
+}
+
+// DefaultDataFile возвращает путь к файлу задач по умолчанию
+func DefaultDataFile() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, ".todo-list.json")
+}
+
+// NewTaskManager создает новый менеджер задач.
+// Если filename пустой, используется DefaultDataFile. Поврежденный файл восстанавливается
+// из резервной копии; если исправной копии нет, возвращается ErrCorruptData.
+func NewTaskManager(filename string) (*TaskManager, error) {
+	if filename == "" {
+		filename = DefaultDataFile()
+	}
+	return newTaskManager(filename, jsonFormat{})
+}
+
+func newTaskManager(filename string, format fileFormat) (*TaskManager, error) {
+	tm := &TaskManager{
+		tasks:    []Task{},
+		trash:    []Task{},
+		tags:     []Tag{},
+		activity: []Activity{},
+		nextID:   1,
+		filename: filename,
+		format:   format,
+	}
+
+	// Загружаем существующие задачи под блокировкой файла: поврежденный файл
+	// восстанавливается из резервной копии и перезаписывается
+	fileLock, err := fsutil.Lock(tm.filename + ".lock")
+	if err != nil {
+		return nil, err
+	}
+	defer fileLock.Unlock()
+
+	if err := tm.loadTasks(); errors.Is(err, ErrStoreLocked) {
+		// Зашифрованный файл загружается после Unlock
+		tm.locked = true
+	} else if err != nil {
+		return nil, err
+	}
+	return tm, nil
+}
+
+// lock захватывает TaskManager для изменения: блокирует его внутри процесса и файл задач
+// между процессами, затем перечитывает файл, если его изменил другой процесс.
+// Возвращаемая функция снимает обе блокировки.
+func (tm *TaskManager) lock() (func(), error) {
+	tm.mu.Lock()
+	if tm.locked {
+		tm.mu.Unlock()
+		return nil, ErrStoreLocked
+	}
+	fileLock, err := fsutil.Lock(tm.filename + ".lock")
+	if err != nil {
+		tm.mu.Unlock()
+		return nil, err
+	}
+
+	unlock := func() {
+		if err := fileLock.Unlock(); err != nil {
+			log.Printf("Error unlocking %s: %v", tm.filename, err)
+		}
+		tm.mu.Unlock()
+	}
+	if tm.changedOnDisk() {
+		if err := tm.reloadTasks(); keepsFile(err) {
+			// Сохранение затерло бы данные, которые эта версия не понимает, не может
+			// расшифровать или которые другой процесс сохранил без шифрования
+			unlock()
+			return nil, err
+		}
+	}
+	return unlock, nil
+}
+
+// rlock захватывает TaskManager для чтения. Файл заменяется атомарно, поэтому для
+// чтения (и перечитывания после другого процесса) блокировка файла не нужна.
+func (tm *TaskManager) rlock() (func(), error) {
+	tm.mu.Lock()
+	if !tm.locked && tm.changedOnDisk() {
+		tm.reloadTasks()
+	}
+	if tm.locked {
+		tm.mu.Unlock()
+		return nil, ErrStoreLocked
+	}
+	return tm.mu.Unlock, nil
+}
+
+// changedOnDisk сообщает, что файл задач записан не этим TaskManager: каждая запись
+// создает новый файл, поэтому чужое сохранение видно по смене файла, времени или размера.
+// Пропавший файл не считается изменением - он будет создан при следующем сохранении.
+func (tm *TaskManager) changedOnDisk() bool {
+	info, err := os.Stat(tm.filename)
+	if err != nil {
+		return false
+	}
+	return tm.saved == nil || !os.SameFile(info, tm.saved) ||
+		!info.ModTime().Equal(tm.saved.ModTime()) || info.Size() != tm.saved.Size()
+}
+
+// List возвращает копию всех задач
+func (tm *TaskManager) List() ([]Task, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tasks := make([]Task, len(tm.tasks))
+	copy(tasks, tm.tasks)
+	return tasks, nil
+}
+
+// ListPage возвращает страницу задач в порядке возрастания ID
+func (tm *TaskManager) ListPage(completed *bool, cursor string, limit int) (TaskPage, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return TaskPage{}, err
+	}
+	defer unlock()
+
+	limit = pageLimit(limit)
+
+	var matched []Task
+	for _, task := range tm.tasks {
+		if completed == nil || task.Completed == *completed {
+			matched = append(matched, task)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].ID < matched[j].ID })
+
+	start, end := 0, len(matched)
+	if cursor != "" {
+		id, back, err := decodeIDCursor(cursor)
+		if err != nil {
+			return TaskPage{}, err
+		}
+		// Первая задача с ID больше курсора (или не меньше - для обратного прохода)
+		pos := sort.Search(len(matched), func(i int) bool {
+			if back {
+				return matched[i].ID >= id
+			}
+			return matched[i].ID > id
+		})
+		if back {
+			end = pos
+			start = max(0, end-limit)
+		} else {
+			start = pos
+		}
+	}
+	end = min(end, start+limit)
+
+	page := TaskPage{Tasks: append([]Task{}, matched[start:end]...)}
+	if len(page.Tasks) > 0 {
+		if end < len(matched) {
+			page.NextCursor = encodeIDCursor(page.Tasks[len(page.Tasks)-1].ID, false)
+		}
+		if start > 0 {
+			page.PrevCursor = encodeIDCursor(page.Tasks[0].ID, true)
+		}
+	}
+	return page, nil
+}
+
+// Search ищет задачи в памяти с учетом основ слов, префиксов и небольших опечаток
+func (tm *TaskManager) Search(query string, limit int) ([]SearchResult, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	q := search.ParseQuery(query)
+	results := []SearchResult{}
+	if q.Empty() {
+		return results, nil
+	}
+
+	for _, task := range tm.tasks {
+		rank := q.Rank(task.Title, task.Description)
+		if rank == 0 {
+			continue
+		}
+		results = append(results, SearchResult{
+			Task:                 task,
+			Rank:                 rank,
+			TitleHighlight:       q.Highlight(task.Title),
+			DescriptionHighlight: q.Highlight(task.Description),
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Rank > results[j].Rank
+	})
+	if limit = pageLimit(limit); len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Get возвращает задачу по ID
+func (tm *TaskManager) Get(id int) (Task, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return Task{}, err
+	}
+	defer unlock()
+
+	return tm.get(id)
+}
+
+func (tm *TaskManager) get(id int) (Task, error) {
+	for _, task := range tm.tasks {
+		if task.ID == id {
+			return task, nil
+		}
+	}
+	return Task{}, ErrTaskNotFound
+}
+
+// Create добавляет задачу и присваивает ей следующий ID
+func (tm *TaskManager) Create(task Task) (Task, error) {
+	unlock, err := tm.lock()
+	if err != nil {
+		return Task{}, err
+	}
+	defer unlock()
+
+	tags, err := tm.registerTags(task.Tags)
+	if err != nil {
+		return Task{}, err
+	}
+	task.Tags = tags
+	task.ID = tm.nextID
+	tm.tasks = append(tm.tasks, task)
+	tm.nextID++
+	tm.recordChange(nil, &task)
+
+	// Сохраняем изменения
+	if err := tm.saveTasks(); err != nil {
+		return Task{}, err
+	}
+
+	return task, nil
+}
+
+// Update заменяет задачу с тем же ID
+func (tm *TaskManager) Update(task Task) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for i := range tm.tasks {
+		if tm.tasks[i].ID == task.ID {
+			before := tm.tasks[i]
+			task.Tags = before.Tags
+			tm.tasks[i] = task
+			tm.recordChange(&before, &task)
+			return tm.saveTasks()
+		}
+	}
+	return ErrTaskNotFound
+}
+
+// Modify меняет задачу функцией fn под той же блокировкой файла, под которой она прочитана
+func (tm *TaskManager) Modify(id int, fn func(task *Task) error) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for i := range tm.tasks {
+		if tm.tasks[i].ID == id {
+			before := tm.tasks[i]
+			task := before
+			task.Reminders = slices.Clone(before.Reminders)
+			task.Tags = slices.Clone(before.Tags)
+			if err := fn(&task); err != nil {
+				return err
+			}
+			task.ID, task.ParentID, task.Tags = before.ID, before.ParentID, before.Tags
+			tm.tasks[i] = task
+			tm.recordChange(&before, &task)
+			return tm.saveTasks()
+		}
+	}
+	return ErrTaskNotFound
+}
+
+// Put заменяет задачу с тем же ID или добавляет ее, если такой нет.
+// Задача с тем же ID убирается из корзины.
+func (tm *TaskManager) Put(task Task) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if task.ID <= 0 {
+		return ErrTaskNotFound
+	}
+	tags, err := tm.registerTags(task.Tags)
+	if err != nil {
+		return err
+	}
+	task.Tags = tags
+	task.DeletedAt = time.Time{}
+	restored := false
+	for _, deleted := range tm.trash {
+		if deleted.ID == task.ID {
+			tm.trash = withoutTask(tm.trash, task.ID)
+			tm.record(task, models.ActivityRestore, taskDiff(&deleted, &task))
+			restored = true
+			break
+		}
+	}
+
+	if task.ID >= tm.nextID {
+		tm.nextID = task.ID + 1
+	}
+	for i := range tm.tasks {
+		if tm.tasks[i].ID == task.ID {
+			before := tm.tasks[i]
+			tm.tasks[i] = task
+			tm.recordChange(&before, &task)
+			return tm.saveTasks()
+		}
+	}
+	tm.tasks = append(tm.tasks, task)
+	if !restored {
+		tm.recordChange(nil, &task)
+	}
+	return tm.saveTasks()
+}
+
+// Move меняет родителя задачи; проверка циклов выполняется в App
+func (tm *TaskManager) Move(id, parentID int) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for i := range tm.tasks {
+		if tm.tasks[i].ID == id {
+			before := tm.tasks[i]
+			tm.tasks[i].ParentID = parentID
+			tm.recordChange(&before, &tm.tasks[i])
+			return tm.saveTasks()
+		}
+	}
+	return ErrTaskNotFound
+}
+
+// Subtree возвращает задачу и всех ее потомков
+func (tm *TaskManager) Subtree(id int) ([]Task, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	task, err := tm.get(id)
+	if err != nil {
+		return nil, err
+	}
+	return append([]Task{task}, descendantsOf(tm.tasks, id)...), nil
+}
+
+// Delete переносит задачу вместе с подзадачами в корзину с одним временем удаления
+func (tm *TaskManager) Delete(id int) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	task, err := tm.get(id)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, deleted := range append([]Task{task}, descendantsOf(tm.tasks, id)...) {
+		tm.tasks = withoutTask(tm.tasks, deleted.ID)
+		deleted.DeletedAt = now
+		tm.trash = append(tm.trash, deleted)
+		tm.record(deleted, models.ActivityDelete, nil)
+	}
+
+	return tm.saveTasks()
+}
+
+// Remove окончательно удаляет задачу (из списка или из корзины); подзадачи не трогает
+func (tm *TaskManager) Remove(id int) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for _, list := range []*[]Task{&tm.tasks, &tm.trash} {
+		for _, task := range *list {
+			if task.ID == id {
+				*list = withoutTask(*list, id)
+				tm.record(task, models.ActivityPurge, nil)
+				return tm.saveTasks()
+			}
+		}
+	}
+	return ErrTaskNotFound
+}
+
+// Trash возвращает задачи из корзины, недавно удаленные первыми
+func (tm *TaskManager) Trash() ([]Task, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tasks := make([]Task, len(tm.trash))
+	copy(tasks, tm.trash)
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].DeletedAt.After(tasks[j].DeletedAt)
+	})
+	return tasks, nil
+}
+
+// Restore возвращает задачу из корзины вместе с подзадачами, удаленными вместе с ней.
+// Если родителя задачи нет среди задач, она становится задачей верхнего уровня.
+func (tm *TaskManager) Restore(id int) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	var root Task
+	found := false
+	for _, task := range tm.trash {
+		if task.ID == id {
+			root, found = task, true
+			break
+		}
+	}
+	if !found {
+		return ErrNotInTrash
+	}
+
+	restored := []Task{root}
+	for _, task := range descendantsOf(tm.trash, id) {
+		if task.DeletedAt.Equal(root.DeletedAt) {
+			restored = append(restored, task)
+		}
+	}
+	if _, err := tm.get(root.ParentID); err != nil {
+		restored[0].ParentID = 0
+	}
+
+	for i, task := range restored {
+		tm.trash = withoutTask(tm.trash, task.ID)
+		task.DeletedAt = time.Time{}
+		task.Tags, _ = tm.registerTags(task.Tags) // имена проверены при сохранении задачи
+		tm.tasks = append(tm.tasks, task)
+
+		// Запись о задаче верхнего уровня показывает и отвязку от удаленного родителя
+		deleted := task
+		if i == 0 {
+			deleted.ParentID = root.ParentID
+		}
+		tm.record(task, models.ActivityRestore, taskDiff(&deleted, &task))
+	}
+
+	return tm.saveTasks()
+}
+
+// Purge окончательно удаляет задачи, попавшие в корзину раньше before
+func (tm *TaskManager) Purge(before time.Time) (int, error) {
+	unlock, err := tm.lock()
+	if err != nil {
+		return 0, err
+	}
+	defer unlock()
+
+	kept := make([]Task, 0, len(tm.trash))
+	for _, task := range tm.trash {
+		if !task.DeletedAt.Before(before) {
+			kept = append(kept, task)
+		} else {
+			tm.record(task, models.ActivityPurge, nil)
+		}
+	}
+
+	purged := len(tm.trash) - len(kept)
+	if purged > 0 {
+		tm.trash = kept
+		if err := tm.saveTasks(); err != nil {
+			return 0, err
+		}
+	}
+	return purged, nil
+}
+
+// withoutTask возвращает новый список задач без задачи id
+func withoutTask(tasks []Task, id int) []Task {
+	result := make([]Task, 0, len(tasks))
+	for _, task := range tasks {
+		if task.ID != id {
+			result = append(result, task)
+		}
+	}
+	return result
+}
+
+// Tags возвращает все метки с числом задач
+func (tm *TaskManager) Tags() ([]Tag, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tags := make([]Tag, len(tm.tags))
+	for i, tag := range tm.tags {
+		tag.Count = 0
+		for _, task := range tm.tasks {
+			if indexTag(task.Tags, tag.Name) >= 0 {
+				tag.Count++
+			}
+		}
+		tags[i] = tag
+	}
+	return tags, nil
+}
+
+// SaveTag создает метку или меняет цвет существующей
+func (tm *TaskManager) SaveTag(tag Tag) (Tag, error) {
+	unlock, err := tm.lock()
+	if err != nil {
+		return Tag{}, err
+	}
+	defer unlock()
+
+	name, err := service.NormalizeTagName(tag.Name)
+	if err != nil {
+		return Tag{}, err
+	}
+
+	if i := tm.findTag(name); i >= 0 {
+		if tag.Color != "" {
+			tm.tags[i].Color = tag.Color
+		}
+		tag = tm.tags[i]
+	} else {
+		tag = Tag{Name: name, Color: tag.Color}
+		if tag.Color == "" {
+			tag.Color = service.DefaultTagColor
+		}
+		tm.tags = append(tm.tags, tag)
+		tm.sortTags()
+	}
+
+	if err := tm.saveTasks(); err != nil {
+		return Tag{}, err
+	}
+	return tag, nil
+}
+
+// SetTaskTags заменяет метки задачи; отсутствующие метки создаются
+func (tm *TaskManager) SetTaskTags(id int, tags []string) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	for i := range tm.tasks {
+		if tm.tasks[i].ID == id {
+			names, err := tm.registerTags(tags)
+			if err != nil {
+				return err
+			}
+			before := tm.tasks[i]
+			tm.tasks[i].Tags = names
+			tm.recordChange(&before, &tm.tasks[i])
+			return tm.saveTasks()
+		}
+	}
+	return ErrTaskNotFound
+}
+
+// RenameTag переименовывает метку в реестре и во всех задачах
+func (tm *TaskManager) RenameTag(name, newName string) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	i := tm.findTag(name)
+	if i < 0 {
+		return ErrTagNotFound
+	}
+	newName, err = service.NormalizeTagName(newName)
+	if err != nil {
+		return err
+	}
+	if j := tm.findTag(newName); j >= 0 && j != i {
+		return ErrTagExists
+	}
+
+	oldName := tm.tags[i].Name
+	tm.tags[i].Name = newName
+	tm.sortTags()
+	for k := range tm.tasks {
+		if indexTag(tm.tasks[k].Tags, oldName) >= 0 {
+			before := tm.tasks[k]
+			tags := append(withoutTag(tm.tasks[k].Tags, oldName), newName)
+			sortTagNames(tags)
+			tm.tasks[k].Tags = tags
+			tm.recordChange(&before, &tm.tasks[k])
+		}
+	}
+
+	return tm.saveTasks()
+}
+
+// MergeTags переносит задачи с меток sources на метку target и удаляет sources
+func (tm *TaskManager) MergeTags(sources []string, target string) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	t := tm.findTag(target)
+	if t < 0 {
+		return ErrTagNotFound
+	}
+	target = tm.tags[t].Name
+	for _, source := range sources {
+		if tm.findTag(source) < 0 {
+			return ErrTagNotFound
+		}
+	}
+
+	for k := range tm.tasks {
+		tags := tm.tasks[k].Tags
+		merged := false
+		for _, source := range sources {
+			if !strings.EqualFold(source, target) && indexTag(tags, source) >= 0 {
+				tags = withoutTag(tags, source)
+				merged = true
+			}
+		}
+		if !merged {
+			continue
+		}
+		if indexTag(tags, target) < 0 {
+			tags = append(tags, target)
+			sortTagNames(tags)
+		}
+		before := tm.tasks[k]
+		tm.tasks[k].Tags = tags
+		tm.recordChange(&before, &tm.tasks[k])
+	}
+
+	for _, source := range sources {
+		if i := tm.findTag(source); i >= 0 && !strings.EqualFold(source, target) {
+			tm.tags = append(tm.tags[:i], tm.tags[i+1:]...)
+		}
+	}
+
+	return tm.saveTasks()
+}
+
+// DeleteTag удаляет метку из реестра и из всех задач
+func (tm *TaskManager) DeleteTag(name string) error {
+	unlock, err := tm.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	i := tm.findTag(name)
+	if i < 0 {
+		return ErrTagNotFound
+	}
+	tm.tags = append(tm.tags[:i], tm.tags[i+1:]...)
+
+	for k := range tm.tasks {
+		if indexTag(tm.tasks[k].Tags, name) >= 0 {
+			before := tm.tasks[k]
+			tm.tasks[k].Tags = withoutTag(tm.tasks[k].Tags, name)
+			tm.recordChange(&before, &tm.tasks[k])
+		}
+	}
+
+	return tm.saveTasks()
+}
+
+// registerTags проверяет имена меток, добавляет новые в реестр и возвращает
+// имена в том виде, в каком они записаны в реестре
+func (tm *TaskManager) registerTags(tags []string) ([]string, error) {
+	names, err := service.NormalizeTags(tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	for i, name := range names {
+		if j := tm.findTag(name); j >= 0 {
+			names[i] = tm.tags[j].Name
+		} else {
+			tm.tags = append(tm.tags, Tag{Name: name, Color: service.DefaultTagColor})
+		}
+	}
+	tm.sortTags()
+	return names, nil
+}
+
+// findTag ищет метку в реестре без учета регистра
+func (tm *TaskManager) findTag(name string) int {
+	for i, tag := range tm.tags {
+		if strings.EqualFold(tag.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
+
+func (tm *TaskManager) sortTags() {
+	sort.Slice(tm.tags, func(i, j int) bool {
+		return strings.ToLower(tm.tags[i].Name) < strings.ToLower(tm.tags[j].Name)
+	})
+}
+
+// withoutTag возвращает новый список меток без метки name
+func withoutTag(tags []string, name string) []string {
+	result := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !strings.EqualFold(tag, name) {
+			result = append(result, tag)
+		}
+	}
+	return result
+}
+
+// sortTagNames сортирует имена меток задачи по алфавиту без учета регистра
+func sortTagNames(tags []string) {
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i]) < strings.ToLower(tags[j])
+	})
+}
+
+// Close ничего не делает: все изменения уже записаны на диск
+func (tm *TaskManager) Close() error {
+	return nil
+}
+
+// loadTasks загружает задачи из файла, заменяя текущие; поврежденный файл
+// восстанавливается из резервной копии, файл старой версии формата переписывается
+// в текущей, а его оригинал сохраняется рядом
+func (tm *TaskManager) loadTasks() error {
+	content, recovered, err := loadTaskFile(tm.filename, tm.format)
+	if err != nil {
+		return err
+	}
+
+	tm.setContent(content)
+	if content.upgradedFrom != 0 && !recovered {
+		original, err := keepOriginal(tm.filename, content.upgradedFrom)
+		if err != nil {
+			return fmt.Errorf("failed to keep original of %s: %w", tm.filename, err)
+		}
+		log.Printf("upgraded %s from format version %d to %d, original saved as %s",
+			tm.filename, content.upgradedFrom, fileFormatVersion, original)
+	}
+	if recovered || content.upgradedFrom != 0 {
+		return tm.saveTasks()
+	}
+	tm.saved, _ = os.Stat(tm.filename)
+	return nil
+}
+
+// reloadTasks перечитывает файл задач, измененный другим процессом. Несохраненных изменений
+// у TaskManager не бывает, поэтому внешняя версия просто заменяет задачи в памяти.
+// Файл, который не удалось прочитать (например, программа синхронизации еще пишет его),
+// пропускается до следующего изменения: задачи в памяти остаются прежними, а резервные
+// копии не трогаются. Ошибка возвращается, чтобы lock не дал изменить файл более новой
+// версии формата. Если файл зашифровали или сменили парольную фразу в другом процессе,
+// хранилище запирается до Unlock, а задачи убираются из памяти.
+func (tm *TaskManager) reloadTasks() error {
+	tm.saved, _ = os.Stat(tm.filename)
+	content, err := readTaskFile(tm.filename, tm.format)
+	if errors.Is(err, ErrStoreLocked) {
+		tm.lockStore()
+		return err
+	}
+	if err != nil {
+		log.Printf("Error reloading tasks from %s: %v", tm.filename, err)
+		return err
+	}
+	tm.setContent(content)
+	tm.external = true
+	return nil
+}
+
+// setContent заменяет задачи в памяти содержимым файла
+func (tm *TaskManager) setContent(content taskFile) {
+	tm.tasks = orEmpty(content.Tasks)
+	tm.trash = orEmpty(content.Trash)
+	tm.tags = orEmpty(content.Tags)
+	tm.activity = orEmpty(content.Activity)
+	tm.nextID = max(content.NextID, 1)
+}
+
+// orEmpty заменяет nil пустым списком
+func orEmpty[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+// saveTasks атомарно сохраняет задачи в файл
+func (tm *TaskManager) saveTasks() error {
+	err := saveTaskFile(tm.filename, tm.format, taskFile{
+		Tasks:    tm.tasks,
+		Trash:    tm.trash,
+		Tags:     tm.tags,
+		Activity: tm.activity,
+		NextID:   tm.nextID,
+	})
+	if err != nil {
+		return err
+	}
+	tm.saved, _ = os.Stat(tm.filename)
+	return nil
+}
@@ -0,0 +1,281 @@
+package backend
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+func newTestManager(t *testing.T) (*TaskManager, string) {
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "tasks.json")
+	tm, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tm, filename
+}
+
+func TestTaskManagerConcurrentCreateUpdateDelete(t *testing.T) {
+	tm, filename := newTestManager(t)
+
+	const workers, perWorker = 4, 10
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				task, err := tm.Create(Task{Title: fmt.Sprintf("task %d-%d", w, i), Priority: "medium"})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				task.Title += " updated"
+				if err := tm.Update(task); err != nil {
+					t.Error(err)
+					return
+				}
+				if i%2 == 1 {
+					if err := tm.Delete(task.ID); err != nil {
+						t.Error(err)
+						return
+					}
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	tasks, err := tm.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	trash, err := tm.Trash()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != workers*perWorker/2 || len(trash) != workers*perWorker/2 {
+		t.Fatalf("tasks = %d, trash = %d, want %d each", len(tasks), len(trash), workers*perWorker/2)
+	}
+
+	ids := make(map[int]bool)
+	for _, task := range append(tasks, trash...) {
+		if ids[task.ID] {
+			t.Errorf("duplicate ID %d", task.ID)
+		}
+		ids[task.ID] = true
+	}
+
+	// Файл на диске совпадает с состоянием в памяти
+	reopened, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := reopened.List(); len(got) != len(tasks) {
+		t.Errorf("reopened file has %d tasks, want %d", len(got), len(tasks))
+	}
+}
+
+func TestTaskManagersShareFile(t *testing.T) {
+	first, filename := newTestManager(t)
+	second, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const perManager = 25
+	var wg sync.WaitGroup
+	for _, tm := range []*TaskManager{first, second} {
+		wg.Add(1)
+		go func(tm *TaskManager) {
+			defer wg.Done()
+			for i := 0; i < perManager; i++ {
+				if _, err := tm.Create(Task{Title: fmt.Sprintf("task %d", i), Priority: "low"}); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(tm)
+	}
+	wg.Wait()
+
+	for name, tm := range map[string]*TaskManager{"first": first, "second": second} {
+		tasks, err := tm.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(tasks) != 2*perManager {
+			t.Errorf("%s manager sees %d tasks, want %d", name, len(tasks), 2*perManager)
+		}
+		ids := make(map[int]bool)
+		for _, task := range tasks {
+			if ids[task.ID] {
+				t.Errorf("%s manager: duplicate ID %d", name, task.ID)
+			}
+			ids[task.ID] = true
+		}
+	}
+}
+
+func TestTaskManagersModifyKeepsConcurrentChanges(t *testing.T) {
+	first, filename := newTestManager(t)
+	second, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := first.Create(Task{Title: "counter", Priority: "medium", Reminders: []int{0}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Каждый Modify увеличивает счетчик в напоминаниях; потерянное обновление
+	// из-за чтения и записи под разными блокировками уменьшило бы итог
+	const perManager = 20
+	var wg sync.WaitGroup
+	for _, tm := range []*TaskManager{first, second} {
+		wg.Add(1)
+		go func(tm *TaskManager) {
+			defer wg.Done()
+			for i := 0; i < perManager; i++ {
+				err := tm.Modify(task.ID, func(task *Task) error {
+					task.Reminders[0]++
+					return nil
+				})
+				if err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(tm)
+	}
+	wg.Wait()
+
+	got, err := first.Get(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Reminders[0] != 2*perManager {
+		t.Errorf("counter = %d, want %d", got.Reminders[0], 2*perManager)
+	}
+}
+
+func TestModifyErrorKeepsTask(t *testing.T) {
+	tm, _ := newTestManager(t)
+	task, err := tm.Create(Task{Title: "task", Priority: "medium"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = tm.Modify(task.ID, func(task *Task) error {
+		task.Title = "changed"
+		return errNotRecurring
+	})
+	if err != errNotRecurring {
+		t.Fatalf("Modify error = %v", err)
+	}
+	if got, _ := tm.Get(task.ID); got.Title != "task" {
+		t.Errorf("title = %q after failed Modify", got.Title)
+	}
+	if err := tm.Modify(task.ID+1, func(*Task) error { return nil }); err != ErrTaskNotFound {
+		t.Errorf("Modify of missing task = %v", err)
+	}
+}
+
+func TestListPage(t *testing.T) {
+	tm, _ := newTestManager(t)
+	for i := 1; i <= 7; i++ {
+		if _, err := tm.Create(Task{Title: fmt.Sprintf("task %d", i), Priority: "medium", Completed: i%2 == 0}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Вперед по страницам из трех задач
+	var pages [][]int
+	cursor := ""
+	for {
+		page, err := tm.ListPage(nil, cursor, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, taskIDs(page.Tasks))
+		if (len(pages) == 1) != (page.PrevCursor == "") {
+			t.Errorf("page %d: prev cursor %q", len(pages), page.PrevCursor)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+		// Новая задача после курсора попадает в конец и не сдвигает страницы
+		if len(pages) == 1 {
+			if _, err := tm.Create(Task{Title: "late", Priority: "low"}); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if fmt.Sprint(pages) != "[[1 2 3] [4 5 6] [7 8]]" {
+		t.Errorf("pages = %v", pages)
+	}
+
+	// Назад от последней страницы
+	last, _ := tm.ListPage(nil, cursor, 3)
+	prev, err := tm.ListPage(nil, last.PrevCursor, 3)
+	if err != nil || fmt.Sprint(taskIDs(prev.Tasks)) != "[4 5 6]" || prev.NextCursor == "" {
+		t.Errorf("previous page = %v, next %q, %v", taskIDs(prev.Tasks), prev.NextCursor, err)
+	}
+
+	completed := true
+	done, _ := tm.ListPage(&completed, "", 0)
+	if fmt.Sprint(taskIDs(done.Tasks)) != "[2 4 6]" || done.NextCursor != "" {
+		t.Errorf("completed = %v", taskIDs(done.Tasks))
+	}
+
+	for _, cursor := range []string{"x", encodeIDCursor(3, false)[:1], "YTE0eA"} {
+		if _, err := tm.ListPage(nil, cursor, 3); err != ErrInvalidCursor {
+			t.Errorf("ListPage(%q) = %v", cursor, err)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	tm, _ := newTestManager(t)
+	for _, task := range []Task{
+		{Title: "Позвонить маме", Description: "купить торт по дороге"},
+		{Title: "Купила молоко", Description: ""},
+		{Title: "Отчет", Description: "ничего общего"},
+		{Title: "Купить торт", Description: "к празднику"},
+	} {
+		task.Priority = "medium"
+		if _, err := tm.Create(task); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	results, err := tm.Search("купить торт", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Совпадение в заголовке важнее совпадения в описании
+	if ids := searchIDs(results); fmt.Sprint(ids) != "[4 1]" {
+		t.Fatalf("results = %v", ids)
+	}
+	if results[0].TitleHighlight != "<mark>Купить</mark> <mark>торт</mark>" || results[1].DescriptionHighlight != "<mark>купить</mark> <mark>торт</mark> по дороге" {
+		t.Errorf("highlights = %q, %q", results[0].TitleHighlight, results[1].DescriptionHighlight)
+	}
+
+	if results, _ := tm.Search("купить", 1); len(results) != 1 {
+		t.Errorf("limit 1 returned %d results", len(results))
+	}
+	if results, err := tm.Search("  ", 0); err != nil || len(results) != 0 {
+		t.Errorf("empty query = %v, %v", results, err)
+	}
+}
+
+func searchIDs(results []SearchResult) []int {
+	ids := make([]int, 0, len(results))
+	for _, r := range results {
+		ids = append(ids, r.ID)
+	}
+	return ids
+}
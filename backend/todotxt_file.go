@@ -0,0 +1,260 @@
+package backend
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"todo-list/backend/internal/recurrence"
+	"todo-list/backend/internal/service"
+	"todo-list/backend/internal/todotxt"
+)
+
+// DefaultTodoTxtFile возвращает путь к файлу todo.txt по умолчанию
+func DefaultTodoTxtFile() string {
+	homeDir, _ := os.UserHomeDir()
+	return filepath.Join(homeDir, "todo.txt")
+}
+
+// NewTodoTxtManager создает менеджер задач, который хранит задачи в файле todo.txt.
+// Файл остается обычным todo.txt: его можно править в редакторе или другими программами,
+// строки без id: получают номер при загрузке. Описания, корзина, цвета меток и журнал
+// изменений хранятся рядом в filename.meta; без него задачи все равно читаются.
+func NewTodoTxtManager(filename string) (*TaskManager, error) {
+	if filename == "" {
+		filename = DefaultTodoTxtFile()
+	}
+	return newTaskManager(filename, &todoTxtFormat{completedOn: map[int]time.Time{}})
+}
+
+// todoTxtMeta сведения, которых нет в строках todo.txt
+type todoTxtMeta struct {
+	NextID       int            `json:"next_id"`
+	Descriptions map[int]string `json:"descriptions,omitempty"`
+	Trash        []Task         `json:"trash"`
+	Tags         []Tag          `json:"tags"`
+	Activity     []Activity     `json:"activity"`
+}
+
+// todoTxtFormat файл задач в формате todo.txt. Приоритет high/medium/low записывается как
+// (A)/(B)/(C), метки с "+" и "@" - проектами и контекстами, остальные - расширением tag:.
+type todoTxtFormat struct {
+	// completedOn даты выполнения задач: в Task их нет, а строки todo.txt их хранят
+	completedOn map[int]time.Time
+}
+
+func (f *todoTxtFormat) encode(content taskFile) ([]byte, []byte, error) {
+	meta := todoTxtMeta{
+		NextID:       content.NextID,
+		Descriptions: map[int]string{},
+		Trash:        content.Trash,
+		Tags:         content.Tags,
+		Activity:     content.Activity,
+	}
+
+	today := time.Now()
+	items := make([]todotxt.Task, 0, len(content.Tasks))
+	for _, task := range content.Tasks {
+		item := todotxt.Task{Completed: task.Completed, Text: task.Title, CreationDate: task.CreatedAt}
+		if task.Completed {
+			if _, ok := f.completedOn[task.ID]; !ok {
+				f.completedOn[task.ID] = today
+			}
+			item.CompletionDate = f.completedOn[task.ID]
+		} else {
+			delete(f.completedOn, task.ID)
+		}
+		item.SetRank(task.Priority)
+		for _, tag := range task.Tags {
+			switch tag[0] {
+			case '+':
+				item.Projects = append(item.Projects, tag[1:])
+			case '@':
+				item.Contexts = append(item.Contexts, tag[1:])
+			default:
+				item.Set("tag", tag)
+			}
+		}
+
+		item.Set("id", strconv.Itoa(task.ID))
+		if task.ParentID != 0 {
+			item.Set("parent", strconv.Itoa(task.ParentID))
+		}
+		if !task.DueDate.IsZero() {
+			item.Set("due", todotxt.FormatDue(task.DueDate))
+		}
+		item.SetRecurrence(task.Recurrence)
+		item.Set("remind", joinOffsets(task.Reminders))
+		items = append(items, item)
+
+		if task.Description != "" {
+			meta.Descriptions[task.ID] = task.Description
+		}
+	}
+
+	var data bytes.Buffer
+	if err := todotxt.WriteAll(&data, items); err != nil {
+		return nil, nil, err
+	}
+	metaData, err := json.MarshalIndent(meta, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+	return data.Bytes(), metaData, nil
+}
+
+func (f *todoTxtFormat) decode(data, metaData []byte) (taskFile, error) {
+	var meta todoTxtMeta
+	if len(metaData) > 0 {
+		// Метаданные вспомогательные: без них задачи читаются, теряются только описания и журнал
+		if err := json.Unmarshal(metaData, &meta); err != nil {
+			log.Printf("Error reading todo.txt metadata: %v", err)
+			meta = todoTxtMeta{}
+		}
+	}
+
+	items, err := todotxt.ReadAll(bytes.NewReader(data))
+	if err != nil {
+		return taskFile{}, err
+	}
+
+	content := taskFile{Trash: meta.Trash, Tags: meta.Tags, Activity: meta.Activity, NextID: meta.NextID}
+	seen := map[int]bool{}
+	for _, task := range content.Trash {
+		seen[task.ID] = true
+		content.NextID = max(content.NextID, task.ID+1)
+	}
+
+	f.completedOn = map[int]time.Time{}
+	var unnumbered []int // задачи без id: или с повторяющимся номером
+	for _, item := range items {
+		task := todoTxtTask(item)
+		if task.ID <= 0 || seen[task.ID] {
+			task.ID = 0
+			unnumbered = append(unnumbered, len(content.Tasks))
+		} else {
+			seen[task.ID] = true
+			content.NextID = max(content.NextID, task.ID+1)
+			task.Description = meta.Descriptions[task.ID]
+		}
+		content.Tasks = append(content.Tasks, task)
+	}
+
+	content.NextID = max(content.NextID, 1)
+	for _, i := range unnumbered {
+		content.Tasks[i].ID = content.NextID
+		content.NextID++
+	}
+	for i, task := range content.Tasks {
+		if task.ParentID != 0 && !seen[task.ParentID] {
+			content.Tasks[i].ParentID = 0
+		}
+		if completed := items[i].CompletionDate; task.Completed && !completed.IsZero() {
+			f.completedOn[content.Tasks[i].ID] = completed
+		}
+	}
+	content.Tags = withTaskTags(content.Tags, content.Tasks)
+	return content, nil
+}
+
+// todoTxtTask переводит строку todo.txt в задачу; строка без приоритета - medium.
+// Файл могли править вручную, поэтому метки и расширения, которые не удалось разобрать,
+// не считаются ошибкой, а остаются в названии задачи.
+func todoTxtTask(item todotxt.Task) Task {
+	task := Task{
+		Completed: item.Completed,
+		Priority:  item.Rank(),
+		CreatedAt: item.CreationDate,
+	}
+	if task.Priority == "" {
+		task.Priority = "medium"
+	}
+	task.ID, _ = strconv.Atoi(item.Get("id"))
+	task.ParentID, _ = strconv.Atoi(item.Get("parent"))
+
+	text := []string{item.Text}
+	var tags []string
+	addTag := func(name string) {
+		if _, err := service.NormalizeTagName(name); err != nil {
+			text = append(text, name)
+			return
+		}
+		tags = append(tags, name)
+	}
+	for _, project := range item.Projects {
+		addTag("+" + project)
+	}
+	for _, context := range item.Contexts {
+		addTag("@" + context)
+	}
+	for _, tag := range item.All("tag") {
+		addTag(tag)
+	}
+	task.Tags, _ = service.NormalizeTags(tags)
+
+	if due := item.Get("due"); due != "" {
+		var err error
+		if task.DueDate, err = todotxt.ParseDue(due); err != nil {
+			text = append(text, "due:"+due)
+		}
+	}
+	if rule, err := item.Recurrence(); err != nil {
+		text = append(text, "rec:"+item.Get("rec"))
+	} else if rule != "" {
+		if parsed, err := recurrence.Parse(rule); err == nil {
+			task.Recurrence = parsed.String()
+		} else {
+			text = append(text, "rrule:"+item.Get("rrule"))
+		}
+	}
+	if remind := item.Get("remind"); remind != "" {
+		for _, value := range strings.Split(remind, ",") {
+			offset, err := strconv.Atoi(value)
+			if err != nil {
+				text = append(text, "remind:"+remind)
+				task.Reminders = nil
+				break
+			}
+			task.Reminders = append(task.Reminders, offset)
+		}
+	}
+
+	task.Title = strings.TrimSpace(strings.Join(text, " "))
+	return task
+}
+
+// withTaskTags добавляет в реестр метки, которые встречаются в задачах, но не записаны
+// в метаданных: например, контекст, добавленный в todo.txt вручную
+func withTaskTags(tags []Tag, tasks []Task) []Tag {
+	known := map[string]bool{}
+	for _, tag := range tags {
+		known[strings.ToLower(tag.Name)] = true
+	}
+	for _, task := range tasks {
+		for _, name := range task.Tags {
+			if !known[strings.ToLower(name)] {
+				known[strings.ToLower(name)] = true
+				tags = append(tags, Tag{Name: name, Color: service.DefaultTagColor})
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return strings.ToLower(tags[i].Name) < strings.ToLower(tags[j].Name)
+	})
+	return tags
+}
+
+// joinOffsets записывает смещения напоминаний через запятую
+func joinOffsets(offsets []int) string {
+	parts := make([]string, len(offsets))
+	for i, offset := range offsets {
+		parts[i] = strconv.Itoa(offset)
+	}
+	return strings.Join(parts, ",")
+}
@@ -0,0 +1,135 @@
+package backend
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"todo-list/backend/internal/transfer"
+)
+
+func newTodoTxtFormat() *todoTxtFormat {
+	return &todoTxtFormat{completedOn: map[int]time.Time{}}
+}
+
+func TestTodoTxtFormatRoundTrip(t *testing.T) {
+	due := time.Date(2026, 10, 20, 0, 0, 0, 0, time.Local)
+	content := taskFile{
+		NextID: 3,
+		Tasks: []Task{
+			{ID: 1, Title: "Отчет", Description: "подробности", Priority: "high", DueDate: due,
+				Tags: []string{"+work", "@office", "q4"}, Recurrence: "RRULE:FREQ=WEEKLY", Reminders: []int{60}},
+			{ID: 2, ParentID: 1, Title: "Таблицы", Completed: true, Priority: "low"},
+		},
+	}
+
+	data, meta, err := newTodoTxtFormat().encode(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := newTodoTxtFormat().decode(data, meta)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tasks) != 2 {
+		t.Fatalf("tasks = %+v", got.Tasks)
+	}
+	first, second := got.Tasks[0], got.Tasks[1]
+	if first.Title != "Отчет" || first.Description != "подробности" || first.Priority != "high" ||
+		!first.DueDate.Equal(due) || first.Recurrence != "RRULE:FREQ=WEEKLY" || !reflect.DeepEqual(first.Reminders, []int{60}) {
+		t.Errorf("first = %+v", first)
+	}
+	if !reflect.DeepEqual(first.Tags, []string{"+work", "@office", "q4"}) {
+		t.Errorf("tags = %v", first.Tags)
+	}
+	if second.ParentID != 1 || !second.Completed || second.Priority != "low" {
+		t.Errorf("second = %+v", second)
+	}
+}
+
+func TestTodoTxtFormatKeepsUnparsedValuesInTitle(t *testing.T) {
+	data := []byte("Позвонить due:завтра remind:скоро\n")
+	got, err := newTodoTxtFormat().decode(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tasks) != 1 || got.Tasks[0].Title != "Позвонить due:завтра remind:скоро" {
+		t.Errorf("tasks = %+v", got.Tasks)
+	}
+	if got.Tasks[0].ID == 0 || got.Tasks[0].Priority != "medium" {
+		t.Errorf("task = %+v, want new ID and medium priority", got.Tasks[0])
+	}
+}
+
+// Проекты и контексты одинаково понимают хранилище todo.txt и выгрузка в todo.txt
+func TestTodoTxtStoreMatchesTransferCodec(t *testing.T) {
+	tags := []string{"+home", "+garden", "@phone", "later"}
+
+	stored, _, err := newTodoTxtFormat().encode(taskFile{Tasks: []Task{{ID: 1, Title: "Полить", Priority: "medium", Tags: tags}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := transfer.Decode(bytes.NewReader(stored), transfer.FormatTodoTxt, transfer.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Tasks) != 1 || doc.Tasks[0].Category != "" || !reflect.DeepEqual(doc.Tasks[0].Tags, tags) {
+		t.Errorf("codec read store file as %+v", doc.Tasks)
+	}
+
+	var exported bytes.Buffer
+	doc = transfer.Document{Tasks: []transfer.Task{{ID: 1, Title: "Полить", Category: "Дача", Tags: tags}}}
+	if err := transfer.Encode(&exported, transfer.FormatTodoTxt, doc, transfer.Options{}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := newTodoTxtFormat().decode(exported.Bytes(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tasks) != 1 || !sameTags(got.Tasks[0].Tags, tags) {
+		t.Errorf("store read exported file as %+v", got.Tasks)
+	}
+}
+
+// sameTags сравнивает метки без учета порядка: хранилище упорядочивает их по имени
+func sameTags(got, want []string) bool {
+	if len(got) != len(want) {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, tag := range got {
+		seen[tag] = true
+	}
+	for _, tag := range want {
+		if !seen[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTodoTxtManagerPersists(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "todo.txt")
+	tm, err := NewTodoTxtManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	task, err := tm.Create(Task{Title: "Купить хлеб", Priority: "low", Tags: []string{"+shop"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, err := NewTodoTxtManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Get(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Купить хлеб" || !reflect.DeepEqual(got.Tags, []string{"+shop"}) {
+		t.Errorf("reopened task = %+v", got)
+	}
+}
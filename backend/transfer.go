@@ -0,0 +1,256 @@
+package backend
+
+import (
+	"io"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"todo-list/backend/internal/reminder"
+	"todo-list/backend/internal/service"
+	"todo-list/backend/internal/transfer"
+)
+
+// ImportReport итог импорта задач; Error - ошибка, из-за которой файл не импортирован
+type ImportReport struct {
+	transfer.Report
+	Error string `json:"error,omitempty"`
+}
+
+// ExportTasks возвращает все задачи в формате format (json, csv, markdown, org, todotxt, ics);
+// columns переименовывает колонки CSV. Пустая строка - ошибка экспорта.
+func (a *App) ExportTasks(format string, columns map[string]string) string {
+	format, err := transfer.ParseFormat(format)
+	if err != nil {
+		log.Printf("Error exporting tasks: %v", err)
+		return ""
+	}
+	var out strings.Builder
+	if err := ExportTasks(a.store, &out, format, transfer.Options{Columns: columns}); err != nil {
+		log.Printf("Error exporting tasks: %v", err)
+		return ""
+	}
+	return out.String()
+}
+
+// ImportTasks импортирует задачи из содержимого файла data. duplicates - skip (по умолчанию),
+// update или create; при dryRun задачи не меняются, а отчет показывает, что было бы сделано.
+// Импорт отменяется одним шагом.
+func (a *App) ImportTasks(format, data string, columns map[string]string, duplicates string, dryRun bool) ImportReport {
+	format, err := transfer.ParseFormat(format)
+	if err != nil {
+		return ImportReport{Error: err.Error()}
+	}
+
+	var report ImportReport
+	run := func() bool {
+		report.Report, err = ImportTasks(a.writer(), strings.NewReader(data), format,
+			transfer.Options{Columns: columns}, transfer.ImportOptions{Duplicates: duplicates, DryRun: dryRun})
+		if err != nil {
+			log.Printf("Error importing tasks: %v", err)
+			report.Error = err.Error()
+			return false
+		}
+		return true
+	}
+	if dryRun {
+		run()
+	} else {
+		a.record("Импорт задач", run)
+	}
+	if report.Items == nil {
+		report.Items = []transfer.ReportItem{}
+	}
+	return report
+}
+
+// ExportTasks записывает все задачи хранилища в w. PostgresStore выгружает и категории,
+// в JSON-файле задач категорий нет.
+func ExportTasks(store Store, w io.Writer, format string, opts transfer.Options) error {
+	var doc transfer.Document
+	if pg, ok := store.(*PostgresStore); ok {
+		var err error
+		if doc, err = pg.service.Transfer.Export(); err != nil {
+			return err
+		}
+	} else {
+		tasks, err := store.List()
+		if err != nil {
+			return err
+		}
+		sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+		for _, task := range tasks {
+			doc.Tasks = append(doc.Tasks, transferTask(task))
+		}
+	}
+	return transfer.Encode(w, format, doc, opts)
+}
+
+// ImportTasks читает файл в формате format и импортирует задачи в хранилище.
+// Категории сохраняет только PostgresStore.
+func ImportTasks(store Store, r io.Reader, format string, opts transfer.Options, importOpts transfer.ImportOptions) (transfer.Report, error) {
+	doc, err := transfer.Decode(r, format, opts)
+	if err != nil {
+		return transfer.Report{}, err
+	}
+	if r, ok := store.(*recorder); ok {
+		if pg, ok := r.Store.(*PostgresStore); ok {
+			// Импорт в PostgreSQL идет в обход Store: история сравнивает все задачи
+			if !importOpts.DryRun {
+				if err := r.touchAll(); err != nil {
+					return transfer.Report{}, err
+				}
+			}
+			return pg.service.Transfer.Import(doc, importOpts)
+		}
+	}
+	if pg, ok := store.(*PostgresStore); ok {
+		return pg.service.Transfer.Import(doc, importOpts)
+	}
+	return transfer.Import(&storeTarget{store: store}, doc, importOpts)
+}
+
+// transferTask преобразует Task в задачу файла обмена
+func transferTask(task Task) transfer.Task {
+	result := transfer.Task{
+		ID:          task.ID,
+		ParentID:    task.ParentID,
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		Priority:    task.Priority,
+		Tags:        task.Tags,
+		Recurrence:  task.Recurrence,
+		Reminders:   task.Reminders,
+	}
+	if !task.DueDate.IsZero() {
+		due := task.DueDate
+		result.DueDate = &due
+	}
+	if !task.CreatedAt.IsZero() {
+		createdAt := task.CreatedAt
+		result.CreatedAt = &createdAt
+	}
+	return result
+}
+
+// storeTarget реализует transfer.Store поверх Store; категории файла пропускаются
+type storeTarget struct {
+	store Store
+}
+
+func (t *storeTarget) Existing() ([]transfer.Existing, error) {
+	tasks, err := t.store.List()
+	if err != nil {
+		return nil, err
+	}
+	existing := make([]transfer.Existing, 0, len(tasks))
+	for _, task := range tasks {
+		existing = append(existing, transfer.Existing{ID: task.ID, ParentID: task.ParentID, Title: task.Title})
+	}
+	return existing, nil
+}
+
+func (t *storeTarget) Check(task *transfer.Task) error {
+	var due time.Time
+	if task.DueDate != nil {
+		due = *task.DueDate
+	}
+	rule, err := normalizeRule(task.Recurrence, due)
+	if err != nil {
+		return err
+	}
+	offsets, err := reminder.NormalizeOffsets(task.Reminders)
+	if err != nil {
+		return err
+	}
+	tags, err := service.NormalizeTags(task.Tags)
+	if err != nil {
+		return err
+	}
+
+	task.Recurrence, task.Tags = rule, tags
+	task.Reminders = nil
+	if len(offsets) > 0 {
+		task.Reminders = offsets
+	}
+	return nil
+}
+
+// Create сохраняет задачу; открытая подзадача открывает и своих предков, как при добавлении в приложении
+func (t *storeTarget) Create(task transfer.Task, parentID int) (int, error) {
+	created := Task{
+		Title:       task.Title,
+		Description: task.Description,
+		Completed:   task.Completed,
+		Priority:    task.Priority,
+		ParentID:    parentID,
+		Recurrence:  task.Recurrence,
+		Reminders:   task.Reminders,
+		Tags:        task.Tags,
+		CreatedAt:   time.Now(),
+	}
+	if created.Priority == "" {
+		created.Priority = "medium"
+	}
+	if task.DueDate != nil {
+		created.DueDate = *task.DueDate
+	}
+
+	created, err := t.store.Create(created)
+	if err != nil {
+		return 0, err
+	}
+	if !created.Completed && parentID != 0 {
+		tasks, err := t.store.List()
+		if err != nil {
+			return 0, err
+		}
+		for _, parent := range ancestorsOf(tasks, created.ID) {
+			if parent.Completed {
+				parent.Completed = false
+				if err := t.store.Update(parent); err != nil {
+					return 0, err
+				}
+			}
+		}
+	}
+	return created.ID, nil
+}
+
+func (t *storeTarget) Update(id int, task transfer.Task) error {
+	existing, err := t.store.Get(id)
+	if err != nil {
+		return err
+	}
+
+	existing.Completed = task.Completed
+	if task.Description != "" {
+		existing.Description = task.Description
+	}
+	if task.Priority != "" {
+		existing.Priority = task.Priority
+	}
+	if task.DueDate != nil {
+		existing.DueDate = *task.DueDate
+	}
+	if task.Recurrence != "" {
+		existing.Recurrence = task.Recurrence
+	}
+	if len(task.Reminders) > 0 {
+		existing.Reminders = task.Reminders
+	}
+
+	if err := t.store.Update(existing); err != nil {
+		return err
+	}
+	if len(task.Tags) > 0 {
+		return t.store.SetTaskTags(id, task.Tags)
+	}
+	return nil
+}
+
+func (t *storeTarget) SaveCategory(category transfer.Category) error {
+	return nil
+}
@@ -0,0 +1,120 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+)
+
+// trashPurgeInterval как часто проверяется срок хранения задач в корзине
+const trashPurgeInterval = time.Hour
+
+// RunTrashPurge окончательно удаляет из корзины все, что лежит там дольше retention:
+// сразу при запуске и затем раз в час, пока не отменен ctx. retention <= 0 отключает очистку.
+func RunTrashPurge(ctx context.Context, purge func(before time.Time) (int, error), retention time.Duration) {
+	if retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(trashPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if n, err := purge(time.Now().Add(-retention)); err != nil {
+			log.Printf("Error purging trash: %v", err)
+		} else if n > 0 {
+			log.Printf("purged %d tasks from trash", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeTrash очищает корзину; изменения задач App выполняет по одному
+func (a *App) purgeTrash(before time.Time) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.store.Purge(before)
+}
+
+// GetTrash возвращает задачи из корзины, недавно удаленные первыми
+func (a *App) GetTrash() []Task {
+	tasks, err := a.store.Trash()
+	if err != nil {
+		log.Printf("Error loading trash: %v", err)
+		return []Task{}
+	}
+	return tasks
+}
+
+// RestoreTask возвращает задачу из корзины вместе с подзадачами, удаленными вместе с ней.
+// Если родитель задачи тоже в корзине, она становится задачей верхнего уровня.
+func (a *App) RestoreTask(id int) bool {
+	return a.record("Восстановление задачи", func() bool {
+		if err := a.writer().Restore(id); err != nil {
+			if !errors.Is(err, ErrNotInTrash) {
+				log.Printf("Error restoring task %d: %v", id, err)
+			}
+			return false
+		}
+
+		// Открытая задача под выполненным родителем открывает предков
+		if task, err := a.store.Get(id); err == nil && !task.Completed {
+			a.setCompleted(ancestorsOf(a.listTasks(), id), false)
+		}
+		return true
+	})
+}
+
+// EmptyTrash окончательно удаляет все задачи из корзины
+func (a *App) EmptyTrash() bool {
+	if _, err := a.purgeTrash(time.Now()); err != nil {
+		log.Printf("Error emptying trash: %v", err)
+		return false
+	}
+	return true
+}
+
+// GetCategoryTrash возвращает удаленные категории; они есть только в PostgreSQL
+func (a *App) GetCategoryTrash() []Category {
+	pg, ok := a.store.(*PostgresStore)
+	if !ok {
+		return []Category{}
+	}
+	deleted, err := pg.service.Category.GetDeletedCategories()
+	if err != nil {
+		log.Printf("Error loading category trash: %v", err)
+		return []Category{}
+	}
+
+	categories := make([]Category, 0, len(deleted))
+	for _, category := range deleted {
+		categories = append(categories, Category{ID: int(category.ID), Name: category.Name, Color: category.Color})
+	}
+	return categories
+}
+
+// RestoreCategory возвращает категорию из корзины; ее задачи снова оказываются в ней
+func (a *App) RestoreCategory(id int) bool {
+	pg, ok := a.store.(*PostgresStore)
+	if !ok {
+		log.Printf("Error restoring category %d: %v", id, ErrNoCategories)
+		return false
+	}
+	if id <= 0 {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, err := pg.service.Category.RestoreCategory(uint(id)); err != nil {
+		log.Printf("Error restoring category %d: %v", id, err)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestRestoreTask(t *testing.T) {
+	a, tm := newTestApp(t)
+	parent := a.AddTask("parent", "", "medium", "")
+	first := a.AddSubtask(parent.ID, "first", "", "medium", "")
+	second := a.AddSubtask(parent.ID, "second", "", "medium", "")
+
+	// first удалена раньше родителя и восстанавливается отдельно от него
+	a.DeleteTask(first.ID)
+	a.DeleteTask(parent.ID)
+	if trash := a.GetTrash(); fmt.Sprint(taskIDs(trash)) != fmt.Sprint([]int{parent.ID, second.ID, first.ID}) {
+		t.Fatalf("trash = %v, want recently deleted first", taskIDs(trash))
+	}
+
+	if !a.RestoreTask(parent.ID) {
+		t.Fatal("RestoreTask failed")
+	}
+	if tasks := a.GetTasks(); fmt.Sprint(taskIDs(tasks)) != fmt.Sprint([]int{parent.ID, second.ID}) {
+		t.Errorf("tasks after restore = %v", taskIDs(tasks))
+	}
+	if got, _ := tm.Get(second.ID); !got.DeletedAt.IsZero() || got.ParentID != parent.ID {
+		t.Errorf("restored subtask = %+v", got)
+	}
+	if a.RestoreTask(parent.ID) {
+		t.Error("task restored twice")
+	}
+	if err := tm.Restore(100); !errors.Is(err, ErrNotInTrash) {
+		t.Errorf("Restore of a missing task = %v", err)
+	}
+
+	// Подзадача без родителя в списке становится задачей верхнего уровня
+	a.DeleteTask(parent.ID)
+	if !a.RestoreTask(first.ID) {
+		t.Fatal("RestoreTask of a subtask failed")
+	}
+	if got, _ := tm.Get(first.ID); got.ParentID != 0 {
+		t.Errorf("orphan parent = %d", got.ParentID)
+	}
+}
+
+func TestRestoreReopensParent(t *testing.T) {
+	a, tm := newTestApp(t)
+	parent := a.AddTask("parent", "", "medium", "")
+	child := a.AddSubtask(parent.ID, "child", "", "medium", "")
+	a.DeleteTask(child.ID)
+	a.ToggleTask(parent.ID)
+
+	// Открытая подзадача под выполненным родителем открывает его
+	if !a.RestoreTask(child.ID) {
+		t.Fatal("RestoreTask failed")
+	}
+	if got, _ := tm.Get(parent.ID); got.Completed {
+		t.Error("parent is still completed")
+	}
+}
+
+func TestPurge(t *testing.T) {
+	a, tm := newTestApp(t)
+	old := a.AddTask("old", "", "medium", "")
+	recent := a.AddTask("recent", "", "medium", "")
+	kept := a.AddTask("kept", "", "medium", "")
+
+	a.DeleteTask(old.ID)
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	a.DeleteTask(recent.ID)
+
+	if n, err := tm.Purge(cutoff); err != nil || n != 1 {
+		t.Fatalf("Purge = %d, %v", n, err)
+	}
+	if trash := a.GetTrash(); len(trash) != 1 || trash[0].ID != recent.ID {
+		t.Errorf("trash = %v", taskIDs(trash))
+	}
+	if n, _ := tm.Purge(cutoff); n != 0 {
+		t.Errorf("second Purge removed %d tasks", n)
+	}
+
+	if !a.EmptyTrash() || len(a.GetTrash()) != 0 {
+		t.Errorf("trash after EmptyTrash = %v", taskIDs(a.GetTrash()))
+	}
+	if tasks := a.GetTasks(); len(tasks) != 1 || tasks[0].ID != kept.ID {
+		t.Errorf("tasks = %v", taskIDs(tasks))
+	}
+	if a.RestoreTask(old.ID) {
+		t.Error("purged task restored")
+	}
+}
+
+func TestRunTrashPurge(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var cutoffs []time.Time
+	purge := func(before time.Time) (int, error) {
+		cutoffs = append(cutoffs, before)
+		return 0, nil
+	}
+
+	RunTrashPurge(ctx, purge, 0)
+	if len(cutoffs) != 0 {
+		t.Fatalf("purge ran with retention disabled")
+	}
+
+	// Очистка выполняется сразу при запуске, затем RunTrashPurge ждет отмены ctx
+	start := time.Now()
+	RunTrashPurge(ctx, purge, 48*time.Hour)
+	if len(cutoffs) != 1 {
+		t.Fatalf("purge ran %d times", len(cutoffs))
+	}
+	if want := start.Add(-48 * time.Hour); cutoffs[0].Before(want) || cutoffs[0].After(time.Now().Add(-48*time.Hour)) {
+		t.Errorf("cutoff = %v, want about %v", cutoffs[0], want)
+	}
+}
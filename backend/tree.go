@@ -0,0 +1,183 @@
+package backend
+
+import (
+	"errors"
+	"log"
+)
+
+// ErrTaskCycle возвращается при попытке перенести задачу в ее собственное поддерево
+var ErrTaskCycle = errors.New("нельзя переместить задачу внутрь ее собственного поддерева")
+
+// Progress прогресс выполнения подзадач
+type Progress struct {
+	Done  int `json:"done"`
+	Total int `json:"total"`
+}
+
+// TaskNode задача вместе с подзадачами
+type TaskNode struct {
+	Task
+	Progress Progress    `json:"progress"`
+	Children []*TaskNode `json:"children"`
+}
+
+// GetTaskTree возвращает все задачи в виде деревьев с прогрессом по подзадачам
+func (a *App) GetTaskTree() []*TaskNode {
+	return buildTaskTree(a.listTasks(), 0)
+}
+
+// GetSubtaskTree возвращает дерево одной задачи
+func (a *App) GetSubtaskTree(id int) *TaskNode {
+	roots := buildTaskTree(a.listTasks(), id)
+	if len(roots) == 0 {
+		return nil
+	}
+	return roots[0]
+}
+
+// GetTaskProgress возвращает число выполненных подзадач (на всех уровнях) и их общее число
+func (a *App) GetTaskProgress(id int) Progress {
+	if node := a.GetSubtaskTree(id); node != nil {
+		return node.Progress
+	}
+	return Progress{}
+}
+
+// MoveTask переносит задачу вместе с подзадачами под parentID (0 - на верхний уровень)
+func (a *App) MoveTask(id, parentID int) bool {
+	return a.record("Перемещение задачи", func() bool { return a.moveTask(id, parentID) })
+}
+
+func (a *App) moveTask(id, parentID int) bool {
+	tasks := a.listTasks()
+	if parentID != 0 {
+		if parentID == id {
+			return false
+		}
+		if _, err := a.store.Get(parentID); err != nil {
+			log.Printf("Error loading parent task %d: %v", parentID, err)
+			return false
+		}
+		for _, child := range descendantsOf(tasks, id) {
+			if child.ID == parentID {
+				log.Printf("Error moving task %d: %v", id, ErrTaskCycle)
+				return false
+			}
+		}
+	}
+
+	if err := a.writer().Move(id, parentID); err != nil {
+		log.Printf("Error moving task %d: %v", id, err)
+		return false
+	}
+
+	// Открытая задача под выполненным родителем открывает новых предков
+	if task, err := a.store.Get(id); err == nil && !task.Completed {
+		a.setCompleted(ancestorsOf(a.listTasks(), id), false)
+	}
+	return true
+}
+
+// setCompleted меняет статус задач, у которых он отличается
+func (a *App) setCompleted(tasks []Task, completed bool) {
+	for _, task := range tasks {
+		if task.Completed == completed {
+			continue
+		}
+		a.modify(task.ID, func(task *Task) error {
+			task.Completed = completed
+			return nil
+		})
+	}
+}
+
+// descendantsOf возвращает всех потомков задачи в порядке обхода в ширину
+func descendantsOf(tasks []Task, id int) []Task {
+	children := make(map[int][]Task)
+	for _, task := range tasks {
+		if task.ParentID != 0 {
+			children[task.ParentID] = append(children[task.ParentID], task)
+		}
+	}
+
+	var result []Task
+	queue := []int{id}
+	seen := map[int]bool{id: true}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, child := range children[current] {
+			if seen[child.ID] {
+				continue
+			}
+			seen[child.ID] = true
+			result = append(result, child)
+			queue = append(queue, child.ID)
+		}
+	}
+	return result
+}
+
+// ancestorsOf возвращает предков задачи от ближайшего к корню
+func ancestorsOf(tasks []Task, id int) []Task {
+	byID := make(map[int]Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	var result []Task
+	seen := map[int]bool{id: true}
+	for parentID := byID[id].ParentID; parentID != 0 && !seen[parentID]; {
+		parent, ok := byID[parentID]
+		if !ok {
+			break
+		}
+		seen[parentID] = true
+		result = append(result, parent)
+		parentID = parent.ParentID
+	}
+	return result
+}
+
+// buildTaskTree собирает задачи в деревья. Корни - rootID либо, если он 0,
+// задачи без родителя (или с удаленным родителем).
+func buildTaskTree(tasks []Task, rootID int) []*TaskNode {
+	nodes := make(map[int]*TaskNode, len(tasks))
+	for _, task := range tasks {
+		nodes[task.ID] = &TaskNode{Task: task, Children: []*TaskNode{}}
+	}
+
+	roots := []*TaskNode{}
+	for _, task := range tasks {
+		node := nodes[task.ID]
+		parent, hasParent := nodes[task.ParentID]
+		switch {
+		case rootID != 0 && task.ID == rootID:
+			roots = append(roots, node)
+		case rootID == 0 && (task.ParentID == 0 || !hasParent):
+			roots = append(roots, node)
+		case hasParent:
+			parent.Children = append(parent.Children, node)
+		}
+	}
+
+	for _, root := range roots {
+		computeTaskProgress(root)
+	}
+	return roots
+}
+
+// computeTaskProgress заполняет Progress узла по всем его потомкам
+func computeTaskProgress(node *TaskNode) Progress {
+	var progress Progress
+	for _, child := range node.Children {
+		childProgress := computeTaskProgress(child)
+		progress.Total += childProgress.Total + 1
+		progress.Done += childProgress.Done
+		if child.Completed {
+			progress.Done++
+		}
+	}
+	node.Progress = progress
+	return progress
+}
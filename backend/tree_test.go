@@ -0,0 +1,121 @@
+package backend
+
+import (
+	"fmt"
+	"testing"
+)
+
+// newTestTree создает задачи 1 > 2 > 3 и 1 > 4, а также отдельную задачу 5
+func newTestTree(t *testing.T) *App {
+	t.Helper()
+	a, _ := newTestApp(t)
+	root := a.AddTask("Ремонт", "", "high", "")
+	child := a.AddSubtask(root.ID, "Кухня", "", "medium", "")
+	a.AddSubtask(child.ID, "Плитка", "", "low", "")
+	a.AddSubtask(root.ID, "Ванная", "", "medium", "")
+	a.AddTask("Отдельная", "", "low", "")
+	if a.AddSubtask(100, "Сирота", "", "low", "").ID != 0 {
+		t.Fatal("subtask of a missing task was created")
+	}
+	return a
+}
+
+// completedIDs возвращает ID выполненных задач
+func completedIDs(a *App) []int {
+	var ids []int
+	for _, task := range a.GetTasks() {
+		if task.Completed {
+			ids = append(ids, task.ID)
+		}
+	}
+	return ids
+}
+
+func TestTaskTree(t *testing.T) {
+	a := newTestTree(t)
+	a.ToggleTask(3)
+
+	roots := a.GetTaskTree()
+	if len(roots) != 2 || roots[0].ID != 1 || roots[1].ID != 5 {
+		t.Fatalf("roots = %+v", roots)
+	}
+	if got := roots[0].Progress; got != (Progress{Done: 1, Total: 3}) {
+		t.Errorf("root progress = %+v", got)
+	}
+	kitchen := roots[0].Children[0]
+	if kitchen.ID != 2 || len(kitchen.Children) != 1 || kitchen.Progress != (Progress{Done: 1, Total: 1}) {
+		t.Errorf("kitchen = %+v", kitchen)
+	}
+	if node := a.GetSubtaskTree(2); node == nil || node.ID != 2 || len(node.Children) != 1 {
+		t.Errorf("subtree = %+v", node)
+	}
+	if a.GetSubtaskTree(100) != nil || a.GetTaskProgress(100) != (Progress{}) {
+		t.Error("tree of a missing task")
+	}
+}
+
+func TestCompletionRollUp(t *testing.T) {
+	a := newTestTree(t)
+
+	// Выполнение задачи закрывает все поддерево
+	a.ToggleTask(1)
+	if got := fmt.Sprint(completedIDs(a)); got != "[1 2 3 4]" {
+		t.Fatalf("completed after closing the root = %s", got)
+	}
+
+	// Открытие подзадачи открывает всех предков, но не соседей
+	a.ToggleTask(3)
+	if got := fmt.Sprint(completedIDs(a)); got != "[4]" {
+		t.Errorf("completed after reopening a leaf = %s", got)
+	}
+
+	// Новая открытая подзадача под выполненной задачей открывает ее
+	a.ToggleTask(4)
+	a.AddSubtask(4, "Зеркало", "", "low", "")
+	if got := fmt.Sprint(completedIDs(a)); got != "[]" {
+		t.Errorf("completed after adding an open subtask = %s", got)
+	}
+}
+
+func TestMoveTask(t *testing.T) {
+	a := newTestTree(t)
+
+	// Перенос в собственное поддерево или под себя запрещен
+	for _, parent := range []int{2, 3, 100} {
+		if a.MoveTask(2, parent) {
+			t.Errorf("MoveTask(2, %d) succeeded", parent)
+		}
+	}
+
+	// Открытая задача под выполненной задачей открывает ее
+	a.ToggleTask(5)
+	if !a.MoveTask(2, 5) {
+		t.Fatal("MoveTask(2, 5) failed")
+	}
+	if got := fmt.Sprint(completedIDs(a)); got != "[]" {
+		t.Errorf("completed after moving an open task = %s", got)
+	}
+	if progress := a.GetTaskProgress(5); progress.Total != 2 {
+		t.Errorf("progress of the new parent = %+v", progress)
+	}
+
+	if !a.MoveTask(2, 0) {
+		t.Fatal("MoveTask to the top level failed")
+	}
+	if roots := a.GetTaskTree(); len(roots) != 3 {
+		t.Errorf("roots after moving to the top level = %d", len(roots))
+	}
+}
+
+func TestDeleteSubtree(t *testing.T) {
+	a := newTestTree(t)
+	if !a.DeleteTask(2) {
+		t.Fatal("DeleteTask failed")
+	}
+	if got := fmt.Sprint(taskIDs(a.GetTasks())); got != "[1 4 5]" {
+		t.Errorf("tasks after deleting a subtree = %s", got)
+	}
+	if got := fmt.Sprint(taskIDs(a.GetTrash())); got != "[2 3]" && got != "[3 2]" {
+		t.Errorf("trash = %s", got)
+	}
+}
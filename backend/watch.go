@@ -0,0 +1,88 @@
+package backend
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// TasksChangedEvent событие Wails: задачи изменены вне приложения (другим процессом
+// или программой синхронизации), список нужно загрузить заново
+const TasksChangedEvent = "tasks:changed"
+
+// StoreLockedEvent событие Wails: файл задач зашифровали или сменили парольную фразу
+// в другом процессе, задачи недоступны до ввода фразы (UnlockStore)
+const StoreLockedEvent = "store:locked"
+
+// fileWatchInterval как часто проверяется файл задач
+const fileWatchInterval = 2 * time.Second
+
+// watcher хранилище, которое замечает изменения своих данных извне
+type watcher interface {
+	Watch(ctx context.Context, interval time.Duration, onChange, onLocked func())
+}
+
+// startWatch следит за изменениями хранилища извне и сообщает о них окну Wails.
+// PostgreSQL изменения извне не отслеживает.
+func (a *App) startWatch(ctx context.Context) {
+	w, ok := a.store.(watcher)
+	if !ok {
+		return
+	}
+	go w.Watch(ctx, fileWatchInterval, func() {
+		runtime.EventsEmit(ctx, TasksChangedEvent)
+	}, func() {
+		runtime.EventsEmit(ctx, StoreLockedEvent)
+	})
+}
+
+// Watch проверяет файл задач раз в interval, пока не отменен ctx, и перечитывает его,
+// если файл изменил другой процесс. onChange вызывается после каждого такого перечитывания,
+// в том числе выполненного при обычном обращении к задачам между проверками.
+// onLocked вызывается, когда открытое хранилище запирается; хранилище, запертое
+// уже при запуске Watch, не сообщается.
+func (tm *TaskManager) Watch(ctx context.Context, interval time.Duration, onChange, onLocked func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	locked := tm.Locked()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := tm.reloadExternal()
+		wasLocked := locked
+		locked = errors.Is(err, ErrStoreLocked)
+		switch {
+		case locked:
+			if !wasLocked {
+				onLocked()
+			}
+		case err != nil:
+			log.Printf("Error checking %s: %v", tm.filename, err)
+		case changed:
+			onChange()
+		}
+	}
+}
+
+// reloadExternal перечитывает файл задач, если его изменил другой процесс, и сообщает,
+// были ли задачи перечитаны с прошлого вызова
+func (tm *TaskManager) reloadExternal() (bool, error) {
+	unlock, err := tm.rlock()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+
+	changed := tm.external
+	tm.external = false
+	return changed, nil
+}
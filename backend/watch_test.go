@@ -0,0 +1,122 @@
+package backend
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// watchEvents запускает Watch и возвращает каналы его уведомлений об изменениях и о запирании
+func watchEvents(t *testing.T, tm *TaskManager) (<-chan struct{}, <-chan struct{}) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	events := make(chan struct{}, 16)
+	locks := make(chan struct{}, 16)
+	go func() {
+		defer close(done)
+		tm.Watch(ctx, 10*time.Millisecond, func() { events <- struct{}{} }, func() { locks <- struct{}{} })
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return events, locks
+}
+
+func TestWatchReportsExternalChange(t *testing.T) {
+	watched, filename := newTestManager(t)
+	other, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	events, _ := watchEvents(t, watched)
+
+	// Собственные изменения уведомлений не вызывают
+	if _, err := watched.Create(Task{Title: "own", Priority: "medium"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-events:
+		t.Fatal("own change reported as external")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	if _, err := other.Create(Task{Title: "external", Priority: "medium"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-events:
+	case <-time.After(2 * time.Second):
+		t.Fatal("external change not reported")
+	}
+	if tasks, _ := watched.List(); len(tasks) != 2 {
+		t.Errorf("watched manager sees %d tasks after reload, want 2", len(tasks))
+	}
+}
+
+func TestWatchReportsChangeReadBetweenChecks(t *testing.T) {
+	watched, filename := newTestManager(t)
+	other, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Файл перечитан обычным чтением еще до запуска проверки: окно все равно узнает об этом
+	if _, err := other.Create(Task{Title: "external", Priority: "medium"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watched.List(); err != nil {
+		t.Fatal(err)
+	}
+	events, _ := watchEvents(t, watched)
+	select {
+	case <-events:
+	case <-time.After(2 * time.Second):
+		t.Fatal("reload during List not reported")
+	}
+}
+
+func TestWatchReportsLockedStore(t *testing.T) {
+	watched, filename := newEncryptedManager(t, "pass")
+	other, err := NewTaskManager(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+	if err := other.Unlock("pass"); err != nil {
+		t.Fatal(err)
+	}
+	events, locks := watchEvents(t, watched)
+
+	// Фразу сменили в другом процессе: окно должно запросить новую, и только один раз
+	if err := other.SetPassphrase("pass", "changed"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-locks:
+	case <-time.After(2 * time.Second):
+		t.Fatal("locked store not reported")
+	}
+	select {
+	case <-locks:
+		t.Fatal("locked store reported twice")
+	case <-events:
+		t.Fatal("locked store reported as a change")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// После разблокировки повторное запирание снова сообщается
+	if err := watched.Unlock("changed"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := other.SetPassphrase("changed", "again"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-locks:
+	case <-time.After(2 * time.Second):
+		t.Fatal("locked store not reported after unlock")
+	}
+}
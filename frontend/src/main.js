@@ -1,4 +1,5 @@
-import { GetTasks, AddTask, DeleteTask, ToggleTask, GetCombinedFilteredTasks } from '../wailsjs/go/backend/App.js';
+import { GetTasks, AddTask, DeleteTask, ToggleTask, GetCombinedFilteredTasks, SnoozeReminder, GetEncryptionStatus, UnlockStore } from '../wailsjs/go/backend/App.js';
+import { EventsOn } from '../wailsjs/runtime/runtime.js';
 
 let todos = [];
 let currentFilters = {
@@ -11,11 +12,25 @@ let taskToDelete = null;
 
 // Инициализация приложения
 document.addEventListener('DOMContentLoaded', async () => {
-    await loadTodos();
     setupEventListeners();
+    setupBackendEvents();
+    // Зашифрованный файл задач открывается только после ввода парольной фразы
+    if (await checkLocked()) {
+        return;
+    }
+    await loadTodos();
     updateStats();
 });
 
+// Подписка на события бэкенда
+function setupBackendEvents() {
+    EventsOn('reminder', showReminder);
+    // Файл задач изменил другой процесс: список загружается заново с текущими фильтрами
+    EventsOn('tasks:changed', applyFilters);
+    // Файл зашифровали или сменили фразу в другом процессе: задачи недоступны до ввода фразы
+    EventsOn('store:locked', checkLocked);
+}
+
 // Настройка обработчиков событий
 function setupEventListeners() {
     // Форма добавления задачи
@@ -82,6 +97,61 @@ function setupEventListeners() {
             hideModal();
         }
     });
+
+    // Ввод парольной фразы
+    const unlockBtn = document.getElementById('unlock-btn');
+    const unlockPassphrase = document.getElementById('unlock-passphrase');
+
+    unlockBtn.addEventListener('click', unlockStore);
+    unlockPassphrase.addEventListener('keypress', (e) => {
+        if (e.key === 'Enter') {
+            unlockStore();
+        }
+    });
+}
+
+// Проверка, заперт ли файл задач; если да, показывается запрос парольной фразы
+async function checkLocked() {
+    try {
+        const status = await GetEncryptionStatus();
+        if (!status.locked) {
+            return false;
+        }
+    } catch (error) {
+        console.error('Ошибка проверки шифрования:', error);
+        return false;
+    }
+
+    todos = [];
+    renderTodos();
+    updateStats();
+    document.getElementById('unlock-modal').classList.add('show');
+    document.getElementById('unlock-passphrase').focus();
+    return true;
+}
+
+// Разблокировка файла задач введенной парольной фразой
+async function unlockStore() {
+    const input = document.getElementById('unlock-passphrase');
+    if (!input.value) {
+        showNotification('Введите парольную фразу', 'error');
+        return;
+    }
+
+    try {
+        const ok = await UnlockStore(input.value);
+        if (!ok) {
+            throw new Error('unlock failed');
+        }
+        input.value = '';
+        document.getElementById('unlock-modal').classList.remove('show');
+        await applyFilters();
+        showNotification('Файл задач открыт', 'success');
+    } catch (error) {
+        console.error('Ошибка разблокировки:', error);
+        input.select();
+        showNotification('Неверная парольная фраза', 'error');
+    }
 }
 
 // Загрузка задач
@@ -237,6 +307,65 @@ async function deleteTodo(id) {
     }
 }
 
+// Показ напоминания о задаче с возможностью отложить его
+function showReminder(reminder) {
+    const container = document.getElementById('reminders');
+
+    // Повторное напоминание о той же задаче заменяет предыдущее
+    container.querySelector(`[data-task-id="${reminder.task_id}"]`)?.remove();
+
+    const card = document.createElement('div');
+    card.className = 'reminder';
+    card.dataset.taskId = reminder.task_id;
+    card.innerHTML = `
+        <div class="reminder-text">
+            <strong>${reminder.snoozed ? '🔁' : '⏰'} ${escapeHtml(reminder.title)}</strong>
+            <span>${getReminderText(reminder)}</span>
+        </div>
+        <div class="reminder-actions">
+            <button class="btn-secondary" data-minutes="10">Через 10 мин</button>
+            <button class="btn-secondary" data-minutes="60">Через час</button>
+            <button class="reminder-close" title="Закрыть">✕</button>
+        </div>
+    `;
+
+    card.querySelectorAll('[data-minutes]').forEach(button => {
+        button.addEventListener('click', () => snoozeReminder(card, reminder.task_id, Number(button.dataset.minutes)));
+    });
+    card.querySelector('.reminder-close').addEventListener('click', () => card.remove());
+
+    container.appendChild(card);
+}
+
+// Отложить напоминание
+async function snoozeReminder(card, taskId, minutes) {
+    try {
+        const ok = await SnoozeReminder(taskId, minutes);
+        if (!ok) {
+            throw new Error('snooze failed');
+        }
+        card.remove();
+        showNotification('Напоминание отложено', 'success');
+    } catch (error) {
+        console.error('Ошибка откладывания напоминания:', error);
+        showNotification('Не удалось отложить напоминание', 'error');
+    }
+}
+
+function getReminderText(reminder) {
+    const dueDate = formatDate(new Date(reminder.due_date));
+    if (reminder.offset <= 0) {
+        return `Срок: ${dueDate}`;
+    }
+    if (reminder.offset % 1440 === 0) {
+        return `Через ${reminder.offset / 1440} дн. (${dueDate})`;
+    }
+    if (reminder.offset % 60 === 0) {
+        return `Через ${reminder.offset / 60} ч (${dueDate})`;
+    }
+    return `Через ${reminder.offset} мин (${dueDate})`;
+}
+
 // Обновление статистики
 function updateStats() {
     const totalTasks = document.getElementById('total-tasks');
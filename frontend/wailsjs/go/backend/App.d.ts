@@ -1,19 +1,112 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 import {backend} from '../models';
+import {reminder} from '../models';
+import {time} from '../models';
+import {context} from '../models';
+
+export function AddRecurringTask(arg1:string,arg2:string,arg3:string,arg4:string,arg5:string):Promise<backend.Task>;
+
+export function AddSubtask(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string):Promise<backend.Task>;
 
 export function AddTask(arg1:string,arg2:string,arg3:string,arg4:string):Promise<backend.Task>;
 
+export function BeginGroup(arg1:string):Promise<void>;
+
+export function ClearHistory():Promise<void>;
+
+export function CreateTag(arg1:string,arg2:string):Promise<backend.Tag>;
+
+export function DeleteTag(arg1:string):Promise<boolean>;
+
 export function DeleteTask(arg1:number):Promise<boolean>;
 
+export function DeleteTasks(arg1:Array<number>):Promise<boolean>;
+
+export function EmptyTrash():Promise<boolean>;
+
+export function EndGroup():Promise<void>;
+
+export function ExportTasks(arg1:string,arg2:Record<string, string>):Promise<string>;
+
+export function GetActivity(arg1:number,arg2:number):Promise<Array<backend.Activity>>;
+
+export function GetCategoryTrash():Promise<Array<backend.Category>>;
+
 export function GetCombinedFilteredTasks(arg1:string,arg2:string,arg3:string,arg4:boolean):Promise<Array<backend.Task>>;
 
+export function GetEncryptionStatus():Promise<backend.EncryptionStatus>;
+
 export function GetFilteredTasks(arg1:string):Promise<Array<backend.Task>>;
 
+export function GetHistoryStatus():Promise<backend.HistoryStatus>;
+
+export function GetReminderSettings():Promise<reminder.Settings>;
+
 export function GetSortedTasks(arg1:string,arg2:boolean):Promise<Array<backend.Task>>;
 
+export function GetSubtaskTree(arg1:number):Promise<backend.TaskNode>;
+
+export function GetTags():Promise<Array<backend.Tag>>;
+
+export function GetTaskHistory(arg1:number,arg2:number):Promise<Array<backend.Activity>>;
+
+export function GetTaskProgress(arg1:number):Promise<backend.Progress>;
+
+export function GetTaskTree():Promise<Array<backend.TaskNode>>;
+
 export function GetTasks():Promise<Array<backend.Task>>;
 
 export function GetTasksByDateFilter(arg1:string):Promise<Array<backend.Task>>;
 
+export function GetTasksByTags(arg1:Array<string>,arg2:boolean):Promise<Array<backend.Task>>;
+
+export function GetTasksPage(arg1:string,arg2:string,arg3:number):Promise<backend.TaskPage>;
+
+export function GetTrash():Promise<Array<backend.Task>>;
+
+export function ImportTasks(arg1:string,arg2:string,arg3:Record<string, string>,arg4:string,arg5:boolean):Promise<backend.ImportReport>;
+
+export function MergeTags(arg1:Array<string>,arg2:string):Promise<boolean>;
+
+export function MoveTask(arg1:number,arg2:number):Promise<boolean>;
+
+export function PreviewRecurrence(arg1:string,arg2:string,arg3:number):Promise<Array<time.Time>>;
+
+export function Redo():Promise<boolean>;
+
+export function RenameTag(arg1:string,arg2:string):Promise<boolean>;
+
+export function RestoreCategory(arg1:number):Promise<boolean>;
+
+export function RestoreTask(arg1:number):Promise<boolean>;
+
+export function SearchTasks(arg1:string,arg2:number):Promise<Array<backend.SearchResult>>;
+
+export function SetReminderSettings(arg1:reminder.Settings):Promise<boolean>;
+
+export function SetStorePassphrase(arg1:string,arg2:string):Promise<boolean>;
+
+export function SetTaskRecurrence(arg1:number,arg2:string):Promise<boolean>;
+
+export function SetTaskReminders(arg1:number,arg2:Array<number>):Promise<boolean>;
+
+export function SetTaskTags(arg1:number,arg2:Array<string>):Promise<boolean>;
+
+export function SetTasksCompleted(arg1:Array<number>,arg2:boolean):Promise<boolean>;
+
+export function Shutdown(arg1:context.Context):Promise<void>;
+
+export function SkipOccurrence(arg1:number):Promise<boolean>;
+
+export function SnoozeReminder(arg1:number,arg2:number):Promise<boolean>;
+
+export function Startup(arg1:context.Context):Promise<void>;
+
 export function ToggleTask(arg1:number):Promise<boolean>;
+
+export function Undo():Promise<boolean>;
+
+export function UnlockStore(arg1:string):Promise<boolean>;
+
+export function UpdateTask(arg1:number,arg2:string,arg3:string,arg4:string,arg5:string):Promise<boolean>;
@@ -2,26 +2,106 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function AddRecurringTask(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['backend']['App']['AddRecurringTask'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function AddSubtask(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['backend']['App']['AddSubtask'](arg1, arg2, arg3, arg4, arg5);
+}
+
 export function AddTask(arg1, arg2, arg3, arg4) {
   return window['go']['backend']['App']['AddTask'](arg1, arg2, arg3, arg4);
 }
 
+export function BeginGroup(arg1) {
+  return window['go']['backend']['App']['BeginGroup'](arg1);
+}
+
+export function ClearHistory() {
+  return window['go']['backend']['App']['ClearHistory']();
+}
+
+export function CreateTag(arg1, arg2) {
+  return window['go']['backend']['App']['CreateTag'](arg1, arg2);
+}
+
+export function DeleteTag(arg1) {
+  return window['go']['backend']['App']['DeleteTag'](arg1);
+}
+
 export function DeleteTask(arg1) {
   return window['go']['backend']['App']['DeleteTask'](arg1);
 }
 
+export function DeleteTasks(arg1) {
+  return window['go']['backend']['App']['DeleteTasks'](arg1);
+}
+
+export function EmptyTrash() {
+  return window['go']['backend']['App']['EmptyTrash']();
+}
+
+export function EndGroup() {
+  return window['go']['backend']['App']['EndGroup']();
+}
+
+export function ExportTasks(arg1, arg2) {
+  return window['go']['backend']['App']['ExportTasks'](arg1, arg2);
+}
+
+export function GetActivity(arg1, arg2) {
+  return window['go']['backend']['App']['GetActivity'](arg1, arg2);
+}
+
+export function GetCategoryTrash() {
+  return window['go']['backend']['App']['GetCategoryTrash']();
+}
+
 export function GetCombinedFilteredTasks(arg1, arg2, arg3, arg4) {
   return window['go']['backend']['App']['GetCombinedFilteredTasks'](arg1, arg2, arg3, arg4);
 }
 
+export function GetEncryptionStatus() {
+  return window['go']['backend']['App']['GetEncryptionStatus']();
+}
+
 export function GetFilteredTasks(arg1) {
   return window['go']['backend']['App']['GetFilteredTasks'](arg1);
 }
 
+export function GetHistoryStatus() {
+  return window['go']['backend']['App']['GetHistoryStatus']();
+}
+
+export function GetReminderSettings() {
+  return window['go']['backend']['App']['GetReminderSettings']();
+}
+
 export function GetSortedTasks(arg1, arg2) {
   return window['go']['backend']['App']['GetSortedTasks'](arg1, arg2);
 }
 
+export function GetSubtaskTree(arg1) {
+  return window['go']['backend']['App']['GetSubtaskTree'](arg1);
+}
+
+export function GetTags() {
+  return window['go']['backend']['App']['GetTags']();
+}
+
+export function GetTaskHistory(arg1, arg2) {
+  return window['go']['backend']['App']['GetTaskHistory'](arg1, arg2);
+}
+
+export function GetTaskProgress(arg1) {
+  return window['go']['backend']['App']['GetTaskProgress'](arg1);
+}
+
+export function GetTaskTree() {
+  return window['go']['backend']['App']['GetTaskTree']();
+}
+
 export function GetTasks() {
   return window['go']['backend']['App']['GetTasks']();
 }
@@ -30,6 +110,106 @@ export function GetTasksByDateFilter(arg1) {
   return window['go']['backend']['App']['GetTasksByDateFilter'](arg1);
 }
 
+export function GetTasksByTags(arg1, arg2) {
+  return window['go']['backend']['App']['GetTasksByTags'](arg1, arg2);
+}
+
+export function GetTasksPage(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['GetTasksPage'](arg1, arg2, arg3);
+}
+
+export function GetTrash() {
+  return window['go']['backend']['App']['GetTrash']();
+}
+
+export function ImportTasks(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['backend']['App']['ImportTasks'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function MergeTags(arg1, arg2) {
+  return window['go']['backend']['App']['MergeTags'](arg1, arg2);
+}
+
+export function MoveTask(arg1, arg2) {
+  return window['go']['backend']['App']['MoveTask'](arg1, arg2);
+}
+
+export function PreviewRecurrence(arg1, arg2, arg3) {
+  return window['go']['backend']['App']['PreviewRecurrence'](arg1, arg2, arg3);
+}
+
+export function Redo() {
+  return window['go']['backend']['App']['Redo']();
+}
+
+export function RenameTag(arg1, arg2) {
+  return window['go']['backend']['App']['RenameTag'](arg1, arg2);
+}
+
+export function RestoreCategory(arg1) {
+  return window['go']['backend']['App']['RestoreCategory'](arg1);
+}
+
+export function RestoreTask(arg1) {
+  return window['go']['backend']['App']['RestoreTask'](arg1);
+}
+
+export function SearchTasks(arg1, arg2) {
+  return window['go']['backend']['App']['SearchTasks'](arg1, arg2);
+}
+
+export function SetReminderSettings(arg1) {
+  return window['go']['backend']['App']['SetReminderSettings'](arg1);
+}
+
+export function SetStorePassphrase(arg1, arg2) {
+  return window['go']['backend']['App']['SetStorePassphrase'](arg1, arg2);
+}
+
+export function SetTaskRecurrence(arg1, arg2) {
+  return window['go']['backend']['App']['SetTaskRecurrence'](arg1, arg2);
+}
+
+export function SetTaskReminders(arg1, arg2) {
+  return window['go']['backend']['App']['SetTaskReminders'](arg1, arg2);
+}
+
+export function SetTaskTags(arg1, arg2) {
+  return window['go']['backend']['App']['SetTaskTags'](arg1, arg2);
+}
+
+export function SetTasksCompleted(arg1, arg2) {
+  return window['go']['backend']['App']['SetTasksCompleted'](arg1, arg2);
+}
+
+export function Shutdown(arg1) {
+  return window['go']['backend']['App']['Shutdown'](arg1);
+}
+
+export function SkipOccurrence(arg1) {
+  return window['go']['backend']['App']['SkipOccurrence'](arg1);
+}
+
+export function SnoozeReminder(arg1, arg2) {
+  return window['go']['backend']['App']['SnoozeReminder'](arg1, arg2);
+}
+
+export function Startup(arg1) {
+  return window['go']['backend']['App']['Startup'](arg1);
+}
+
 export function ToggleTask(arg1) {
   return window['go']['backend']['App']['ToggleTask'](arg1);
 }
+
+export function Undo() {
+  return window['go']['backend']['App']['Undo']();
+}
+
+export function UnlockStore(arg1) {
+  return window['go']['backend']['App']['UnlockStore'](arg1);
+}
+
+export function UpdateTask(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['backend']['App']['UpdateTask'](arg1, arg2, arg3, arg4, arg5);
+}
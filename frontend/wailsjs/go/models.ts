@@ -1,15 +1,244 @@
 export namespace backend {
 	
+	export class Activity {
+	    id: number;
+	    task_id: number;
+	    title: string;
+	    action: string;
+	    changes: models.FieldChange[];
+	    source: string;
+	    actor?: string;
+	    at: time.Time;
+	
+	    static createFrom(source: any = {}) {
+	        return new Activity(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.task_id = source["task_id"];
+	        this.title = source["title"];
+	        this.action = source["action"];
+	        this.changes = this.convertValues(source["changes"], models.FieldChange);
+	        this.source = source["source"];
+	        this.actor = source["actor"];
+	        this.at = this.convertValues(source["at"], time.Time);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Category {
+	    id: number;
+	    name: string;
+	    color: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Category(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.count = source["count"];
+	    }
+	}
+	export class EncryptionStatus {
+	    supported: boolean;
+	    enabled: boolean;
+	    locked: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new EncryptionStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.supported = source["supported"];
+	        this.enabled = source["enabled"];
+	        this.locked = source["locked"];
+	    }
+	}
+	export class HistoryStatus {
+	    can_undo: boolean;
+	    can_redo: boolean;
+	    undo_label: string;
+	    redo_label: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new HistoryStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.can_undo = source["can_undo"];
+	        this.can_redo = source["can_redo"];
+	        this.undo_label = source["undo_label"];
+	        this.redo_label = source["redo_label"];
+	    }
+	}
+	export class ImportReport {
+	    dry_run: boolean;
+	    created: number;
+	    updated: number;
+	    skipped: number;
+	    failed: number;
+	    items: transfer.ReportItem[];
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.dry_run = source["dry_run"];
+	        this.created = source["created"];
+	        this.updated = source["updated"];
+	        this.skipped = source["skipped"];
+	        this.failed = source["failed"];
+	        this.items = this.convertValues(source["items"], transfer.ReportItem);
+	        this.error = source["error"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Progress {
+	    done: number;
+	    total: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Progress(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.done = source["done"];
+	        this.total = source["total"];
+	    }
+	}
+	export class SearchResult {
+	    id: number;
+	    title: string;
+	    description: string;
+	    completed: boolean;
+	    priority: string;
+	    due_date: time.Time;
+	    parent_id: number;
+	    recurrence: string;
+	    reminders: number[];
+	    tags: string[];
+	    created_at: time.Time;
+	    deleted_at: time.Time;
+	    rank: number;
+	    title_highlight: string;
+	    description_highlight: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SearchResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.completed = source["completed"];
+	        this.priority = source["priority"];
+	        this.due_date = this.convertValues(source["due_date"], time.Time);
+	        this.parent_id = source["parent_id"];
+	        this.recurrence = source["recurrence"];
+	        this.reminders = source["reminders"];
+	        this.tags = source["tags"];
+	        this.created_at = this.convertValues(source["created_at"], time.Time);
+	        this.deleted_at = this.convertValues(source["deleted_at"], time.Time);
+	        this.rank = source["rank"];
+	        this.title_highlight = source["title_highlight"];
+	        this.description_highlight = source["description_highlight"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class Tag {
+	    name: string;
+	    color: string;
+	    count: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new Tag(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.color = source["color"];
+	        this.count = source["count"];
+	    }
+	}
 	export class Task {
 	    id: number;
 	    title: string;
 	    description: string;
 	    completed: boolean;
 	    priority: string;
-	    // Go type: time
-	    due_date: any;
-	    // Go type: time
-	    created_at: any;
+	    due_date: time.Time;
+	    parent_id: number;
+	    recurrence: string;
+	    reminders: number[];
+	    tags: string[];
+	    created_at: time.Time;
+	    deleted_at: time.Time;
 	
 	    static createFrom(source: any = {}) {
 	        return new Task(source);
@@ -22,8 +251,13 @@ export namespace backend {
 	        this.description = source["description"];
 	        this.completed = source["completed"];
 	        this.priority = source["priority"];
-	        this.due_date = this.convertValues(source["due_date"], null);
-	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.due_date = this.convertValues(source["due_date"], time.Time);
+	        this.parent_id = source["parent_id"];
+	        this.recurrence = source["recurrence"];
+	        this.reminders = source["reminders"];
+	        this.tags = source["tags"];
+	        this.created_at = this.convertValues(source["created_at"], time.Time);
+	        this.deleted_at = this.convertValues(source["deleted_at"], time.Time);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -44,6 +278,157 @@ export namespace backend {
 		    return a;
 		}
 	}
+	export class TaskNode {
+	    id: number;
+	    title: string;
+	    description: string;
+	    completed: boolean;
+	    priority: string;
+	    due_date: time.Time;
+	    parent_id: number;
+	    recurrence: string;
+	    reminders: number[];
+	    tags: string[];
+	    created_at: time.Time;
+	    deleted_at: time.Time;
+	    progress: Progress;
+	    children: TaskNode[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskNode(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.title = source["title"];
+	        this.description = source["description"];
+	        this.completed = source["completed"];
+	        this.priority = source["priority"];
+	        this.due_date = this.convertValues(source["due_date"], time.Time);
+	        this.parent_id = source["parent_id"];
+	        this.recurrence = source["recurrence"];
+	        this.reminders = source["reminders"];
+	        this.tags = source["tags"];
+	        this.created_at = this.convertValues(source["created_at"], time.Time);
+	        this.deleted_at = this.convertValues(source["deleted_at"], time.Time);
+	        this.progress = this.convertValues(source["progress"], Progress);
+	        this.children = this.convertValues(source["children"], TaskNode);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TaskPage {
+	    tasks: Task[];
+	    next_cursor: string;
+	    prev_cursor: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TaskPage(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tasks = this.convertValues(source["tasks"], Task);
+	        this.next_cursor = source["next_cursor"];
+	        this.prev_cursor = source["prev_cursor"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace models {
+	
+	export class FieldChange {
+	    field: string;
+	    old: string;
+	    new: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new FieldChange(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.field = source["field"];
+	        this.old = source["old"];
+	        this.new = source["new"];
+	    }
+	}
+
+}
+
+export namespace reminder {
+	
+	export class Settings {
+	    enabled: boolean;
+	    default_offsets: number[];
+	    quiet_start: string;
+	    quiet_end: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Settings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.default_offsets = source["default_offsets"];
+	        this.quiet_start = source["quiet_start"];
+	        this.quiet_end = source["quiet_end"];
+	    }
+	}
+
+}
+
+export namespace time {
+	
+	export class Time {
+	
+	
+	    static createFrom(source: any = {}) {
+	        return new Time(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	
+	    }
+	}
 
 }
 
@@ -2,6 +2,7 @@ package main
 
 import (
 	"embed"
+	"os"
 	"todo-list/backend/cmd"
 )
 
@@ -9,5 +10,5 @@ import (
 var Assets embed.FS
 
 func main() {
-	cmd.Start(Assets)
+	os.Exit(cmd.Execute(Assets, os.Args[1:]))
 }